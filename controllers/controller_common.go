@@ -25,8 +25,10 @@ import (
 
 	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
 	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+	"github.com/banzaicloud/kafka-operator/pkg/cruisecontrol"
 	"github.com/banzaicloud/kafka-operator/pkg/errorfactory"
 	"github.com/banzaicloud/kafka-operator/pkg/kafkaclient"
+	"github.com/banzaicloud/kafka-operator/pkg/scale"
 )
 
 // clusterRefLabel is the label key used for referencing KafkaUsers/KafkaTopics
@@ -37,6 +39,14 @@ var clusterRefLabel = "kafkaCluster"
 // use as var so it can be overwritten from unit tests
 var newKafkaFromCluster = kafkaclient.NewFromCluster
 
+// newCruiseControlScaler points to the function for retrieving cruise control clients,
+// use as var so it can be overwritten from unit tests
+var newCruiseControlScaler = scale.NewCruiseControlScaler
+
+// newCruiseControlClient points to the function for retrieving clients of the REST API of cruise control,
+// use as var so it can be overwritten from unit tests
+var newCruiseControlClient = cruisecontrol.NewFromCluster
+
 // requeueWithError is a convenience wrapper around logging an error message
 // separate from the stacktrace and then passing the error through to the controller
 // manager
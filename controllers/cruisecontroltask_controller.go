@@ -18,6 +18,8 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -68,6 +70,10 @@ func (r *CruiseControlTaskReconciler) Reconcile(ctx context.Context, request ctr
 
 	log.V(1).Info("Reconciling")
 
+	if err := r.updateOperationQueue(instance, log); err != nil {
+		return requeueWithError(log, "failed to update the cruise control operation queue", err)
+	}
+
 	brokersWithRunningCCTask := make(map[string]v1beta1.BrokerState)
 	brokerVolumesWithRunningCCTask := make(map[string]map[string]v1beta1.VolumeState)
 	for brokerId, brokerStatus := range instance.Status.BrokersState {
@@ -112,34 +118,38 @@ func (r *CruiseControlTaskReconciler) Reconcile(ctx context.Context, request ctr
 		}
 	}
 
-	var brokersWithDownscaleRequired []string
-	var brokersWithUpscaleRequired []string
-	brokersWithDiskRebalanceRequired := make(map[string][]string)
-
-	for brokerId, brokerStatus := range instance.Status.BrokersState {
-		if brokerStatus.GracefulActionState.CruiseControlState == v1beta1.GracefulUpscaleRequired {
-			brokersWithUpscaleRequired = append(brokersWithUpscaleRequired, brokerId)
-		} else if brokerStatus.GracefulActionState.CruiseControlState == v1beta1.GracefulDownscaleRequired {
-			brokersWithDownscaleRequired = append(brokersWithDownscaleRequired, brokerId)
-		}
-
-		for mountPath, volumeState := range brokerStatus.GracefulActionState.VolumeStates {
-			if volumeState.CruiseControlVolumeState == v1beta1.GracefulDiskRebalanceRequired {
-				brokersWithDiskRebalanceRequired[brokerId] = append(brokersWithDiskRebalanceRequired[brokerId], mountPath)
-			}
+	batch := nextCruiseControlOperationBatch(instance.Status.CruiseControlOperationQueue, instance.Spec.CruiseControlConfig.CruiseControlTaskSpec, time.Now())
+	if len(batch) == 0 {
+		if len(instance.Status.CruiseControlOperationQueue) > 0 {
+			// the queued operations are waiting for the backoff of their failed tasks
+			return ctrl.Result{
+				RequeueAfter: time.Duration(20) * time.Second,
+			}, nil
 		}
+		return reconciled()
 	}
+	batchBrokerIds := make([]string, 0, len(batch))
+	for _, operation := range batch {
+		batchBrokerIds = append(batchBrokerIds, operation.BrokerId)
+	}
+	log.Info("Submitting queued cruise control operations", "operation", batch[0].Operation, "brokerId(s)", batchBrokerIds)
 
 	var taskId, startTime string
-	switch {
-	case len(brokersWithUpscaleRequired) > 0:
-		err = r.handlePodAddCCTask(instance, brokersWithUpscaleRequired, log)
-	case len(brokersWithDownscaleRequired) > 0:
-		err = r.handlePodDeleteCCTask(instance, brokersWithDownscaleRequired, log)
-	case len(brokersWithDiskRebalanceRequired) > 0:
+	switch batch[0].Operation {
+	case v1beta1.CruiseControlOperationUpscale:
+		err = r.handlePodAddCCTask(instance, batchBrokerIds, log)
+	case v1beta1.CruiseControlOperationDownscale:
+		err = r.handlePodDeleteCCTask(instance, batchBrokerIds, log)
+	case v1beta1.CruiseControlOperationDiskRebalance:
+		brokersWithDiskRebalanceRequired := make(map[string][]string, len(batch))
+		for _, operation := range batch {
+			brokersWithDiskRebalanceRequired[operation.BrokerId] = operation.MountPaths
+		}
 		// create new cc task, set status to running
-		cc := scale.NewCruiseControlScaler(instance.Namespace, instance.Spec.GetKubernetesClusterDomain(), instance.Spec.CruiseControlConfig.CruiseControlEndpoint, instance.Name)
-		taskId, startTime, err = cc.RebalanceDisks(brokersWithDiskRebalanceRequired)
+		var cc scale.CruiseControlScaler
+		if cc, err = newCruiseControlScaler(r.Client, instance); err == nil {
+			taskId, startTime, err = cc.RebalanceDisks(brokersWithDiskRebalanceRequired)
+		}
 		if err != nil {
 			log.Error(err, "executing disk rebalance cc task failed")
 		} else {
@@ -152,6 +162,7 @@ func (r *CruiseControlTaskReconciler) Reconcile(ctx context.Context, request ctr
 						CruiseControlTaskId:      taskId,
 						TaskStarted:              startTime,
 						CruiseControlVolumeState: v1beta1.GracefulDiskRebalanceRunning,
+						FailedAttempts:           instance.Status.BrokersState[brokerId].GracefulActionState.VolumeStates[mountPath].FailedAttempts,
 					}
 				}
 				if len(brokerVolumeState) > 0 {
@@ -165,6 +176,11 @@ func (r *CruiseControlTaskReconciler) Reconcile(ctx context.Context, request ctr
 		}
 	}
 
+	if err == nil {
+		// the submitted operations are no longer required so they are dropped from the queue
+		err = r.updateOperationQueue(instance, log)
+	}
+
 	if err != nil {
 		switch errors.Cause(err).(type) {
 		case errorfactory.CruiseControlNotReady:
@@ -187,30 +203,36 @@ func (r *CruiseControlTaskReconciler) Reconcile(ctx context.Context, request ctr
 	return reconciled()
 }
 func (r *CruiseControlTaskReconciler) handlePodAddCCTask(kafkaCluster *v1beta1.KafkaCluster, brokerIds []string, log logr.Logger) error {
-	cc := scale.NewCruiseControlScaler(kafkaCluster.Namespace, kafkaCluster.Spec.GetKubernetesClusterDomain(), kafkaCluster.Spec.CruiseControlConfig.CruiseControlEndpoint, kafkaCluster.Name)
-	uTaskId, taskStartTime, scaleErr := cc.UpScaleCluster(brokerIds)
+	cc, err := newCruiseControlScaler(r.Client, kafkaCluster)
+	if err != nil {
+		return errorfactory.New(errorfactory.CruiseControlNotReady{}, err, "could not create cruise control client")
+	}
+	policy := kafkaCluster.Spec.CruiseControlConfig.CruiseControlTaskSpec.GetScalingPolicy(v1beta1.CruiseControlOperationUpscale)
+	uTaskId, taskStartTime, scaleErr := cc.UpScaleCluster(brokerIds, scalingOptions(policy))
 	if scaleErr != nil {
 		log.Info("Cannot upscale broker(s)", "brokerId(s)", brokerIds, "error", scaleErr.Error())
 		return errorfactory.New(errorfactory.CruiseControlNotReady{}, scaleErr, fmt.Sprintf("broker id(s): %s", brokerIds))
 	}
 	statusErr := k8sutil.UpdateBrokerStatus(r.Client, brokerIds, kafkaCluster,
-		v1beta1.GracefulActionState{CruiseControlTaskId: uTaskId, CruiseControlState: v1beta1.GracefulUpscaleRunning,
-			TaskStarted: taskStartTime}, log)
+		runningActionStates(kafkaCluster, brokerIds, uTaskId, taskStartTime, v1beta1.GracefulUpscaleRunning), log)
 	if statusErr != nil {
 		return errors.WrapIfWithDetails(statusErr, "could not update status for broker", "id(s)", brokerIds)
 	}
 	return nil
 }
 func (r *CruiseControlTaskReconciler) handlePodDeleteCCTask(kafkaCluster *v1beta1.KafkaCluster, brokerIds []string, log logr.Logger) error {
-	cc := scale.NewCruiseControlScaler(kafkaCluster.Namespace, kafkaCluster.Spec.GetKubernetesClusterDomain(), kafkaCluster.Spec.CruiseControlConfig.CruiseControlEndpoint, kafkaCluster.Name)
-	uTaskId, taskStartTime, err := cc.DownsizeCluster(brokerIds)
+	cc, err := newCruiseControlScaler(r.Client, kafkaCluster)
+	if err != nil {
+		return errorfactory.New(errorfactory.CruiseControlNotReady{}, err, "could not create cruise control client")
+	}
+	policy := kafkaCluster.Spec.CruiseControlConfig.CruiseControlTaskSpec.GetScalingPolicy(v1beta1.CruiseControlOperationDownscale)
+	uTaskId, taskStartTime, err := cc.DownsizeCluster(brokerIds, scalingOptions(policy))
 	if err != nil {
 		log.Info("cruise control communication error during downscaling broker(s)", "id(s)", brokerIds)
 		return errorfactory.New(errorfactory.CruiseControlNotReady{}, err, fmt.Sprintf("broker(s) id(s): %s", brokerIds))
 	}
 	err = k8sutil.UpdateBrokerStatus(r.Client, brokerIds, kafkaCluster,
-		v1beta1.GracefulActionState{CruiseControlTaskId: uTaskId, CruiseControlState: v1beta1.GracefulDownscaleRunning,
-			TaskStarted: taskStartTime}, log)
+		runningActionStates(kafkaCluster, brokerIds, uTaskId, taskStartTime, v1beta1.GracefulDownscaleRunning), log)
 	if err != nil {
 		return errors.WrapIfWithDetails(err, "could not update status for broker(s)", "id(s)", brokerIds)
 	}
@@ -218,6 +240,194 @@ func (r *CruiseControlTaskReconciler) handlePodDeleteCCTask(kafkaCluster *v1beta
 	return nil
 }
 
+// runningActionStates returns the states of the brokers whose task has been submitted to CC, the failed
+// attempts of the operation are kept until the task completes
+func runningActionStates(kafkaCluster *v1beta1.KafkaCluster, brokerIds []string, taskId, taskStarted string, state v1beta1.CruiseControlState) map[string]v1beta1.GracefulActionState {
+	states := make(map[string]v1beta1.GracefulActionState, len(brokerIds))
+	for _, brokerId := range brokerIds {
+		states[brokerId] = v1beta1.GracefulActionState{
+			CruiseControlTaskId: taskId,
+			CruiseControlState:  state,
+			TaskStarted:         taskStarted,
+			FailedAttempts:      kafkaCluster.Status.BrokersState[brokerId].GracefulActionState.FailedAttempts,
+		}
+	}
+	return states
+}
+
+// recordCCTask appends the finished task to the task history of the cluster and updates the task metrics
+func (r *CruiseControlTaskReconciler) recordCCTask(kafkaCluster *v1beta1.KafkaCluster, record v1beta1.CruiseControlTaskRecord, log logr.Logger) {
+	finishedAt := time.Now()
+	record.FinishedAt = finishedAt.Format(time.RFC3339)
+	sort.Slice(record.BrokerIds, func(i, j int) bool { return brokerIdLess(record.BrokerIds[i], record.BrokerIds[j]) })
+
+	labels := []string{kafkaCluster.Namespace, kafkaCluster.Name, string(record.Operation), string(record.Outcome)}
+	if startedAt, err := ccutils.ParseTimeStampToUnixTime(record.StartedAt); err == nil {
+		cruiseControlTaskDuration.WithLabelValues(labels...).Observe(finishedAt.Sub(startedAt).Seconds())
+	}
+	if record.Outcome != v1beta1.CruiseControlTaskOutcomeCompleted {
+		cruiseControlTaskFailures.WithLabelValues(labels...).Inc()
+	}
+
+	history := append(append([]v1beta1.CruiseControlTaskRecord{}, kafkaCluster.Status.CruiseControlTaskHistory...), record)
+	if limit := kafkaCluster.Spec.CruiseControlConfig.CruiseControlTaskSpec.GetTaskHistoryLimit(); len(history) > limit {
+		history = history[len(history)-limit:]
+	}
+	// the history is informational, failing to persist it must not block the operations
+	if err := k8sutil.UpdateCruiseControlTaskHistory(r.Client, kafkaCluster, history, log); err != nil {
+		log.Error(err, "could not record cruise control task", "taskId", record.TaskId)
+	}
+}
+
+// scalingOptions returns the CC request parameters of the scaling policy
+func scalingOptions(policy v1beta1.GracefulScalingPolicy) map[string]string {
+	options := make(map[string]string)
+	if policy.ReplicationThrottle != nil {
+		options["replication_throttle"] = strconv.FormatInt(*policy.ReplicationThrottle, 10)
+	}
+	if policy.ExcludedTopics != "" {
+		options["excluded_topics"] = policy.ExcludedTopics
+	}
+	return options
+}
+
+// updateOperationQueue syncs the queue of the pending operations with the broker states and persists it when it has changed
+func (r *CruiseControlTaskReconciler) updateOperationQueue(kafkaCluster *v1beta1.KafkaCluster, log logr.Logger) error {
+	queue := syncCruiseControlOperationQueue(kafkaCluster.Status.CruiseControlOperationQueue, kafkaCluster.Status.BrokersState, time.Now())
+	if reflect.DeepEqual(queue, kafkaCluster.Status.CruiseControlOperationQueue) {
+		return nil
+	}
+	return k8sutil.UpdateCruiseControlOperationQueue(r.Client, kafkaCluster, queue, log)
+}
+
+type queuedOperationKey struct {
+	brokerId  string
+	operation v1beta1.CruiseControlOperationType
+}
+
+// syncCruiseControlOperationQueue keeps the queued operations which are still required in their order and
+// appends the newly required ones ordered by operation type and broker id
+func syncCruiseControlOperationQueue(queue []v1beta1.CruiseControlOperation, brokersState map[string]v1beta1.BrokerState, now time.Time) []v1beta1.CruiseControlOperation {
+	required := make(map[queuedOperationKey]v1beta1.CruiseControlOperation)
+	for brokerId, brokerStatus := range brokersState {
+		retryAfter := brokerStatus.GracefulActionState.RetryAfter
+		switch brokerStatus.GracefulActionState.CruiseControlState {
+		case v1beta1.GracefulUpscaleRequired:
+			required[queuedOperationKey{brokerId, v1beta1.CruiseControlOperationUpscale}] = v1beta1.CruiseControlOperation{
+				BrokerId: brokerId, Operation: v1beta1.CruiseControlOperationUpscale, RetryAfter: retryAfter,
+			}
+		case v1beta1.GracefulDownscaleRequired:
+			required[queuedOperationKey{brokerId, v1beta1.CruiseControlOperationDownscale}] = v1beta1.CruiseControlOperation{
+				BrokerId: brokerId, Operation: v1beta1.CruiseControlOperationDownscale, RetryAfter: retryAfter,
+			}
+		}
+
+		var mountPaths []string
+		var diskRetryAfter time.Time
+		for mountPath, volumeState := range brokerStatus.GracefulActionState.VolumeStates {
+			if volumeState.CruiseControlVolumeState == v1beta1.GracefulDiskRebalanceRequired {
+				mountPaths = append(mountPaths, mountPath)
+				if volumeRetryAfter, err := time.Parse(time.RFC3339, volumeState.RetryAfter); err == nil && volumeRetryAfter.After(diskRetryAfter) {
+					diskRetryAfter = volumeRetryAfter
+				}
+			}
+		}
+		if len(mountPaths) > 0 {
+			sort.Strings(mountPaths)
+			operation := v1beta1.CruiseControlOperation{
+				BrokerId: brokerId, Operation: v1beta1.CruiseControlOperationDiskRebalance, MountPaths: mountPaths,
+			}
+			if !diskRetryAfter.IsZero() {
+				operation.RetryAfter = diskRetryAfter.Format(time.RFC3339)
+			}
+			required[queuedOperationKey{brokerId, v1beta1.CruiseControlOperationDiskRebalance}] = operation
+		}
+	}
+
+	var synced []v1beta1.CruiseControlOperation
+	for _, operation := range queue {
+		key := queuedOperationKey{operation.BrokerId, operation.Operation}
+		if requiredOperation, ok := required[key]; ok {
+			operation.MountPaths = requiredOperation.MountPaths
+			operation.RetryAfter = requiredOperation.RetryAfter
+			synced = append(synced, operation)
+			delete(required, key)
+		}
+	}
+
+	added := make([]v1beta1.CruiseControlOperation, 0, len(required))
+	for _, operation := range required {
+		operation.EnqueuedAt = now.Format(time.RFC3339)
+		added = append(added, operation)
+	}
+	operationOrder := map[v1beta1.CruiseControlOperationType]int{
+		v1beta1.CruiseControlOperationUpscale:       0,
+		v1beta1.CruiseControlOperationDownscale:     1,
+		v1beta1.CruiseControlOperationDiskRebalance: 2,
+	}
+	sort.Slice(added, func(i, j int) bool {
+		if added[i].Operation != added[j].Operation {
+			return operationOrder[added[i].Operation] < operationOrder[added[j].Operation]
+		}
+		return brokerIdLess(added[i].BrokerId, added[j].BrokerId)
+	})
+
+	return append(synced, added...)
+}
+
+// brokerIdLess orders the broker ids numerically, ids which are not numbers are ordered as strings
+func brokerIdLess(a, b string) bool {
+	aId, aErr := strconv.Atoi(a)
+	bId, bErr := strconv.Atoi(b)
+	if aErr != nil || bErr != nil {
+		return a < b
+	}
+	return aId < bId
+}
+
+// nextCruiseControlOperationBatch returns the queued operations to be submitted in the next CC task, it is only called
+// when no task is running as the CC tasks are executed one at a time. The operation at the head of the queue is
+// batched with the queued operations of the same type up to the limit of its policy, disk rebalances wait for the
+// scaling operations whose policy does not allow them to be interleaved. Operations whose previous task has failed
+// are skipped until their backoff expires.
+func nextCruiseControlOperationBatch(queue []v1beta1.CruiseControlOperation, taskSpec v1beta1.CruiseControlTaskSpec, now time.Time) []v1beta1.CruiseControlOperation {
+	var ready []v1beta1.CruiseControlOperation
+	for _, operation := range queue {
+		if retryAfter, err := time.Parse(time.RFC3339, operation.RetryAfter); err == nil && now.Before(retryAfter) {
+			continue
+		}
+		ready = append(ready, operation)
+	}
+	queue = ready
+	if len(queue) == 0 {
+		return nil
+	}
+
+	next := queue[0].Operation
+	if next == v1beta1.CruiseControlOperationDiskRebalance {
+		for _, operation := range queue {
+			if operation.Operation != v1beta1.CruiseControlOperationDiskRebalance &&
+				!taskSpec.GetScalingPolicy(operation.Operation).InterleaveDiskRebalance {
+				next = operation.Operation
+				break
+			}
+		}
+	}
+
+	limit := len(queue)
+	if maxBrokers := taskSpec.GetScalingPolicy(next).MaxBrokersPerTask; maxBrokers > 0 {
+		limit = int(maxBrokers)
+	}
+
+	var batch []v1beta1.CruiseControlOperation
+	for _, operation := range queue {
+		if operation.Operation == next && len(batch) < limit {
+			batch = append(batch, operation)
+		}
+	}
+	return batch
+}
+
 func (r *CruiseControlTaskReconciler) checkCCTaskState(kafkaCluster *v1beta1.KafkaCluster, brokersState map[string]v1beta1.BrokerState, log logr.Logger) error {
 	if len(brokersState) == 0 {
 		return nil
@@ -239,7 +449,10 @@ func (r *CruiseControlTaskReconciler) checkCCTaskState(kafkaCluster *v1beta1.Kaf
 	}
 
 	// check cc task status
-	cc := scale.NewCruiseControlScaler(kafkaCluster.Namespace, kafkaCluster.Spec.GetKubernetesClusterDomain(), kafkaCluster.Spec.CruiseControlConfig.CruiseControlEndpoint, kafkaCluster.Name)
+	cc, err := newCruiseControlScaler(r.Client, kafkaCluster)
+	if err != nil {
+		return errorfactory.New(errorfactory.CruiseControlNotReady{}, err, "could not create cruise control client")
+	}
 	status, err := cc.GetCCTaskState(ccTaskId)
 	if err != nil {
 		log.Info("Cruise control communication error checking running task", "taskId", ccTaskId)
@@ -249,6 +462,7 @@ func (r *CruiseControlTaskReconciler) checkCCTaskState(kafkaCluster *v1beta1.Kaf
 	if status == v1beta1.CruiseControlTaskNotFound || status == v1beta1.CruiseControlTaskCompletedWithError {
 		// CC task failed or not found in CC,
 		// reschedule it by marking broker CruiseControlState= GracefulUpscaleRequired or GracefulDownscaleRequired
+		errorMessage := fmt.Sprintf("Previous cc task status invalid: %s", status)
 		var brokerIds []string
 		requiredBrokerCCState := make(map[string]v1beta1.GracefulActionState, len(brokersState))
 		for brokerId, brokerState := range brokersState {
@@ -258,11 +472,7 @@ func (r *CruiseControlTaskReconciler) checkCCTaskState(kafkaCluster *v1beta1.Kaf
 			}
 
 			brokerIds = append(brokerIds, brokerId)
-			requiredBrokerCCState[brokerId] = v1beta1.GracefulActionState{
-				CruiseControlState:  requiredCCState,
-				ErrorMessage:        "Previous cc task status invalid",
-				CruiseControlTaskId: ccTaskId,
-			}
+			requiredBrokerCCState[brokerId] = r.failedActionState(kafkaCluster, brokerState.GracefulActionState, requiredCCState, errorMessage)
 		}
 
 		err = k8sutil.UpdateBrokerStatus(r.Client, brokerIds, kafkaCluster, requiredBrokerCCState, log)
@@ -270,7 +480,8 @@ func (r *CruiseControlTaskReconciler) checkCCTaskState(kafkaCluster *v1beta1.Kaf
 		if err != nil {
 			return errors.WrapIfWithDetails(err, "could not update status for broker(s)", "id(s)", strings.Join(brokerIds, ","))
 		}
-		return errorfactory.New(errorfactory.CruiseControlTaskFailure{}, err, "CC task failed", fmt.Sprintf("cc task id: %s", ccTaskId))
+		r.recordCCTask(kafkaCluster, brokersTaskRecord(ccTaskId, brokersState, v1beta1.CruiseControlTaskOutcomeFailed, errorMessage), log)
+		return errorfactory.New(errorfactory.CruiseControlTaskFailure{}, errors.New(errorMessage), "CC task failed", fmt.Sprintf("cc task id: %s", ccTaskId))
 	}
 
 	if status == v1beta1.CruiseControlTaskCompleted {
@@ -291,6 +502,7 @@ func (r *CruiseControlTaskReconciler) checkCCTaskState(kafkaCluster *v1beta1.Kaf
 		if err != nil {
 			return errors.WrapIfWithDetails(err, "could not update status for broker(s)", "id(s)", strings.Join(brokerIds, ","))
 		}
+		r.recordCCTask(kafkaCluster, brokersTaskRecord(ccTaskId, brokersState, v1beta1.CruiseControlTaskOutcomeCompleted, ""), log)
 		return nil
 	}
 	var brokersWithTimedOutCCTask []string
@@ -308,12 +520,8 @@ func (r *CruiseControlTaskReconciler) checkCCTaskState(kafkaCluster *v1beta1.Kaf
 					return err
 				}
 
-				timedOutBrokerCCState[brokerId] = v1beta1.GracefulActionState{
-					CruiseControlState:  requiredCCState,
-					CruiseControlTaskId: brokerState.GracefulActionState.CruiseControlTaskId,
-					ErrorMessage:        "Timed out waiting for the task to complete",
-					TaskStarted:         brokerState.GracefulActionState.TaskStarted,
-				}
+				timedOutBrokerCCState[brokerId] = r.failedActionState(kafkaCluster, brokerState.GracefulActionState, requiredCCState,
+					"Timed out waiting for the task to complete")
 			}
 		}
 	}
@@ -321,7 +529,6 @@ func (r *CruiseControlTaskReconciler) checkCCTaskState(kafkaCluster *v1beta1.Kaf
 	// task timed out
 	if len(brokersWithTimedOutCCTask) > 0 {
 		log.Info("Killing Cruise control task", "taskId", ccTaskId)
-		cc := scale.NewCruiseControlScaler(kafkaCluster.Namespace, kafkaCluster.Spec.GetKubernetesClusterDomain(), kafkaCluster.Spec.CruiseControlConfig.CruiseControlEndpoint, kafkaCluster.Name)
 		err = cc.KillCCTask()
 
 		if err != nil {
@@ -332,6 +539,8 @@ func (r *CruiseControlTaskReconciler) checkCCTaskState(kafkaCluster *v1beta1.Kaf
 		if err != nil {
 			return errors.WrapIfWithDetails(err, "could not update status for broker(s)", "id(s)", strings.Join(brokersWithTimedOutCCTask, ","))
 		}
+		r.recordCCTask(kafkaCluster, brokersTaskRecord(ccTaskId, brokersState, v1beta1.CruiseControlTaskOutcomeTimedOut,
+			"Timed out waiting for the task to complete"), log)
 		return errorfactory.New(errorfactory.CruiseControlTaskTimeout{}, errors.New("cc task timed out"), fmt.Sprintf("cc task id: %s", ccTaskId))
 	}
 
@@ -340,6 +549,42 @@ func (r *CruiseControlTaskReconciler) checkCCTaskState(kafkaCluster *v1beta1.Kaf
 	return errorfactory.New(errorfactory.CruiseControlTaskRunning{}, errors.New("cc task is still running"), fmt.Sprintf("cc task id: %s", ccTaskId))
 }
 
+// failedActionState returns the state of a broker whose task has failed or timed out, the operation is
+// resubmitted after the backoff of its consecutive failures
+func (r *CruiseControlTaskReconciler) failedActionState(kafkaCluster *v1beta1.KafkaCluster, state v1beta1.GracefulActionState,
+	requiredCCState v1beta1.CruiseControlState, errorMessage string) v1beta1.GracefulActionState {
+	failedAttempts := state.FailedAttempts + 1
+	return v1beta1.GracefulActionState{
+		CruiseControlState:  requiredCCState,
+		CruiseControlTaskId: state.CruiseControlTaskId,
+		ErrorMessage:        errorMessage,
+		TaskStarted:         state.TaskStarted,
+		FailedAttempts:      failedAttempts,
+		RetryAfter: time.Now().Add(kafkaCluster.Spec.CruiseControlConfig.CruiseControlTaskSpec.GetRetryBackoff(failedAttempts)).
+			Format(time.RFC3339),
+		VolumeStates: state.VolumeStates,
+	}
+}
+
+// brokersTaskRecord returns the history record of a finished upscale or downscale task
+func brokersTaskRecord(ccTaskId string, brokersState map[string]v1beta1.BrokerState, outcome v1beta1.CruiseControlTaskOutcome,
+	errorMessage string) v1beta1.CruiseControlTaskRecord {
+	record := v1beta1.CruiseControlTaskRecord{TaskId: ccTaskId, Outcome: outcome, ErrorMessage: errorMessage}
+	for brokerId, brokerState := range brokersState {
+		record.BrokerIds = append(record.BrokerIds, brokerId)
+		record.StartedAt = brokerState.GracefulActionState.TaskStarted
+		if brokerState.GracefulActionState.CruiseControlState.IsDownscale() {
+			record.Operation = v1beta1.CruiseControlOperationDownscale
+		} else {
+			record.Operation = v1beta1.CruiseControlOperationUpscale
+		}
+		if attempt := brokerState.GracefulActionState.FailedAttempts + 1; attempt > record.Attempt {
+			record.Attempt = attempt
+		}
+	}
+	return record
+}
+
 // getCorrectRequiredCCState returns the correct Required CC state based on that we upscale or downscale
 func (r *CruiseControlTaskReconciler) getCorrectRequiredCCState(ccState kafkav1beta1.CruiseControlState) (kafkav1beta1.CruiseControlState, error) {
 	if ccState.IsDownscale() {
@@ -373,7 +618,10 @@ func (r *CruiseControlTaskReconciler) checkVolumeCCTaskState(kafkaCluster *v1bet
 	}
 
 	// check cc task status
-	cc := scale.NewCruiseControlScaler(kafkaCluster.Namespace, kafkaCluster.Spec.GetKubernetesClusterDomain(), kafkaCluster.Spec.CruiseControlConfig.CruiseControlEndpoint, kafkaCluster.Name)
+	cc, err := newCruiseControlScaler(r.Client, kafkaCluster)
+	if err != nil {
+		return errorfactory.New(errorfactory.CruiseControlNotReady{}, err, "could not create cruise control client")
+	}
 	status, err := cc.GetCCTaskState(ccTaskId)
 	if err != nil {
 		log.Info("Cruise control communication error checking running task", "taskId", ccTaskId)
@@ -383,26 +631,25 @@ func (r *CruiseControlTaskReconciler) checkVolumeCCTaskState(kafkaCluster *v1bet
 	if status == v1beta1.CruiseControlTaskNotFound || status == v1beta1.CruiseControlTaskCompletedWithError {
 		// CC task failed or not found in CC,
 		// reschedule it by marking volume CruiseControlVolumeState=GracefulDiskRebalanceRequired
+		errorMessage := fmt.Sprintf("Previous disk rebalance cc task status invalid: %s", status)
 		var brokerIds []string
 		requiredBrokerVolumesCCState := make(map[string]map[string]v1beta1.VolumeState, len(brokersVolumesState))
 		for brokerId, volumesState := range brokersVolumesState {
 			brokerIds = append(brokerIds, brokerId)
 
-			volumesState := make(map[string]v1beta1.VolumeState, len(volumesState))
-			for mountPath := range volumesState {
-				volumesState[mountPath] = kafkav1beta1.VolumeState{
-					CruiseControlVolumeState: v1beta1.GracefulDiskRebalanceRequired,
-					ErrorMessage:             "Previous disk rebalance cc task status invalid",
-				}
+			requiredVolumesState := make(map[string]v1beta1.VolumeState, len(volumesState))
+			for mountPath, volumeState := range volumesState {
+				requiredVolumesState[mountPath] = r.failedVolumeState(kafkaCluster, volumeState, errorMessage)
 			}
 
-			requiredBrokerVolumesCCState[brokerId] = volumesState
+			requiredBrokerVolumesCCState[brokerId] = requiredVolumesState
 		}
 		err = k8sutil.UpdateBrokerStatus(r.Client, brokerIds, kafkaCluster, requiredBrokerVolumesCCState, log)
 		if err != nil {
 			return errors.WrapIfWithDetails(err, "could not update status for broker volume(s)", "id(s)", strings.Join(brokerIds, ","))
 		}
-		return errorfactory.New(errorfactory.CruiseControlTaskFailure{}, err, "CC task failed", fmt.Sprintf("cc task id: %s", ccTaskId))
+		r.recordCCTask(kafkaCluster, volumesTaskRecord(ccTaskId, brokersVolumesState, v1beta1.CruiseControlTaskOutcomeFailed, errorMessage), log)
+		return errorfactory.New(errorfactory.CruiseControlTaskFailure{}, errors.New(errorMessage), "CC task failed", fmt.Sprintf("cc task id: %s", ccTaskId))
 	}
 
 	if status == v1beta1.CruiseControlTaskCompleted {
@@ -428,6 +675,7 @@ func (r *CruiseControlTaskReconciler) checkVolumeCCTaskState(kafkaCluster *v1bet
 		if err != nil {
 			return errors.WrapIfWithDetails(err, "could not update status for broker(s)", "id(s)", strings.Join(brokerIds, ","))
 		}
+		r.recordCCTask(kafkaCluster, volumesTaskRecord(ccTaskId, brokersVolumesState, v1beta1.CruiseControlTaskOutcomeCompleted, ""), log)
 		return nil
 	}
 
@@ -445,12 +693,8 @@ func (r *CruiseControlTaskReconciler) checkVolumeCCTaskState(kafkaCluster *v1bet
 				}
 
 				if time.Since(parsedTime).Minutes() > kafkaCluster.Spec.CruiseControlConfig.CruiseControlTaskSpec.GetDurationMinutes() {
-					volumesStateWithTimedOutDiskCCTask[mountPath] = kafkav1beta1.VolumeState{
-						CruiseControlVolumeState: v1beta1.GracefulDiskRebalanceRequired,
-						CruiseControlTaskId:      volumeState.CruiseControlTaskId,
-						ErrorMessage:             "Timed out waiting for the disk rebalance cc task to complete",
-						TaskStarted:              volumeState.TaskStarted,
-					}
+					volumesStateWithTimedOutDiskCCTask[mountPath] = r.failedVolumeState(kafkaCluster, volumeState,
+						"Timed out waiting for the disk rebalance cc task to complete")
 				}
 			}
 		}
@@ -463,8 +707,6 @@ func (r *CruiseControlTaskReconciler) checkVolumeCCTaskState(kafkaCluster *v1bet
 	// task timed out
 	if len(brokersWithTimedOutCCTask) > 0 {
 		log.Info("Killing Cruise control task", "taskId", ccTaskId)
-		cc := scale.NewCruiseControlScaler(kafkaCluster.Namespace, kafkaCluster.Spec.GetKubernetesClusterDomain(), kafkaCluster.Spec.CruiseControlConfig.CruiseControlEndpoint, kafkaCluster.Name)
-
 		err = cc.KillCCTask()
 		if err != nil {
 			return errorfactory.New(errorfactory.CruiseControlNotReady{}, err, "cc communication error")
@@ -473,6 +715,8 @@ func (r *CruiseControlTaskReconciler) checkVolumeCCTaskState(kafkaCluster *v1bet
 		if err != nil {
 			return errors.WrapIfWithDetails(err, "could not update status for broker(s)", "id(s)", strings.Join(brokersWithTimedOutCCTask, ","))
 		}
+		r.recordCCTask(kafkaCluster, volumesTaskRecord(ccTaskId, brokersVolumesState, v1beta1.CruiseControlTaskOutcomeTimedOut,
+			"Timed out waiting for the disk rebalance cc task to complete"), log)
 		return errorfactory.New(errorfactory.CruiseControlTaskTimeout{}, errors.New("cc task timed out"), fmt.Sprintf("cc task id: %s", ccTaskId))
 	}
 
@@ -481,6 +725,39 @@ func (r *CruiseControlTaskReconciler) checkVolumeCCTaskState(kafkaCluster *v1bet
 	return errorfactory.New(errorfactory.CruiseControlTaskRunning{}, errors.New("cc task is still running"), fmt.Sprintf("cc task id: %s", ccTaskId))
 }
 
+// failedVolumeState returns the state of a volume whose disk rebalance task has failed or timed out, the
+// rebalance is resubmitted after the backoff of its consecutive failures
+func (r *CruiseControlTaskReconciler) failedVolumeState(kafkaCluster *v1beta1.KafkaCluster, state v1beta1.VolumeState, errorMessage string) v1beta1.VolumeState {
+	failedAttempts := state.FailedAttempts + 1
+	return v1beta1.VolumeState{
+		CruiseControlVolumeState: v1beta1.GracefulDiskRebalanceRequired,
+		CruiseControlTaskId:      state.CruiseControlTaskId,
+		ErrorMessage:             errorMessage,
+		TaskStarted:              state.TaskStarted,
+		FailedAttempts:           failedAttempts,
+		RetryAfter: time.Now().Add(kafkaCluster.Spec.CruiseControlConfig.CruiseControlTaskSpec.GetRetryBackoff(failedAttempts)).
+			Format(time.RFC3339),
+	}
+}
+
+// volumesTaskRecord returns the history record of a finished disk rebalance task
+func volumesTaskRecord(ccTaskId string, brokersVolumesState map[string]map[string]v1beta1.VolumeState, outcome v1beta1.CruiseControlTaskOutcome,
+	errorMessage string) v1beta1.CruiseControlTaskRecord {
+	record := v1beta1.CruiseControlTaskRecord{
+		TaskId: ccTaskId, Operation: v1beta1.CruiseControlOperationDiskRebalance, Outcome: outcome, ErrorMessage: errorMessage,
+	}
+	for brokerId, volumesState := range brokersVolumesState {
+		record.BrokerIds = append(record.BrokerIds, brokerId)
+		for _, volumeState := range volumesState {
+			record.StartedAt = volumeState.TaskStarted
+			if attempt := volumeState.FailedAttempts + 1; attempt > record.Attempt {
+				record.Attempt = attempt
+			}
+		}
+	}
+	return record
+}
+
 // SetupCruiseControlWithManager registers cruise control controller to the manager
 func SetupCruiseControlWithManager(mgr ctrl.Manager) *ctrl.Builder {
 	builder := ctrl.NewControllerManagedBy(mgr).For(&kafkav1beta1.KafkaCluster{}).Named("CruiseControl")
@@ -0,0 +1,293 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	//nolint:staticcheck
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+	"github.com/banzaicloud/kafka-operator/pkg/scale"
+)
+
+// fakeScalingScaler records the scaling operations submitted to cruise control
+type fakeScalingScaler struct {
+	scale.CruiseControlScaler
+	upscaled  []string
+	options   map[string]string
+	taskState v1beta1.CruiseControlUserTaskState
+}
+
+func (f *fakeScalingScaler) GetCCTaskState(uTaskID string) (v1beta1.CruiseControlUserTaskState, error) {
+	return f.taskState, nil
+}
+
+func (f *fakeScalingScaler) UpScaleCluster(brokerIDs []string, options map[string]string) (string, string, error) {
+	f.upscaled = brokerIDs
+	f.options = options
+	return "task-1", time.Now().Format(time.RFC1123), nil
+}
+
+func brokerWithState(state v1beta1.CruiseControlState) v1beta1.BrokerState {
+	return v1beta1.BrokerState{GracefulActionState: v1beta1.GracefulActionState{CruiseControlState: state}}
+}
+
+func queuedBrokerIds(queue []v1beta1.CruiseControlOperation) []string {
+	brokerIds := make([]string, 0, len(queue))
+	for _, operation := range queue {
+		brokerIds = append(brokerIds, string(operation.Operation)+"/"+operation.BrokerId)
+	}
+	return brokerIds
+}
+
+func TestSyncCruiseControlOperationQueue(t *testing.T) {
+	now := time.Now()
+	brokersState := map[string]v1beta1.BrokerState{
+		"10": brokerWithState(v1beta1.GracefulUpscaleRequired),
+		"2":  brokerWithState(v1beta1.GracefulUpscaleRequired),
+		"3":  brokerWithState(v1beta1.GracefulDownscaleRequired),
+		"4":  brokerWithState(v1beta1.GracefulUpscaleRunning),
+		"5": {GracefulActionState: v1beta1.GracefulActionState{
+			CruiseControlState: v1beta1.GracefulUpscaleSucceeded,
+			VolumeStates: map[string]v1beta1.VolumeState{
+				"/data2": {CruiseControlVolumeState: v1beta1.GracefulDiskRebalanceRequired},
+				"/data1": {CruiseControlVolumeState: v1beta1.GracefulDiskRebalanceRequired},
+				"/data3": {CruiseControlVolumeState: v1beta1.GracefulDiskRebalanceSucceeded},
+			},
+		}},
+	}
+
+	queue := syncCruiseControlOperationQueue(nil, brokersState, now)
+	expected := []string{"Upscale/2", "Upscale/10", "Downscale/3", "DiskRebalance/5"}
+	if got := queuedBrokerIds(queue); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected queue %v, got: %v", expected, got)
+	}
+	if !reflect.DeepEqual(queue[3].MountPaths, []string{"/data1", "/data2"}) {
+		t.Error("Expected the required volumes to be queued, got:", queue[3].MountPaths)
+	}
+
+	// queued operations keep their position, the ones no longer required are dropped
+	brokersState["2"] = brokerWithState(v1beta1.GracefulUpscaleRunning)
+	brokersState["1"] = brokerWithState(v1beta1.GracefulUpscaleRequired)
+	queue = syncCruiseControlOperationQueue(queue, brokersState, now.Add(time.Minute))
+	expected = []string{"Upscale/10", "Downscale/3", "DiskRebalance/5", "Upscale/1"}
+	if got := queuedBrokerIds(queue); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected queue %v, got: %v", expected, got)
+	}
+	if queue[0].EnqueuedAt != now.Format(time.RFC3339) {
+		t.Error("Expected the queued operation to keep its enqueue time, got:", queue[0].EnqueuedAt)
+	}
+
+	if queue := syncCruiseControlOperationQueue(queue, nil, now); queue != nil {
+		t.Error("Expected empty queue, got:", queue)
+	}
+}
+
+func TestNextCruiseControlOperationBatch(t *testing.T) {
+	queue := []v1beta1.CruiseControlOperation{
+		{BrokerId: "5", Operation: v1beta1.CruiseControlOperationDiskRebalance},
+		{BrokerId: "1", Operation: v1beta1.CruiseControlOperationUpscale},
+		{BrokerId: "3", Operation: v1beta1.CruiseControlOperationDownscale},
+		{BrokerId: "2", Operation: v1beta1.CruiseControlOperationUpscale},
+		{BrokerId: "4", Operation: v1beta1.CruiseControlOperationUpscale},
+	}
+
+	// disk rebalances wait for the scaling operations by default
+	batch := nextCruiseControlOperationBatch(queue, v1beta1.CruiseControlTaskSpec{}, time.Now())
+	if got := queuedBrokerIds(batch); !reflect.DeepEqual(got, []string{"Upscale/1", "Upscale/2", "Upscale/4"}) {
+		t.Error("Expected all the upscales to be batched, got:", got)
+	}
+
+	taskSpec := v1beta1.CruiseControlTaskSpec{
+		UpscalePolicy: &v1beta1.GracefulScalingPolicy{MaxBrokersPerTask: 2, InterleaveDiskRebalance: true},
+	}
+	batch = nextCruiseControlOperationBatch(queue, taskSpec, time.Now())
+	if got := queuedBrokerIds(batch); !reflect.DeepEqual(got, []string{"Downscale/3"}) {
+		t.Error("Expected the downscale to precede the disk rebalance, got:", got)
+	}
+
+	taskSpec.DownscalePolicy = &v1beta1.GracefulScalingPolicy{InterleaveDiskRebalance: true}
+	batch = nextCruiseControlOperationBatch(queue, taskSpec, time.Now())
+	if got := queuedBrokerIds(batch); !reflect.DeepEqual(got, []string{"DiskRebalance/5"}) {
+		t.Error("Expected the interleaved disk rebalance, got:", got)
+	}
+
+	batch = nextCruiseControlOperationBatch(queue[1:], taskSpec, time.Now())
+	if got := queuedBrokerIds(batch); !reflect.DeepEqual(got, []string{"Upscale/1", "Upscale/2"}) {
+		t.Error("Expected the upscales to be limited by the policy, got:", got)
+	}
+
+	if batch := nextCruiseControlOperationBatch(nil, taskSpec, time.Now()); batch != nil {
+		t.Error("Expected no batch for empty queue, got:", batch)
+	}
+}
+
+func TestCruiseControlTaskReconcileBatchesUpscale(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	throttle := int64(10485760)
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: testNamespace},
+		Spec: v1beta1.KafkaClusterSpec{
+			CruiseControlConfig: v1beta1.CruiseControlConfig{
+				CruiseControlTaskSpec: v1beta1.CruiseControlTaskSpec{
+					UpscalePolicy: &v1beta1.GracefulScalingPolicy{
+						MaxBrokersPerTask:   1,
+						ReplicationThrottle: &throttle,
+						ExcludedTopics:      "__.*",
+					},
+				},
+			},
+		},
+		Status: v1beta1.KafkaClusterStatus{
+			BrokersState: map[string]v1beta1.BrokerState{
+				"1": brokerWithState(v1beta1.GracefulUpscaleRequired),
+				"2": brokerWithState(v1beta1.GracefulUpscaleRequired),
+			},
+		},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(cluster).Build()
+
+	scaler := &fakeScalingScaler{}
+	newCruiseControlScaler = func(client.Client, *v1beta1.KafkaCluster) (scale.CruiseControlScaler, error) {
+		return scaler, nil
+	}
+	defer func() { newCruiseControlScaler = scale.NewCruiseControlScaler }()
+
+	r := &CruiseControlTaskReconciler{Client: k8sClient, Scheme: scheme, Log: log}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "kafka", Namespace: testNamespace}}
+	if _, err := r.Reconcile(context.TODO(), request); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+
+	if !reflect.DeepEqual(scaler.upscaled, []string{"1"}) {
+		t.Error("Expected a single broker to be upscaled, got:", scaler.upscaled)
+	}
+	expectedOptions := map[string]string{"replication_throttle": "10485760", "excluded_topics": "__.*"}
+	if !reflect.DeepEqual(scaler.options, expectedOptions) {
+		t.Error("Expected the options of the upscale policy, got:", scaler.options)
+	}
+
+	updated := &v1beta1.KafkaCluster{}
+	if err := k8sClient.Get(context.TODO(), request.NamespacedName, updated); err != nil {
+		t.Fatal(err)
+	}
+	if state := updated.Status.BrokersState["1"].GracefulActionState; state.CruiseControlState != v1beta1.GracefulUpscaleRunning || state.CruiseControlTaskId != "task-1" {
+		t.Error("Expected upscale of broker 1 to be running, got:", state)
+	}
+	if got := queuedBrokerIds(updated.Status.CruiseControlOperationQueue); !reflect.DeepEqual(got, []string{"Upscale/2"}) {
+		t.Error("Expected broker 2 to stay queued, got:", got)
+	}
+}
+
+func TestCruiseControlTaskReconcileRetriesFailedTask(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: testNamespace},
+		Status: v1beta1.KafkaClusterStatus{
+			BrokersState: map[string]v1beta1.BrokerState{
+				"1": {GracefulActionState: v1beta1.GracefulActionState{
+					CruiseControlState:  v1beta1.GracefulUpscaleRunning,
+					CruiseControlTaskId: "task-1",
+					TaskStarted:         time.Now().UTC().Format("Mon, 2 Jan 2006 15:04:05 GMT"),
+					FailedAttempts:      1,
+				}},
+			},
+		},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(cluster).Build()
+
+	scaler := &fakeScalingScaler{taskState: v1beta1.CruiseControlTaskCompletedWithError}
+	newCruiseControlScaler = func(client.Client, *v1beta1.KafkaCluster) (scale.CruiseControlScaler, error) {
+		return scaler, nil
+	}
+	defer func() { newCruiseControlScaler = scale.NewCruiseControlScaler }()
+
+	r := &CruiseControlTaskReconciler{Client: k8sClient, Scheme: scheme, Log: log}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "kafka", Namespace: testNamespace}}
+	getCluster := func() *v1beta1.KafkaCluster {
+		updated := &v1beta1.KafkaCluster{}
+		if err := k8sClient.Get(context.TODO(), request.NamespacedName, updated); err != nil {
+			t.Fatal(err)
+		}
+		return updated
+	}
+
+	if _, err := r.Reconcile(context.TODO(), request); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	updated := getCluster()
+	state := updated.Status.BrokersState["1"].GracefulActionState
+	if state.CruiseControlState != v1beta1.GracefulUpscaleRequired || state.FailedAttempts != 2 {
+		t.Error("Expected upscale to be required again after the second failure, got:", state)
+	}
+	if retryAfter, err := time.Parse(time.RFC3339, state.RetryAfter); err != nil || time.Until(retryAfter) < 50*time.Second {
+		t.Error("Expected doubled retry backoff, got:", state.RetryAfter)
+	}
+	history := updated.Status.CruiseControlTaskHistory
+	if len(history) != 1 || history[0].TaskId != "task-1" || history[0].Outcome != v1beta1.CruiseControlTaskOutcomeFailed ||
+		history[0].Operation != v1beta1.CruiseControlOperationUpscale || history[0].Attempt != 2 {
+		t.Error("Expected the failed task to be recorded, got:", history)
+	}
+
+	// the operation waits for its backoff
+	if result, err := r.Reconcile(context.TODO(), request); err != nil || result.RequeueAfter == 0 {
+		t.Error("Expected requeue during the backoff, got:", result, err)
+	}
+	if scaler.upscaled != nil {
+		t.Error("Expected no upscale during the backoff, got:", scaler.upscaled)
+	}
+	if queue := getCluster().Status.CruiseControlOperationQueue; len(queue) != 1 || queue[0].RetryAfter != state.RetryAfter {
+		t.Error("Expected the backing off operation to be queued, got:", queue)
+	}
+
+	// the failed attempts are kept when the operation is resubmitted
+	updated = getCluster()
+	updated.Spec.CruiseControlConfig.CruiseControlTaskSpec.MaxRetryBackoffSeconds = 1
+	if err := k8sClient.Update(context.TODO(), updated); err != nil {
+		t.Fatal(err)
+	}
+	brokerState := updated.Status.BrokersState["1"]
+	brokerState.GracefulActionState.RetryAfter = time.Now().Add(-time.Second).Format(time.RFC3339)
+	updated.Status.BrokersState["1"] = brokerState
+	if err := k8sClient.Status().Update(context.TODO(), updated); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(context.TODO(), request); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if !reflect.DeepEqual(scaler.upscaled, []string{"1"}) {
+		t.Error("Expected the upscale to be resubmitted, got:", scaler.upscaled)
+	}
+	if state := getCluster().Status.BrokersState["1"].GracefulActionState; state.CruiseControlState != v1beta1.GracefulUpscaleRunning || state.FailedAttempts != 2 {
+		t.Error("Expected running upscale keeping the failed attempts, got:", state)
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	cruiseControlTaskDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kafka_operator_cruise_control_task_duration_seconds",
+		Help:    "Duration of the finished CruiseControl tasks of the graceful operations",
+		Buckets: prometheus.ExponentialBuckets(30, 2, 10),
+	}, []string{"namespace", "cluster", "operation", "outcome"})
+
+	cruiseControlTaskFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_operator_cruise_control_task_failures_total",
+		Help: "Number of the failed and timed out CruiseControl tasks of the graceful operations",
+	}, []string{"namespace", "cluster", "operation", "outcome"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(cruiseControlTaskDuration, cruiseControlTaskFailures)
+}
@@ -29,10 +29,14 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/banzaicloud/k8s-objectmatcher/patch"
 
@@ -125,6 +129,7 @@ func (r *KafkaClusterReconciler) Reconcile(ctx context.Context, request ctrl.Req
 		cruisecontrol.New(r.Client, instance),
 	}
 
+	var blocked bool
 	for _, rec := range reconcilers {
 		err = rec.Reconcile(log)
 		if err != nil {
@@ -169,6 +174,14 @@ func (r *KafkaClusterReconciler) Reconcile(ctx context.Context, request ctrl.Req
 				return ctrl.Result{
 					RequeueAfter: time.Duration(30) * time.Second,
 				}, nil
+			case errorfactory.KafkaVersionDowngrade:
+				// the rest of the components are still reconciled
+				log.Info("Kafka version downgrade is blocked", "error", err.Error())
+				blocked = true
+			case errorfactory.BrokerDecommissionBlocked:
+				// the rest of the components are still reconciled
+				log.Info("Broker removal is blocked", "error", err.Error())
+				blocked = true
 			default:
 				return requeueWithError(log, err.Error(), err)
 			}
@@ -180,6 +193,12 @@ func (r *KafkaClusterReconciler) Reconcile(ctx context.Context, request ctrl.Req
 		return requeueWithError(log, "failed to ensure finalizers on kafkacluster instance", err)
 	}
 
+	if blocked {
+		return ctrl.Result{
+			RequeueAfter: time.Duration(60) * time.Second,
+		}, nil
+	}
+
 	//Update rolling upgrade last successful state
 	if instance.Status.State == v1beta1.KafkaClusterRollingUpgrading {
 		if err := k8sutil.UpdateRollingUpgradeState(r.Client, instance, time.Now(), log); err != nil {
@@ -221,20 +240,12 @@ func (r *KafkaClusterReconciler) checkFinalizers(ctx context.Context, log logr.L
 	}
 
 	// If we haven't deleted all kafkatopics yet, iterate namespaces and delete all kafkatopics
-	// with the matching label.
+	// with the matching label, except the ones with orphan deletion policy.
 	if util.StringSliceContains(cluster.GetFinalizers(), clusterTopicsFinalizer) {
 		log.Info(fmt.Sprintf("Sending delete kafkatopics request to all namespaces for cluster %s/%s", cluster.Namespace, cluster.Name))
 		for _, ns := range namespaces {
-			if err := r.Client.DeleteAllOf(
-				ctx,
-				&v1alpha1.KafkaTopic{},
-				client.InNamespace(ns),
-				client.MatchingLabels{clusterRefLabel: clusterLabelString(cluster)},
-			); err != nil {
-				if client.IgnoreNotFound(err) != nil {
-					return requeueWithError(log, "failed to send delete request for children kafkatopics", err)
-				}
-				log.Info(fmt.Sprintf("No matching kafkatopics in namespace: %s", ns))
+			if err := r.deleteClusterTopics(ctx, log, cluster, ns); err != nil {
+				return requeueWithError(log, "failed to send delete request for children kafkatopics", err)
 			}
 		}
 		if cluster, err = r.removeFinalizer(ctx, cluster, clusterTopicsFinalizer); err != nil {
@@ -256,6 +267,7 @@ func (r *KafkaClusterReconciler) checkFinalizers(ctx context.Context, log logr.L
 	); err != nil {
 		return requeueWithError(log, "failed to list kafkatopics", err)
 	}
+	childTopics.Items = withoutOrphanTopics(childTopics.Items)
 	if len(childTopics.Items) > 0 {
 		log.Info(fmt.Sprintf("Still waiting for the following topics to be deleted: %v", topicListToStrSlice(childTopics)))
 		return ctrl.Result{
@@ -316,6 +328,45 @@ func (r *KafkaClusterReconciler) checkFinalizers(ctx context.Context, log logr.L
 	return ctrl.Result{}, nil
 }
 
+// deleteClusterTopics deletes the kafkatopics of the cluster in the namespace, the ones with
+// orphan deletion policy are kept
+func (r *KafkaClusterReconciler) deleteClusterTopics(ctx context.Context, log logr.Logger, cluster *v1beta1.KafkaCluster, namespace string) error {
+	var topics v1alpha1.KafkaTopicList
+	if err := r.Client.List(
+		ctx,
+		&topics,
+		client.InNamespace(namespace),
+		client.MatchingLabels{clusterRefLabel: clusterLabelString(cluster)},
+	); err != nil {
+		return err
+	}
+	if len(topics.Items) == 0 {
+		log.Info(fmt.Sprintf("No matching kafkatopics in namespace: %s", namespace))
+		return nil
+	}
+	for i, topic := range topics.Items {
+		if topic.GetDeletionPolicy() == v1alpha1.TopicDeletionPolicyOrphan {
+			log.Info(fmt.Sprintf("Keeping kafkatopic %s/%s due to its orphan deletion policy", topic.Namespace, topic.Name))
+			continue
+		}
+		if err := r.Client.Delete(ctx, &topics.Items[i]); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withoutOrphanTopics returns the kafkatopics which are not kept on cluster deletion
+func withoutOrphanTopics(topics []v1alpha1.KafkaTopic) []v1alpha1.KafkaTopic {
+	filtered := make([]v1alpha1.KafkaTopic, 0, len(topics))
+	for _, topic := range topics {
+		if topic.GetDeletionPolicy() != v1alpha1.TopicDeletionPolicyOrphan {
+			filtered = append(filtered, topic)
+		}
+	}
+	return filtered
+}
+
 func topicListToStrSlice(list v1alpha1.KafkaTopicList) []string {
 	names := make([]string, 0)
 	for _, topic := range list.Items {
@@ -358,6 +409,7 @@ func SetupKafkaClusterWithManager(mgr ctrl.Manager, log logr.Logger) *ctrl.Build
 	kafkaWatches(builder)
 	envoyWatches(builder)
 	cruiseControlWatches(builder)
+	nodeWatches(builder, mgr.GetClient(), log)
 
 	builder.WithEventFilter(
 		predicate.Funcs{
@@ -387,6 +439,12 @@ func SetupKafkaClusterWithManager(mgr ctrl.Manager, log logr.Logger) *ctrl.Build
 					} else if patchResult.IsEmpty() {
 						return false
 					}
+				case *corev1.Node:
+					// only the changes affecting the broker capacity of Cruise Control are relevant
+					old := e.ObjectOld.(*corev1.Node)
+					new := e.ObjectNew.(*corev1.Node)
+					return !reflect.DeepEqual(old.Status.Allocatable, new.Status.Allocatable) ||
+						!reflect.DeepEqual(old.GetLabels(), new.GetLabels())
 				case *v1beta1.KafkaCluster:
 					old := e.ObjectOld.(*v1beta1.KafkaCluster)
 					new := e.ObjectNew.(*v1beta1.KafkaCluster)
@@ -427,3 +485,22 @@ func cruiseControlWatches(builder *ctrl.Builder) *ctrl.Builder {
 		Owns(&appsv1.Deployment{}).
 		Owns(&corev1.ConfigMap{})
 }
+
+// nodeWatches enqueues every KafkaCluster when a node changes so that the broker capacity of Cruise Control stays up to date
+func nodeWatches(builder *ctrl.Builder, c client.Client, log logr.Logger) *ctrl.Builder {
+	return builder.Watches(&source.Kind{Type: &corev1.Node{}}, handler.EnqueueRequestsFromMapFunc(
+		func(client.Object) []reconcile.Request {
+			clusters := &v1beta1.KafkaClusterList{}
+			if err := c.List(context.TODO(), clusters); err != nil {
+				log.Error(err, "could not list kafka clusters on node change")
+				return nil
+			}
+			requests := make([]reconcile.Request, 0, len(clusters.Items))
+			for _, cluster := range clusters.Items {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace},
+				})
+			}
+			return requests
+		}))
+}
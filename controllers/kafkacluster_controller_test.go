@@ -0,0 +1,73 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	//nolint:staticcheck
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
+	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+)
+
+func TestDeleteClusterTopics(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	cluster := &v1beta1.KafkaCluster{ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: testNamespace}}
+	newTopic := func(name string, policy v1alpha1.TopicDeletionPolicy) *v1alpha1.KafkaTopic {
+		return &v1alpha1.KafkaTopic{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: testNamespace,
+				Labels:    applyClusterRefLabel(cluster, nil),
+			},
+			Spec: v1alpha1.KafkaTopicSpec{Name: name, DeletionPolicy: policy},
+		}
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newTopic("deleted", ""),
+		newTopic("retained", v1alpha1.TopicDeletionPolicyRetain),
+		newTopic("orphaned", v1alpha1.TopicDeletionPolicyOrphan),
+	).Build()
+
+	r := &KafkaClusterReconciler{Client: k8sClient}
+	if err := r.deleteClusterTopics(context.TODO(), log, cluster, testNamespace); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+
+	var topics v1alpha1.KafkaTopicList
+	if err := k8sClient.List(context.TODO(), &topics, client.InNamespace(testNamespace)); err != nil {
+		t.Fatal(err)
+	}
+	if names := topicListToStrSlice(topics); len(names) != 1 || names[0] != testNamespace+"/orphaned" {
+		t.Error("Expected only the orphaned kafkatopic to be kept, got:", names)
+	}
+	if remaining := withoutOrphanTopics(topics.Items); len(remaining) != 0 {
+		t.Error("Expected orphaned kafkatopics not to be waited for, got:", remaining)
+	}
+
+	if err := r.deleteClusterTopics(context.TODO(), log, cluster, "empty-namespace"); err != nil {
+		t.Error("Expected no error for namespace without kafkatopics, got:", err)
+	}
+}
@@ -0,0 +1,302 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
+	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+	"github.com/banzaicloud/kafka-operator/pkg/cruisecontrol"
+	"github.com/banzaicloud/kafka-operator/pkg/k8sutil"
+	"github.com/banzaicloud/kafka-operator/pkg/scale"
+)
+
+// rebalanceRequeueInterval is the interval the state of the submitted cruise control tasks is checked with
+const rebalanceRequeueInterval = 20 * time.Second
+
+// preferredLeaderElectionGoal is the cruise control goal moving the leadership to the preferred leaders
+const preferredLeaderElectionGoal = "PreferredLeaderElectionGoal"
+
+// SetupKafkaRebalanceWithManager registers kafka rebalance controller with manager
+func SetupKafkaRebalanceWithManager(mgr ctrl.Manager) error {
+	r := &KafkaRebalanceReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("KafkaRebalance"),
+	}
+
+	// the status updates of the controller are filtered out, the cancellation is requested with an annotation
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.KafkaRebalance{}).
+		Named("KafkaRebalance").
+		WithEventFilter(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{})).
+		Complete(r)
+}
+
+// blank assignment to verify that KafkaRebalanceReconciler implements reconcile.Reconciler
+var _ reconcile.Reconciler = &KafkaRebalanceReconciler{}
+
+// KafkaRebalanceReconciler submits the operations requested by KafkaRebalances to cruise control
+// and follows the progress of their cruise control user tasks
+type KafkaRebalanceReconciler struct {
+	Client client.Client
+	Log    logr.Logger
+}
+
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkarebalances,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkarebalances/status,verbs=get;update;patch
+
+// Reconcile reconciles the kafka rebalance
+func (r *KafkaRebalanceReconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := r.Log.WithValues("kafkarebalance", request.NamespacedName)
+
+	instance := &v1alpha1.KafkaRebalance{}
+	if err := r.Client.Get(ctx, request.NamespacedName, instance); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconciled()
+		}
+		return requeueWithError(reqLogger, err.Error(), err)
+	}
+
+	// Finished operations are kept for their status, a new KafkaRebalance has to be created to run them again
+	if instance.Status.IsFinished() {
+		return reconciled()
+	}
+
+	clusterNamespace := getClusterRefNamespace(instance.Namespace, instance.Spec.ClusterRef)
+	cluster, err := k8sutil.LookupKafkaCluster(r.Client, instance.Spec.ClusterRef.Name, clusterNamespace)
+	if err != nil {
+		return requeueWithError(reqLogger, "failed to lookup referenced cluster", err)
+	}
+	cc, err := newCruiseControlScaler(r.Client, cluster)
+	if err != nil {
+		return requeueWithError(reqLogger, "failed to create cruise control client", err)
+	}
+
+	// an approval given to a proposal which has been computed for a previous spec is revoked
+	if instance.IsApproved() && instance.Status.Proposal != nil && instance.Status.ProposalGeneration != instance.Generation {
+		reqLogger.Info("Revoking approval of outdated proposal")
+		delete(instance.Annotations, v1alpha1.ApproveRebalanceAnnotation)
+		if err := r.Client.Update(ctx, instance); err != nil {
+			return requeueWithError(reqLogger, "failed to revoke approval of kafkarebalance", err)
+		}
+	}
+
+	status := instance.Status.DeepCopy()
+	if err = r.progressRebalance(reqLogger, cc, cluster, instance, status); err != nil {
+		reqLogger.Info("Cruise control communication error", "error", err.Error())
+		status.Message = fmt.Sprintf("Cruise control is not available: %s", err)
+	}
+
+	if !reflect.DeepEqual(instance.Status, *status) {
+		instance.Status = *status
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			return requeueWithError(reqLogger, "failed to update kafkarebalance status", err)
+		}
+	}
+
+	if status.IsFinished() {
+		reqLogger.Info("Cruise control operation finished", "state", status.State, "taskId", status.TaskID)
+		return reconciled()
+	}
+	// the approval annotation triggers the next reconcile
+	if status.State == v1alpha1.RebalanceStateProposalReady {
+		return reconciled()
+	}
+	return ctrl.Result{RequeueAfter: rebalanceRequeueInterval}, nil
+}
+
+// progressRebalance submits, cancels or follows the cruise control task of the operation and sets its
+// state in the status, errors are returned when cruise control can not be reached
+func (r *KafkaRebalanceReconciler) progressRebalance(reqLogger logr.Logger, cc scale.CruiseControlScaler, cluster *v1beta1.KafkaCluster,
+	rebalance *v1alpha1.KafkaRebalance, status *v1alpha1.KafkaRebalanceStatus) error {
+	switch {
+	case rebalance.IsCancellationRequested():
+		// the operation is cancelled before being submitted when no task id is known
+		if status.TaskID != "" {
+			taskState, err := cc.GetCCTaskState(status.TaskID)
+			if err != nil {
+				return err
+			}
+			// cruise control stops the execution of whichever task is running, so it is only stopped
+			// while the task of the operation is the one being executed
+			if taskState == v1beta1.CruiseControlTaskActive || taskState == v1beta1.CruiseControlTaskInExecution {
+				if err := cc.KillCCTask(); err != nil {
+					return err
+				}
+				reqLogger.Info("Stopped cruise control task", "taskId", status.TaskID)
+			}
+		}
+		status.State = v1alpha1.RebalanceStateCancelled
+		status.FinishedAt = time.Now().Format(time.RFC3339)
+		status.Message = "Operation has been cancelled"
+	case status.TaskID == "":
+		if rebalance.Spec.IsBrokerOperation() && len(rebalance.Spec.BrokerIDs) == 0 {
+			status.State = v1alpha1.RebalanceStateFailed
+			status.FinishedAt = time.Now().Format(time.RFC3339)
+			status.Message = fmt.Sprintf("No brokers are given for the %s operation", rebalance.Spec.GetOperation())
+			return nil
+		}
+		if rebalance.Spec.RequireApproval {
+			// the proposal is recomputed when the spec has changed since it has been reviewed
+			if status.Proposal == nil || status.ProposalGeneration != rebalance.Generation {
+				return proposeRebalance(reqLogger, cc, rebalance, status)
+			}
+			if !rebalance.IsApproved() {
+				status.State = v1alpha1.RebalanceStateProposalReady
+				status.Message = "Waiting for the approval of the proposal"
+				return nil
+			}
+		}
+		if hasRunningCruiseControlTask(cluster) {
+			status.State = v1alpha1.RebalanceStatePending
+			status.Message = "Waiting for the running cruise control tasks of the cluster to finish"
+			return nil
+		}
+		taskID, summary, err := submitRebalance(cc, rebalance.Spec)
+		if err != nil {
+			status.State = v1alpha1.RebalanceStatePending
+			return err
+		}
+		reqLogger.Info("Submitted cruise control operation", "operation", rebalance.Spec.GetOperation(), "taskId", taskID)
+		status.State = v1alpha1.RebalanceStateActive
+		status.TaskID = taskID
+		status.StartedAt = time.Now().Format(time.RFC3339)
+		status.Summary = summary
+		status.Message = ""
+	default:
+		taskState, err := cc.GetCCTaskState(status.TaskID)
+		if err != nil {
+			return err
+		}
+		status.Message = ""
+		switch taskState {
+		case v1beta1.CruiseControlTaskActive:
+			status.State = v1alpha1.RebalanceStateActive
+		case v1beta1.CruiseControlTaskInExecution:
+			status.State = v1alpha1.RebalanceStateInExecution
+		case v1beta1.CruiseControlTaskCompleted:
+			status.State = v1alpha1.RebalanceStateCompleted
+		case v1beta1.CruiseControlTaskCompletedWithError:
+			status.State = v1alpha1.RebalanceStateCompletedWithError
+			status.Message = "Cruise control task has been completed with error"
+		case v1beta1.CruiseControlTaskNotFound:
+			status.State = v1alpha1.RebalanceStateFailed
+			status.Message = "Cruise control task is not found, cruise control may have been restarted"
+		default:
+			return fmt.Errorf("unknown cruise control task state %q", taskState)
+		}
+		if status.IsFinished() {
+			status.FinishedAt = time.Now().Format(time.RFC3339)
+		}
+	}
+	return nil
+}
+
+// proposeRebalance requests the dry run proposal of the operation from cruise control, the proposal
+// is polled with the id of the proposal task until cruise control has computed it
+func proposeRebalance(reqLogger logr.Logger, cc scale.CruiseControlScaler, rebalance *v1alpha1.KafkaRebalance, status *v1alpha1.KafkaRebalanceStatus) error {
+	taskID := status.ProposalTaskID
+	if status.ProposalGeneration != rebalance.Generation {
+		taskID = ""
+	}
+	taskID, result, err := requestProposal(cc, rebalance.Spec, taskID)
+	if err != nil {
+		return err
+	}
+	status.ProposalTaskID = taskID
+	status.ProposalGeneration = rebalance.Generation
+	status.Proposal = result.ProposalSummary()
+	if result == nil {
+		status.State = v1alpha1.RebalanceStateProposalPending
+		status.Message = "Cruise control is computing the proposal"
+		return nil
+	}
+	reqLogger.Info("Proposal of the cruise control operation is ready", "operation", rebalance.Spec.GetOperation(),
+		"replicaMovements", result.Summary.NumReplicaMovements, "leaderMovements", result.Summary.NumLeaderMovements,
+		"dataToMoveMB", result.Summary.DataToMoveMB, "violatedGoals", result.ViolatedGoals())
+	status.State = v1alpha1.RebalanceStateProposalReady
+	status.Message = "Waiting for the approval of the proposal"
+	return nil
+}
+
+// requestProposal requests the dry run proposal of the operation from cruise control
+func requestProposal(cc scale.CruiseControlScaler, spec v1alpha1.KafkaRebalanceSpec, taskID string) (string, *cruisecontrol.OptimizationResult, error) {
+	switch spec.GetOperation() {
+	case v1alpha1.RebalanceOperationLeaderElection:
+		return cc.ProposeRebalance([]string{preferredLeaderElectionGoal}, spec.Options, taskID)
+	case v1alpha1.RebalanceOperationDemoteBrokers:
+		return cc.ProposeDemotion(rebalanceBrokerIDs(spec), spec.Options, taskID)
+	case v1alpha1.RebalanceOperationAddBrokers:
+		return cc.ProposeUpScale(rebalanceBrokerIDs(spec), spec.Options, taskID)
+	case v1alpha1.RebalanceOperationRemoveBrokers:
+		return cc.ProposeDownsize(rebalanceBrokerIDs(spec), spec.Options, taskID)
+	default:
+		return cc.ProposeRebalance(spec.Goals, spec.Options, taskID)
+	}
+}
+
+// submitRebalance submits the requested operation to cruise control
+func submitRebalance(cc scale.CruiseControlScaler, spec v1alpha1.KafkaRebalanceSpec) (string, *v1alpha1.RebalanceProposalSummary, error) {
+	switch spec.GetOperation() {
+	case v1alpha1.RebalanceOperationLeaderElection:
+		return cc.RebalanceWithGoals([]string{preferredLeaderElectionGoal}, spec.Options)
+	case v1alpha1.RebalanceOperationDemoteBrokers:
+		return cc.DemoteBrokers(rebalanceBrokerIDs(spec), spec.Options)
+	case v1alpha1.RebalanceOperationAddBrokers:
+		taskID, _, err := cc.UpScaleCluster(rebalanceBrokerIDs(spec), spec.Options)
+		return taskID, nil, err
+	case v1alpha1.RebalanceOperationRemoveBrokers:
+		taskID, _, err := cc.DownsizeCluster(rebalanceBrokerIDs(spec), spec.Options)
+		return taskID, nil, err
+	default:
+		return cc.RebalanceWithGoals(spec.Goals, spec.Options)
+	}
+}
+
+func rebalanceBrokerIDs(spec v1alpha1.KafkaRebalanceSpec) []string {
+	brokerIDs := make([]string, 0, len(spec.BrokerIDs))
+	for _, brokerID := range spec.BrokerIDs {
+		brokerIDs = append(brokerIDs, strconv.Itoa(int(brokerID)))
+	}
+	return brokerIDs
+}
+
+// hasRunningCruiseControlTask returns true if the operator is running a cruise control task on the cluster
+// for the graceful scaling or the disk rebalance of the brokers
+func hasRunningCruiseControlTask(cluster *v1beta1.KafkaCluster) bool {
+	for _, brokerState := range cluster.Status.BrokersState {
+		if brokerState.GracefulActionState.CruiseControlState.IsRunningState() {
+			return true
+		}
+		for _, volumeState := range brokerState.GracefulActionState.VolumeStates {
+			if volumeState.CruiseControlVolumeState == v1beta1.GracefulDiskRebalanceRunning {
+				return true
+			}
+		}
+	}
+	return false
+}
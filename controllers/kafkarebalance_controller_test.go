@@ -0,0 +1,295 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	//nolint:staticcheck
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
+	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+	"github.com/banzaicloud/kafka-operator/pkg/cruisecontrol"
+	"github.com/banzaicloud/kafka-operator/pkg/scale"
+)
+
+// fakeCruiseControlScaler records the operations submitted to cruise control
+type fakeCruiseControlScaler struct {
+	scale.CruiseControlScaler
+	goals     []string
+	demoted   []string
+	killed    bool
+	taskState v1beta1.CruiseControlUserTaskState
+	// proposal is returned by the dry runs, the proposal is pending while it is nil
+	proposal      *cruisecontrol.OptimizationResult
+	proposalTasks []string
+}
+
+func (f *fakeCruiseControlScaler) ProposeRebalance(goals []string, options map[string]string, uTaskID string) (string, *cruisecontrol.OptimizationResult, error) {
+	f.proposalTasks = append(f.proposalTasks, uTaskID)
+	return "proposal-1", f.proposal, nil
+}
+
+func (f *fakeCruiseControlScaler) ProposeDownsize(brokerIDs []string, options map[string]string, uTaskID string) (string, *cruisecontrol.OptimizationResult, error) {
+	f.proposalTasks = append(f.proposalTasks, uTaskID)
+	return "proposal-2", f.proposal, nil
+}
+
+func (f *fakeCruiseControlScaler) DownsizeCluster(brokerIDs []string, options map[string]string) (string, string, error) {
+	f.demoted = brokerIDs
+	return "task-3", "", nil
+}
+
+func (f *fakeCruiseControlScaler) RebalanceWithGoals(goals []string, options map[string]string) (string, *v1alpha1.RebalanceProposalSummary, error) {
+	f.goals = goals
+	return "task-1", &v1alpha1.RebalanceProposalSummary{NumReplicaMovements: 3}, nil
+}
+
+func (f *fakeCruiseControlScaler) DemoteBrokers(brokerIDs []string, options map[string]string) (string, *v1alpha1.RebalanceProposalSummary, error) {
+	f.demoted = brokerIDs
+	return "task-2", nil, nil
+}
+
+func (f *fakeCruiseControlScaler) KillCCTask() error {
+	f.killed = true
+	return nil
+}
+
+func (f *fakeCruiseControlScaler) GetCCTaskState(uTaskID string) (v1beta1.CruiseControlUserTaskState, error) {
+	return f.taskState, nil
+}
+
+func newRebalanceTestReconciler(t *testing.T, objects ...runtime.Object) (*KafkaRebalanceReconciler, *fakeCruiseControlScaler) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	cluster := &v1beta1.KafkaCluster{ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: testNamespace}}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(append(objects, cluster)...).Build()
+
+	cc := &fakeCruiseControlScaler{}
+	newCruiseControlScaler = func(k8sclient client.Client, cluster *v1beta1.KafkaCluster) (scale.CruiseControlScaler, error) {
+		return cc, nil
+	}
+	t.Cleanup(func() { newCruiseControlScaler = scale.NewCruiseControlScaler })
+	return &KafkaRebalanceReconciler{Client: k8sClient, Log: log}, cc
+}
+
+func reconcileRebalance(t *testing.T, r *KafkaRebalanceReconciler, name string) *v1alpha1.KafkaRebalance {
+	key := types.NamespacedName{Name: name, Namespace: testNamespace}
+	if _, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	rebalance := &v1alpha1.KafkaRebalance{}
+	if err := r.Client.Get(context.TODO(), key, rebalance); err != nil {
+		t.Fatal(err)
+	}
+	return rebalance
+}
+
+func newTestRebalance(name string, spec v1alpha1.KafkaRebalanceSpec) *v1alpha1.KafkaRebalance {
+	spec.ClusterRef = v1alpha1.ClusterReference{Name: "kafka"}
+	return &v1alpha1.KafkaRebalance{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace}, Spec: spec}
+}
+
+func TestKafkaRebalanceLifecycle(t *testing.T) {
+	r, cc := newRebalanceTestReconciler(t, newTestRebalance("rebalance", v1alpha1.KafkaRebalanceSpec{Goals: []string{"RackAwareGoal"}}))
+
+	rebalance := reconcileRebalance(t, r, "rebalance")
+	if rebalance.Status.State != v1alpha1.RebalanceStateActive || rebalance.Status.TaskID != "task-1" {
+		t.Error("Expected active rebalance with task id, got:", rebalance.Status)
+	}
+	if rebalance.Status.Summary == nil || rebalance.Status.Summary.NumReplicaMovements != 3 {
+		t.Error("Expected proposal summary in status, got:", rebalance.Status.Summary)
+	}
+	if !reflect.DeepEqual(cc.goals, []string{"RackAwareGoal"}) {
+		t.Error("Expected rebalance with the given goals, got:", cc.goals)
+	}
+
+	cc.taskState = v1beta1.CruiseControlTaskInExecution
+	if rebalance = reconcileRebalance(t, r, "rebalance"); rebalance.Status.State != v1alpha1.RebalanceStateInExecution {
+		t.Error("Expected rebalance in execution, got:", rebalance.Status.State)
+	}
+
+	cc.taskState = v1beta1.CruiseControlTaskCompleted
+	if rebalance = reconcileRebalance(t, r, "rebalance"); rebalance.Status.State != v1alpha1.RebalanceStateCompleted || rebalance.Status.FinishedAt == "" {
+		t.Error("Expected completed rebalance with finish time, got:", rebalance.Status)
+	}
+
+	// finished operations are not progressed anymore
+	cc.taskState = v1beta1.CruiseControlTaskNotFound
+	if rebalance = reconcileRebalance(t, r, "rebalance"); rebalance.Status.State != v1alpha1.RebalanceStateCompleted {
+		t.Error("Expected rebalance to stay completed, got:", rebalance.Status.State)
+	}
+}
+
+func TestKafkaRebalanceOperations(t *testing.T) {
+	r, cc := newRebalanceTestReconciler(t,
+		newTestRebalance("election", v1alpha1.KafkaRebalanceSpec{Operation: v1alpha1.RebalanceOperationLeaderElection}),
+		newTestRebalance("demotion", v1alpha1.KafkaRebalanceSpec{Operation: v1alpha1.RebalanceOperationDemoteBrokers, BrokerIDs: []int32{1, 2}}),
+		newTestRebalance("empty-demotion", v1alpha1.KafkaRebalanceSpec{Operation: v1alpha1.RebalanceOperationDemoteBrokers}),
+	)
+
+	reconcileRebalance(t, r, "election")
+	if !reflect.DeepEqual(cc.goals, []string{preferredLeaderElectionGoal}) {
+		t.Error("Expected preferred leader election goal, got:", cc.goals)
+	}
+
+	if rebalance := reconcileRebalance(t, r, "demotion"); rebalance.Status.TaskID != "task-2" {
+		t.Error("Expected demotion task id, got:", rebalance.Status.TaskID)
+	}
+	if !reflect.DeepEqual(cc.demoted, []string{"1", "2"}) {
+		t.Error("Expected brokers 1 and 2 to be demoted, got:", cc.demoted)
+	}
+
+	if rebalance := reconcileRebalance(t, r, "empty-demotion"); rebalance.Status.State != v1alpha1.RebalanceStateFailed {
+		t.Error("Expected demotion without brokers to fail, got:", rebalance.Status.State)
+	}
+}
+
+func TestKafkaRebalanceCancellation(t *testing.T) {
+	rebalance := newTestRebalance("rebalance", v1alpha1.KafkaRebalanceSpec{})
+	rebalance.SetAnnotations(map[string]string{v1alpha1.CancelRebalanceAnnotation: "true"})
+	rebalance.Status = v1alpha1.KafkaRebalanceStatus{State: v1alpha1.RebalanceStateInExecution, TaskID: "task-1"}
+	r, cc := newRebalanceTestReconciler(t, rebalance)
+	cc.taskState = v1beta1.CruiseControlTaskInExecution
+
+	if rebalance = reconcileRebalance(t, r, "rebalance"); rebalance.Status.State != v1alpha1.RebalanceStateCancelled {
+		t.Error("Expected cancelled rebalance, got:", rebalance.Status.State)
+	}
+	if !cc.killed {
+		t.Error("Expected the cruise control task to be stopped")
+	}
+}
+
+func TestKafkaRebalanceCancellationOfFinishedTask(t *testing.T) {
+	rebalance := newTestRebalance("rebalance", v1alpha1.KafkaRebalanceSpec{})
+	rebalance.SetAnnotations(map[string]string{v1alpha1.CancelRebalanceAnnotation: "true"})
+	rebalance.Status = v1alpha1.KafkaRebalanceStatus{State: v1alpha1.RebalanceStateInExecution, TaskID: "task-1"}
+	r, cc := newRebalanceTestReconciler(t, rebalance)
+	cc.taskState = v1beta1.CruiseControlTaskCompleted
+
+	if rebalance = reconcileRebalance(t, r, "rebalance"); rebalance.Status.State != v1alpha1.RebalanceStateCancelled {
+		t.Error("Expected cancelled rebalance, got:", rebalance.Status.State)
+	}
+	// the execution of the other tasks of cruise control must not be stopped
+	if cc.killed {
+		t.Error("Expected the execution of cruise control not to be stopped")
+	}
+}
+
+func TestKafkaRebalanceWaitsForRunningTasks(t *testing.T) {
+	r, cc := newRebalanceTestReconciler(t, newTestRebalance("rebalance", v1alpha1.KafkaRebalanceSpec{}))
+	cluster := &v1beta1.KafkaCluster{}
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: "kafka", Namespace: testNamespace}, cluster); err != nil {
+		t.Fatal(err)
+	}
+	cluster.Status.BrokersState = map[string]v1beta1.BrokerState{
+		"0": {GracefulActionState: v1beta1.GracefulActionState{CruiseControlState: v1beta1.GracefulUpscaleRunning}},
+	}
+	if err := r.Client.Update(context.TODO(), cluster); err != nil {
+		t.Fatal(err)
+	}
+
+	if rebalance := reconcileRebalance(t, r, "rebalance"); rebalance.Status.State != v1alpha1.RebalanceStatePending {
+		t.Error("Expected pending rebalance, got:", rebalance.Status.State)
+	}
+	if cc.goals != nil {
+		t.Error("Expected no operation to be submitted, got goals:", cc.goals)
+	}
+}
+
+func TestKafkaRebalanceApproval(t *testing.T) {
+	r, cc := newRebalanceTestReconciler(t, newTestRebalance("rebalance", v1alpha1.KafkaRebalanceSpec{RequireApproval: true}))
+
+	rebalance := reconcileRebalance(t, r, "rebalance")
+	if rebalance.Status.State != v1alpha1.RebalanceStateProposalPending || rebalance.Status.ProposalTaskID != "proposal-1" {
+		t.Error("Expected pending proposal with task id, got:", rebalance.Status)
+	}
+
+	cc.proposal = &cruisecontrol.OptimizationResult{
+		Summary:     cruisecontrol.OptimizationSummary{NumReplicaMovements: 10, NumLeaderMovements: 2, DataToMoveMB: 1024},
+		GoalSummary: []cruisecontrol.GoalResult{{Goal: "RackAwareGoal", Status: "FIXED"}, {Goal: "DiskCapacityGoal", Status: "VIOLATED"}},
+	}
+	rebalance = reconcileRebalance(t, r, "rebalance")
+	if rebalance.Status.State != v1alpha1.RebalanceStateProposalReady {
+		t.Error("Expected proposal to be ready, got:", rebalance.Status.State)
+	}
+	if !reflect.DeepEqual(cc.proposalTasks, []string{"", "proposal-1"}) {
+		t.Error("Expected the pending proposal to be polled with its task id, got:", cc.proposalTasks)
+	}
+	if proposal := rebalance.Status.Proposal; proposal == nil || proposal.DataToMoveMB != 1024 ||
+		!reflect.DeepEqual(proposal.ViolatedGoals, []string{"DiskCapacityGoal"}) {
+		t.Error("Expected proposal summary with violated goals, got:", proposal)
+	}
+
+	// not executed without approval
+	if rebalance = reconcileRebalance(t, r, "rebalance"); rebalance.Status.TaskID != "" || cc.goals != nil {
+		t.Error("Expected no execution without approval, got:", rebalance.Status)
+	}
+
+	rebalance.SetAnnotations(map[string]string{v1alpha1.ApproveRebalanceAnnotation: "true"})
+	if err := r.Client.Update(context.TODO(), rebalance); err != nil {
+		t.Fatal(err)
+	}
+	if rebalance = reconcileRebalance(t, r, "rebalance"); rebalance.Status.State != v1alpha1.RebalanceStateActive || rebalance.Status.TaskID != "task-1" {
+		t.Error("Expected approved rebalance to be executed, got:", rebalance.Status)
+	}
+}
+
+func TestKafkaRebalanceOutdatedApproval(t *testing.T) {
+	rebalance := newTestRebalance("removal", v1alpha1.KafkaRebalanceSpec{
+		Operation:       v1alpha1.RebalanceOperationRemoveBrokers,
+		BrokerIDs:       []int32{3},
+		RequireApproval: true,
+	})
+	rebalance.Generation = 2
+	rebalance.SetAnnotations(map[string]string{v1alpha1.ApproveRebalanceAnnotation: "true"})
+	rebalance.Status = v1alpha1.KafkaRebalanceStatus{
+		State:              v1alpha1.RebalanceStateProposalReady,
+		Proposal:           &v1alpha1.RebalanceProposalSummary{NumReplicaMovements: 1},
+		ProposalTaskID:     "proposal-1",
+		ProposalGeneration: 1,
+	}
+	r, cc := newRebalanceTestReconciler(t, rebalance)
+	cc.proposal = &cruisecontrol.OptimizationResult{Summary: cruisecontrol.OptimizationSummary{NumReplicaMovements: 5}}
+
+	rebalance = reconcileRebalance(t, r, "removal")
+	if rebalance.IsApproved() {
+		t.Error("Expected approval of the outdated proposal to be revoked")
+	}
+	if !reflect.DeepEqual(cc.proposalTasks, []string{""}) {
+		t.Error("Expected a new proposal to be requested, got:", cc.proposalTasks)
+	}
+	if rebalance.Status.State != v1alpha1.RebalanceStateProposalReady || rebalance.Status.Proposal.NumReplicaMovements != 5 ||
+		rebalance.Status.ProposalGeneration != 2 {
+		t.Error("Expected the new proposal to wait for approval, got:", rebalance.Status)
+	}
+	if cc.demoted != nil {
+		t.Error("Expected no brokers to be removed, got:", cc.demoted)
+	}
+}
@@ -17,11 +17,19 @@ package controllers
 import (
 	"context"
 	"errors"
+	"fmt"
 	"reflect"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -34,17 +42,24 @@ import (
 	"github.com/banzaicloud/kafka-operator/pkg/k8sutil"
 	"github.com/banzaicloud/kafka-operator/pkg/kafkaclient"
 	"github.com/banzaicloud/kafka-operator/pkg/util"
+	kafkautil "github.com/banzaicloud/kafka-operator/pkg/util/kafka"
+	properties "github.com/banzaicloud/kafka-operator/properties/pkg"
 )
 
 var topicFinalizer = "finalizer.kafkatopics.kafka.banzaicloud.io"
 
+// topicReassignmentRequeueInterval is the interval the progress of partition reassignments is checked with
+const topicReassignmentRequeueInterval = 15 * time.Second
+
 // SetupKafkaTopicWithManager registers kafka topic controller with manager
-func SetupKafkaTopicWithManager(mgr ctrl.Manager, maxConcurrentReconciles int) error {
+func SetupKafkaTopicWithManager(mgr ctrl.Manager, maxConcurrentReconciles int, resyncPeriod time.Duration) error {
 	// Create a new controller
 	r := &KafkaTopicReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-		Log:    ctrl.Log.WithName("controllers").WithName("KafkaTopic"),
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		Log:          ctrl.Log.WithName("controllers").WithName("KafkaTopic"),
+		Recorder:     mgr.GetEventRecorderFor("kafkatopic"),
+		ResyncPeriod: resyncPeriod,
 	}
 
 	c, err := controller.New("kafkatopic", mgr, controller.Options{Reconciler: r, MaxConcurrentReconciles: maxConcurrentReconciles})
@@ -71,16 +86,25 @@ type KafkaTopicReconciler struct {
 	Client client.Client
 	Scheme *runtime.Scheme
 	Log    logr.Logger
+	// Recorder emits the events about the configuration changes of the topics
+	Recorder record.EventRecorder
+	// ResyncPeriod is the interval the partition health and effective configuration
+	// of the topics are refreshed in their status at, resync is disabled when zero
+	ResyncPeriod time.Duration
 }
 
 // +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkatopics,verbs=get;list;watch;create;update;patch;delete;deletecollection
 // +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkatopics/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile reconciles the kafka topic
 func (r *KafkaTopicReconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
 	reqLogger := r.Log.WithValues("kafkatopic", request.NamespacedName, "Request.Name", request.Name)
 	reqLogger.Info("Reconciling KafkaTopic")
 	var err error
+	var reassignment *v1alpha1.TopicReassignmentStatus
+	var partitionsChanged bool
+	var configDiff properties.DiffResult
 
 	// Fetch the KafkaTopic instance
 	instance := &v1alpha1.KafkaTopic{}
@@ -110,6 +134,11 @@ func (r *KafkaTopicReconciler) Reconcile(ctx context.Context, request reconcile.
 		return requeueWithError(reqLogger, "failed to lookup referenced cluster", err)
 	}
 
+	// Topics which are kept in kafka are finalized without connecting to the cluster
+	if k8sutil.IsMarkedForDeletion(instance.ObjectMeta) && instance.GetDeletionPolicy() != v1alpha1.TopicDeletionPolicyDelete {
+		return r.checkFinalizers(ctx, reqLogger, nil, instance)
+	}
+
 	// Get a kafka connection
 	broker, close, err := newKafkaFromCluster(r.Client, cluster)
 	if err != nil {
@@ -134,18 +163,47 @@ func (r *KafkaTopicReconciler) Reconcile(ctx context.Context, request reconcile.
 		return requeueWithError(reqLogger, instance.Spec.Name, errors.New("topic is still creating"))
 	}
 
+	// Validate the topic configuration before applying it
+	configErr := kafkautil.ValidateTopicConfig(instance.Spec.Config)
+	if configErr != nil {
+		reqLogger.Info("Topic config is invalid", "error", configErr.Error())
+		r.recordEvent(instance, corev1.EventTypeWarning, "InvalidConfig", fmt.Sprintf("Topic config is not applied: %s", configErr))
+		if existing == nil {
+			return requeueWithError(reqLogger, "failed to create kafka topic with invalid config", configErr)
+		}
+	}
+
 	// we got a topic back
 	if existing != nil {
 		reqLogger.Info("Topic already exists, verifying configuration")
-		// Ensure partition count
-		if changed, err := broker.EnsurePartitionCount(instance.Spec.Name, instance.Spec.Partitions); err != nil {
+		// Ensure partition count and replica assignment, the new partitions show up in the topic metadata
+		// with some delay, so the replica assignment is only verified when the partition count is unchanged
+		if partitionsChanged, err = broker.EnsurePartitionCount(instance.Spec.Name, instance.Spec.Partitions); err != nil {
 			return requeueWithError(reqLogger, "failed to ensure topic partition count", err)
-		} else if changed {
+		} else if partitionsChanged {
 			reqLogger.Info("Increased partition count for topic")
+		} else if reassignment, err = r.ensureReplicaAssignment(reqLogger, broker, instance); err != nil {
+			return requeueWithError(reqLogger, "failed to ensure topic replica assignment", err)
 		}
-		// Ensure topic configurations
-		if err = broker.EnsureTopicConfig(instance.Spec.Name, util.MapStringStringPointer(instance.Spec.Config)); err != nil {
-			return requeueWithError(reqLogger, "failure to ensure topic config", err)
+		// Ensure topic configurations, the replicas of the topic are throttled while being reassigned.
+		// The throttled replicas are dropped from the config once the reassignment is done, the rate
+		// set on the brokers only applies to throttled replicas so it is left in place.
+		config := util.MapStringStringPointer(instance.Spec.Config)
+		if reassignment != nil && instance.Spec.ReassignmentThrottleRate != nil {
+			if err = broker.SetReplicationThrottleRate(*instance.Spec.ReassignmentThrottleRate); err != nil {
+				return requeueWithError(reqLogger, "failed to set replication throttle rate", err)
+			}
+			config[kafkaclient.LeaderThrottledReplicasConfig] = util.StringPointer("*")
+			config[kafkaclient.FollowerThrottledReplicasConfig] = util.StringPointer("*")
+		}
+		if configErr == nil {
+			if configDiff, err = broker.EnsureTopicConfig(instance.Spec.Name, config); err != nil {
+				return requeueWithError(reqLogger, "failure to ensure topic config", err)
+			}
+			if len(configDiff) > 0 {
+				reqLogger.Info("Changed topic config", "keys", configDiff.Keys())
+				r.recordConfigChanges(instance, configDiff)
+			}
 		}
 		reqLogger.Info("Verified partitions and configuration for topic")
 	} else if err = broker.CreateTopic(&kafkaclient.CreateTopicOptions{
@@ -173,18 +231,177 @@ func (r *KafkaTopicReconciler) Reconcile(ctx context.Context, request reconcile.
 	}
 
 	// set topic status as created
-	if instance.Status.State != v1alpha1.TopicStateCreated {
-		instance.Status = v1alpha1.KafkaTopicStatus{State: v1alpha1.TopicStateCreated}
+	status := v1alpha1.KafkaTopicStatus{
+		State:                    v1alpha1.TopicStateCreated,
+		ObservedGeneration:       instance.Generation,
+		LastAppliedConfigChanges: instance.Status.LastAppliedConfigChanges,
+		LastConfigChangeAt:       instance.Status.LastConfigChangeAt,
+	}
+	if len(configDiff) > 0 {
+		status.LastAppliedConfigChanges = topicConfigChanges(configDiff)
+		status.LastConfigChangeAt = time.Now().Format(time.RFC3339)
+	}
+	if existing != nil {
+		if err = r.observeTopicStatus(broker, instance, &status); err != nil {
+			return requeueWithError(reqLogger, "failed to observe kafkatopic status", err)
+		}
+	}
+	status.Reassignment = reassignment
+	status.Conditions = instance.Status.DeepCopy().Conditions
+	setTopicConditions(&status, instance.Spec.Config, existing != nil, configErr)
+	if !reflect.DeepEqual(instance.Status, status) {
+		instance.Status = status
 		if err := r.Client.Status().Update(ctx, instance); err != nil {
 			return requeueWithError(reqLogger, "failed to update kafkatopic status", err)
 		}
 	}
 
+	if reassignment != nil {
+		reqLogger.Info("Partitions of the topic are being reassigned", "partitions", reassignment.Partitions, "bytesRemaining", reassignment.BytesRemaining)
+		return ctrl.Result{RequeueAfter: topicReassignmentRequeueInterval}, nil
+	}
+	if partitionsChanged {
+		return ctrl.Result{RequeueAfter: topicReassignmentRequeueInterval}, nil
+	}
+
 	reqLogger.Info("Ensured topic")
 
+	if r.ResyncPeriod > 0 {
+		return ctrl.Result{RequeueAfter: r.ResyncPeriod}, nil
+	}
 	return reconciled()
 }
 
+// observeTopicStatus fills the partition health and the effective configuration of the topic in the status
+func (r *KafkaTopicReconciler) observeTopicStatus(broker kafkaclient.KafkaClient, topic *v1alpha1.KafkaTopic, status *v1alpha1.KafkaTopicStatus) error {
+	topicMeta, err := broker.DescribeTopic(topic.Spec.Name)
+	if err != nil {
+		return err
+	}
+	observed := broker.TopicMetaToStatus(topicMeta)
+	status.PartitionCount = observed.PartitionCount
+	status.ReplicationFactor = observed.ReplicationFactor
+	status.UnderReplicatedPartitions = observed.UnderReplicatedPartitions
+	status.OfflinePartitions = observed.OfflinePartitions
+	status.LeaderDistribution = observed.LeaderDistribution
+	if status.EffectiveConfig, err = broker.DescribeTopicConfig(topic.Spec.Name); err != nil {
+		return err
+	}
+	return nil
+}
+
+// setTopicConditions sets the conditions of the topic based on the observed status, a newly
+// created topic is not ready until its partitions are observed in the next reconcile
+func setTopicConditions(status *v1alpha1.KafkaTopicStatus, desiredConfig map[string]string, observed bool, configErr error) {
+	ready := metav1.Condition{Type: v1alpha1.TopicConditionReady, Status: metav1.ConditionTrue, Reason: "PartitionsOnline"}
+	switch {
+	case !observed:
+		ready.Status, ready.Reason, ready.Message = metav1.ConditionFalse, "TopicCreating", "Topic has been created, its partitions are not observed yet"
+	case status.OfflinePartitions > 0:
+		ready.Status, ready.Reason = metav1.ConditionFalse, "PartitionsOffline"
+		ready.Message = fmt.Sprintf("%d partitions have no leader", status.OfflinePartitions)
+	}
+	meta.SetStatusCondition(&status.Conditions, ready)
+	if !observed {
+		return
+	}
+
+	underReplicated := metav1.Condition{Type: v1alpha1.TopicConditionUnderReplicated, Status: metav1.ConditionFalse, Reason: "ReplicasInSync"}
+	if status.UnderReplicatedPartitions > 0 {
+		underReplicated.Status, underReplicated.Reason = metav1.ConditionTrue, "ReplicasOutOfSync"
+		underReplicated.Message = fmt.Sprintf("%d partitions have replicas out of sync", status.UnderReplicatedPartitions)
+	}
+	meta.SetStatusCondition(&status.Conditions, underReplicated)
+
+	drifted := make([]string, 0)
+	for key, value := range desiredConfig {
+		if effective, ok := status.EffectiveConfig[key]; !ok || effective != value {
+			drifted = append(drifted, key)
+		}
+	}
+	sort.Strings(drifted)
+	configDrift := metav1.Condition{Type: v1alpha1.TopicConditionConfigDrift, Status: metav1.ConditionFalse, Reason: "ConfigApplied"}
+	switch {
+	case configErr != nil:
+		configDrift.Status, configDrift.Reason = metav1.ConditionTrue, "InvalidConfig"
+		configDrift.Message = configErr.Error()
+	case len(drifted) > 0:
+		configDrift.Status, configDrift.Reason = metav1.ConditionTrue, "ConfigDiffers"
+		configDrift.Message = fmt.Sprintf("Effective value of %s differs from the spec", strings.Join(drifted, ", "))
+	}
+	meta.SetStatusCondition(&status.Conditions, configDrift)
+}
+
+// recordConfigChanges emits an event about the configuration overrides changed on the topic, changes made
+// while the spec has already been reconciled are corrections of drifts introduced outside of the operator
+func (r *KafkaTopicReconciler) recordConfigChanges(topic *v1alpha1.KafkaTopic, diff properties.DiffResult) {
+	drifted := false
+	if topic.Status.State == v1alpha1.TopicStateCreated && topic.Status.ObservedGeneration == topic.Generation {
+		for _, key := range diff.Keys() {
+			// the throttled replicas are managed by the operator during reassignments
+			if key != kafkaclient.LeaderThrottledReplicasConfig && key != kafkaclient.FollowerThrottledReplicasConfig {
+				drifted = true
+				break
+			}
+		}
+	}
+	changes := make([]string, 0, len(diff))
+	for _, change := range topicConfigChanges(diff) {
+		changes = append(changes, fmt.Sprintf("%s: %q -> %q", change.Key, change.Previous, change.Applied))
+	}
+	if drifted {
+		r.recordEvent(topic, corev1.EventTypeWarning, "ConfigDriftCorrected",
+			fmt.Sprintf("Topic config drifted from the spec and has been corrected: %s", strings.Join(changes, ", ")))
+		return
+	}
+	r.recordEvent(topic, corev1.EventTypeNormal, "ConfigApplied", fmt.Sprintf("Topic config has been changed: %s", strings.Join(changes, ", ")))
+}
+
+func (r *KafkaTopicReconciler) recordEvent(topic *v1alpha1.KafkaTopic, eventType, reason, message string) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Event(topic, eventType, reason, message)
+}
+
+// topicConfigChanges converts the difference of the previous and the applied topic config overrides
+func topicConfigChanges(diff properties.DiffResult) []v1alpha1.TopicConfigChange {
+	changes := make([]v1alpha1.TopicConfigChange, 0, len(diff))
+	for _, key := range diff.Keys() {
+		previous, applied := diff[key][0], diff[key][1]
+		changes = append(changes, v1alpha1.TopicConfigChange{
+			Key:      key,
+			Previous: previous.Value(),
+			Applied:  applied.Value(),
+		})
+	}
+	return changes
+}
+
+// ensureReplicaAssignment returns the progress of the ongoing reassignment of the topic, a new reassignment
+// is started when the replicas do not match the replication factor or the partition assignments of the topic
+func (r *KafkaTopicReconciler) ensureReplicaAssignment(reqLogger logr.Logger, broker kafkaclient.KafkaClient, topic *v1alpha1.KafkaTopic) (*v1alpha1.TopicReassignmentStatus, error) {
+	reassignment, err := broker.GetReassignmentStatus(topic.Spec.Name)
+	if err != nil {
+		return nil, err
+	}
+	if reassignment == nil {
+		reassigned, err := broker.EnsureReplicaAssignment(topic.Spec.Name, topic.Spec.ReplicationFactor, topic.Spec.PartitionAssignments)
+		if err != nil || len(reassigned) == 0 {
+			return nil, err
+		}
+		reqLogger.Info("Started partition reassignment", "partitions", reassigned)
+		reassignment = &v1alpha1.TopicReassignmentStatus{Partitions: reassigned}
+	}
+	// keep the start time of the reassignment across reconciles
+	if topic.Status.Reassignment != nil && topic.Status.Reassignment.StartedAt != "" {
+		reassignment.StartedAt = topic.Status.Reassignment.StartedAt
+	} else {
+		reassignment.StartedAt = time.Now().Format(time.RFC3339)
+	}
+	return reassignment, nil
+}
+
 func (r *KafkaTopicReconciler) ensureClusterLabel(ctx context.Context, cluster *v1beta1.KafkaCluster, topic *v1alpha1.KafkaTopic) (*v1alpha1.KafkaTopic, error) {
 	labels := applyClusterRefLabel(cluster, topic.GetLabels())
 	if !reflect.DeepEqual(labels, topic.GetLabels()) {
@@ -225,6 +442,10 @@ func (r *KafkaTopicReconciler) removeFinalizer(ctx context.Context, topic *v1alp
 }
 
 func (r *KafkaTopicReconciler) finalizeKafkaTopic(reqLogger logr.Logger, broker kafkaclient.KafkaClient, topic *v1alpha1.KafkaTopic) error {
+	if policy := topic.GetDeletionPolicy(); policy != v1alpha1.TopicDeletionPolicyDelete {
+		reqLogger.Info("Keeping topic in kafka due to the deletion policy", "deletionPolicy", policy)
+		return nil
+	}
 	exists, err := broker.GetTopic(topic.Spec.Name)
 	if err != nil {
 		return err
@@ -0,0 +1,146 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
+	"github.com/banzaicloud/kafka-operator/pkg/kafkaclient"
+	properties "github.com/banzaicloud/kafka-operator/properties/pkg"
+)
+
+func TestSetTopicConditions(t *testing.T) {
+	status := &v1alpha1.KafkaTopicStatus{State: v1alpha1.TopicStateCreated}
+	setTopicConditions(status, map[string]string{"retention.ms": "1000"}, false, nil)
+	if ready := status.GetCondition(v1alpha1.TopicConditionReady); ready == nil || ready.Status != metav1.ConditionFalse {
+		t.Error("Expected newly created topic not to be ready, got:", ready)
+	}
+	if len(status.Conditions) != 1 {
+		t.Error("Expected only the ready condition for newly created topic, got:", status.Conditions)
+	}
+
+	status.EffectiveConfig = map[string]string{"retention.ms": "1000", "cleanup.policy": "delete"}
+	setTopicConditions(status, map[string]string{"retention.ms": "1000"}, true, nil)
+	for conditionType, expected := range map[string]metav1.ConditionStatus{
+		v1alpha1.TopicConditionReady:           metav1.ConditionTrue,
+		v1alpha1.TopicConditionUnderReplicated: metav1.ConditionFalse,
+		v1alpha1.TopicConditionConfigDrift:     metav1.ConditionFalse,
+	} {
+		if condition := status.GetCondition(conditionType); condition == nil || condition.Status != expected {
+			t.Errorf("Expected %s condition to be %s, got: %v", conditionType, expected, condition)
+		}
+	}
+
+	status.OfflinePartitions = 1
+	status.UnderReplicatedPartitions = 2
+	setTopicConditions(status, map[string]string{"retention.ms": "2000"}, true, nil)
+	for conditionType, expected := range map[string]metav1.ConditionStatus{
+		v1alpha1.TopicConditionReady:           metav1.ConditionFalse,
+		v1alpha1.TopicConditionUnderReplicated: metav1.ConditionTrue,
+		v1alpha1.TopicConditionConfigDrift:     metav1.ConditionTrue,
+	} {
+		if condition := status.GetCondition(conditionType); condition == nil || condition.Status != expected {
+			t.Errorf("Expected %s condition to be %s, got: %v", conditionType, expected, condition)
+		}
+	}
+}
+
+func TestSetTopicConditionsInvalidConfig(t *testing.T) {
+	status := &v1alpha1.KafkaTopicStatus{State: v1alpha1.TopicStateCreated}
+	setTopicConditions(status, map[string]string{"retention.msec": "1000"}, true, errors.New("unknown topic config"))
+	if condition := status.GetCondition(v1alpha1.TopicConditionConfigDrift); condition == nil || condition.Reason != "InvalidConfig" {
+		t.Error("Expected config drift condition due to invalid config, got:", condition)
+	}
+}
+
+func TestRecordConfigChanges(t *testing.T) {
+	current := properties.NewProperties()
+	current.Set("retention.ms", "1000")
+	current.Set("cleanup.policy", "compact")
+	desired := properties.NewProperties()
+	desired.Set("retention.ms", "2000")
+	diff := current.Diff(desired)
+
+	changes := topicConfigChanges(diff)
+	expected := []v1alpha1.TopicConfigChange{
+		{Key: "cleanup.policy", Previous: "compact"},
+		{Key: "retention.ms", Previous: "1000", Applied: "2000"},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected changes %v, got: %v", expected, changes)
+	}
+
+	recorder := record.NewFakeRecorder(10)
+	r := &KafkaTopicReconciler{Recorder: recorder}
+	topic := &v1alpha1.KafkaTopic{}
+	topic.Generation = 2
+
+	// spec has changed since the last reconcile
+	topic.Status = v1alpha1.KafkaTopicStatus{State: v1alpha1.TopicStateCreated, ObservedGeneration: 1}
+	r.recordConfigChanges(topic, diff)
+	if event := <-recorder.Events; !strings.HasPrefix(event, "Normal ConfigApplied") {
+		t.Error("Expected config applied event, got:", event)
+	}
+
+	// spec is unchanged since the last reconcile
+	topic.Status.ObservedGeneration = 2
+	r.recordConfigChanges(topic, diff)
+	if event := <-recorder.Events; !strings.HasPrefix(event, "Warning ConfigDriftCorrected") {
+		t.Error("Expected config drift corrected event, got:", event)
+	}
+
+	// throttled replicas are managed by the operator
+	throttled := properties.NewProperties()
+	throttled.Set(kafkaclient.LeaderThrottledReplicasConfig, "*")
+	r.recordConfigChanges(topic, properties.NewProperties().Diff(throttled))
+	if event := <-recorder.Events; !strings.HasPrefix(event, "Normal ConfigApplied") {
+		t.Error("Expected config applied event, got:", event)
+	}
+}
+
+func TestFinalizeKafkaTopic(t *testing.T) {
+	r := &KafkaTopicReconciler{Log: log}
+	broker, _, _ := kafkaclient.NewMockFromCluster(nil, nil)
+	if err := broker.CreateTopic(&kafkaclient.CreateTopicOptions{Name: "test-topic", Partitions: 1, ReplicationFactor: 1}); err != nil {
+		t.Fatal(err)
+	}
+	topic := &v1alpha1.KafkaTopic{Spec: v1alpha1.KafkaTopicSpec{Name: "test-topic"}}
+
+	for _, policy := range []v1alpha1.TopicDeletionPolicy{v1alpha1.TopicDeletionPolicyRetain, v1alpha1.TopicDeletionPolicyOrphan} {
+		topic.Spec.DeletionPolicy = policy
+		// topics kept in kafka are finalized without a kafka connection
+		if err := r.finalizeKafkaTopic(log, nil, topic); err != nil {
+			t.Errorf("Expected no error with %s policy, got: %v", policy, err)
+		}
+		if detail, _ := broker.GetTopic("test-topic"); detail == nil {
+			t.Errorf("Expected topic to be kept with %s policy", policy)
+		}
+	}
+
+	topic.Spec.DeletionPolicy = ""
+	if err := r.finalizeKafkaTopic(log, broker, topic); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if detail, _ := broker.GetTopic("test-topic"); detail != nil {
+		t.Error("Expected topic to be deleted with the default policy, got:", detail)
+	}
+}
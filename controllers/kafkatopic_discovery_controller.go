@@ -0,0 +1,189 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
+	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+	"github.com/banzaicloud/kafka-operator/pkg/k8sutil"
+	"github.com/banzaicloud/kafka-operator/pkg/util"
+)
+
+// invalidResourceNameChars matches the characters which are replaced in the sanitized resource names
+var invalidResourceNameChars = regexp.MustCompile(`[^a-z0-9-]`)
+
+// SetupKafkaTopicDiscoveryWithManager registers the topic discovery controller to the manager
+func SetupKafkaTopicDiscoveryWithManager(mgr ctrl.Manager, namespace string, period time.Duration) error {
+	r := &KafkaTopicDiscoveryReconciler{
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("KafkaTopicDiscovery"),
+		Namespace: namespace,
+		Period:    period,
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1beta1.KafkaCluster{}).
+		Named("KafkaTopicDiscovery").
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		Complete(r)
+}
+
+// KafkaTopicDiscoveryReconciler generates KafkaTopics adopting the topics of the kafka clusters
+// which are not managed by any KafkaTopic yet
+type KafkaTopicDiscoveryReconciler struct {
+	Client client.Client
+	Log    logr.Logger
+	// Namespace is where the KafkaTopics of the discovered topics are created
+	Namespace string
+	// Period is the interval the topics of the kafka clusters are listed at
+	Period time.Duration
+}
+
+// Reconcile generates KafkaTopics for the unmanaged topics of the kafka cluster
+func (r *KafkaTopicDiscoveryReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	reqLogger := r.Log.WithValues("kafkacluster", request.NamespacedName)
+
+	cluster, err := k8sutil.LookupKafkaCluster(r.Client, request.Name, request.Namespace)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconciled()
+		}
+		return requeueWithError(reqLogger, "failed to lookup kafka cluster", err)
+	}
+	if k8sutil.IsMarkedForDeletion(cluster.ObjectMeta) {
+		return reconciled()
+	}
+	if cluster.Status.State != v1beta1.KafkaClusterRunning {
+		return ctrl.Result{RequeueAfter: r.Period}, nil
+	}
+
+	broker, close, err := newKafkaFromCluster(r.Client, cluster)
+	if err != nil {
+		return checkBrokerConnectionError(reqLogger, err)
+	}
+	defer close()
+
+	topics, err := broker.ListTopics()
+	if err != nil {
+		return requeueWithError(reqLogger, "failed to list topics", err)
+	}
+
+	managed, err := r.managedTopics(ctx, cluster)
+	if err != nil {
+		return requeueWithError(reqLogger, "failed to list kafkatopics", err)
+	}
+
+	// a topic failing to be adopted does not hold back the discovery of the rest of the topics
+	var createErr error
+	for name, detail := range topics {
+		// internal topics are managed by kafka and its components
+		if managed[name] || strings.HasPrefix(name, "__") {
+			continue
+		}
+		topic := discoveredKafkaTopic(cluster, r.Namespace, name, detail.NumPartitions, int32(detail.ReplicationFactor), detail.ConfigEntries)
+		if err = r.Client.Create(ctx, topic); err != nil {
+			if apierrors.IsAlreadyExists(err) {
+				reqLogger.Info("KafkaTopic of discovered topic already exists", "topic", name, "kafkatopic", topic.Name)
+				continue
+			}
+			reqLogger.Error(err, "failed to create kafkatopic for discovered topic", "topic", name, "kafkatopic", topic.Name)
+			createErr = errors.Combine(createErr, errors.WrapIfWithDetails(err, "failed to create kafkatopic", "topic", name))
+			continue
+		}
+		reqLogger.Info("Created KafkaTopic for discovered topic", "topic", name, "kafkatopic", topic.Name)
+	}
+	if createErr != nil {
+		return requeueWithError(reqLogger, "failed to create kafkatopics for discovered topics", createErr)
+	}
+
+	return ctrl.Result{RequeueAfter: r.Period}, nil
+}
+
+// managedTopics returns the names of the topics of the cluster which are managed by a KafkaTopic
+func (r *KafkaTopicDiscoveryReconciler) managedTopics(ctx context.Context, cluster *v1beta1.KafkaCluster) (map[string]bool, error) {
+	topics := &v1alpha1.KafkaTopicList{}
+	if err := r.Client.List(ctx, topics); err != nil {
+		return nil, err
+	}
+	managed := make(map[string]bool, len(topics.Items))
+	for _, topic := range topics.Items {
+		if topic.Spec.ClusterRef.Name == cluster.Name && getClusterRefNamespace(topic.Namespace, topic.Spec.ClusterRef) == cluster.Namespace {
+			managed[topic.Spec.Name] = true
+		}
+	}
+	return managed, nil
+}
+
+// discoveredKafkaTopic returns a KafkaTopic adopting the given topic as it is
+func discoveredKafkaTopic(cluster *v1beta1.KafkaCluster, namespace, name string, partitions, replicationFactor int32, config map[string]*string) *v1alpha1.KafkaTopic {
+	topicConfig := make(map[string]string, len(config))
+	for key, value := range config {
+		if value != nil {
+			topicConfig[key] = *value
+		}
+	}
+	return &v1alpha1.KafkaTopic{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        discoveredTopicResourceName(cluster, name),
+			Namespace:   namespace,
+			Labels:      util.MergeLabels(applyClusterRefLabel(cluster, nil), map[string]string{v1alpha1.DiscoveredTopicLabel: "true"}),
+			Annotations: map[string]string{v1alpha1.AdoptTopicAnnotation: "true"},
+		},
+		Spec: v1alpha1.KafkaTopicSpec{
+			Name:              name,
+			Partitions:        partitions,
+			ReplicationFactor: replicationFactor,
+			Config:            topicConfig,
+			// the operator has not created the topic, so it is kept in kafka when the KafkaTopic is removed
+			DeletionPolicy: v1alpha1.TopicDeletionPolicyRetain,
+			ClusterRef: v1alpha1.ClusterReference{
+				Name:      cluster.Name,
+				Namespace: cluster.Namespace,
+			},
+		},
+	}
+}
+
+// discoveredTopicResourceName returns the name of the KafkaTopic of a discovered topic, topic names
+// which are not valid resource names are sanitized and suffixed with a hash to avoid collisions
+func discoveredTopicResourceName(cluster *v1beta1.KafkaCluster, topic string) string {
+	name := cluster.Name + "-" + topic
+	if len(validation.IsDNS1123Subdomain(name)) == 0 {
+		return name
+	}
+	sum := sha256.Sum256([]byte(topic))
+	suffix := "-" + hex.EncodeToString(sum[:])[:8]
+	sanitized := invalidResourceNameChars.ReplaceAllString(strings.ToLower(name), "-")
+	if maxLength := validation.DNS1123SubdomainMaxLength - len(suffix); len(sanitized) > maxLength {
+		sanitized = sanitized[:maxLength]
+	}
+	return strings.Trim(sanitized, "-") + suffix
+}
@@ -0,0 +1,217 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	//nolint:staticcheck
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
+	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+	"github.com/banzaicloud/kafka-operator/pkg/kafkaclient"
+	"github.com/banzaicloud/kafka-operator/pkg/util"
+)
+
+func TestDiscoveredTopicResourceName(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{ObjectMeta: metav1.ObjectMeta{Name: "kafka"}}
+
+	if name := discoveredTopicResourceName(cluster, "orders.v1"); name != "kafka-orders.v1" {
+		t.Error("Expected valid topic name to be kept, got:", name)
+	}
+
+	upper := discoveredTopicResourceName(cluster, "Orders_V1")
+	lower := discoveredTopicResourceName(cluster, "orders-v1")
+	if upper == lower {
+		t.Error("Expected different names for different topics, got:", upper)
+	}
+	for _, topic := range []string{"Orders_V1", "_schemas", "a-.b", string(make([]byte, 249))} {
+		if errs := validation.IsDNS1123Subdomain(discoveredTopicResourceName(cluster, topic)); len(errs) != 0 {
+			t.Errorf("Expected valid resource name for topic %q, got: %v", topic, errs)
+		}
+	}
+}
+
+func TestKafkaTopicDiscovery(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: testNamespace},
+		Status:     v1beta1.KafkaClusterStatus{State: v1beta1.KafkaClusterRunning},
+	}
+	managed := &v1alpha1.KafkaTopic{
+		ObjectMeta: metav1.ObjectMeta{Name: "managed", Namespace: testNamespace},
+		Spec: v1alpha1.KafkaTopicSpec{
+			Name:       "managed",
+			ClusterRef: v1alpha1.ClusterReference{Name: "kafka"},
+		},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, managed).Build()
+
+	broker, _, _ := kafkaclient.NewMockFromCluster(k8sClient, cluster)
+	for _, topic := range []string{"managed", "__consumer_offsets"} {
+		if err := broker.CreateTopic(&kafkaclient.CreateTopicOptions{Name: topic, Partitions: 1, ReplicationFactor: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := broker.CreateTopic(&kafkaclient.CreateTopicOptions{
+		Name:              "orders",
+		Partitions:        3,
+		ReplicationFactor: 2,
+		Config:            map[string]*string{"retention.ms": util.StringPointer("1000")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetNewKafkaFromCluster(func(client.Client, *v1beta1.KafkaCluster) (kafkaclient.KafkaClient, func(), error) {
+		return broker, func() {}, nil
+	})
+	defer SetNewKafkaFromCluster(kafkaclient.NewFromCluster)
+
+	r := &KafkaTopicDiscoveryReconciler{
+		Client:    k8sClient,
+		Log:       log,
+		Namespace: "discovered",
+		Period:    time.Minute,
+	}
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "kafka", Namespace: testNamespace}}
+	if _, err = r.Reconcile(context.TODO(), request); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+
+	topics := &v1alpha1.KafkaTopicList{}
+	if err = k8sClient.List(context.TODO(), topics, client.InNamespace("discovered")); err != nil {
+		t.Fatal(err)
+	}
+	if len(topics.Items) != 1 {
+		t.Fatal("Expected a single discovered topic, got:", topics.Items)
+	}
+	discovered := topics.Items[0]
+	if discovered.Name != "kafka-orders" || discovered.Spec.Name != "orders" {
+		t.Error("Expected kafkatopic kafka-orders for topic orders, got:", discovered.Name, discovered.Spec.Name)
+	}
+	if discovered.Spec.Partitions != 3 || discovered.Spec.ReplicationFactor != 2 || discovered.Spec.Config["retention.ms"] != "1000" {
+		t.Error("Expected the layout and config of the topic in the spec, got:", discovered.Spec)
+	}
+	if discovered.Spec.GetDeletionPolicy() != v1alpha1.TopicDeletionPolicyRetain {
+		t.Error("Expected discovered topic to be retained in kafka on deletion, got:", discovered.Spec.DeletionPolicy)
+	}
+	if !discovered.IsAdoptionRequested() || discovered.Labels[v1alpha1.DiscoveredTopicLabel] != "true" {
+		t.Error("Expected adoption annotation and discovered label, got:", discovered.Annotations, discovered.Labels)
+	}
+
+	// discovered topics are not generated again
+	if _, err = r.Reconcile(context.TODO(), request); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if err = k8sClient.List(context.TODO(), topics, client.InNamespace("discovered")); err != nil {
+		t.Fatal(err)
+	}
+	if len(topics.Items) != 1 {
+		t.Error("Expected a single discovered topic, got:", topics.Items)
+	}
+}
+
+// failingCreateClient fails the creation of the KafkaTopics of the given topics
+type failingCreateClient struct {
+	client.Client
+	failingTopics map[string]bool
+}
+
+func (c *failingCreateClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if topic, ok := obj.(*v1alpha1.KafkaTopic); ok && c.failingTopics[topic.Spec.Name] {
+		return errors.New("create failed")
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func TestKafkaTopicDiscoveryContinuesOnError(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: testNamespace},
+		Status:     v1beta1.KafkaClusterStatus{State: v1beta1.KafkaClusterRunning},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+
+	broker, _, _ := kafkaclient.NewMockFromCluster(k8sClient, cluster)
+	for _, topic := range []string{"orders", "payments"} {
+		if err := broker.CreateTopic(&kafkaclient.CreateTopicOptions{Name: topic, Partitions: 1, ReplicationFactor: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	SetNewKafkaFromCluster(func(client.Client, *v1beta1.KafkaCluster) (kafkaclient.KafkaClient, func(), error) {
+		return broker, func() {}, nil
+	})
+	defer SetNewKafkaFromCluster(kafkaclient.NewFromCluster)
+
+	r := &KafkaTopicDiscoveryReconciler{
+		Client:    &failingCreateClient{Client: k8sClient, failingTopics: map[string]bool{"orders": true}},
+		Log:       log,
+		Namespace: "discovered",
+		Period:    time.Minute,
+	}
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "kafka", Namespace: testNamespace}}
+	if _, err := r.Reconcile(context.TODO(), request); err == nil {
+		t.Error("Expected the failed creation to be returned, got nil")
+	}
+
+	topics := &v1alpha1.KafkaTopicList{}
+	if err := k8sClient.List(context.TODO(), topics, client.InNamespace("discovered")); err != nil {
+		t.Fatal(err)
+	}
+	if len(topics.Items) != 1 || topics.Items[0].Spec.Name != "payments" {
+		t.Error("Expected the rest of the topics to be discovered, got:", topics.Items)
+	}
+}
+
+func TestDiscoveredTopicDeletionPolicy(t *testing.T) {
+	topic := &v1alpha1.KafkaTopic{}
+	if policy := topic.GetDeletionPolicy(); policy != v1alpha1.TopicDeletionPolicyDelete {
+		t.Error("Expected topics to be deleted from kafka by default, got:", policy)
+	}
+	// the discovered topics created without deletion policy are kept in kafka
+	topic.SetLabels(map[string]string{v1alpha1.DiscoveredTopicLabel: "true"})
+	if policy := topic.GetDeletionPolicy(); policy != v1alpha1.TopicDeletionPolicyRetain {
+		t.Error("Expected discovered topic to be retained in kafka, got:", policy)
+	}
+	topic.Spec.DeletionPolicy = v1alpha1.TopicDeletionPolicyDelete
+	if policy := topic.GetDeletionPolicy(); policy != v1alpha1.TopicDeletionPolicyDelete {
+		t.Error("Expected the explicit deletion policy to be kept, got:", policy)
+	}
+}
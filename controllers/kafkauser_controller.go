@@ -23,9 +23,11 @@ import (
 	"emperror.dev/errors"
 	"github.com/go-logr/logr"
 	certv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -37,6 +39,7 @@ import (
 	"github.com/banzaicloud/kafka-operator/api/v1beta1"
 	"github.com/banzaicloud/kafka-operator/pkg/errorfactory"
 	"github.com/banzaicloud/kafka-operator/pkg/k8sutil"
+	"github.com/banzaicloud/kafka-operator/pkg/kafkaclient"
 	"github.com/banzaicloud/kafka-operator/pkg/pki"
 	"github.com/banzaicloud/kafka-operator/pkg/util"
 	kafkautil "github.com/banzaicloud/kafka-operator/pkg/util/kafka"
@@ -47,12 +50,14 @@ import (
 var userFinalizer = "finalizer.kafkausers.kafka.banzaicloud.io"
 
 // SetupKafkaUserWithManager registers KafkaUser controller to the manager
-func SetupKafkaUserWithManager(mgr ctrl.Manager, certManagerNamespace bool) error {
+func SetupKafkaUserWithManager(mgr ctrl.Manager, certManagerNamespace bool, resyncPeriod time.Duration) error {
 	// Create a new reconciler
 	r := &KafkaUserReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-		Log:    ctrl.Log.WithName("controllers").WithName("KafkaUser"),
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		Log:          ctrl.Log.WithName("controllers").WithName("KafkaUser"),
+		Recorder:     mgr.GetEventRecorderFor("kafkauser"),
+		ResyncPeriod: resyncPeriod,
 	}
 
 	// Create a new controller
@@ -88,13 +93,18 @@ var _ reconcile.Reconciler = &KafkaUserReconciler{}
 type KafkaUserReconciler struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	Client client.Client
-	Scheme *runtime.Scheme
-	Log    logr.Logger
+	Client   client.Client
+	Scheme   *runtime.Scheme
+	Log      logr.Logger
+	Recorder record.EventRecorder
+	// ResyncPeriod is the interval users are reconciled at to correct drifts
+	// of their ACLs in kafka, resync is disabled when zero
+	ResyncPeriod time.Duration
 }
 
 // +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkausers,verbs=get;list;watch;create;update;patch;delete;deletecollection
 // +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkausers/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=cert-manager.io,resources=issuers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=cert-manager.io,resources=clusterissuers,verbs=get;list;watch;create;update;patch;delete
@@ -134,7 +144,10 @@ func (r *KafkaUserReconciler) Reconcile(ctx context.Context, request reconcile.R
 
 	var kafkaUser string
 
-	if instance.Spec.GetIfCertShouldBeCreated() {
+	if instance.Spec.IsSCRAM() {
+		// SCRAM users are authenticated by their name, no certificates are involved
+		kafkaUser = instance.Name
+	} else if instance.Spec.GetIfCertShouldBeCreated() {
 		// Avoid panic if the user wants to create a kafka user but the cluster is in plaintext mode
 		// TODO: refactor this and use webhook to validate if the cluster is eligible to create a kafka user
 		if cluster.Spec.ListenersConfig.SSLSecrets == nil && instance.Spec.PKIBackendSpec == nil {
@@ -213,24 +226,62 @@ func (r *KafkaUserReconciler) Reconcile(ctx context.Context, request reconcile.R
 		return requeueWithError(reqLogger, "failed to ensure kafkacluster label on user", err)
 	}
 
-	// If topic grants supplied, grab a broker connection and set ACLs
-	if len(instance.Spec.TopicGrants) > 0 {
-		broker, close, err := newKafkaFromCluster(r.Client, cluster)
+	// ACLs and quotas which apply to the user, or were applied previously, are reconciled,
+	// the ones which are not desired anymore are removed
+	desiredACLs, err := kafkautil.DesiredUserACLs(kafkaUser, &instance.Spec)
+	if err != nil {
+		return requeueWithError(reqLogger, "failed to determine ACLs for kafkauser", err)
+	}
+	reconcileACLs := len(desiredACLs) > 0 || len(instance.Status.ACLs) > 0
+	quotas := kafkautil.EffectiveUserQuotas(cluster, instance)
+	reconcileQuotas := quotas != nil || instance.Status.Quotas != nil
+
+	// grab a single broker connection for the SCRAM credentials, the ACLs and the quotas
+	var broker kafkaclient.KafkaClient
+	if instance.Spec.IsSCRAM() || reconcileACLs || reconcileQuotas {
+		var close func()
+		broker, close, err = newKafkaFromCluster(r.Client, cluster)
 		if err != nil {
 			return checkBrokerConnectionError(reqLogger, err)
 		}
 		defer close()
+	}
 
-		// TODO (tinyzimmer): Should probably take this opportunity to see if we are removing any ACLs
-		for _, grant := range instance.Spec.TopicGrants {
-			reqLogger.Info(fmt.Sprintf("Ensuring %s ACLs for User: %s -> Topic: %s", grant.AccessType, kafkaUser, grant.TopicName))
-			// CreateUserACLs returns no error if the ACLs already exist
-			if err = broker.CreateUserACLs(grant.AccessType, grant.PatternType, kafkaUser, grant.TopicName); err != nil {
-				return requeueWithError(reqLogger, "failed to ensure ACLs for kafkauser", err)
+	// ensure SCRAM credentials and rotate them if requested
+	credentialsRotatedAt := instance.Status.CredentialsRotatedAt
+	if instance.Spec.IsSCRAM() {
+		rotatedAt, err := r.reconcileScramCredentials(ctx, reqLogger, broker, instance)
+		if err != nil {
+			return checkBrokerConnectionError(reqLogger, err)
+		}
+		if rotatedAt != "" {
+			reqLogger.Info("Rotated SCRAM credentials for user")
+			credentialsRotatedAt = rotatedAt
+			annotations := instance.GetAnnotations()
+			delete(annotations, v1alpha1.RotateCredentialsAnnotation)
+			instance.SetAnnotations(annotations)
+			if instance, err = r.updateAndFetchLatest(ctx, instance); err != nil {
+				return requeueWithError(reqLogger, "failed to remove rotate credentials annotation from kafkauser", err)
 			}
 		}
 	}
 
+	if reconcileACLs {
+		reqLogger.Info(fmt.Sprintf("Ensuring %d ACLs for User: %s", len(desiredACLs), kafkaUser))
+		created, deleted, err := broker.ReconcileUserACLs(kafkaUser, desiredACLs)
+		r.recordACLCorrections(instance, created, deleted)
+		if err != nil {
+			return requeueWithError(reqLogger, "failed to ensure ACLs for kafkauser", err)
+		}
+	}
+
+	if reconcileQuotas {
+		reqLogger.Info(fmt.Sprintf("Ensuring client quotas for User: %s", kafkaUser))
+		if err = broker.AlterUserQuotas(kafkaUser, quotas); err != nil {
+			return requeueWithError(reqLogger, "failed to ensure quotas for kafkauser", err)
+		}
+	}
+
 	// ensure a finalizer for cleanup on deletion
 	if !util.StringSliceContains(instance.GetFinalizers(), userFinalizer) {
 		r.addFinalizer(reqLogger, instance)
@@ -241,18 +292,47 @@ func (r *KafkaUserReconciler) Reconcile(ctx context.Context, request reconcile.R
 
 	// set user status
 	instance.Status = v1alpha1.KafkaUserStatus{
-		State: v1alpha1.UserStateCreated,
+		State:                v1alpha1.UserStateCreated,
+		CredentialsRotatedAt: credentialsRotatedAt,
+		Quotas:               quotas,
 	}
-	if len(instance.Spec.TopicGrants) > 0 {
-		instance.Status.ACLs = kafkautil.GrantsToACLStrings(kafkaUser, instance.Spec.TopicGrants)
+	if len(desiredACLs) > 0 {
+		instance.Status.ACLs = kafkautil.ACLStrings(desiredACLs)
 	}
 	if err := r.Client.Status().Update(ctx, instance); err != nil {
 		return requeueWithError(reqLogger, "failed to update kafkauser status", err)
 	}
 
+	if r.ResyncPeriod > 0 {
+		return ctrl.Result{RequeueAfter: r.ResyncPeriod}, nil
+	}
 	return reconciled()
 }
 
+// recordACLCorrections emits an event for every ACL of the user which has been created or
+// removed while the status of the user reported the opposite
+func (r *KafkaUserReconciler) recordACLCorrections(user *v1alpha1.KafkaUser, created, deleted []kafkautil.UserACLEntry) {
+	if r.Recorder == nil {
+		return
+	}
+	reported := make(map[string]struct{}, len(user.Status.ACLs))
+	for _, acl := range user.Status.ACLs {
+		reported[acl] = struct{}{}
+	}
+	for _, entry := range created {
+		if _, ok := reported[entry.String()]; ok {
+			r.Recorder.Eventf(user, corev1.EventTypeWarning, "ACLRecreated", "ACL %s was missing from kafka and has been recreated", entry.String())
+		}
+	}
+	for _, entry := range deleted {
+		if _, ok := reported[entry.String()]; ok {
+			r.Recorder.Eventf(user, corev1.EventTypeNormal, "ACLRemoved", "ACL %s is not desired anymore and has been removed", entry.String())
+		} else {
+			r.Recorder.Eventf(user, corev1.EventTypeWarning, "UnmanagedACLRemoved", "ACL %s was not created by the operator and has been removed", entry.String())
+		}
+	}
+}
+
 func (r *KafkaUserReconciler) ensureClusterLabel(ctx context.Context, cluster *v1beta1.KafkaCluster, user *v1alpha1.KafkaUser) (*v1alpha1.KafkaUser, error) {
 	labels := applyClusterRefLabel(cluster, user.GetLabels())
 	if !reflect.DeepEqual(labels, user.GetLabels()) {
@@ -276,11 +356,16 @@ func (r *KafkaUserReconciler) checkFinalizers(ctx context.Context, reqLogger log
 	// run finalizers
 	var err error
 	if util.StringSliceContains(instance.GetFinalizers(), userFinalizer) {
-		if len(instance.Spec.TopicGrants) > 0 {
-			if err = r.finalizeKafkaUserACLs(reqLogger, cluster, user); err != nil {
+		if hasUserACLs(instance) || instance.Status.Quotas != nil {
+			if err = r.finalizeKafkaUserACLs(reqLogger, cluster, instance, user); err != nil {
 				return requeueWithError(reqLogger, "failed to finalize kafkauser", err)
 			}
 		}
+		if instance.Spec.IsSCRAM() {
+			if err = r.finalizeKafkaUserScramCredentials(reqLogger, cluster, user); err != nil {
+				return requeueWithError(reqLogger, "failed to finalize kafkauser SCRAM credentials", err)
+			}
+		}
 		// remove finalizer
 		if err = r.removeFinalizer(ctx, instance); err != nil {
 			return requeueWithError(reqLogger, "failed to remove finalizer from kafkauser", err)
@@ -295,24 +380,37 @@ func (r *KafkaUserReconciler) removeFinalizer(ctx context.Context, user *v1alpha
 	return err
 }
 
-func (r *KafkaUserReconciler) finalizeKafkaUserACLs(reqLogger logr.Logger, cluster *v1beta1.KafkaCluster, user string) error {
+func (r *KafkaUserReconciler) finalizeKafkaUserACLs(reqLogger logr.Logger, cluster *v1beta1.KafkaCluster, instance *v1alpha1.KafkaUser, user string) error {
 	if k8sutil.IsMarkedForDeletion(cluster.ObjectMeta) {
 		reqLogger.Info("Cluster is being deleted, skipping ACL deletion")
 		return nil
 	}
 	var err error
-	reqLogger.Info("Deleting user ACLs from kafka")
 	broker, close, err := newKafkaFromCluster(r.Client, cluster)
 	if err != nil {
 		return err
 	}
 	defer close()
-	if err = broker.DeleteUserACLs(user); err != nil {
-		return err
+	if hasUserACLs(instance) {
+		reqLogger.Info("Deleting user ACLs from kafka")
+		if err = broker.DeleteUserACLs(user); err != nil {
+			return err
+		}
+	}
+	if instance.Status.Quotas != nil {
+		reqLogger.Info("Deleting user quotas from kafka")
+		if err = broker.DeleteUserQuotas(user); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+// hasUserACLs returns true if the user has, or had previously, ACLs in kafka
+func hasUserACLs(user *v1alpha1.KafkaUser) bool {
+	return len(user.Spec.TopicGrants) > 0 || len(user.Spec.ACLs) > 0 || len(user.Status.ACLs) > 0
+}
+
 func (r *KafkaUserReconciler) addFinalizer(reqLogger logr.Logger, user *v1alpha1.KafkaUser) {
 	reqLogger.Info("Adding Finalizer for the KafkaUser")
 	user.SetFinalizers(append(user.GetFinalizers(), userFinalizer))
@@ -0,0 +1,69 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
+	kafkautil "github.com/banzaicloud/kafka-operator/pkg/util/kafka"
+)
+
+func TestRecordACLCorrections(t *testing.T) {
+	newEntry := func(topic string) kafkautil.UserACLEntry {
+		return kafkautil.UserACLEntry{
+			Resource: sarama.Resource{
+				ResourceType:        sarama.AclResourceTopic,
+				ResourceName:        topic,
+				ResourcePatternType: sarama.AclPatternLiteral,
+			},
+			Acl: sarama.Acl{
+				Principal:      "User:test-user",
+				Host:           "*",
+				Operation:      sarama.AclOperationRead,
+				PermissionType: sarama.AclPermissionAllow,
+			},
+		}
+	}
+	recreated, created, removed, unmanaged := newEntry("recreated"), newEntry("created"), newEntry("removed"), newEntry("unmanaged")
+
+	recorder := record.NewFakeRecorder(10)
+	r := &KafkaUserReconciler{Recorder: recorder}
+	user := &v1alpha1.KafkaUser{
+		Status: v1alpha1.KafkaUserStatus{
+			ACLs: []string{recreated.String(), removed.String()},
+		},
+	}
+	r.recordACLCorrections(user, []kafkautil.UserACLEntry{recreated, created}, []kafkautil.UserACLEntry{removed, unmanaged})
+
+	close(recorder.Events)
+	events := make([]string, 0)
+	for event := range recorder.Events {
+		events = append(events, event)
+	}
+	expectedReasons := []string{"Warning ACLRecreated", "Normal ACLRemoved", "Warning UnmanagedACLRemoved"}
+	if len(events) != len(expectedReasons) {
+		t.Fatalf("Expected %d events, got: %v", len(expectedReasons), events)
+	}
+	for i, reason := range expectedReasons {
+		if !strings.HasPrefix(events[i], reason) {
+			t.Errorf("Expected event with reason %q, got: %s", reason, events[i])
+		}
+	}
+}
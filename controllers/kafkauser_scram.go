@@ -0,0 +1,174 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
+	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+	"github.com/banzaicloud/kafka-operator/pkg/errorfactory"
+	"github.com/banzaicloud/kafka-operator/pkg/k8sutil"
+	"github.com/banzaicloud/kafka-operator/pkg/kafkaclient"
+	certutil "github.com/banzaicloud/kafka-operator/pkg/util/cert"
+)
+
+// scramPasswordLength is the length of the generated SCRAM passwords
+const scramPasswordLength = 32
+
+// scramSASLMechanism returns the SASL mechanism clients have to use for the given authentication type
+func scramSASLMechanism(authType v1alpha1.UserAuthenticationType) string {
+	switch authType {
+	case v1alpha1.UserAuthenticationSCRAMSHA256:
+		return "SCRAM-SHA-256"
+	default:
+		return "SCRAM-SHA-512"
+	}
+}
+
+// reconcileScramCredentials ensures the user secret holding the SCRAM password exists and
+// the credentials are present in kafka. The credentials are upserted whenever the password
+// of the secret has been generated, and the credentials of the mechanisms the user does not
+// authenticate with anymore are removed. When the rotate annotation is set a new password is
+// generated and the time of the rotation is returned.
+func (r *KafkaUserReconciler) reconcileScramCredentials(ctx context.Context, reqLogger logr.Logger, broker kafkaclient.KafkaClient, user *v1alpha1.KafkaUser) (rotatedAt string, err error) {
+	_, rotate := user.GetAnnotations()[v1alpha1.RotateCredentialsAnnotation]
+
+	secret, generated, err := r.reconcileScramSecret(ctx, user, rotate)
+	if err != nil {
+		return "", err
+	}
+
+	authType := user.Spec.GetAuthenticationType()
+	mechanism := kafkaclient.ScramMechanismMapping(authType)
+	infos, err := broker.DescribeUserScramCredentials(user.Name)
+	if err != nil {
+		return "", err
+	}
+	// the credentials are upserted when the password has been generated or kafka lacks them
+	upsert := true
+	staleMechanisms := make([]sarama.ScramMechanismType, 0)
+	for _, info := range infos {
+		if info.Mechanism == mechanism {
+			upsert = generated
+		} else {
+			staleMechanisms = append(staleMechanisms, info.Mechanism)
+		}
+	}
+
+	if upsert {
+		reqLogger.Info("Upserting SCRAM credentials for user", "mechanism", scramSASLMechanism(authType))
+		if err = broker.UpsertUserScramCredentials(user.Name, authType, string(secret.Data[v1alpha1.PasswordKey])); err != nil {
+			return "", err
+		}
+	}
+	if len(staleMechanisms) > 0 {
+		reqLogger.Info("Deleting SCRAM credentials of the mechanisms the user does not authenticate with anymore")
+		if err = broker.DeleteUserScramCredentials(user.Name, staleMechanisms...); err != nil {
+			return "", err
+		}
+	}
+
+	if rotate {
+		rotatedAt = time.Now().Format(time.RFC3339)
+	}
+	return rotatedAt, nil
+}
+
+// reconcileScramSecret creates or updates the user secret holding the SCRAM credentials, generated is true
+// when a new password has been generated, i.e. the secret has been created, its password was missing or rotated
+func (r *KafkaUserReconciler) reconcileScramSecret(ctx context.Context, user *v1alpha1.KafkaUser, rotate bool) (secret *corev1.Secret, generated bool, err error) {
+	mechanism := []byte(scramSASLMechanism(user.Spec.GetAuthenticationType()))
+
+	secret = &corev1.Secret{}
+	err = r.Client.Get(ctx, types.NamespacedName{Name: user.Spec.SecretName, Namespace: user.Namespace}, secret)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, false, errorfactory.New(errorfactory.APIFailure{}, err, "failed to get user secret")
+	}
+
+	if apierrors.IsNotFound(err) {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      user.Spec.SecretName,
+				Namespace: user.Namespace,
+			},
+			Data: map[string][]byte{
+				v1alpha1.UsernameKey:      []byte(user.Name),
+				v1alpha1.PasswordKey:      certutil.GeneratePass(scramPasswordLength),
+				v1alpha1.SASLMechanismKey: mechanism,
+			},
+		}
+		if err = controllerutil.SetControllerReference(user, secret, r.Scheme); err != nil {
+			return nil, false, errorfactory.New(errorfactory.InternalError{}, err, "could not set controller reference on user secret")
+		}
+		if err = r.Client.Create(ctx, secret); err != nil {
+			return nil, false, errorfactory.New(errorfactory.APIFailure{}, err, "could not create user secret")
+		}
+		return secret, true, nil
+	}
+
+	updated := false
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte)
+	}
+	if len(secret.Data[v1alpha1.PasswordKey]) == 0 || rotate {
+		secret.Data[v1alpha1.PasswordKey] = certutil.GeneratePass(scramPasswordLength)
+		generated = true
+		updated = true
+	}
+	if string(secret.Data[v1alpha1.UsernameKey]) != user.Name {
+		secret.Data[v1alpha1.UsernameKey] = []byte(user.Name)
+		updated = true
+	}
+	if string(secret.Data[v1alpha1.SASLMechanismKey]) != string(mechanism) {
+		secret.Data[v1alpha1.SASLMechanismKey] = mechanism
+		updated = true
+	}
+	if err = controllerutil.SetControllerReference(user, secret, r.Scheme); err != nil && !k8sutil.IsAlreadyOwnedError(err) {
+		return nil, false, errorfactory.New(errorfactory.InternalError{}, err, "error checking controller reference on user secret")
+	} else if err == nil {
+		updated = true
+	}
+	if updated {
+		if err = r.Client.Update(ctx, secret); err != nil {
+			return nil, false, errorfactory.New(errorfactory.APIFailure{}, err, "could not update user secret")
+		}
+	}
+	return secret, generated, nil
+}
+
+// finalizeKafkaUserScramCredentials removes the SCRAM credentials of the user from kafka
+func (r *KafkaUserReconciler) finalizeKafkaUserScramCredentials(reqLogger logr.Logger, cluster *v1beta1.KafkaCluster, user string) error {
+	if k8sutil.IsMarkedForDeletion(cluster.ObjectMeta) {
+		reqLogger.Info("Cluster is being deleted, skipping SCRAM credentials deletion")
+		return nil
+	}
+	reqLogger.Info("Deleting user SCRAM credentials from kafka")
+	broker, close, err := newKafkaFromCluster(r.Client, cluster)
+	if err != nil {
+		return err
+	}
+	defer close()
+	return broker.DeleteUserScramCredentials(user)
+}
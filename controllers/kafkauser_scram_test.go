@@ -0,0 +1,145 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	//nolint:staticcheck
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
+	"github.com/banzaicloud/kafka-operator/pkg/kafkaclient"
+)
+
+// scramRecordingClient records the passwords the SCRAM credentials are upserted with
+type scramRecordingClient struct {
+	kafkaclient.KafkaClient
+	upserted []string
+}
+
+func (c *scramRecordingClient) UpsertUserScramCredentials(user string, authType v1alpha1.UserAuthenticationType, password string) error {
+	c.upserted = append(c.upserted, password)
+	return c.KafkaClient.UpsertUserScramCredentials(user, authType, password)
+}
+
+func newScramTestReconciler(t *testing.T) (*KafkaUserReconciler, *v1alpha1.KafkaUser) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	user := &v1alpha1.KafkaUser{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-user", Namespace: testNamespace, UID: "test-uid"},
+		Spec: v1alpha1.KafkaUserSpec{
+			SecretName:         "test-user-secret",
+			AuthenticationType: v1alpha1.UserAuthenticationSCRAMSHA512,
+		},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(user).Build()
+	return &KafkaUserReconciler{Client: k8sClient, Scheme: scheme, Log: log}, user
+}
+
+func getScramSecret(t *testing.T, r *KafkaUserReconciler) *corev1.Secret {
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: "test-user-secret", Namespace: testNamespace}, secret); err != nil {
+		t.Fatal(err)
+	}
+	return secret
+}
+
+func TestReconcileScramCredentials(t *testing.T) {
+	r, user := newScramTestReconciler(t)
+	mock, close, _ := kafkaclient.NewMockFromCluster(nil, nil)
+	defer close()
+	broker := &scramRecordingClient{KafkaClient: mock}
+
+	// the credentials are created along with the secret
+	if _, err := r.reconcileScramCredentials(context.TODO(), log, broker, user); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	password := string(getScramSecret(t, r).Data[v1alpha1.PasswordKey])
+	if len(broker.upserted) != 1 || broker.upserted[0] != password {
+		t.Fatal("Expected the credentials to be upserted with the password of the secret, got:", broker.upserted)
+	}
+
+	// nothing changes when the secret and the credentials are in place
+	if _, err := r.reconcileScramCredentials(context.TODO(), log, broker, user); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if len(broker.upserted) != 1 {
+		t.Error("Expected no upsert of existing credentials, got:", broker.upserted)
+	}
+
+	// the credentials are upserted when the password of the secret is regenerated
+	secret := getScramSecret(t, r)
+	delete(secret.Data, v1alpha1.PasswordKey)
+	if err := r.Client.Update(context.TODO(), secret); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.reconcileScramCredentials(context.TODO(), log, broker, user); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	regenerated := string(getScramSecret(t, r).Data[v1alpha1.PasswordKey])
+	if regenerated == password || len(broker.upserted) != 2 || broker.upserted[1] != regenerated {
+		t.Error("Expected the credentials to be upserted with the regenerated password, got:", broker.upserted)
+	}
+
+	// the credentials are upserted when the secret is recreated
+	if err := r.Client.Delete(context.TODO(), getScramSecret(t, r)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.reconcileScramCredentials(context.TODO(), log, broker, user); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	recreated := string(getScramSecret(t, r).Data[v1alpha1.PasswordKey])
+	if len(broker.upserted) != 3 || broker.upserted[2] != recreated {
+		t.Error("Expected the credentials to be upserted with the password of the recreated secret, got:", broker.upserted)
+	}
+}
+
+func TestReconcileScramCredentialsMechanismChange(t *testing.T) {
+	r, user := newScramTestReconciler(t)
+	broker, close, _ := kafkaclient.NewMockFromCluster(nil, nil)
+	defer close()
+
+	if _, err := r.reconcileScramCredentials(context.TODO(), log, broker, user); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+
+	user.Spec.AuthenticationType = v1alpha1.UserAuthenticationSCRAMSHA256
+	if _, err := r.reconcileScramCredentials(context.TODO(), log, broker, user); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	infos, err := broker.DescribeUserScramCredentials(user.Name)
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if len(infos) != 1 || infos[0].Mechanism != sarama.SCRAM_MECHANISM_SHA_256 {
+		t.Error("Expected only the SCRAM-SHA-256 credentials to be kept, got:", infos)
+	}
+	if mechanism := string(getScramSecret(t, r).Data[v1alpha1.SASLMechanismKey]); mechanism != "SCRAM-SHA-256" {
+		t.Error("Expected the secret to hold the new mechanism, got:", mechanism)
+	}
+}
@@ -0,0 +1,338 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+	"github.com/banzaicloud/kafka-operator/pkg/cruisecontrol"
+	"github.com/banzaicloud/kafka-operator/pkg/k8sutil"
+)
+
+// selfHealingCheckInterval is the interval the offline replicas of the brokers are checked with
+const selfHealingCheckInterval = time.Minute
+
+// maxSelfHealingIncidents is the number of incidents kept in the status of the cluster
+const maxSelfHealingIncidents = 10
+
+// SetupSelfHealingWithManager registers the self-healing controller with manager
+func SetupSelfHealingWithManager(mgr ctrl.Manager) error {
+	r := &SelfHealingReconciler{
+		Client:   mgr.GetClient(),
+		Log:      ctrl.Log.WithName("controllers").WithName("SelfHealing"),
+		Recorder: mgr.GetEventRecorderFor("selfhealing"),
+	}
+
+	// the brokers are checked periodically, the status updates of the cluster are filtered out
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1beta1.KafkaCluster{}).
+		Named("SelfHealing").
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		Complete(r)
+}
+
+// blank assignment to verify that SelfHealingReconciler implements reconcile.Reconciler
+var _ reconcile.Reconciler = &SelfHealingReconciler{}
+
+// SelfHealingReconciler recovers the offline replicas of the brokers which have been lost permanently
+// through cruise control once they have not recovered within the grace period
+type SelfHealingReconciler struct {
+	Client client.Client
+	Log    logr.Logger
+	// Recorder emits the events about the lost brokers and their healing
+	Recorder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkaclusters,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkaclusters/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Reconcile checks the brokers of the cluster for offline replicas and heals the lost ones
+func (r *SelfHealingReconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	log := r.Log.WithValues("clusterName", request.Name, "clusterNamespace", request.Namespace)
+
+	cluster := &v1beta1.KafkaCluster{}
+	if err := r.Client.Get(ctx, request.NamespacedName, cluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconciled()
+		}
+		return requeueWithError(log, err.Error(), err)
+	}
+
+	if !cluster.Spec.CruiseControlConfig.SelfHealing.IsEnabled() || k8sutil.IsMarkedForDeletion(cluster.ObjectMeta) {
+		return reconciled()
+	}
+	if cluster.Status.CruiseControlTopicStatus != v1beta1.CruiseControlTopicReady {
+		log.V(1).Info("cruise control is not ready yet")
+		return ctrl.Result{RequeueAfter: selfHealingCheckInterval}, nil
+	}
+
+	cc, err := newCruiseControlClient(r.Client, cluster)
+	if err != nil {
+		return requeueWithError(log, "failed to create cruise control client", err)
+	}
+	if err := r.heal(ctx, log, cluster, cc); err != nil {
+		return requeueWithError(log, "self-healing failed", err)
+	}
+	return ctrl.Result{RequeueAfter: selfHealingCheckInterval}, nil
+}
+
+// heal opens an incident for the brokers with offline replicas, starts the healing of the brokers which have
+// not recovered within the grace period and follows the cruise control tasks healing them
+func (r *SelfHealingReconciler) heal(ctx context.Context, log logr.Logger, cluster *v1beta1.KafkaCluster, cc cruisecontrol.Client) error {
+	config := cluster.Spec.CruiseControlConfig.SelfHealing
+	state, err := cc.KafkaClusterState(ctx)
+	if err != nil {
+		return err
+	}
+	offlineReplicas := state.KafkaBrokerState.OfflineReplicaCountByBrokerID
+
+	now := time.Now()
+	incidents := append([]v1beta1.SelfHealingIncident(nil), cluster.Status.SelfHealingIncidents...)
+	changed := false
+
+	for _, broker := range cluster.Spec.Brokers {
+		brokerId := strconv.Itoa(int(broker.Id))
+		if offlineReplicas[brokerId] == 0 || openIncident(incidents, brokerId) != nil {
+			continue
+		}
+		// the brokers which are being added or removed are taken care of by the cruise control task controller
+		if cluster.Status.BrokersState[brokerId].GracefulActionState.CruiseControlState.IsRunningState() {
+			continue
+		}
+		log.Info("broker has offline replicas", "brokerId", brokerId, "offlineReplicas", offlineReplicas[brokerId])
+		r.event(cluster, corev1.EventTypeWarning, "BrokerReplicasOffline",
+			"broker %s has %d offline replicas, it is healed unless it recovers within %s", brokerId, offlineReplicas[brokerId], config.GetGracePeriod())
+		incidents = append(incidents, v1beta1.SelfHealingIncident{
+			BrokerId:   brokerId,
+			State:      v1beta1.SelfHealingDetected,
+			DetectedAt: now.Format(time.RFC3339),
+		})
+		changed = true
+	}
+
+	var due []*v1beta1.SelfHealingIncident
+	for i := range incidents {
+		incident := &incidents[i]
+		switch incident.State {
+		case v1beta1.SelfHealingDetected:
+			if offlineReplicas[incident.BrokerId] == 0 {
+				log.Info("broker has recovered", "brokerId", incident.BrokerId)
+				r.event(cluster, corev1.EventTypeNormal, "BrokerRecovered", "broker %s has recovered within the grace period", incident.BrokerId)
+				incident.State = v1beta1.SelfHealingRecovered
+				incident.FinishedAt = now.Format(time.RFC3339)
+				changed = true
+				continue
+			}
+			if detectedAt, err := time.Parse(time.RFC3339, incident.DetectedAt); err == nil && now.Sub(detectedAt) < config.GetGracePeriod() {
+				continue
+			}
+			due = append(due, incident)
+		case v1beta1.SelfHealingInProgress:
+			if r.checkHealingTask(ctx, log, cluster, cc, incident) {
+				changed = true
+			}
+		}
+	}
+
+	if len(due) > 0 {
+		if hasRunningCruiseControlTask(cluster) {
+			log.Info("waiting for the running cruise control tasks to finish before healing the brokers")
+		} else {
+			r.startHealing(ctx, log, cluster, cc, due)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+	return k8sutil.UpdateSelfHealingIncidents(r.Client, cluster, trimSelfHealingIncidents(incidents), log)
+}
+
+// startHealing submits the cruise control operation recovering the replicas of the brokers, a single task heals all of them
+func (r *SelfHealingReconciler) startHealing(ctx context.Context, log logr.Logger, cluster *v1beta1.KafkaCluster, cc cruisecontrol.Client,
+	incidents []*v1beta1.SelfHealingIncident) {
+	action := cluster.Spec.CruiseControlConfig.SelfHealing.GetAction()
+	brokerIDs := make([]string, 0, len(incidents))
+	for _, incident := range incidents {
+		brokerIDs = append(brokerIDs, incident.BrokerId)
+	}
+
+	var rsp *cruisecontrol.OperationResponse
+	var err error
+	switch action {
+	case v1beta1.SelfHealingActionRemoveBroker:
+		rsp, err = cc.RemoveBrokers(ctx, cruisecontrol.BrokerOperationRequest{BrokerIDs: brokerIDs})
+	default:
+		rsp, err = cc.FixOfflineReplicas(ctx, cruisecontrol.OperationRequest{})
+	}
+
+	for _, incident := range incidents {
+		if err != nil {
+			incident.Message = fmt.Sprintf("could not start %s: %s", action, err)
+			continue
+		}
+		incident.State = v1beta1.SelfHealingInProgress
+		incident.Action = action
+		incident.CruiseControlTaskId = rsp.TaskID
+		incident.Message = ""
+	}
+	if err != nil {
+		log.Error(err, "could not start self-healing", "brokerIds", brokerIDs, "action", action)
+		return
+	}
+	log.Info("healing lost brokers", "brokerIds", brokerIDs, "action", action, "taskId", rsp.TaskID)
+	r.event(cluster, corev1.EventTypeWarning, "SelfHealingStarted", "broker(s) %s did not recover within the grace period, %s has been started",
+		strings.Join(brokerIDs, ","), action)
+}
+
+// checkHealingTask follows the cruise control task healing the broker of the incident and replaces the broker once
+// the task has completed, true is returned when the incident has changed
+func (r *SelfHealingReconciler) checkHealingTask(ctx context.Context, log logr.Logger, cluster *v1beta1.KafkaCluster, cc cruisecontrol.Client,
+	incident *v1beta1.SelfHealingIncident) bool {
+	tasks, err := cc.UserTasks(ctx, incident.CruiseControlTaskId)
+	if err != nil {
+		log.Error(err, "could not get the state of the self-healing task", "taskId", incident.CruiseControlTaskId)
+		return false
+	}
+	status := string(v1beta1.CruiseControlTaskNotFound)
+	for _, task := range tasks.UserTasks {
+		if task.UserTaskID == incident.CruiseControlTaskId {
+			status = task.Status
+		}
+	}
+
+	switch status {
+	case cruisecontrol.TaskStatusActive, cruisecontrol.TaskStatusInExecution:
+		return false
+	case cruisecontrol.TaskStatusCompleted:
+		if cluster.Spec.CruiseControlConfig.SelfHealing.ReplaceBroker {
+			replacement, err := r.replaceBroker(ctx, cluster, incident.BrokerId)
+			if err != nil {
+				// the replacement is retried with the next check
+				log.Error(err, "could not replace broker", "brokerId", incident.BrokerId)
+				incident.Message = fmt.Sprintf("could not replace broker: %s", err)
+				return true
+			}
+			incident.ReplacementBrokerId = replacement
+		}
+		incident.State = v1beta1.SelfHealingSucceeded
+		incident.Message = ""
+		log.Info("broker has been healed", "brokerId", incident.BrokerId, "replacementBrokerId", incident.ReplacementBrokerId)
+		message := fmt.Sprintf("replicas of broker %s have been recovered by %s", incident.BrokerId, incident.Action)
+		if incident.ReplacementBrokerId != "" {
+			message += fmt.Sprintf(", it has been replaced by broker %s", incident.ReplacementBrokerId)
+		}
+		r.event(cluster, corev1.EventTypeNormal, "SelfHealingSucceeded", message)
+	default:
+		incident.State = v1beta1.SelfHealingFailed
+		incident.Message = fmt.Sprintf("cruise control task %s is %s", incident.CruiseControlTaskId, status)
+		log.Info("self-healing failed", "brokerId", incident.BrokerId, "taskId", incident.CruiseControlTaskId, "taskState", status)
+		r.event(cluster, corev1.EventTypeWarning, "SelfHealingFailed", "healing broker %s failed: %s", incident.BrokerId, incident.Message)
+	}
+	incident.FinishedAt = time.Now().Format(time.RFC3339)
+	return true
+}
+
+// replaceBroker changes the id of the lost broker in the spec to a fresh one, the pods and volumes of the lost broker
+// are removed and the new broker is added to the cluster by the kafka cluster controller
+func (r *SelfHealingReconciler) replaceBroker(ctx context.Context, cluster *v1beta1.KafkaCluster, brokerId string) (string, error) {
+	index := -1
+	var maxId int32 = -1
+	for i, broker := range cluster.Spec.Brokers {
+		if strconv.Itoa(int(broker.Id)) == brokerId {
+			index = i
+		}
+		if broker.Id > maxId {
+			maxId = broker.Id
+		}
+	}
+	// the broker has been replaced already when the status update of the incident failed
+	if index < 0 {
+		return "", nil
+	}
+	for id := range cluster.Status.BrokersState {
+		if parsed, err := strconv.Atoi(id); err == nil && int32(parsed) > maxId {
+			maxId = int32(parsed)
+		}
+	}
+
+	status := cluster.Status.DeepCopy()
+	cluster.Spec.Brokers[index].Id = maxId + 1
+	if err := r.Client.Update(ctx, cluster); err != nil {
+		return "", err
+	}
+	// the update overwrites the status with the stored one which does not hold the changes of this reconcile yet
+	cluster.Status = *status
+	return strconv.Itoa(int(maxId + 1)), nil
+}
+
+func (r *SelfHealingReconciler) event(cluster *v1beta1.KafkaCluster, eventType, reason, messageFmt string, args ...interface{}) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Eventf(cluster, eventType, reason, messageFmt, args...)
+}
+
+// openIncident returns the incident of the broker which has not been closed yet, nil is returned when there is none
+func openIncident(incidents []v1beta1.SelfHealingIncident, brokerId string) *v1beta1.SelfHealingIncident {
+	for i := range incidents {
+		if incidents[i].BrokerId == brokerId && incidents[i].IsOpen() {
+			return &incidents[i]
+		}
+	}
+	return nil
+}
+
+// trimSelfHealingIncidents drops the oldest closed incidents above maxSelfHealingIncidents
+func trimSelfHealingIncidents(incidents []v1beta1.SelfHealingIncident) []v1beta1.SelfHealingIncident {
+	closed := 0
+	for _, incident := range incidents {
+		if !incident.IsOpen() {
+			closed++
+		}
+	}
+	drop := len(incidents) - maxSelfHealingIncidents
+	if drop <= 0 {
+		return incidents
+	}
+	if drop > closed {
+		drop = closed
+	}
+	trimmed := make([]v1beta1.SelfHealingIncident, 0, len(incidents)-drop)
+	for _, incident := range incidents {
+		if drop > 0 && !incident.IsOpen() {
+			drop--
+			continue
+		}
+		trimmed = append(trimmed, incident)
+	}
+	return trimmed
+}
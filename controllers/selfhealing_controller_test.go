@@ -0,0 +1,269 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	//nolint:staticcheck
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+	"github.com/banzaicloud/kafka-operator/pkg/cruisecontrol"
+)
+
+func newSelfHealingTestReconciler(t *testing.T, config *v1beta1.SelfHealingConfig) (*SelfHealingReconciler, *cruisecontrol.FakeServer, *record.FakeRecorder) {
+	scheme := runtime.NewScheme()
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: testNamespace},
+		Spec: v1beta1.KafkaClusterSpec{
+			Brokers:             []v1beta1.Broker{{Id: 0}, {Id: 1}, {Id: 2}},
+			CruiseControlConfig: v1beta1.CruiseControlConfig{SelfHealing: config},
+		},
+		Status: v1beta1.KafkaClusterStatus{CruiseControlTopicStatus: v1beta1.CruiseControlTopicReady},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(cluster).Build()
+
+	server := cruisecontrol.NewFakeServer()
+	t.Cleanup(server.Close)
+	newCruiseControlClient = func(client.Client, *v1beta1.KafkaCluster) (cruisecontrol.Client, error) {
+		return cruisecontrol.New(server.ClientConfig()), nil
+	}
+	t.Cleanup(func() { newCruiseControlClient = cruisecontrol.NewFromCluster })
+
+	recorder := record.NewFakeRecorder(10)
+	return &SelfHealingReconciler{Client: k8sClient, Log: log, Recorder: recorder}, server, recorder
+}
+
+func reconcileSelfHealing(t *testing.T, r *SelfHealingReconciler) *v1beta1.KafkaCluster {
+	key := types.NamespacedName{Name: "kafka", Namespace: testNamespace}
+	if _, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	cluster := &v1beta1.KafkaCluster{}
+	if err := r.Client.Get(context.TODO(), key, cluster); err != nil {
+		t.Fatal(err)
+	}
+	return cluster
+}
+
+func expectEvent(t *testing.T, recorder *record.FakeRecorder, reason string) {
+	t.Helper()
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, reason) {
+			t.Errorf("Expected %s event, got: %s", reason, event)
+		}
+	default:
+		t.Errorf("Expected %s event, got none", reason)
+	}
+}
+
+func TestSelfHealingRecoveredBroker(t *testing.T) {
+	gracePeriod := int32(3600)
+	r, server, recorder := newSelfHealingTestReconciler(t, &v1beta1.SelfHealingConfig{Enabled: true, GracePeriodSeconds: &gracePeriod})
+	server.ClusterState.KafkaBrokerState.OfflineReplicaCountByBrokerID = map[string]int32{"1": 5}
+
+	cluster := reconcileSelfHealing(t, r)
+	if len(cluster.Status.SelfHealingIncidents) != 1 {
+		t.Fatal("Expected an incident for broker 1, got:", cluster.Status.SelfHealingIncidents)
+	}
+	if incident := cluster.Status.SelfHealingIncidents[0]; incident.BrokerId != "1" || incident.State != v1beta1.SelfHealingDetected {
+		t.Error("Expected detected incident of broker 1, got:", incident)
+	}
+	expectEvent(t, recorder, "BrokerReplicasOffline")
+
+	// the broker is not healed within the grace period
+	cluster = reconcileSelfHealing(t, r)
+	if incident := cluster.Status.SelfHealingIncidents[0]; incident.State != v1beta1.SelfHealingDetected {
+		t.Error("Expected incident to wait for the grace period, got:", incident)
+	}
+	for _, action := range server.ReceivedActions() {
+		if action != "kafka_cluster_state" {
+			t.Error("Expected no cruise control operation within the grace period, got:", action)
+		}
+	}
+
+	server.ClusterState.KafkaBrokerState.OfflineReplicaCountByBrokerID = map[string]int32{}
+	cluster = reconcileSelfHealing(t, r)
+	if incident := cluster.Status.SelfHealingIncidents[0]; incident.State != v1beta1.SelfHealingRecovered || incident.FinishedAt == "" {
+		t.Error("Expected recovered incident, got:", incident)
+	}
+	expectEvent(t, recorder, "BrokerRecovered")
+}
+
+func TestSelfHealingReplaceBroker(t *testing.T) {
+	gracePeriod := int32(0)
+	r, server, recorder := newSelfHealingTestReconciler(t, &v1beta1.SelfHealingConfig{
+		Enabled:            true,
+		GracePeriodSeconds: &gracePeriod,
+		Action:             v1beta1.SelfHealingActionRemoveBroker,
+		ReplaceBroker:      true,
+	})
+	server.ClusterState.KafkaBrokerState.OfflineReplicaCountByBrokerID = map[string]int32{"1": 5}
+
+	cluster := reconcileSelfHealing(t, r)
+	incident := cluster.Status.SelfHealingIncidents[0]
+	if incident.State != v1beta1.SelfHealingInProgress || incident.Action != v1beta1.SelfHealingActionRemoveBroker || incident.CruiseControlTaskId == "" {
+		t.Fatal("Expected healing in progress, got:", incident)
+	}
+	request := server.Requests[len(server.Requests)-1]
+	if request.Action != "remove_broker" || request.Params.Get("brokerid") != "1" {
+		t.Error("Expected broker 1 to be removed, got:", request)
+	}
+	expectEvent(t, recorder, "BrokerReplicasOffline")
+	expectEvent(t, recorder, "SelfHealingStarted")
+
+	// the incident stays open while the task is running
+	cluster = reconcileSelfHealing(t, r)
+	if incident = cluster.Status.SelfHealingIncidents[0]; incident.State != v1beta1.SelfHealingInProgress {
+		t.Error("Expected healing in progress, got:", incident)
+	}
+
+	server.SetTaskStatus(incident.CruiseControlTaskId, cruisecontrol.TaskStatusCompleted)
+	cluster = reconcileSelfHealing(t, r)
+	if incident = cluster.Status.SelfHealingIncidents[0]; incident.State != v1beta1.SelfHealingSucceeded || incident.ReplacementBrokerId != "3" {
+		t.Error("Expected broker 1 to be replaced by broker 3, got:", incident)
+	}
+	if ids := []int32{cluster.Spec.Brokers[0].Id, cluster.Spec.Brokers[1].Id, cluster.Spec.Brokers[2].Id}; ids[1] != 3 {
+		t.Error("Expected broker 1 to be replaced in the spec, got:", ids)
+	}
+	expectEvent(t, recorder, "SelfHealingSucceeded")
+}
+
+func TestSelfHealingFailedTask(t *testing.T) {
+	gracePeriod := int32(0)
+	r, server, recorder := newSelfHealingTestReconciler(t, &v1beta1.SelfHealingConfig{Enabled: true, GracePeriodSeconds: &gracePeriod})
+	server.ClusterState.KafkaBrokerState.OfflineReplicaCountByBrokerID = map[string]int32{"0": 1, "2": 3}
+
+	cluster := reconcileSelfHealing(t, r)
+	if len(cluster.Status.SelfHealingIncidents) != 2 {
+		t.Fatal("Expected incidents for broker 0 and 2, got:", cluster.Status.SelfHealingIncidents)
+	}
+	taskID := cluster.Status.SelfHealingIncidents[0].CruiseControlTaskId
+	for _, incident := range cluster.Status.SelfHealingIncidents {
+		if incident.State != v1beta1.SelfHealingInProgress || incident.Action != v1beta1.SelfHealingActionFixOfflineReplicas || incident.CruiseControlTaskId != taskID {
+			t.Error("Expected both brokers to be healed by the same task, got:", incident)
+		}
+	}
+	fixes := 0
+	for _, action := range server.ReceivedActions() {
+		if action == "fix_offline_replicas" {
+			fixes++
+		}
+	}
+	if fixes != 1 {
+		t.Error("Expected a single fix_offline_replicas request, got:", fixes)
+	}
+
+	server.SetTaskStatus(taskID, cruisecontrol.TaskStatusCompletedWithError)
+	cluster = reconcileSelfHealing(t, r)
+	for _, incident := range cluster.Status.SelfHealingIncidents {
+		if incident.State != v1beta1.SelfHealingFailed || incident.Message == "" {
+			t.Error("Expected failed incident with message, got:", incident)
+		}
+	}
+	expectEvent(t, recorder, "BrokerReplicasOffline")
+	expectEvent(t, recorder, "BrokerReplicasOffline")
+	expectEvent(t, recorder, "SelfHealingStarted")
+	expectEvent(t, recorder, "SelfHealingFailed")
+}
+
+func TestTrimSelfHealingIncidents(t *testing.T) {
+	incidents := []v1beta1.SelfHealingIncident{{BrokerId: "0", State: v1beta1.SelfHealingDetected}}
+	for i := 0; i < maxSelfHealingIncidents+2; i++ {
+		incidents = append(incidents, v1beta1.SelfHealingIncident{BrokerId: "1", State: v1beta1.SelfHealingRecovered})
+	}
+	trimmed := trimSelfHealingIncidents(incidents)
+	if len(trimmed) != maxSelfHealingIncidents {
+		t.Error("Expected incidents to be trimmed, got:", len(trimmed))
+	}
+	if openIncident(trimmed, "0") == nil {
+		t.Error("Expected open incident to be kept")
+	}
+}
+
+// ccResponses are responses of cruise control in the shape the state endpoints serve them
+var ccResponses = map[string]string{
+	"/kafkacruisecontrol/state": `{
+		"MonitorState": {"state": "RUNNING", "trainingPct": 20.0, "trained": false, "numFlawedPartitions": 0,
+			"numValidPartitions": 36, "numTotalPartitions": 36, "monitoringCoveragePct": 100.0},
+		"ExecutorState": {"state": "NO_TASK_IN_PROGRESS"},
+		"AnalyzerState": {"isProposalReady": true, "readyGoals": ["ReplicaCapacityGoal", "DiskCapacityGoal"]},
+		"AnomalyDetectorState": {"selfHealingEnabled": [], "selfHealingDisabled": ["DISK_FAILURE", "BROKER_FAILURE"]},
+		"version": 1
+	}`,
+	"/kafkacruisecontrol/kafka_cluster_state": `{
+		"KafkaPartitionState": {
+			"offline": [],
+			"urp": [{"topic": "test", "partition": 0, "leader": 0, "replicas": [0, 1, 2], "in-sync": [0, 2], "out-of-sync": [1], "offline": [1]}],
+			"with-offline-replicas": [{"topic": "test", "partition": 0, "leader": 0, "replicas": [0, 1, 2], "in-sync": [0, 2], "out-of-sync": [1], "offline": [1]}],
+			"under-min-isr": []
+		},
+		"KafkaBrokerState": {
+			"IsController": {"0": true, "1": false, "2": false},
+			"OfflineLogDirsByBrokerId": {"0": [], "1": ["/kafka-logs/kafka"], "2": []},
+			"ReplicaCountByBrokerId": {"0": 12, "1": 12, "2": 12},
+			"OutOfSyncCountByBrokerId": {"1": 12},
+			"Summary": {"StdLeadersPerBroker": 0.0, "Leaders": 12, "MaxLeadersPerBroker": 4, "Topics": 1, "MaxReplicasPerBroker": 12, "StdReplicasPerBroker": 0.0, "Brokers": 3, "AvgReplicationFactor": 3.0, "AvgLeadersPerBroker": 4.0, "Replicas": 36, "AvgReplicasPerBroker": 12.0},
+			"OfflineReplicaCountByBrokerId": {"1": 12},
+			"LeaderCountByBrokerId": {"0": 4, "1": 4, "2": 4},
+			"OnlineLogDirsByBrokerId": {"0": ["/kafka-logs/kafka"], "1": [], "2": ["/kafka-logs/kafka"]}
+		},
+		"version": 1
+	}`,
+}
+
+func TestSelfHealingCruiseControlResponses(t *testing.T) {
+	gracePeriod := int32(3600)
+	r, _, recorder := newSelfHealingTestReconciler(t, &v1beta1.SelfHealingConfig{Enabled: true, GracePeriodSeconds: &gracePeriod})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response, ok := ccResponses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(response))
+	}))
+	defer server.Close()
+	newCruiseControlClient = func(client.Client, *v1beta1.KafkaCluster) (cruisecontrol.Client, error) {
+		return cruisecontrol.New(&cruisecontrol.Config{BaseURL: server.URL + "/kafkacruisecontrol", Timeout: 5 * time.Second}), nil
+	}
+
+	cluster := reconcileSelfHealing(t, r)
+	if len(cluster.Status.SelfHealingIncidents) != 1 {
+		t.Fatal("Expected an incident for broker 1, got:", cluster.Status.SelfHealingIncidents)
+	}
+	if incident := cluster.Status.SelfHealingIncidents[0]; incident.BrokerId != "1" || incident.State != v1beta1.SelfHealingDetected {
+		t.Error("Expected detected incident of broker 1, got:", incident)
+	}
+	expectEvent(t, recorder, "BrokerReplicasOffline")
+}
@@ -91,8 +91,8 @@ var _ = Describe("KafkaTopic", func() {
 					Partitions:        17,
 					ReplicationFactor: 19,
 					Config: map[string]string{
-						"key1": "value1",
-						"key2": "value2",
+						"retention.ms":   "604800000",
+						"cleanup.policy": "delete",
 					},
 					ClusterRef: v1alpha1.ClusterReference{
 						Name:      kafkaCluster.Name,
@@ -123,8 +123,8 @@ var _ = Describe("KafkaTopic", func() {
 				NumPartitions:     17,
 				ReplicationFactor: 19,
 				ConfigEntries: map[string]*string{
-					"key1": util.StringPointer("value1"),
-					"key2": util.StringPointer("value2"),
+					"retention.ms":   util.StringPointer("604800000"),
+					"cleanup.policy": util.StringPointer("delete"),
 				},
 			}))
 
@@ -150,7 +150,7 @@ var _ = Describe("KafkaTopic", func() {
 				Partitions:        11,
 				ReplicationFactor: 13,
 				Config: map[string]*string{
-					"key": util.StringPointer("value"),
+					"segment.ms": util.StringPointer("3600000"),
 				},
 			})
 			Expect(err).NotTo(HaveOccurred())
@@ -169,8 +169,8 @@ var _ = Describe("KafkaTopic", func() {
 					Partitions:        17,
 					ReplicationFactor: 19,
 					Config: map[string]string{
-						"key1": "value1",
-						"key2": "value2",
+						"retention.ms":   "604800000",
+						"cleanup.policy": "delete",
 					},
 					ClusterRef: v1alpha1.ClusterReference{
 						Name:      kafkaCluster.Name,
@@ -200,8 +200,10 @@ var _ = Describe("KafkaTopic", func() {
 			Expect(detail).To(Equal(&sarama.TopicDetail{
 				NumPartitions:     11,
 				ReplicationFactor: 13,
+				// the stale override is removed and the spec config is applied
 				ConfigEntries: map[string]*string{
-					"key": util.StringPointer("value"),
+					"retention.ms":   util.StringPointer("604800000"),
+					"cleanup.policy": util.StringPointer("delete"),
 				},
 			}))
 
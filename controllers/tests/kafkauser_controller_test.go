@@ -210,11 +210,62 @@ var _ = Describe("KafkaTopic", func() {
 
 		Expect(user.Status.ACLs).To(ConsistOf(
 			"User:CN=kafkauser-1,Topic,ANY,test-topic-1,Describe,Allow,*",
+			"User:CN=kafkauser-1,Topic,ANY,test-topic-1,DescribeConfigs,Allow,*",
 			"User:CN=kafkauser-1,Topic,ANY,test-topic-1,Read,Allow,*",
 			"User:CN=kafkauser-1,Group,LITERAL,*,Read,Allow,*",
 			"User:CN=kafkauser-1,Topic,LITERAL,test-topic-2,Describe,Allow,*",
+			"User:CN=kafkauser-1,Topic,LITERAL,test-topic-2,DescribeConfigs,Allow,*",
 			"User:CN=kafkauser-1,Topic,LITERAL,test-topic-2,Create,Allow,*",
 			"User:CN=kafkauser-1,Topic,LITERAL,test-topic-2,Write,Allow,*",
 		))
 	})
+
+	It("reconciles SCRAM users properly", func() {
+		userCRName := fmt.Sprintf("kafkauser-scram-%v", count)
+		user := v1alpha1.KafkaUser{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      userCRName,
+				Namespace: namespace,
+			},
+			Spec: v1alpha1.KafkaUserSpec{
+				SecretName: userCRName + "-secret",
+				ClusterRef: v1alpha1.ClusterReference{
+					Namespace: namespace,
+					Name:      kafkaClusterCRName,
+				},
+				AuthenticationType: v1alpha1.UserAuthenticationSCRAMSHA512,
+			},
+		}
+
+		err := k8sClient.Create(context.TODO(), &user)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() (v1alpha1.UserState, error) {
+			user := v1alpha1.KafkaUser{}
+			err := k8sClient.Get(context.Background(), types.NamespacedName{
+				Namespace: kafkaCluster.Namespace,
+				Name:      userCRName,
+			}, &user)
+			if err != nil {
+				return "", err
+			}
+			return user.Status.State, nil
+		}, 5*time.Second, 100*time.Millisecond).Should(Equal(v1alpha1.UserStateCreated))
+
+		secret := corev1.Secret{}
+		err = k8sClient.Get(context.Background(), types.NamespacedName{
+			Namespace: namespace,
+			Name:      user.Spec.SecretName,
+		}, &secret)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(secret.Data).To(HaveKeyWithValue(v1alpha1.UsernameKey, []byte(userCRName)))
+		Expect(secret.Data).To(HaveKeyWithValue(v1alpha1.SASLMechanismKey, []byte("SCRAM-SHA-512")))
+		Expect(secret.Data).To(HaveKey(v1alpha1.PasswordKey))
+
+		mockKafkaClient, _ := getMockedKafkaClientForCluster(kafkaCluster)
+		infos, err := mockKafkaClient.DescribeUserScramCredentials(userCRName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(infos).To(HaveLen(1))
+		Expect(infos[0].Mechanism).To(Equal(sarama.SCRAM_MECHANISM_SHA_512))
+	})
 })
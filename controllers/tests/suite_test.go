@@ -149,10 +149,16 @@ var _ = BeforeSuite(func(done Done) {
 	err = controllers.SetupKafkaClusterWithManager(mgr, kafkaClusterReconciler.Log).Complete(&kafkaClusterReconciler)
 	Expect(err).NotTo(HaveOccurred())
 
-	err = controllers.SetupKafkaTopicWithManager(mgr, 10)
+	err = controllers.SetupKafkaTopicWithManager(mgr, 10, 0)
 	Expect(err).NotTo(HaveOccurred())
 
-	err = controllers.SetupKafkaUserWithManager(mgr, true)
+	err = controllers.SetupKafkaRebalanceWithManager(mgr)
+	Expect(err).NotTo(HaveOccurred())
+
+	err = controllers.SetupSelfHealingWithManager(mgr)
+	Expect(err).NotTo(HaveOccurred())
+
+	err = controllers.SetupKafkaUserWithManager(mgr, true, 0)
 	Expect(err).NotTo(HaveOccurred())
 
 	kafkaClusterCCReconciler := controllers.CruiseControlTaskReconciler{
@@ -4,7 +4,7 @@ go 1.16
 
 require (
 	emperror.dev/errors v0.8.0
-	github.com/Shopify/sarama v1.29.0
+	github.com/Shopify/sarama v1.31.1
 	github.com/banzaicloud/bank-vaults/pkg/sdk v0.3.1
 	github.com/banzaicloud/istio-client-go v0.0.9
 	github.com/banzaicloud/istio-operator v0.0.0-20210603082335-fd31d6ff3e0d
@@ -29,11 +29,16 @@ require (
 	github.com/onsi/ginkgo v1.16.1
 	github.com/onsi/gomega v1.11.0
 	github.com/pavel-v-chernykh/keystore-go v2.1.0+incompatible
+	github.com/prometheus/client_golang v1.7.1
 	github.com/prometheus/common v0.10.0
 	github.com/shirou/gopsutil v3.20.12+incompatible // indirect
 	github.com/spf13/cast v1.3.1 // indirect
+	github.com/xdg/scram v1.0.3
+	github.com/xdg/stringprep v1.0.3 // indirect
 	go.uber.org/zap v1.15.0
 	google.golang.org/protobuf v1.25.0
+	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
+	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b // indirect
 	gotest.tools v2.2.0+incompatible
 	k8s.io/api v0.20.2
 	k8s.io/apiextensions-apiserver v0.20.1
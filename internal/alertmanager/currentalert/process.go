@@ -309,8 +309,16 @@ func downScale(log logr.Logger, labels model.LabelSet, client client.Client) err
 		return nil
 	}
 
-	cc := scale.NewCruiseControlScaler(string(labels["namespace"]), cr.Spec.GetKubernetesClusterDomain(), cr.Spec.CruiseControlConfig.CruiseControlEndpoint, cr.Name)
-	brokerId, err := cc.GetBrokerIDWithLeastPartition()
+	cc, err := scale.NewCruiseControlScaler(client, cr)
+	if err != nil {
+		return err
+	}
+	// with rack awareness the broker is removed from the most populated rack to keep the racks balanced
+	var candidates []string
+	if cr.Spec.RackAwareness != nil {
+		candidates = k8sutil.BrokersOfMostPopulatedRack(cr)
+	}
+	brokerId, err := cc.GetBrokerIDWithLeastPartitionAmong(candidates)
 	if err != nil {
 		return err
 	}
@@ -393,6 +401,7 @@ func upScale(log logr.Logger, labels model.LabelSet, annotations model.LabelSet,
 		}
 	}
 
+	// with rack awareness the new broker is pinned to the least populated rack by the KafkaCluster reconciler
 	err = k8sutil.AddNewBrokerToCr(broker, string(labels["kafka_cr"]), string(labels["namespace"]), client)
 	if err != nil {
 		return err
@@ -33,6 +33,7 @@ import (
 	"flag"
 	"os"
 	"strings"
+	"time"
 
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 
@@ -84,6 +85,10 @@ func main() {
 		verboseLogging                    bool
 		certManagerEnabled                bool
 		maxKafkaTopicConcurrentReconciles int
+		kafkaUserResyncPeriod             time.Duration
+		kafkaTopicResyncPeriod            time.Duration
+		topicDiscoveryNamespace           string
+		topicDiscoveryPeriod              time.Duration
 	)
 
 	flag.StringVar(&namespaces, "namespaces", "", "Comma separated list of namespaces where operator listens for resources")
@@ -96,6 +101,10 @@ func main() {
 	flag.BoolVar(&verboseLogging, "verbose", false, "Enable verbose logging")
 	flag.BoolVar(&certManagerEnabled, "cert-manager-enabled", false, "Enable cert-manager integration")
 	flag.IntVar(&maxKafkaTopicConcurrentReconciles, "max-kafka-topic-concurrent-reconciles", 10, "Define max amount of concurrent KafkaTopic reconciles")
+	flag.DurationVar(&kafkaTopicResyncPeriod, "kafka-topic-resync-period", 5*time.Minute, "Interval of the periodic KafkaTopic reconciliation refreshing the partition health in the topic status, 0 disables it")
+	flag.StringVar(&topicDiscoveryNamespace, "topic-discovery-namespace", "", "Namespace KafkaTopics are generated in for the unmanaged topics of the kafka clusters, topic discovery is disabled when empty")
+	flag.DurationVar(&topicDiscoveryPeriod, "topic-discovery-period", 5*time.Minute, "Interval the kafka clusters are checked for unmanaged topics at")
+	flag.DurationVar(&kafkaUserResyncPeriod, "kafka-user-resync-period", 5*time.Minute, "Interval of the periodic KafkaUser reconciliation correcting ACL drifts, 0 disables it")
 	flag.Parse()
 
 	ctrl.SetLogger(util.CreateLogger(verboseLogging, developmentLogging))
@@ -152,12 +161,29 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err = controllers.SetupKafkaTopicWithManager(mgr, maxKafkaTopicConcurrentReconciles); err != nil {
+	if err = controllers.SetupKafkaTopicWithManager(mgr, maxKafkaTopicConcurrentReconciles, kafkaTopicResyncPeriod); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "KafkaTopic")
 		os.Exit(1)
 	}
 
-	if err = controllers.SetupKafkaUserWithManager(mgr, certManagerEnabled); err != nil {
+	if topicDiscoveryNamespace != "" {
+		if err = controllers.SetupKafkaTopicDiscoveryWithManager(mgr, topicDiscoveryNamespace, topicDiscoveryPeriod); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "KafkaTopicDiscovery")
+			os.Exit(1)
+		}
+	}
+
+	if err = controllers.SetupKafkaRebalanceWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KafkaRebalance")
+		os.Exit(1)
+	}
+
+	if err = controllers.SetupSelfHealingWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SelfHealing")
+		os.Exit(1)
+	}
+
+	if err = controllers.SetupKafkaUserWithManager(mgr, certManagerEnabled, kafkaUserResyncPeriod); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "KafkaUser")
 		os.Exit(1)
 	}
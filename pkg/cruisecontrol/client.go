@@ -0,0 +1,338 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cruisecontrol
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+)
+
+const (
+	stateAction              = "state"
+	kafkaClusterStateAction  = "kafka_cluster_state"
+	loadAction               = "load"
+	partitionLoadAction      = "partition_load"
+	proposalsAction          = "proposals"
+	userTasksAction          = "user_tasks"
+	rebalanceAction          = "rebalance"
+	addBrokerAction          = "add_broker"
+	removeBrokerAction       = "remove_broker"
+	demoteBrokerAction       = "demote_broker"
+	fixOfflineReplicasAction = "fix_offline_replicas"
+	stopProposalAction       = "stop_proposal_execution"
+	pauseSamplingAction      = "pause_sampling"
+	resumeSamplingAction     = "resume_sampling"
+
+	userTaskIDHeader = "User-Task-Id"
+)
+
+var log = logf.Log.WithName("cruise-control-client")
+
+// transports are the transports of the TLS clients keyed by BaseURL, the clients are created on every reconcile
+// and share the transport, so that the idle connections are reused instead of leaked
+var transports = struct {
+	sync.Mutex
+	byURL map[string]sharedTransport
+}{byURL: map[string]sharedTransport{}}
+
+type sharedTransport struct {
+	tlsVersion string
+	transport  *http.Transport
+}
+
+// Client is a client of the REST API of cruise control
+type Client interface {
+	State(ctx context.Context) (*StateResponse, error)
+	KafkaClusterState(ctx context.Context) (*KafkaClusterStateResponse, error)
+	Load(ctx context.Context) (*LoadResponse, error)
+	PartitionLoad(ctx context.Context, options map[string]string) (*PartitionLoadResponse, error)
+	Proposals(ctx context.Context, request OperationRequest) (*OptimizationResult, error)
+	UserTasks(ctx context.Context, taskIDs ...string) (*UserTasksResponse, error)
+
+	Rebalance(ctx context.Context, request RebalanceRequest) (*OperationResponse, error)
+	AddBrokers(ctx context.Context, request BrokerOperationRequest) (*OperationResponse, error)
+	RemoveBrokers(ctx context.Context, request BrokerOperationRequest) (*OperationResponse, error)
+	DemoteBrokers(ctx context.Context, request BrokerOperationRequest) (*OperationResponse, error)
+	FixOfflineReplicas(ctx context.Context, request OperationRequest) (*OperationResponse, error)
+	StopProposalExecution(ctx context.Context) error
+
+	PauseSampling(ctx context.Context, reason string) error
+	ResumeSampling(ctx context.Context, reason string) error
+}
+
+// APIError is returned when cruise control answers with an unexpected status code
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("cruise control returned %d: %s", e.StatusCode, e.Message)
+}
+
+type ccClient struct {
+	opts       *Config
+	httpClient *http.Client
+}
+
+// New returns a new cruise control client
+func New(opts *Config) Client {
+	return &ccClient{
+		opts:       opts,
+		httpClient: &http.Client{Transport: transportFor(opts)},
+	}
+}
+
+// transportFor returns the transport shared by the clients of the same BaseURL and TLS config,
+// the transport of an outdated TLS config is closed
+func transportFor(opts *Config) http.RoundTripper {
+	if opts.TLSConfig == nil {
+		return http.DefaultTransport
+	}
+	tlsVersion := opts.tlsVersion
+	if tlsVersion == "" {
+		tlsVersion = fmt.Sprintf("%p", opts.TLSConfig)
+	}
+
+	transports.Lock()
+	defer transports.Unlock()
+	shared, ok := transports.byURL[opts.BaseURL]
+	if ok && shared.tlsVersion == tlsVersion {
+		return shared.transport
+	}
+	if ok {
+		shared.transport.CloseIdleConnections()
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = opts.TLSConfig
+	transports.byURL[opts.BaseURL] = sharedTransport{tlsVersion: tlsVersion, transport: transport}
+	return transport
+}
+
+// NewFromCluster is a convenience wrapper around New() and ClusterConfig()
+func NewFromCluster(k8sclient client.Client, cluster *v1beta1.KafkaCluster) (Client, error) {
+	opts, err := ClusterConfig(k8sclient, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return New(opts), nil
+}
+
+// State returns the state of the cruise control components
+func (c *ccClient) State(ctx context.Context) (*StateResponse, error) {
+	state := &StateResponse{}
+	params := url.Values{}
+	params.Set("verbose", "true")
+	if _, err := c.get(ctx, stateAction, params, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// KafkaClusterState returns the state of the brokers and the partitions of the kafka cluster
+func (c *ccClient) KafkaClusterState(ctx context.Context) (*KafkaClusterStateResponse, error) {
+	state := &KafkaClusterStateResponse{}
+	params := url.Values{}
+	params.Set("verbose", "true")
+	if _, err := c.get(ctx, kafkaClusterStateAction, params, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// Load returns the load of the brokers
+func (c *ccClient) Load(ctx context.Context) (*LoadResponse, error) {
+	load := &LoadResponse{}
+	if _, err := c.get(ctx, loadAction, url.Values{}, load); err != nil {
+		return nil, err
+	}
+	return load, nil
+}
+
+// PartitionLoad returns the load of the partitions, the options filter and sort the partitions, e.g. topic or resource
+func (c *ccClient) PartitionLoad(ctx context.Context, options map[string]string) (*PartitionLoadResponse, error) {
+	params := url.Values{}
+	for option, value := range options {
+		params.Set(option, value)
+	}
+	load := &PartitionLoadResponse{}
+	if _, err := c.get(ctx, partitionLoadAction, params, load); err != nil {
+		return nil, err
+	}
+	return load, nil
+}
+
+// Proposals returns the proposal of cruise control for balancing the cluster according to the goals
+func (c *ccClient) Proposals(ctx context.Context, request OperationRequest) (*OptimizationResult, error) {
+	params := request.params()
+	params.Del("dryrun")
+	result := &OptimizationResult{}
+	if _, err := c.get(ctx, proposalsAction, params, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// UserTasks returns the user tasks with the given ids, all the tasks are returned when no ids are given
+func (c *ccClient) UserTasks(ctx context.Context, taskIDs ...string) (*UserTasksResponse, error) {
+	params := url.Values{}
+	if len(taskIDs) > 0 {
+		params.Set("user_task_ids", strings.Join(taskIDs, ","))
+	}
+	tasks := &UserTasksResponse{}
+	if _, err := c.get(ctx, userTasksAction, params, tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// Rebalance moves replicas and leaders of the cluster according to the goals
+func (c *ccClient) Rebalance(ctx context.Context, request RebalanceRequest) (*OperationResponse, error) {
+	return c.operation(ctx, rebalanceAction, request.params(), request.TaskID)
+}
+
+// AddBrokers moves replicas to the given brokers
+func (c *ccClient) AddBrokers(ctx context.Context, request BrokerOperationRequest) (*OperationResponse, error) {
+	return c.operation(ctx, addBrokerAction, request.params(), request.TaskID)
+}
+
+// RemoveBrokers moves all replicas away from the given brokers
+func (c *ccClient) RemoveBrokers(ctx context.Context, request BrokerOperationRequest) (*OperationResponse, error) {
+	return c.operation(ctx, removeBrokerAction, request.params(), request.TaskID)
+}
+
+// DemoteBrokers moves the leadership of the partitions away from the given brokers
+func (c *ccClient) DemoteBrokers(ctx context.Context, request BrokerOperationRequest) (*OperationResponse, error) {
+	return c.operation(ctx, demoteBrokerAction, request.params(), request.TaskID)
+}
+
+// FixOfflineReplicas moves the offline replicas of the cluster to healthy brokers and disks
+func (c *ccClient) FixOfflineReplicas(ctx context.Context, request OperationRequest) (*OperationResponse, error) {
+	return c.operation(ctx, fixOfflineReplicasAction, request.params(), request.TaskID)
+}
+
+// StopProposalExecution stops the execution of the ongoing operation
+func (c *ccClient) StopProposalExecution(ctx context.Context) error {
+	_, err := c.post(ctx, stopProposalAction, url.Values{}, "", nil)
+	return err
+}
+
+// PauseSampling pauses the collection of the load metrics
+func (c *ccClient) PauseSampling(ctx context.Context, reason string) error {
+	params := url.Values{}
+	params.Set("reason", reason)
+	_, err := c.post(ctx, pauseSamplingAction, params, "", nil)
+	return err
+}
+
+// ResumeSampling resumes the collection of the load metrics
+func (c *ccClient) ResumeSampling(ctx context.Context, reason string) error {
+	params := url.Values{}
+	params.Set("reason", reason)
+	_, err := c.post(ctx, resumeSamplingAction, params, "", nil)
+	return err
+}
+
+// operation posts an operation to cruise control, cruise control answers with 202 and the progress of
+// the operation until it has computed the proposal, the result of the operation is nil until then
+func (c *ccClient) operation(ctx context.Context, action string, params url.Values, taskID string) (*OperationResponse, error) {
+	result := &OptimizationResult{}
+	rsp, err := c.post(ctx, action, params, taskID, result)
+	if err != nil {
+		return nil, err
+	}
+	response := &OperationResponse{
+		TaskID: rsp.Header.Get(userTaskIDHeader),
+		Date:   rsp.Header.Get("Date"),
+	}
+	if rsp.StatusCode == http.StatusOK {
+		response.Result = result
+	}
+	return response, nil
+}
+
+func (c *ccClient) get(ctx context.Context, action string, params url.Values, into interface{}) (*http.Response, error) {
+	return c.do(ctx, http.MethodGet, action, params, "", into)
+}
+
+func (c *ccClient) post(ctx context.Context, action string, params url.Values, taskID string, into interface{}) (*http.Response, error) {
+	return c.do(ctx, http.MethodPost, action, params, taskID, into)
+}
+
+// do sends a request to cruise control and decodes the response into the given value, the body of the
+// responses with status 202 is a progress report and is not decoded
+func (c *ccClient) do(ctx context.Context, method, action string, params url.Values, taskID string, into interface{}) (*http.Response, error) {
+	if c.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.opts.Timeout)
+		defer cancel()
+	}
+
+	params.Set("json", "true")
+	requestURL := fmt.Sprintf("%s/%s?%s", strings.TrimSuffix(c.opts.BaseURL, "/"), action, params.Encode())
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.opts.BasicAuthUser != "" {
+		req.SetBasicAuth(c.opts.BasicAuthUser, c.opts.BasicAuthPassword)
+	}
+	if taskID != "" {
+		req.Header.Set(userTaskIDHeader, taskID)
+	}
+
+	rsp, err := c.httpClient.Do(req)
+	if err != nil {
+		log.Error(err, "error during talking to cruise-control", "action", action)
+		return nil, err
+	}
+	defer rsp.Body.Close()
+
+	body, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case rsp.StatusCode == http.StatusAccepted:
+		return rsp, nil
+	case rsp.StatusCode != http.StatusOK:
+		apiErr := &APIError{StatusCode: rsp.StatusCode, Message: rsp.Status}
+		var errorResponse struct {
+			ErrorMessage string `json:"errorMessage"`
+		}
+		if json.Unmarshal(body, &errorResponse) == nil && errorResponse.ErrorMessage != "" {
+			apiErr.Message = errorResponse.ErrorMessage
+		}
+		log.Error(apiErr, "non-200 response returned by cruise-control", "action", action, "status", rsp.Status)
+		return rsp, apiErr
+	case into != nil:
+		if err = json.Unmarshal(body, into); err != nil {
+			return rsp, err
+		}
+	}
+	return rsp, nil
+}
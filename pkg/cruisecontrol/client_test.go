@@ -0,0 +1,267 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cruisecontrol
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestClientOperations(t *testing.T) {
+	server := NewFakeServer()
+	defer server.Close()
+	server.Proposal = &OptimizationResult{
+		Summary:     OptimizationSummary{NumReplicaMovements: 3, DataToMoveMB: 100},
+		GoalSummary: []GoalResult{{Goal: "DiskCapacityGoal", Status: GoalStatusFixed}, {Goal: "RackAwareGoal", Status: GoalStatusViolated}},
+	}
+	client := New(server.ClientConfig())
+	ctx := context.Background()
+
+	rsp, err := client.Rebalance(ctx, RebalanceRequest{OperationRequest: OperationRequest{Goals: []string{"DiskCapacityGoal"}}, RebalanceDisk: true})
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if rsp.TaskID != "task-1" {
+		t.Error("Expected task id task-1, got:", rsp.TaskID)
+	}
+	if rsp.Result == nil || rsp.Result.Summary.NumReplicaMovements != 3 {
+		t.Error("Expected the proposal as result, got:", rsp.Result)
+	}
+	request := server.Requests[0]
+	if request.Method != http.MethodPost || request.Params.Get("goals") != "DiskCapacityGoal" ||
+		request.Params.Get("rebalance_disk") != "true" || request.Params.Get("dryrun") != "false" || request.Params.Get("json") != "true" {
+		t.Error("Unexpected rebalance request:", request)
+	}
+	if task := server.Tasks["task-1"]; task == nil || task.Status != TaskStatusActive {
+		t.Error("Expected an active task for the rebalance, got:", task)
+	}
+
+	if _, err = client.RemoveBrokers(ctx, BrokerOperationRequest{OperationRequest: OperationRequest{DryRun: true}, BrokerIDs: []string{"1", "2"}}); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if request = server.Requests[1]; request.Action != removeBrokerAction || request.Params.Get("brokerid") != "1,2" || request.Params.Get("dryrun") != "true" {
+		t.Error("Unexpected remove broker request:", request)
+	}
+	if _, ok := server.Tasks["task-2"]; ok {
+		t.Error("Expected no task for a dry run")
+	}
+
+	for _, operation := range []func() (*OperationResponse, error){
+		func() (*OperationResponse, error) {
+			return client.AddBrokers(ctx, BrokerOperationRequest{BrokerIDs: []string{"3"}})
+		},
+		func() (*OperationResponse, error) {
+			return client.DemoteBrokers(ctx, BrokerOperationRequest{BrokerIDs: []string{"0"}})
+		},
+		func() (*OperationResponse, error) { return client.FixOfflineReplicas(ctx, OperationRequest{}) },
+	} {
+		if _, err = operation(); err != nil {
+			t.Error("Expected no error, got:", err)
+		}
+	}
+	expected := []string{rebalanceAction, removeBrokerAction, addBrokerAction, demoteBrokerAction, fixOfflineReplicasAction}
+	if actions := server.ReceivedActions(); !reflect.DeepEqual(actions, expected) {
+		t.Errorf("Expected actions %v, got: %v", expected, actions)
+	}
+
+	if err = client.StopProposalExecution(ctx); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if status := server.Tasks["task-1"].Status; status != TaskStatusCompletedWithError {
+		t.Error("Expected the stopped task to be completed with error, got:", status)
+	}
+}
+
+func TestClientPendingOperation(t *testing.T) {
+	server := NewFakeServer()
+	defer server.Close()
+	server.Proposal = nil
+	client := New(server.ClientConfig())
+
+	request := RebalanceRequest{OperationRequest: OperationRequest{DryRun: true}}
+	rsp, err := client.Rebalance(context.Background(), request)
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if rsp.Result != nil {
+		t.Error("Expected no result while the proposal is computed, got:", rsp.Result)
+	}
+
+	server.Proposal = &OptimizationResult{Summary: OptimizationSummary{NumLeaderMovements: 2}}
+	request.TaskID = rsp.TaskID
+	if rsp, err = client.Rebalance(context.Background(), request); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if rsp.TaskID != request.TaskID {
+		t.Errorf("Expected task id %s, got: %s", request.TaskID, rsp.TaskID)
+	}
+	if rsp.Result == nil || rsp.Result.Summary.NumLeaderMovements != 2 {
+		t.Error("Expected the computed proposal, got:", rsp.Result)
+	}
+	if taskID := server.Requests[1].TaskID; taskID != request.TaskID {
+		t.Error("Expected the task id to be sent in the header, got:", taskID)
+	}
+}
+
+func TestClientQueries(t *testing.T) {
+	server := NewFakeServer()
+	defer server.Close()
+	server.State.ExecutorState.State = "NO_TASK_IN_PROGRESS"
+	server.ClusterState.KafkaBrokerState.OfflineReplicaCountByBrokerID = map[string]int32{"1": 4}
+	server.Load.Brokers = []BrokerLoad{{Broker: 0, BrokerState: BrokerStateAlive}}
+	server.PartitionLoad.Records = []PartitionLoad{{Topic: "test-topic", Partition: 0}}
+	server.SetTaskStatus("task-1", TaskStatusCompleted)
+	server.SetTaskStatus("task-2", TaskStatusInExecution)
+	client := New(server.ClientConfig())
+	ctx := context.Background()
+
+	if state, err := client.State(ctx); err != nil {
+		t.Error("Expected no error, got:", err)
+	} else if state.ExecutorState.State != "NO_TASK_IN_PROGRESS" {
+		t.Error("Expected executor state, got:", state.ExecutorState)
+	}
+	if state, err := client.KafkaClusterState(ctx); err != nil {
+		t.Error("Expected no error, got:", err)
+	} else if state.KafkaBrokerState.OfflineReplicaCountByBrokerID["1"] != 4 {
+		t.Error("Expected offline replicas of broker 1, got:", state.KafkaBrokerState)
+	}
+	if load, err := client.Load(ctx); err != nil {
+		t.Error("Expected no error, got:", err)
+	} else if len(load.Brokers) != 1 || load.Brokers[0].BrokerState != BrokerStateAlive {
+		t.Error("Expected load of broker 0, got:", load)
+	}
+	if load, err := client.PartitionLoad(ctx, map[string]string{"topic": "test-topic"}); err != nil {
+		t.Error("Expected no error, got:", err)
+	} else if len(load.Records) != 1 {
+		t.Error("Expected load of a partition, got:", load)
+	}
+	if topic := server.Requests[3].Params.Get("topic"); topic != "test-topic" {
+		t.Error("Expected topic filter to be sent, got:", topic)
+	}
+	if _, err := client.Proposals(ctx, OperationRequest{DryRun: true}); err != nil {
+		t.Error("Expected no error, got:", err)
+	} else if _, ok := server.Requests[4].Params["dryrun"]; ok {
+		t.Error("Expected no dryrun parameter for proposals")
+	}
+	if tasks, err := client.UserTasks(ctx, "task-2", "unknown"); err != nil {
+		t.Error("Expected no error, got:", err)
+	} else if len(tasks.UserTasks) != 1 || tasks.UserTasks[0].Status != TaskStatusInExecution {
+		t.Error("Expected task-2 in execution, got:", tasks.UserTasks)
+	}
+
+	if err := client.PauseSampling(ctx, "upgrade"); err != nil {
+		t.Error("Expected no error, got:", err)
+	} else if server.Sampling {
+		t.Error("Expected sampling to be paused")
+	}
+	if err := client.ResumeSampling(ctx, "upgrade"); err != nil {
+		t.Error("Expected no error, got:", err)
+	} else if !server.Sampling {
+		t.Error("Expected sampling to be resumed")
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	server := NewFakeServer()
+	defer server.Close()
+	server.Proposal = nil
+	client := New(server.ClientConfig())
+
+	_, err := client.Proposals(context.Background(), OperationRequest{})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatal("Expected an API error, got:", err)
+	}
+	if apiErr.StatusCode != http.StatusInternalServerError || apiErr.Message != "no proposal" {
+		t.Error("Expected internal server error with the message of cruise control, got:", apiErr)
+	}
+
+	server.Close()
+	if _, err = client.State(context.Background()); err == nil {
+		t.Error("Expected error for unreachable cruise control, got nil")
+	}
+}
+
+func TestClientAuthentication(t *testing.T) {
+	server := NewFakeTLSServer()
+	defer server.Close()
+	server.Username, server.Password = "admin", "secret"
+
+	if _, err := New(server.ClientConfig()).State(context.Background()); err != nil {
+		t.Error("Expected no error with valid credentials over TLS, got:", err)
+	}
+
+	conf := server.ClientConfig()
+	conf.BasicAuthPassword = "wrong"
+	var apiErr *APIError
+	if _, err := New(conf).State(context.Background()); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Error("Expected unauthorized error, got:", err)
+	}
+
+	conf = server.ClientConfig()
+	conf.TLSConfig = nil
+	if _, err := New(conf).State(context.Background()); err == nil {
+		t.Error("Expected error for untrusted server certificate, got nil")
+	}
+}
+
+func TestClientTransport(t *testing.T) {
+	if transport := New(&Config{BaseURL: "http://cc/kafkacruisecontrol"}).(*ccClient).httpClient.Transport; transport != http.DefaultTransport {
+		t.Error("Expected plain http clients to use the default transport, got:", transport)
+	}
+
+	conf := &Config{BaseURL: "https://cc/kafkacruisecontrol", TLSConfig: &tls.Config{}, tlsVersion: "secret=cc-tls/1"}
+	first := New(conf).(*ccClient).httpClient.Transport
+	if second := New(conf).(*ccClient).httpClient.Transport; second != first {
+		t.Error("Expected the clients of the same TLS config to share the transport")
+	}
+	conf = &Config{BaseURL: conf.BaseURL, TLSConfig: &tls.Config{}, tlsVersion: "secret=cc-tls/2"}
+	if renewed := New(conf).(*ccClient).httpClient.Transport; renewed == first {
+		t.Error("Expected new transport for the renewed TLS config")
+	} else if renewed.(*http.Transport).TLSClientConfig != conf.TLSConfig {
+		t.Error("Expected the renewed transport to use the renewed TLS config")
+	}
+}
+
+func TestOptimizationResult(t *testing.T) {
+	var result *OptimizationResult
+	if summary := result.ProposalSummary(); summary != nil {
+		t.Error("Expected nil summary for nil result, got:", summary)
+	}
+
+	result = &OptimizationResult{
+		Summary: OptimizationSummary{NumReplicaMovements: 5, NumLeaderMovements: 2, DataToMoveMB: 1024, MonitoredPartitionsPercentage: 99.5},
+		GoalSummary: []GoalResult{
+			{Goal: "RackAwareGoal", Status: GoalStatusViolated},
+			{Goal: "DiskCapacityGoal", Status: GoalStatusNoAction},
+			{Goal: "ReplicaDistributionGoal", Status: GoalStatusViolated},
+		},
+	}
+	expected := []string{"RackAwareGoal", "ReplicaDistributionGoal"}
+	if violated := result.ViolatedGoals(); !reflect.DeepEqual(violated, expected) {
+		t.Errorf("Expected violated goals %v, got: %v", expected, violated)
+	}
+	summary := result.ProposalSummary()
+	if summary.NumReplicaMovements != 5 || summary.NumLeaderMovements != 2 || summary.DataToMoveMB != 1024 {
+		t.Error("Expected movements of the result, got:", summary)
+	}
+	if !reflect.DeepEqual(summary.ViolatedGoals, expected) {
+		t.Error("Expected violated goals in the summary, got:", summary.ViolatedGoals)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cruisecontrol
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
+	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+	"github.com/banzaicloud/kafka-operator/pkg/errorfactory"
+)
+
+const (
+	basePath            = "kafkacruisecontrol"
+	serviceNameTemplate = "%s-cruisecontrol-svc"
+	servicePort         = 8090
+)
+
+// Config are the options of connecting to the REST API of cruise control
+type Config struct {
+	// BaseURL is the url the API is served at, e.g. http://kafka-cruisecontrol-svc.kafka.svc.cluster.local:8090/kafkacruisecontrol
+	BaseURL string
+	// TLSConfig is used when the BaseURL is https
+	TLSConfig *tls.Config
+	// Timeout limits the duration of the requests
+	Timeout time.Duration
+
+	BasicAuthUser     string
+	BasicAuthPassword string
+
+	// tlsVersion identifies the TLS material of the TLSConfig, the clients of the same BaseURL and tlsVersion share their connections
+	tlsVersion string
+}
+
+// ClusterConfig creates connection options from a KafkaCluster CR
+func ClusterConfig(client client.Client, cluster *v1beta1.KafkaCluster) (*Config, error) {
+	ccConfig := cluster.Spec.CruiseControlConfig
+	apiConfig := ccConfig.APIConfig
+
+	host := ccConfig.CruiseControlEndpoint
+	if host == "" {
+		host = fmt.Sprintf("%s.%s.svc.%s:%d", fmt.Sprintf(serviceNameTemplate, cluster.Name),
+			cluster.Namespace, cluster.Spec.GetKubernetesClusterDomain(), servicePort)
+	}
+
+	conf := &Config{
+		BaseURL: fmt.Sprintf("http://%s/%s", host, basePath),
+		Timeout: time.Duration(apiConfig.GetRequestTimeoutSeconds()) * time.Second,
+	}
+	if apiConfig == nil {
+		return conf, nil
+	}
+
+	if apiConfig.TLS {
+		conf.BaseURL = fmt.Sprintf("https://%s/%s", host, basePath)
+		tlsConfig, tlsVersion, err := clusterTLSConfig(client, cluster.Namespace, apiConfig)
+		if err != nil {
+			return conf, err
+		}
+		conf.TLSConfig, conf.tlsVersion = tlsConfig, tlsVersion
+	}
+
+	if apiConfig.BasicAuthSecretName != "" {
+		secret, err := getSecret(client, apiConfig.BasicAuthSecretName, cluster.Namespace)
+		if err != nil {
+			return conf, err
+		}
+		conf.BasicAuthUser, conf.BasicAuthPassword = string(secret.Data[v1alpha1.UsernameKey]), string(secret.Data[v1alpha1.PasswordKey])
+		if conf.BasicAuthUser == "" || conf.BasicAuthPassword == "" {
+			return conf, errorfactory.New(errorfactory.ResourceNotReady{},
+				fmt.Errorf("secret must contain the %q and %q keys", v1alpha1.UsernameKey, v1alpha1.PasswordKey),
+				"cruise control credentials are incomplete", "secret", apiConfig.BasicAuthSecretName)
+		}
+	}
+	return conf, nil
+}
+
+// clusterTLSConfig returns the TLS config cruise control is verified and the operator is authenticated with,
+// and the version of the secret the config is built from
+func clusterTLSConfig(client client.Client, namespace string, apiConfig *v1beta1.CruiseControlAPIConfig) (*tls.Config, string, error) {
+	//nolint:gosec
+	tlsConfig := &tls.Config{InsecureSkipVerify: apiConfig.InsecureSkipVerify}
+	tlsVersion := fmt.Sprintf("insecure=%t", apiConfig.InsecureSkipVerify)
+	if apiConfig.TLSSecretName == "" {
+		return tlsConfig, tlsVersion, nil
+	}
+
+	secret, err := getSecret(client, apiConfig.TLSSecretName, namespace)
+	if err != nil {
+		return nil, "", err
+	}
+	tlsVersion = fmt.Sprintf("%s/secret=%s/%s", tlsVersion, secret.Name, secret.ResourceVersion)
+	if caCert, ok := secret.Data[v1alpha1.CoreCACertKey]; ok {
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caCert) {
+			return nil, "", errorfactory.New(errorfactory.InternalError{}, errors.New("invalid CA certificate"),
+				"could not parse cruise control CA certificate", "secret", apiConfig.TLSSecretName)
+		}
+	}
+	if _, ok := secret.Data[corev1.TLSCertKey]; ok {
+		cert, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+		if err != nil {
+			return nil, "", errorfactory.New(errorfactory.InternalError{}, err,
+				"could not parse cruise control client certificate", "secret", apiConfig.TLSSecretName)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, tlsVersion, nil
+}
+
+func getSecret(client client.Client, name, namespace string) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, errorfactory.New(errorfactory.ResourceNotReady{}, err, "cruise control secret not found", "secret", name)
+		}
+		return nil, errorfactory.New(errorfactory.APIFailure{}, err, "could not get cruise control secret", "secret", name)
+	}
+	return secret, nil
+}
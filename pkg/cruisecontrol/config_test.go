@@ -0,0 +1,117 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cruisecontrol
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	//nolint:staticcheck
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
+	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+	"github.com/banzaicloud/kafka-operator/pkg/errorfactory"
+)
+
+func newTestCluster() *v1beta1.KafkaCluster {
+	return &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+	}
+}
+
+func TestClusterConfig(t *testing.T) {
+	client := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	cluster := newTestCluster()
+
+	conf, err := ClusterConfig(client, cluster)
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if expected := "http://kafka-cruisecontrol-svc.kafka.svc.cluster.local:8090/kafkacruisecontrol"; conf.BaseURL != expected {
+		t.Errorf("Expected base url %s, got: %s", expected, conf.BaseURL)
+	}
+	if conf.Timeout != 30*time.Second {
+		t.Error("Expected default timeout of 30s, got:", conf.Timeout)
+	}
+
+	cluster.Spec.CruiseControlConfig.CruiseControlEndpoint = "cruisecontrol:9090"
+	cluster.Spec.CruiseControlConfig.APIConfig = &v1beta1.CruiseControlAPIConfig{
+		TLS:                   true,
+		InsecureSkipVerify:    true,
+		BasicAuthSecretName:   "cc-credentials",
+		RequestTimeoutSeconds: 10,
+	}
+
+	if _, err = ClusterConfig(client, cluster); err == nil {
+		t.Error("Expected error for missing credentials secret, got nil")
+	} else if _, ok := err.(errorfactory.ResourceNotReady); !ok {
+		t.Error("Expected resource not ready error, got:", err)
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "cc-credentials", Namespace: "kafka"},
+		Data:       map[string][]byte{v1alpha1.UsernameKey: []byte("admin")},
+	}
+	if err = client.Create(context.TODO(), secret); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if _, err = ClusterConfig(client, cluster); err == nil {
+		t.Error("Expected error for incomplete credentials, got nil")
+	}
+
+	secret.Data[v1alpha1.PasswordKey] = []byte("secret")
+	if err = client.Update(context.TODO(), secret); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if conf, err = ClusterConfig(client, cluster); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if conf.BaseURL != "https://cruisecontrol:9090/kafkacruisecontrol" {
+		t.Error("Expected https base url of the endpoint, got:", conf.BaseURL)
+	}
+	if conf.TLSConfig == nil || !conf.TLSConfig.InsecureSkipVerify {
+		t.Error("Expected TLS config skipping verification, got:", conf.TLSConfig)
+	}
+	if conf.BasicAuthUser != "admin" || conf.BasicAuthPassword != "secret" {
+		t.Error("Expected credentials of the secret, got:", conf.BasicAuthUser, conf.BasicAuthPassword)
+	}
+	if conf.Timeout != 10*time.Second {
+		t.Error("Expected timeout of 10s, got:", conf.Timeout)
+	}
+}
+
+func TestClusterTLSConfig(t *testing.T) {
+	client := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	cluster := newTestCluster()
+	cluster.Spec.CruiseControlConfig.APIConfig = &v1beta1.CruiseControlAPIConfig{TLS: true, TLSSecretName: "cc-tls"}
+
+	if err := client.Create(context.TODO(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "cc-tls", Namespace: "kafka"},
+		Data:       map[string][]byte{v1alpha1.CoreCACertKey: []byte("not a certificate")},
+	}); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if _, err := ClusterConfig(client, cluster); err == nil {
+		t.Error("Expected error for invalid CA certificate, got nil")
+	} else if _, ok := err.(errorfactory.InternalError); !ok {
+		t.Error("Expected internal error, got:", err)
+	}
+}
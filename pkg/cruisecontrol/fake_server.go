@@ -0,0 +1,201 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cruisecontrol
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FakeServer serves the REST API of cruise control from memory for unit tests
+type FakeServer struct {
+	*httptest.Server
+
+	mu sync.Mutex
+	// State, ClusterState, Load and PartitionLoad are returned by the corresponding endpoints
+	State         StateResponse
+	ClusterState  KafkaClusterStateResponse
+	Load          LoadResponse
+	PartitionLoad PartitionLoadResponse
+	// Proposal is the result of the operations, nil makes the operations pending
+	Proposal *OptimizationResult
+	// Tasks are the user tasks keyed by task id, the operations executed are added as Active tasks
+	Tasks map[string]*UserTask
+	// Requests are the requests received by the server in order
+	Requests []FakeRequest
+	// Sampling is false while the sampling of the load metrics is paused
+	Sampling bool
+	// Username and Password are required from the clients when set
+	Username string
+	Password string
+
+	taskCount int
+}
+
+// FakeRequest is a request received by the fake server
+type FakeRequest struct {
+	Method string
+	Action string
+	Params url.Values
+	TaskID string
+}
+
+// NewFakeServer starts a fake cruise control serving over http
+func NewFakeServer() *FakeServer {
+	s := newFakeServer()
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// NewFakeTLSServer starts a fake cruise control serving over https
+func NewFakeTLSServer() *FakeServer {
+	s := newFakeServer()
+	s.Server = httptest.NewTLSServer(s)
+	return s
+}
+
+func newFakeServer() *FakeServer {
+	return &FakeServer{
+		Tasks:    make(map[string]*UserTask),
+		Sampling: true,
+		Proposal: &OptimizationResult{},
+	}
+}
+
+// ClientConfig returns the options of connecting to the fake server
+func (s *FakeServer) ClientConfig() *Config {
+	conf := &Config{
+		BaseURL:           s.URL + "/" + basePath,
+		Timeout:           5 * time.Second,
+		BasicAuthUser:     s.Username,
+		BasicAuthPassword: s.Password,
+	}
+	if transport, ok := s.Client().Transport.(*http.Transport); ok {
+		conf.TLSConfig = transport.TLSClientConfig
+	}
+	return conf
+}
+
+// SetTaskStatus sets the status of a user task
+func (s *FakeServer) SetTaskStatus(taskID, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if task, ok := s.Tasks[taskID]; ok {
+		task.Status = status
+		return
+	}
+	s.Tasks[taskID] = &UserTask{UserTaskID: taskID, Status: status}
+}
+
+// ReceivedActions returns the actions of the requests received by the server in order
+func (s *FakeServer) ReceivedActions() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	actions := make([]string, 0, len(s.Requests))
+	for _, request := range s.Requests {
+		actions = append(actions, request.Action)
+	}
+	return actions
+}
+
+// ServeHTTP implements the endpoints of cruise control
+func (s *FakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.Username != "" {
+		if user, password, ok := r.BasicAuth(); !ok || user != s.Username || password != s.Password {
+			writeFakeResponse(w, http.StatusUnauthorized, map[string]string{"errorMessage": "unauthorized"})
+			return
+		}
+	}
+
+	action := strings.TrimPrefix(r.URL.Path, "/"+basePath+"/")
+	params := r.URL.Query()
+	taskID := r.Header.Get(userTaskIDHeader)
+	s.Requests = append(s.Requests, FakeRequest{Method: r.Method, Action: action, Params: params, TaskID: taskID})
+
+	switch action {
+	case stateAction:
+		writeFakeResponse(w, http.StatusOK, s.State)
+	case kafkaClusterStateAction:
+		writeFakeResponse(w, http.StatusOK, s.ClusterState)
+	case loadAction:
+		writeFakeResponse(w, http.StatusOK, s.Load)
+	case partitionLoadAction:
+		writeFakeResponse(w, http.StatusOK, s.PartitionLoad)
+	case proposalsAction:
+		if s.Proposal == nil {
+			writeFakeResponse(w, http.StatusInternalServerError, map[string]string{"errorMessage": "no proposal"})
+			return
+		}
+		writeFakeResponse(w, http.StatusOK, s.Proposal)
+	case userTasksAction:
+		response := UserTasksResponse{UserTasks: []UserTask{}}
+		for _, id := range strings.Split(params.Get("user_task_ids"), ",") {
+			if task, ok := s.Tasks[id]; ok {
+				response.UserTasks = append(response.UserTasks, *task)
+			}
+		}
+		writeFakeResponse(w, http.StatusOK, response)
+	case rebalanceAction, addBrokerAction, removeBrokerAction, demoteBrokerAction, fixOfflineReplicasAction:
+		if r.Method != http.MethodPost {
+			writeFakeResponse(w, http.StatusMethodNotAllowed, map[string]string{"errorMessage": "POST is required"})
+			return
+		}
+		if taskID == "" {
+			s.taskCount++
+			taskID = fmt.Sprintf("task-%d", s.taskCount)
+		}
+		w.Header().Set(userTaskIDHeader, taskID)
+		if s.Proposal == nil {
+			writeFakeResponse(w, http.StatusAccepted, map[string]interface{}{"progress": []string{}})
+			return
+		}
+		if params.Get("dryrun") == "false" {
+			if _, ok := s.Tasks[taskID]; !ok {
+				s.Tasks[taskID] = &UserTask{UserTaskID: taskID, RequestURL: r.URL.String(), Status: TaskStatusActive}
+			}
+		}
+		writeFakeResponse(w, http.StatusOK, s.Proposal)
+	case stopProposalAction:
+		for _, task := range s.Tasks {
+			if task.Status == TaskStatusActive || task.Status == TaskStatusInExecution {
+				task.Status = TaskStatusCompletedWithError
+			}
+		}
+		writeFakeResponse(w, http.StatusOK, map[string]string{"message": "Proposal execution stopped."})
+	case pauseSamplingAction:
+		s.Sampling = false
+		writeFakeResponse(w, http.StatusOK, map[string]string{"message": "Metric sampling paused."})
+	case resumeSamplingAction:
+		s.Sampling = true
+		writeFakeResponse(w, http.StatusOK, map[string]string{"message": "Metric sampling resumed."})
+	default:
+		writeFakeResponse(w, http.StatusNotFound, map[string]string{"errorMessage": "unknown endpoint " + action})
+	}
+}
+
+func writeFakeResponse(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
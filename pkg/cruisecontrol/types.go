@@ -0,0 +1,270 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cruisecontrol
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
+)
+
+// Statuses of the cruise control user tasks
+const (
+	TaskStatusActive             = "Active"
+	TaskStatusInExecution        = "InExecution"
+	TaskStatusCompleted          = "Completed"
+	TaskStatusCompletedWithError = "CompletedWithError"
+)
+
+// Statuses of the goals in the optimization result
+const (
+	GoalStatusFixed    = "FIXED"
+	GoalStatusNoAction = "NO-ACTION"
+	GoalStatusViolated = "VIOLATED"
+)
+
+// BrokerStateAlive is the state of the brokers which are alive in the cluster load
+const BrokerStateAlive = "ALIVE"
+
+// OperationRequest holds the parameters common to the operations moving replicas and leaders
+type OperationRequest struct {
+	// DryRun only computes the proposal of the operation without executing it
+	DryRun bool
+	// Goals are the goals the operation is optimized for, the default goals of cruise control are used when empty
+	Goals []string
+	// TaskID repeats the request of an operation which is still in progress to get its result
+	TaskID string
+	// Options are passed to cruise control as additional request parameters
+	Options map[string]string
+}
+
+func (r OperationRequest) params() url.Values {
+	params := url.Values{}
+	for option, value := range r.Options {
+		params.Set(option, value)
+	}
+	if len(r.Goals) > 0 {
+		params.Set("goals", strings.Join(r.Goals, ","))
+	}
+	params.Set("dryrun", fmt.Sprintf("%t", r.DryRun))
+	return params
+}
+
+// RebalanceRequest holds the parameters of a rebalance
+type RebalanceRequest struct {
+	OperationRequest
+	// RebalanceDisk moves replicas between the disks of the brokers instead of between the brokers
+	RebalanceDisk bool
+}
+
+func (r RebalanceRequest) params() url.Values {
+	params := r.OperationRequest.params()
+	if r.RebalanceDisk {
+		params.Set("rebalance_disk", "true")
+	}
+	return params
+}
+
+// BrokerOperationRequest holds the parameters of the add, remove and demote broker operations
+type BrokerOperationRequest struct {
+	OperationRequest
+	BrokerIDs []string
+}
+
+func (r BrokerOperationRequest) params() url.Values {
+	params := r.OperationRequest.params()
+	params.Set("brokerid", strings.Join(r.BrokerIDs, ","))
+	return params
+}
+
+// OperationResponse is the answer of cruise control to an operation
+type OperationResponse struct {
+	// TaskID is the id of the user task the operation is run in
+	TaskID string
+	// Date is the time cruise control has accepted the operation at
+	Date string
+	// Result is the proposal of the operation, nil while cruise control is still computing it
+	Result *OptimizationResult
+}
+
+// OptimizationResult is the proposal computed by cruise control for an operation
+type OptimizationResult struct {
+	Summary     OptimizationSummary `json:"summary"`
+	GoalSummary []GoalResult        `json:"goalSummary"`
+}
+
+// OptimizationSummary holds the movements the proposal consists of
+type OptimizationSummary struct {
+	NumReplicaMovements            int32    `json:"numReplicaMovements"`
+	NumLeaderMovements             int32    `json:"numLeaderMovements"`
+	DataToMoveMB                   int64    `json:"dataToMoveMB"`
+	NumIntraBrokerReplicaMovements int32    `json:"numIntraBrokerReplicaMovements"`
+	IntraBrokerDataToMoveMB        int64    `json:"intraBrokerDataToMoveMB"`
+	MonitoredPartitionsPercentage  float64  `json:"monitoredPartitionsPercentage"`
+	ProvisionStatus                string   `json:"provisionStatus"`
+	ExcludedTopics                 []string `json:"excludedTopics"`
+}
+
+// GoalResult is the outcome of the optimization for a goal
+type GoalResult struct {
+	Goal   string `json:"goal"`
+	Status string `json:"status"`
+}
+
+// ViolatedGoals returns the goals which are still violated after the optimization
+func (r *OptimizationResult) ViolatedGoals() []string {
+	var violated []string
+	for _, goal := range r.GoalSummary {
+		if goal.Status == GoalStatusViolated {
+			violated = append(violated, goal.Goal)
+		}
+	}
+	return violated
+}
+
+// ProposalSummary converts the optimization result to be stored in the status of a KafkaRebalance
+func (r *OptimizationResult) ProposalSummary() *v1alpha1.RebalanceProposalSummary {
+	if r == nil {
+		return nil
+	}
+	return &v1alpha1.RebalanceProposalSummary{
+		NumReplicaMovements:            r.Summary.NumReplicaMovements,
+		NumLeaderMovements:             r.Summary.NumLeaderMovements,
+		DataToMoveMB:                   r.Summary.DataToMoveMB,
+		NumIntraBrokerReplicaMovements: r.Summary.NumIntraBrokerReplicaMovements,
+		IntraBrokerDataToMoveMB:        r.Summary.IntraBrokerDataToMoveMB,
+		MonitoredPartitionsPercentage:  fmt.Sprintf("%g", r.Summary.MonitoredPartitionsPercentage),
+		ProvisionStatus:                r.Summary.ProvisionStatus,
+		ExcludedTopics:                 r.Summary.ExcludedTopics,
+		ViolatedGoals:                  r.ViolatedGoals(),
+	}
+}
+
+// StateResponse is the state of the cruise control components
+type StateResponse struct {
+	MonitorState  MonitorState  `json:"MonitorState"`
+	ExecutorState ExecutorState `json:"ExecutorState"`
+	AnalyzerState AnalyzerState `json:"AnalyzerState"`
+}
+
+// KafkaClusterStateResponse is the state of the brokers and the partitions of the kafka cluster
+type KafkaClusterStateResponse struct {
+	KafkaBrokerState    KafkaBrokerState    `json:"KafkaBrokerState"`
+	KafkaPartitionState KafkaPartitionState `json:"KafkaPartitionState"`
+}
+
+// MonitorState is the state of the load monitor of cruise control
+type MonitorState struct {
+	State                 string  `json:"state"`
+	NumMonitoredWindows   int32   `json:"numMonitoredWindows"`
+	MonitoringCoveragePct float64 `json:"monitoringCoveragePct"`
+	NumValidPartitions    int32   `json:"numValidPartitions"`
+	NumTotalPartitions    int32   `json:"numTotalPartitions"`
+	Reason                string  `json:"reason,omitempty"`
+}
+
+// ExecutorState is the state of the executor of cruise control, e.g. NO_TASK_IN_PROGRESS
+type ExecutorState struct {
+	State               string `json:"state"`
+	TriggeredUserTaskID string `json:"triggeredUserTaskId,omitempty"`
+}
+
+// AnalyzerState is the state of the goal optimizer of cruise control
+type AnalyzerState struct {
+	IsProposalReady bool     `json:"isProposalReady"`
+	ReadyGoals      []string `json:"readyGoals"`
+}
+
+// KafkaBrokerState holds the replica distribution and the log directories of the brokers keyed by broker id
+type KafkaBrokerState struct {
+	ReplicaCountByBrokerID        map[string]int32    `json:"ReplicaCountByBrokerId"`
+	LeaderCountByBrokerID         map[string]int32    `json:"LeaderCountByBrokerId"`
+	OutOfSyncCountByBrokerID      map[string]int32    `json:"OutOfSyncCountByBrokerId"`
+	OfflineReplicaCountByBrokerID map[string]int32    `json:"OfflineReplicaCountByBrokerId"`
+	OnlineLogDirsByBrokerID       map[string][]string `json:"OnlineLogDirsByBrokerId"`
+	OfflineLogDirsByBrokerID      map[string][]string `json:"OfflineLogDirsByBrokerId"`
+}
+
+// KafkaPartitionState lists the partitions which are not healthy
+type KafkaPartitionState struct {
+	Offline             []PartitionState `json:"offline"`
+	UnderReplicated     []PartitionState `json:"urp"`
+	WithOfflineReplicas []PartitionState `json:"with-offline-replicas"`
+	UnderMinISR         []PartitionState `json:"under-min-isr"`
+}
+
+// PartitionState describes the replicas of a partition
+type PartitionState struct {
+	Topic     string  `json:"topic"`
+	Partition int32   `json:"partition"`
+	Leader    int32   `json:"leader"`
+	Replicas  []int32 `json:"replicas"`
+	InSync    []int32 `json:"in-sync"`
+	OutOfSync []int32 `json:"out-of-sync"`
+	Offline   []int32 `json:"offline"`
+}
+
+// LoadResponse is the load of the brokers of the cluster
+type LoadResponse struct {
+	Brokers []BrokerLoad `json:"brokers"`
+}
+
+// BrokerLoad is the load of a broker
+type BrokerLoad struct {
+	Broker      int32   `json:"Broker"`
+	BrokerState string  `json:"BrokerState"`
+	Host        string  `json:"Host"`
+	Rack        string  `json:"Rack"`
+	Replicas    int32   `json:"Replicas"`
+	Leaders     int32   `json:"Leaders"`
+	DiskMB      float64 `json:"DiskMB"`
+	DiskPct     float64 `json:"DiskPct"`
+	CPUPct      float64 `json:"CpuPct"`
+	NwInRate    float64 `json:"NwInRate"`
+	NwOutRate   float64 `json:"NwOutRate"`
+}
+
+// PartitionLoadResponse is the load of the partitions of the cluster
+type PartitionLoadResponse struct {
+	Records []PartitionLoad `json:"records"`
+}
+
+// PartitionLoad is the load of a partition
+type PartitionLoad struct {
+	Topic           string  `json:"topic"`
+	Partition       int32   `json:"partition"`
+	Leader          int32   `json:"leader"`
+	Followers       []int32 `json:"followers"`
+	CPU             float64 `json:"cpu"`
+	Disk            float64 `json:"disk"`
+	NetworkInbound  float64 `json:"networkInbound"`
+	NetworkOutbound float64 `json:"networkOutbound"`
+}
+
+// UserTasksResponse lists the user tasks of cruise control
+type UserTasksResponse struct {
+	UserTasks []UserTask `json:"userTasks"`
+}
+
+// UserTask is an operation requested from cruise control
+type UserTask struct {
+	UserTaskID     string `json:"UserTaskId"`
+	RequestURL     string `json:"RequestURL"`
+	ClientIdentity string `json:"ClientIdentity"`
+	StartMs        string `json:"StartMs"`
+	Status         string `json:"Status"`
+}
@@ -76,6 +76,12 @@ type PerBrokerConfigNotReady struct{ error }
 // LoadBalancerIPNotReady states that the LoadBalancer IP is not yet created
 type LoadBalancerIPNotReady struct{ error }
 
+// KafkaVersionDowngrade states that the requested Kafka version is older than the one the brokers run
+type KafkaVersionDowngrade struct{ error }
+
+// BrokerDecommissionBlocked states that the broker can not be removed without losing replicas
+type BrokerDecommissionBlocked struct{ error }
+
 // New creates a new error factory error
 func New(t interface{}, err error, msg string, wrapArgs ...interface{}) error {
 	wrapped := errors.WrapIfWithDetails(err, msg, wrapArgs...)
@@ -120,6 +126,10 @@ func New(t interface{}, err error, msg string, wrapArgs ...interface{}) error {
 		return PerBrokerConfigNotReady{wrapped}
 	case LoadBalancerIPNotReady:
 		return LoadBalancerIPNotReady{wrapped}
+	case KafkaVersionDowngrade:
+		return KafkaVersionDowngrade{wrapped}
+	case BrokerDecommissionBlocked:
+		return BrokerDecommissionBlocked{wrapped}
 	}
 	return wrapped
 }
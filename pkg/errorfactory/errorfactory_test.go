@@ -38,6 +38,8 @@ var errorTypes = []error{
 	FatalReconcileError{},
 	CruiseControlNotReady{},
 	CruiseControlTaskRunning{},
+	KafkaVersionDowngrade{},
+	BrokerDecommissionBlocked{},
 }
 
 func TestNew(t *testing.T) {
@@ -0,0 +1,195 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	runtimeClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+	"github.com/banzaicloud/kafka-operator/pkg/errorfactory"
+)
+
+const brokerRackConfig = "broker.rack="
+
+// AvailableRacks returns the racks of the schedulable nodes carrying every rack awareness label
+// together with the node selector pinning a broker to the rack
+func AvailableRacks(client runtimeClient.Reader, labels []string) (map[string]map[string]string, error) {
+	nodes := &corev1.NodeList{}
+	if err := client.List(context.TODO(), nodes); err != nil {
+		return nil, errorfactory.New(errorfactory.APIFailure{}, err, "listing nodes failed")
+	}
+
+	racks := make(map[string]map[string]string)
+	for _, node := range nodes.Items {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		selector := make(map[string]string, len(labels))
+		for _, label := range labels {
+			if value, ok := node.Labels[label]; ok {
+				selector[label] = value
+			}
+		}
+		if len(selector) != len(labels) {
+			continue
+		}
+		racks[rackOf(selector)] = selector
+	}
+	return racks, nil
+}
+
+// BrokerRacks returns the rack of the brokers of the cluster, brokers whose rack is not known yet are left out
+func BrokerRacks(cr *v1beta1.KafkaCluster) map[int32]string {
+	brokerRacks := make(map[int32]string, len(cr.Spec.Brokers))
+	for _, broker := range cr.Spec.Brokers {
+		if rack := rackFromConfig(broker.ReadOnlyConfig); rack != "" {
+			brokerRacks[broker.Id] = rack
+			continue
+		}
+		if state, ok := cr.Status.BrokersState[strconv.Itoa(int(broker.Id))]; ok {
+			if rack := rackFromConfig(string(state.RackAwarenessState)); rack != "" {
+				brokerRacks[broker.Id] = rack
+				continue
+			}
+		}
+		// brokers which are not running yet can already be pinned to a rack
+		if cr.Spec.RackAwareness != nil && broker.BrokerConfig != nil {
+			selector := make(map[string]string, len(cr.Spec.RackAwareness.Labels))
+			for _, label := range cr.Spec.RackAwareness.Labels {
+				if value, ok := broker.BrokerConfig.NodeSelector[label]; ok {
+					selector[label] = value
+				}
+			}
+			if len(selector) > 0 && len(selector) == len(cr.Spec.RackAwareness.Labels) {
+				brokerRacks[broker.Id] = rackOf(selector)
+			}
+		}
+	}
+	return brokerRacks
+}
+
+// PlaceBrokersInRacks pins each of the given brokers to the rack having the fewest brokers at the time of its placement
+// by setting the node selector of the broker, it returns the ids of the brokers which were placed
+func PlaceBrokersInRacks(cr *v1beta1.KafkaCluster, racks map[string]map[string]string, brokerIDs []int32) []int32 {
+	if len(racks) == 0 {
+		return nil
+	}
+
+	counts := make(map[string]int, len(racks))
+	for rack := range racks {
+		counts[rack] = 0
+	}
+	for _, rack := range BrokerRacks(cr) {
+		if _, ok := counts[rack]; ok {
+			counts[rack]++
+		}
+	}
+
+	placed := make([]int32, 0, len(brokerIDs))
+	for _, id := range brokerIDs {
+		for i := range cr.Spec.Brokers {
+			if cr.Spec.Brokers[i].Id != id {
+				continue
+			}
+			rack := leastPopulatedRack(counts)
+			if cr.Spec.Brokers[i].BrokerConfig == nil {
+				cr.Spec.Brokers[i].BrokerConfig = &v1beta1.BrokerConfig{}
+			}
+			nodeSelector := make(map[string]string, len(racks[rack]))
+			for key, value := range cr.Spec.Brokers[i].BrokerConfig.NodeSelector {
+				nodeSelector[key] = value
+			}
+			for key, value := range racks[rack] {
+				nodeSelector[key] = value
+			}
+			cr.Spec.Brokers[i].BrokerConfig.NodeSelector = nodeSelector
+			counts[rack]++
+			placed = append(placed, id)
+		}
+	}
+	return placed
+}
+
+// BrokersOfMostPopulatedRack returns the ids of the brokers running in the racks having the most brokers,
+// it returns nil when the rack of the brokers is not known
+func BrokersOfMostPopulatedRack(cr *v1beta1.KafkaCluster) []string {
+	brokerRacks := BrokerRacks(cr)
+	counts := make(map[string]int)
+	for _, rack := range brokerRacks {
+		counts[rack]++
+	}
+
+	max := 0
+	for _, count := range counts {
+		if count > max {
+			max = count
+		}
+	}
+	if max == 0 {
+		return nil
+	}
+
+	brokerIDs := make([]string, 0, max)
+	for _, broker := range cr.Spec.Brokers {
+		if rack, ok := brokerRacks[broker.Id]; ok && counts[rack] == max {
+			brokerIDs = append(brokerIDs, strconv.Itoa(int(broker.Id)))
+		}
+	}
+	return brokerIDs
+}
+
+// leastPopulatedRack returns the rack with the fewest brokers, ties are broken by the name of the rack
+func leastPopulatedRack(counts map[string]int) string {
+	racks := make([]string, 0, len(counts))
+	for rack := range counts {
+		racks = append(racks, rack)
+	}
+	sort.Strings(racks)
+
+	least := racks[0]
+	for _, rack := range racks[1:] {
+		if counts[rack] < counts[least] {
+			least = rack
+		}
+	}
+	return least
+}
+
+// rackOf returns the rack name of the given rack awareness labels the same way it is set in the broker.rack config
+func rackOf(selector map[string]string) string {
+	values := make([]string, 0, len(selector))
+	for _, value := range selector {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return strings.Join(values, ",")
+}
+
+// rackFromConfig returns the value of the broker.rack property in the given broker config
+func rackFromConfig(config string) string {
+	for _, line := range strings.Split(config, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, brokerRackConfig) {
+			return strings.TrimPrefix(line, brokerRackConfig)
+		}
+	}
+	return ""
+}
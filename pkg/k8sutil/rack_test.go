@@ -0,0 +1,120 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	//nolint:staticcheck
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+)
+
+const zoneLabel = "topology.kubernetes.io/zone"
+
+func rackAwareCluster() *v1beta1.KafkaCluster {
+	return &v1beta1.KafkaCluster{
+		Spec: v1beta1.KafkaClusterSpec{
+			RackAwareness: &v1beta1.RackAwareness{Labels: []string{zoneLabel}},
+			Brokers: []v1beta1.Broker{
+				{Id: 0, ReadOnlyConfig: "auto.create.topics.enable=false\nbroker.rack=zone-a\n"},
+				{Id: 1},
+				{Id: 2, BrokerConfig: &v1beta1.BrokerConfig{NodeSelector: map[string]string{zoneLabel: "zone-a"}}},
+				{Id: 3},
+			},
+		},
+		Status: v1beta1.KafkaClusterStatus{
+			BrokersState: map[string]v1beta1.BrokerState{
+				"1": {RackAwarenessState: "broker.rack=zone-b\n"},
+				"3": {RackAwarenessState: v1beta1.WaitingForRackAwareness},
+			},
+		},
+	}
+}
+
+func TestAvailableRacks(t *testing.T) {
+	node := func(name string, labels map[string]string, unschedulable bool) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+		}
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+		node("node-1", map[string]string{zoneLabel: "zone-a"}, false),
+		node("node-2", map[string]string{zoneLabel: "zone-b"}, false),
+		node("node-3", map[string]string{zoneLabel: "zone-c"}, true),
+		node("node-4", map[string]string{}, false),
+	).Build()
+
+	racks, err := AvailableRacks(client, []string{zoneLabel})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]map[string]string{
+		"zone-a": {zoneLabel: "zone-a"},
+		"zone-b": {zoneLabel: "zone-b"},
+	}
+	if !reflect.DeepEqual(racks, expected) {
+		t.Errorf("expected %v, got %v", expected, racks)
+	}
+}
+
+func TestBrokerRacks(t *testing.T) {
+	expected := map[int32]string{0: "zone-a", 1: "zone-b", 2: "zone-a"}
+	if racks := BrokerRacks(rackAwareCluster()); !reflect.DeepEqual(racks, expected) {
+		t.Errorf("expected %v, got %v", expected, racks)
+	}
+}
+
+func TestPlaceBrokersInRacks(t *testing.T) {
+	cr := rackAwareCluster()
+	cr.Spec.Brokers = append(cr.Spec.Brokers, v1beta1.Broker{Id: 4})
+	racks := map[string]map[string]string{
+		"zone-a": {zoneLabel: "zone-a"},
+		"zone-b": {zoneLabel: "zone-b"},
+		"zone-c": {zoneLabel: "zone-c"},
+	}
+
+	placed := PlaceBrokersInRacks(cr, racks, []int32{3, 4})
+	if !reflect.DeepEqual(placed, []int32{3, 4}) {
+		t.Errorf("expected brokers 3 and 4 to be placed, got %v", placed)
+	}
+	// zone-a already holds two brokers and zone-b one, so the new brokers go to zone-c and zone-b
+	expected := map[int32]string{0: "zone-a", 1: "zone-b", 2: "zone-a", 3: "zone-c", 4: "zone-b"}
+	if brokerRacks := BrokerRacks(cr); !reflect.DeepEqual(brokerRacks, expected) {
+		t.Errorf("expected %v, got %v", expected, brokerRacks)
+	}
+
+	if placed := PlaceBrokersInRacks(cr, nil, []int32{4}); len(placed) != 0 {
+		t.Errorf("expected no broker to be placed without racks, got %v", placed)
+	}
+}
+
+func TestBrokersOfMostPopulatedRack(t *testing.T) {
+	if brokerIDs := BrokersOfMostPopulatedRack(rackAwareCluster()); !reflect.DeepEqual(brokerIDs, []string{"0", "2"}) {
+		t.Errorf("expected brokers of zone-a, got %v", brokerIDs)
+	}
+
+	cr := &v1beta1.KafkaCluster{Spec: v1beta1.KafkaClusterSpec{Brokers: []v1beta1.Broker{{Id: 0}, {Id: 1}}}}
+	if brokerIDs := BrokersOfMostPopulatedRack(cr); brokerIDs != nil {
+		t.Errorf("expected no brokers when racks are unknown, got %v", brokerIDs)
+	}
+}
@@ -44,6 +44,17 @@ import (
 
 // Reconcile reconciles K8S resources
 func Reconcile(log logr.Logger, client runtimeClient.Client, desired runtime.Object, cr *v1beta1.KafkaCluster) error {
+	return reconcile(log, client, desired, cr, nil)
+}
+
+// ReconcileBrokerConfigMap reconciles the config map of a broker, the changes of the broker config are classified
+// by the dynamic update modes described by the running brokers
+func ReconcileBrokerConfigMap(log logr.Logger, client runtimeClient.Client, desired *corev1.ConfigMap, cr *v1beta1.KafkaCluster,
+	configModes kafka.ConfigUpdateModes) error {
+	return reconcile(log, client, desired, cr, configModes)
+}
+
+func reconcile(log logr.Logger, client runtimeClient.Client, desired runtime.Object, cr *v1beta1.KafkaCluster, configModes kafka.ConfigUpdateModes) error {
 	desiredType := reflect.TypeOf(desired)
 	var current = desired.DeepCopyObject().(runtimeClient.Object)
 	var err error
@@ -144,15 +155,19 @@ func Reconcile(log logr.Logger, client runtimeClient.Client, desired runtime.Obj
 					}
 
 					var statusErr error
-					// if only per broker configs are changed, do not trigger rolling upgrade by setting ConfigOutOfSync status
-					if kafka.ShouldRefreshOnlyPerBrokerConfigs(currentConfigs, desiredConfigs, log) {
+					configChanges := configModes.ClassifyConfigChanges(currentConfigs, desiredConfigs)
+					// if only dynamically updatable configs are changed, do not trigger rolling upgrade by setting ConfigOutOfSync status
+					if kafka.ShouldRefreshOnlyPerBrokerConfigs(configChanges, log) {
 						log.V(1).Info("setting per broker config status to out of sync")
 						statusErr = UpdateBrokerStatus(client, []string{id}, cr, v1beta1.PerBrokerConfigOutOfSync, log)
 					} else {
 						statusErr = UpdateBrokerStatus(client, []string{id}, cr, v1beta1.ConfigOutOfSync, log)
 					}
+					if statusErr == nil {
+						statusErr = UpdateBrokerStatus(client, []string{id}, cr, configChanges, log)
+					}
 					if statusErr != nil {
-						return errors.WrapIfWithDetails(err, "updating status for resource failed", "kind", desiredType)
+						return errors.WrapIfWithDetails(statusErr, "updating status for resource failed", "kind", desiredType)
 					}
 				}
 			}
@@ -213,6 +228,16 @@ func IsPodContainsPendingContainer(pod *corev1.Pod) bool {
 	return false
 }
 
+// IsPodReady returns true if the pod has the Ready condition set to true
+func IsPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
 func GetDefaultInitContainerResourceRequirements() corev1.ResourceRequirements {
 	return corev1.ResourceRequirements{
 		Limits: corev1.ResourceList{
@@ -122,6 +122,12 @@ func generateBrokerState(brokerIDs []string, cluster *banzaicloudv1beta1.KafkaCl
 			for mountPath, volumeState := range state {
 				brokerState.GracefulActionState.VolumeStates[mountPath] = volumeState
 			}
+		case banzaicloudv1beta1.GracefulRestartState:
+			brokerState.GracefulRestartState = s
+		case banzaicloudv1beta1.ConfigChanges:
+			brokerState.ConfigChanges = s
+		case banzaicloudv1beta1.DecommissionStatus:
+			brokerState.DecommissionStatus = s
 		case banzaicloudv1beta1.KafkaVersion:
 			brokerState.Image = s.Image
 			brokerState.Version = s.Version
@@ -262,6 +268,80 @@ func UpdateRollingUpgradeState(c client.Client, cluster *v1beta1.KafkaCluster, t
 	return nil
 }
 
+// updateClusterStatus applies the given change to the status of the cluster and updates it, the change is
+// applied again on the latest version of the cluster when the update conflicts
+func updateClusterStatus(c client.Client, cluster *v1beta1.KafkaCluster, mutate func(*v1beta1.KafkaCluster), errMsg string) error {
+	typeMeta := cluster.TypeMeta
+
+	mutate(cluster)
+
+	err := c.Status().Update(context.Background(), cluster)
+	if apierrors.IsNotFound(err) {
+		err = c.Update(context.Background(), cluster)
+	}
+	if err != nil {
+		if !apierrors.IsConflict(err) {
+			return errors.WrapIf(err, errMsg)
+		}
+		err := c.Get(context.TODO(), types.NamespacedName{
+			Namespace: cluster.Namespace,
+			Name:      cluster.Name,
+		}, cluster)
+		if err != nil {
+			return errors.WrapIf(err, "could not get config for updating status")
+		}
+
+		mutate(cluster)
+
+		err = c.Status().Update(context.Background(), cluster)
+		if apierrors.IsNotFound(err) {
+			err = c.Update(context.Background(), cluster)
+		}
+		if err != nil {
+			return errors.WrapIf(err, errMsg)
+		}
+	}
+	// update loses the typeMeta of the config that's used later when setting ownerrefs
+	cluster.TypeMeta = typeMeta
+	return nil
+}
+
+// UpdateSelfHealingIncidents updates the self-healing incidents in the status of the cluster
+func UpdateSelfHealingIncidents(c client.Client, cluster *v1beta1.KafkaCluster, incidents []v1beta1.SelfHealingIncident, logger logr.Logger) error {
+	err := updateClusterStatus(c, cluster, func(cluster *v1beta1.KafkaCluster) {
+		cluster.Status.SelfHealingIncidents = incidents
+	}, "could not update self-healing incidents")
+	if err != nil {
+		return err
+	}
+	logger.Info("Self-healing incidents updated")
+	return nil
+}
+
+// UpdateCruiseControlOperationQueue updates the queue of the pending CruiseControl operations in the status of the cluster
+func UpdateCruiseControlOperationQueue(c client.Client, cluster *v1beta1.KafkaCluster, queue []v1beta1.CruiseControlOperation, logger logr.Logger) error {
+	err := updateClusterStatus(c, cluster, func(cluster *v1beta1.KafkaCluster) {
+		cluster.Status.CruiseControlOperationQueue = queue
+	}, "could not update cruise control operation queue")
+	if err != nil {
+		return err
+	}
+	logger.Info("Cruise control operation queue updated", "length", len(queue))
+	return nil
+}
+
+// UpdateCruiseControlTaskHistory updates the history of the finished CruiseControl tasks in the status of the cluster
+func UpdateCruiseControlTaskHistory(c client.Client, cluster *v1beta1.KafkaCluster, history []v1beta1.CruiseControlTaskRecord, logger logr.Logger) error {
+	err := updateClusterStatus(c, cluster, func(cluster *v1beta1.KafkaCluster) {
+		cluster.Status.CruiseControlTaskHistory = history
+	}, "could not update cruise control task history")
+	if err != nil {
+		return err
+	}
+	logger.Info("Cruise control task history updated")
+	return nil
+}
+
 func UpdateListenerStatuses(ctx context.Context, c client.Client, cluster *v1beta1.KafkaCluster, logger logr.Logger,
 	intListenerStatuses, extListenerStatuses map[string]v1beta1.ListenerStatusList) error {
 	typeMeta := cluster.TypeMeta
@@ -349,3 +429,27 @@ func CreateInternalListenerStatuses(kafkaCluster *v1beta1.KafkaCluster) (map[str
 
 	return intListenerStatuses, controllerIntListenerStatuses
 }
+
+// UpdateKafkaUpgradeStatus updates the progress of the Kafka version upgrade in the status of the cluster
+func UpdateKafkaUpgradeStatus(c client.Client, cluster *v1beta1.KafkaCluster, upgradeStatus *v1beta1.KafkaUpgradeStatus, logger logr.Logger) error {
+	err := updateClusterStatus(c, cluster, func(cluster *v1beta1.KafkaCluster) {
+		cluster.Status.UpgradeStatus = upgradeStatus
+	}, "could not update Kafka upgrade status")
+	if err != nil {
+		return err
+	}
+	logger.Info("Kafka upgrade status updated")
+	return nil
+}
+
+// UpdateRollingUpgradeStatus updates the progress of the rolling upgrade in the status of the cluster
+func UpdateRollingUpgradeStatus(c client.Client, cluster *v1beta1.KafkaCluster, rollingUpgrade v1beta1.RollingUpgradeStatus, logger logr.Logger) error {
+	err := updateClusterStatus(c, cluster, func(cluster *v1beta1.KafkaCluster) {
+		cluster.Status.RollingUpgrade = rollingUpgrade
+	}, "could not update rolling upgrade status")
+	if err != nil {
+		return err
+	}
+	logger.Info("rolling upgrade status updated", "phase", rollingUpgrade.Phase)
+	return nil
+}
@@ -22,12 +22,14 @@ import (
 	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
 	"github.com/banzaicloud/kafka-operator/api/v1beta1"
 	"github.com/banzaicloud/kafka-operator/pkg/errorfactory"
+	kafkautil "github.com/banzaicloud/kafka-operator/pkg/util/kafka"
+	properties "github.com/banzaicloud/kafka-operator/properties/pkg"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 var log = logf.Log.WithName("kafka_util")
-var apiVersion = sarama.V2_6_0_0
+var apiVersion = sarama.V2_7_0_0
 
 // KafkaClient is the exported interface for kafka operations
 type KafkaClient interface {
@@ -35,22 +37,40 @@ type KafkaClient interface {
 	ListTopics() (map[string]sarama.TopicDetail, error)
 	CreateTopic(*CreateTopicOptions) error
 	EnsurePartitionCount(string, int32) (bool, error)
-	EnsureTopicConfig(string, map[string]*string) error
+	EnsureTopicConfig(string, map[string]*string) (properties.DiffResult, error)
+	EnsureReplicaAssignment(string, int32, []v1alpha1.PartitionAssignment) ([]int32, error)
+	GetReassignmentStatus(string) (*v1alpha1.TopicReassignmentStatus, error)
+	SetReplicationThrottleRate(int64) error
 	DeleteTopic(string, bool) error
 	GetTopic(string) (*sarama.TopicDetail, error)
 	DescribeTopic(string) (*sarama.TopicMetadata, error)
-	CreateUserACLs(v1alpha1.KafkaAccessType, v1alpha1.KafkaPatternType, string, string) error
+	DescribeTopicConfig(string) (map[string]string, error)
+	DescribeTopicConfigOverrides(string) (*properties.Properties, error)
 	ListUserACLs() ([]sarama.ResourceAcls, error)
+	DescribeUserACLs(string) ([]kafkautil.UserACLEntry, error)
+	ReconcileUserACLs(string, []kafkautil.UserACLEntry) ([]kafkautil.UserACLEntry, []kafkautil.UserACLEntry, error)
 	DeleteUserACLs(string) error
+	UpsertUserScramCredentials(string, v1alpha1.UserAuthenticationType, string) error
+	DescribeUserScramCredentials(string) ([]*sarama.UserScramCredentialsResponseInfo, error)
+	DeleteUserScramCredentials(string, ...sarama.ScramMechanismType) error
+	DescribeUserQuotas(string) (*v1alpha1.UserQuotas, error)
+	AlterUserQuotas(string, *v1alpha1.UserQuotas) error
+	DeleteUserQuotas(string) error
 
 	Brokers() map[int32]string
 	DescribeCluster() ([]*sarama.Broker, int32, error)
 
 	OfflineReplicaCount() (int, error)
 	AllReplicaInSync() (bool, error)
+	BrokerLeaderCount(int32) (int, error)
+	BrokerReplicasInSync(int32) (bool, error)
+	PartitionsBelowMinISRWithout([]int32) ([]string, error)
+	BrokerReplicaCount(int32) (int, error)
+	TopicsBelowReplicationFactorWithout([]int32) ([]string, error)
 
 	AlterPerBrokerConfig(int32, map[string]*string, bool) error
 	DescribePerBrokerConfig(int32, []string) ([]*sarama.ConfigEntry, error)
+	DescribeBrokerConfig(int32) ([]sarama.ConfigEntry, error)
 
 	AlterClusterWideConfig(map[string]*string, bool) error
 	DescribeClusterWideConfig() ([]sarama.ConfigEntry, error)
@@ -162,6 +182,14 @@ func (k *kafkaClient) getSaramaConfig() (config *sarama.Config) {
 		config.Net.TLS.Enable = true
 		config.Net.TLS.Config = k.opts.TLSConfig
 	}
+	if k.opts.UseSASL {
+		config.Net.SASL.Enable = true
+		config.Net.SASL.Handshake = true
+		config.Net.SASL.Mechanism = k.opts.SASLMechanism
+		config.Net.SASL.User = k.opts.SASLUser
+		config.Net.SASL.Password = k.opts.SASLPassword
+		config.Net.SASL.SCRAMClientGeneratorFunc = scramClientGenerator(k.opts.SASLMechanism)
+	}
 	config.Version = apiVersion
 	return
 }
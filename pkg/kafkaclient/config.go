@@ -15,11 +15,20 @@
 package kafkaclient
 
 import (
+	"context"
 	"crypto/tls"
+	"errors"
+	"fmt"
 
+	"github.com/Shopify/sarama"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
 	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+	"github.com/banzaicloud/kafka-operator/pkg/errorfactory"
 	"github.com/banzaicloud/kafka-operator/pkg/pki"
 	clientutil "github.com/banzaicloud/kafka-operator/pkg/util/client"
 )
@@ -32,6 +41,11 @@ type KafkaConfig struct {
 	UseSSL    bool
 	TLSConfig *tls.Config
 
+	UseSASL       bool
+	SASLMechanism sarama.SASLMechanism
+	SASLUser      string
+	SASLPassword  string
+
 	OperationTimeout int64
 }
 
@@ -48,5 +62,39 @@ func ClusterConfig(client client.Client, cluster *v1beta1.KafkaCluster) (*KafkaC
 		conf.UseSSL = true
 		conf.TLSConfig = tlsConfig
 	}
+	if clientutil.UseSASL(cluster) {
+		if err := setSASLConfig(client, cluster, conf); err != nil {
+			return conf, err
+		}
+	}
 	return conf, nil
 }
+
+// setSASLConfig populates the SASL credentials of the operator from the secret
+// referenced by the cluster's adminSasl config
+func setSASLConfig(client client.Client, cluster *v1beta1.KafkaCluster, conf *KafkaConfig) error {
+	saslConfig := cluster.Spec.ListenersConfig.AdminSASL
+	if saslConfig == nil {
+		return errorfactory.New(errorfactory.ResourceNotReady{},
+			errors.New("adminSasl is not configured"),
+			"inner broker listener requires SASL but no admin credentials are set")
+	}
+	secret := &corev1.Secret{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: saslConfig.SecretName, Namespace: cluster.Namespace}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return errorfactory.New(errorfactory.ResourceNotReady{}, err, "admin SASL secret not found", "secret", saslConfig.SecretName)
+		}
+		return errorfactory.New(errorfactory.APIFailure{}, err, "could not get admin SASL secret", "secret", saslConfig.SecretName)
+	}
+	user, password := string(secret.Data[v1alpha1.UsernameKey]), string(secret.Data[v1alpha1.PasswordKey])
+	if user == "" || password == "" {
+		return errorfactory.New(errorfactory.ResourceNotReady{},
+			fmt.Errorf("secret must contain the %q and %q keys", v1alpha1.UsernameKey, v1alpha1.PasswordKey),
+			"admin SASL credentials are incomplete", "secret", saslConfig.SecretName)
+	}
+	conf.UseSASL = true
+	conf.SASLMechanism = sarama.SASLMechanism(saslConfig.GetMechanism())
+	conf.SASLUser = user
+	conf.SASLPassword = password
+	return nil
+}
@@ -17,7 +17,13 @@ package kafkaclient
 import (
 	"testing"
 
+	"github.com/Shopify/sarama"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
 
 	"github.com/banzaicloud/kafka-operator/api/v1beta1"
 	"github.com/banzaicloud/kafka-operator/pkg/pki"
@@ -50,3 +56,39 @@ func TestClusterConfig(t *testing.T) {
 		t.Error("Expected no error got:", err)
 	}
 }
+
+func TestClusterConfigSASL(t *testing.T) {
+	cluster := newMockCluster()
+	cluster.Spec.ListenersConfig.SSLSecrets = nil
+	cluster.Spec.ListenersConfig.InternalListeners[0].Type = v1beta1.SecurityProtocolSaslPlaintext
+
+	if _, err := ClusterConfig(fake.NewClientBuilder().Build(), cluster); err == nil {
+		t.Error("Expected error when adminSasl is not configured, got nil")
+	}
+
+	cluster.Spec.ListenersConfig.AdminSASL = &v1beta1.AdminSASLConfig{SecretName: "admin-sasl"}
+	if _, err := ClusterConfig(fake.NewClientBuilder().Build(), cluster); err == nil {
+		t.Error("Expected error when admin SASL secret is missing, got nil")
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "admin-sasl", Namespace: cluster.Namespace},
+		Data: map[string][]byte{
+			v1alpha1.UsernameKey: []byte("admin"),
+			v1alpha1.PasswordKey: []byte("admin-secret"),
+		},
+	}
+	conf, err := ClusterConfig(fake.NewClientBuilder().WithObjects(secret).Build(), cluster)
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if !conf.UseSASL || conf.SASLUser != "admin" || conf.SASLPassword != "admin-secret" {
+		t.Error("Expected SASL credentials to be read from the secret, got:", conf)
+	}
+	if conf.SASLMechanism != sarama.SASLTypeSCRAMSHA512 {
+		t.Error("Expected default mechanism SCRAM-SHA-512, got:", conf.SASLMechanism)
+	}
+	if conf.UseSSL {
+		t.Error("Expected SSL to be disabled for sasl_plaintext listener")
+	}
+}
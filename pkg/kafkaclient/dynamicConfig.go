@@ -31,28 +31,26 @@ func (k *kafkaClient) DescribeClusterWideConfig() ([]sarama.ConfigEntry, error)
 	return k.admin.DescribeConfig(sarama.ConfigResource{Type: sarama.BrokerResource, Name: "", ConfigNames: []string{}})
 }
 
+// AlterPerBrokerConfig sets the given configs of the broker incrementally so that the dynamic configs set by the
+// operator elsewhere are kept, the configs with nil value are removed from the broker
 func (k *kafkaClient) AlterPerBrokerConfig(brokerId int32, configChange map[string]*string, validateOnly bool) error {
-	broker := k.GetBroker(brokerId)
-	if broker == nil {
+	if k.GetBroker(brokerId) == nil {
 		return errorfactory.New(errorfactory.BrokersNotReady{}, errors.New("brokerNotReady"), fmt.Sprintf("could not get %d broker", brokerId))
 	}
-	err := broker.Open(k.getSaramaConfig())
-	if err != nil {
-		return errors.WrapIf(err, "could not open connection to broker")
+	entries := make(map[string]sarama.IncrementalAlterConfigsEntry, len(configChange))
+	for name, value := range configChange {
+		if value == nil {
+			entries[name] = sarama.IncrementalAlterConfigsEntry{Operation: sarama.IncrementalAlterConfigsOperationDelete}
+			continue
+		}
+		entries[name] = sarama.IncrementalAlterConfigsEntry{Operation: sarama.IncrementalAlterConfigsOperationSet, Value: value}
 	}
-	defer broker.Close()
+	return k.admin.IncrementalAlterConfig(sarama.BrokerResource, strconv.Itoa(int(brokerId)), entries, validateOnly)
+}
 
-	_, err = broker.AlterConfigs(&sarama.AlterConfigsRequest{
-		ValidateOnly: validateOnly,
-		Resources: []*sarama.AlterConfigsResource{
-			{
-				Type:          sarama.BrokerResource,
-				Name:          strconv.Itoa(int(brokerId)),
-				ConfigEntries: configChange,
-			},
-		},
-	})
-	return err
+// DescribeBrokerConfig returns every config of the broker along with its metadata
+func (k *kafkaClient) DescribeBrokerConfig(brokerId int32) ([]sarama.ConfigEntry, error) {
+	return k.admin.DescribeConfig(sarama.ConfigResource{Type: sarama.BrokerResource, Name: strconv.Itoa(int(brokerId))})
 }
 
 func (k *kafkaClient) DescribePerBrokerConfig(brokerId int32, config []string) ([]*sarama.ConfigEntry, error) {
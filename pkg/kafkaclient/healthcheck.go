@@ -16,10 +16,15 @@ package kafkaclient
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 
 	"emperror.dev/errors"
+	"github.com/Shopify/sarama"
 )
 
+const minInsyncReplicasConfig = "min.insync.replicas"
+
 func (k *kafkaClient) OfflineReplicaCount() (int, error) {
 	availableTopics, err := k.client.Topics()
 	if err != nil {
@@ -71,3 +76,200 @@ func (k *kafkaClient) AllReplicaInSync() (bool, error) {
 	log.Info("all replicas are in sync")
 	return true, nil
 }
+
+// BrokerLeaderCount returns the number of partitions led by the given broker
+func (k *kafkaClient) BrokerLeaderCount(brokerID int32) (int, error) {
+	availableTopics, err := k.client.Topics()
+	if err != nil {
+		return 0, errors.WrapIf(err, "could not fetch topics")
+	}
+	leaderCount := 0
+	for _, topic := range availableTopics {
+		partitions, err := k.client.Partitions(topic)
+		if err != nil {
+			return 0, errors.WrapIfWithDetails(err, "could not fetch partition", "topic", topic)
+		}
+		for _, partition := range partitions {
+			leader, err := k.client.Leader(topic, partition)
+			if errors.Is(err, sarama.ErrLeaderNotAvailable) {
+				continue
+			}
+			if err != nil {
+				return 0, errors.WrapIfWithDetails(err, "could not fetch leader", "topic", topic, "partition", partition)
+			}
+			if leader.ID() == brokerID {
+				leaderCount++
+			}
+		}
+	}
+	log.Info(fmt.Sprintf("broker %d leads %d partitions", brokerID, leaderCount))
+	return leaderCount, nil
+}
+
+// BrokerReplicasInSync returns true when all the replicas hosted by the given broker are in sync
+func (k *kafkaClient) BrokerReplicasInSync(brokerID int32) (bool, error) {
+	availableTopics, err := k.client.Topics()
+	if err != nil {
+		return false, errors.WrapIf(err, "could not fetch topics")
+	}
+	for _, topic := range availableTopics {
+		partitions, err := k.client.Partitions(topic)
+		if err != nil {
+			return false, errors.WrapIfWithDetails(err, "could not fetch partition", "topic", topic)
+		}
+		for _, partition := range partitions {
+			replicas, err := k.client.Replicas(topic, partition)
+			if err != nil {
+				return false, errors.WrapIfWithDetails(err, "could not fetch replicas", "topic", topic, "partition", partition)
+			}
+			if !containsBroker(replicas, brokerID) {
+				continue
+			}
+			isrReplicas, err := k.client.InSyncReplicas(topic, partition)
+			if err != nil {
+				return false, errors.WrapIfWithDetails(err, "could not fetch isr replicas", "topic", topic, "partition", partition)
+			}
+			if !containsBroker(isrReplicas, brokerID) {
+				log.Info(fmt.Sprintf("replicas of broker %d are not in sync", brokerID))
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// PartitionsBelowMinISRWithout returns the partitions hosted by the given brokers which would have fewer in sync
+// replicas than their min.insync.replicas if the brokers were stopped
+func (k *kafkaClient) PartitionsBelowMinISRWithout(brokerIDs []int32) ([]string, error) {
+	availableTopics, err := k.client.Topics()
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not fetch topics")
+	}
+	var unavailable []string
+	for _, topic := range availableTopics {
+		partitions, err := k.client.Partitions(topic)
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "could not fetch partition", "topic", topic)
+		}
+		minISR := 0
+		for _, partition := range partitions {
+			replicas, err := k.client.Replicas(topic, partition)
+			if err != nil {
+				return nil, errors.WrapIfWithDetails(err, "could not fetch replicas", "topic", topic, "partition", partition)
+			}
+			if !containsAnyBroker(replicas, brokerIDs) {
+				continue
+			}
+			isrReplicas, err := k.client.InSyncReplicas(topic, partition)
+			if err != nil {
+				return nil, errors.WrapIfWithDetails(err, "could not fetch isr replicas", "topic", topic, "partition", partition)
+			}
+			// the config of the topic is only fetched for the topics hosted by the brokers
+			if minISR == 0 {
+				if minISR, err = k.topicMinISR(topic); err != nil {
+					return nil, err
+				}
+			}
+			// partitions which lose their min.insync.replicas whichever of their replicas is stopped, or whose
+			// replicas are all stopped, can not be kept available by the order of the restarts
+			if len(replicas) <= minISR || containsAllBrokers(brokerIDs, replicas) {
+				continue
+			}
+			remaining := 0
+			for _, id := range isrReplicas {
+				if !containsBroker(brokerIDs, id) {
+					remaining++
+				}
+			}
+			if remaining < minISR {
+				unavailable = append(unavailable, fmt.Sprintf("%s-%d", topic, partition))
+			}
+		}
+	}
+	return unavailable, nil
+}
+
+// BrokerReplicaCount returns the number of partition replicas hosted by the given broker based on the metadata of the topics
+func (k *kafkaClient) BrokerReplicaCount(brokerID int32) (int, error) {
+	topics, err := k.ListTopics()
+	if err != nil {
+		return 0, errors.WrapIf(err, "could not list topics")
+	}
+	replicaCount := 0
+	for topic := range topics {
+		meta, err := k.DescribeTopic(topic)
+		if err != nil {
+			return 0, errors.WrapIfWithDetails(err, "could not describe topic", "topic", topic)
+		}
+		for _, partition := range meta.Partitions {
+			if containsBroker(partition.Replicas, brokerID) {
+				replicaCount++
+			}
+		}
+	}
+	log.Info(fmt.Sprintf("broker %d hosts %d replicas", brokerID, replicaCount))
+	return replicaCount, nil
+}
+
+// TopicsBelowReplicationFactorWithout returns the topics whose replication factor could not be kept by the
+// brokers remaining once the given brokers are removed
+func (k *kafkaClient) TopicsBelowReplicationFactorWithout(brokerIDs []int32) ([]string, error) {
+	topics, err := k.ListTopics()
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not list topics")
+	}
+	remainingBrokers := 0
+	for _, broker := range k.brokers {
+		if !containsBroker(brokerIDs, broker.ID()) {
+			remainingBrokers++
+		}
+	}
+	var belowReplicationFactor []string
+	for topic, detail := range topics {
+		if int(detail.ReplicationFactor) > remainingBrokers {
+			belowReplicationFactor = append(belowReplicationFactor, topic)
+		}
+	}
+	sort.Strings(belowReplicationFactor)
+	return belowReplicationFactor, nil
+}
+
+// topicMinISR returns the min.insync.replicas of the given topic
+func (k *kafkaClient) topicMinISR(topic string) (int, error) {
+	config, err := k.DescribeTopicConfig(topic)
+	if err != nil {
+		return 0, err
+	}
+	minISR, err := strconv.Atoi(config[minInsyncReplicasConfig])
+	if err != nil || minISR < 1 {
+		return 1, nil
+	}
+	return minISR, nil
+}
+
+func containsAnyBroker(brokers []int32, brokerIDs []int32) bool {
+	for _, id := range brokerIDs {
+		if containsBroker(brokers, id) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAllBrokers(brokers []int32, brokerIDs []int32) bool {
+	for _, id := range brokerIDs {
+		if !containsBroker(brokers, id) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsBroker(brokers []int32, brokerID int32) bool {
+	for _, id := range brokers {
+		if id == brokerID {
+			return true
+		}
+	}
+	return false
+}
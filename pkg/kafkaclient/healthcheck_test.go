@@ -0,0 +1,86 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"testing"
+
+	"github.com/Shopify/sarama"
+)
+
+func TestBrokerReplicaCount(t *testing.T) {
+	client := newOpenedMockClient()
+	if count, err := client.BrokerReplicaCount(0); err != nil || count != 0 {
+		t.Error("Expected no replica without topics, got:", count, err)
+	}
+
+	client.admin.CreateTopic("test-topic", &sarama.TopicDetail{NumPartitions: 1, ReplicationFactor: 1}, false)
+	if count, err := client.BrokerReplicaCount(0); err != nil || count != 0 {
+		t.Error("Expected no replica hosted by the broker, got:", count, err)
+	}
+
+	client.admin.CreateTopic("hosted-topic", &sarama.TopicDetail{NumPartitions: 2, ReplicationFactor: 1}, false)
+	if count, err := client.BrokerReplicaCount(0); err != nil || count != 2 {
+		t.Error("Expected the replicas of the partitions to be counted, got:", count, err)
+	}
+
+	client.admin, _ = newMockClusterAdminFailOps([]string{}, sarama.NewConfig())
+	if _, err := client.BrokerReplicaCount(0); err == nil {
+		t.Error("Expected error on BrokerReplicaCount, got nil")
+	}
+}
+
+func TestTopicsBelowReplicationFactorWithout(t *testing.T) {
+	client := newOpenedMockClient()
+	client.admin.CreateTopic("test-topic", &sarama.TopicDetail{NumPartitions: 1, ReplicationFactor: 1}, false)
+
+	// the mock cluster has a single broker with id 0
+	if topics, err := client.TopicsBelowReplicationFactorWithout([]int32{1}); err != nil || len(topics) != 0 {
+		t.Error("Expected the replication factor to be kept, got:", topics, err)
+	}
+	if topics, err := client.TopicsBelowReplicationFactorWithout([]int32{0}); err != nil || len(topics) != 1 || topics[0] != "test-topic" {
+		t.Error("Expected test-topic to fall below its replication factor, got:", topics, err)
+	}
+}
+
+func TestPartitionsBelowMinISRWithout(t *testing.T) {
+	client := newOpenedMockClient()
+	admin := client.admin.(*mockClusterAdmin)
+	client.client = admin
+
+	minISR := func(value string) *sarama.TopicDetail {
+		return &sarama.TopicDetail{ConfigEntries: map[string]*string{minInsyncReplicasConfig: &value}}
+	}
+	// a topic with a single replica can not be kept available during the restart of its broker
+	admin.CreateTopic("single-replica", minISR("1"), false)
+	admin.mockPartitions["single-replica"] = []mockPartition{{replicas: []int32{0}, isrReplicas: []int32{0}}}
+	// a topic whose min.insync.replicas equals its replication factor loses it whichever replica is stopped
+	admin.CreateTopic("min-isr-replication-factor", minISR("3"), false)
+	admin.mockPartitions["min-isr-replication-factor"] = []mockPartition{{replicas: []int32{0, 1, 2}, isrReplicas: []int32{0, 1, 2}}}
+	// a topic which keeps its min.insync.replicas only while its in sync replicas are running
+	admin.CreateTopic("replicated", minISR("2"), false)
+	admin.mockPartitions["replicated"] = []mockPartition{{replicas: []int32{0, 1, 2}, isrReplicas: []int32{0, 1}}}
+
+	if partitions, err := client.PartitionsBelowMinISRWithout([]int32{2}); err != nil || len(partitions) != 0 {
+		t.Error("Expected every partition to stay available, got:", partitions, err)
+	}
+	if partitions, err := client.PartitionsBelowMinISRWithout([]int32{0}); err != nil || len(partitions) != 1 || partitions[0] != "replicated-0" {
+		t.Error("Expected only replicated-0 to fall below its min.insync.replicas, got:", partitions, err)
+	}
+	// the partitions whose replicas are all stopped can not be kept available either
+	if partitions, err := client.PartitionsBelowMinISRWithout([]int32{0, 1, 2}); err != nil || len(partitions) != 0 {
+		t.Error("Expected no partition to be reported when all of its replicas are stopped, got:", partitions, err)
+	}
+}
@@ -31,6 +31,17 @@ type mockClusterAdmin struct {
 	failOps    bool
 	mockTopics map[string]sarama.TopicDetail
 	mockACLs   map[sarama.Resource]*sarama.ResourceAcls
+	mockScram  map[string]map[sarama.ScramMechanismType]int32
+	mockQuotas map[string]map[string]float64
+	// mockReassignments holds the ongoing partition reassignments per topic
+	mockReassignments map[string]map[int32]*sarama.PartitionReplicaReassignmentsStatus
+	// mockPartitions holds the replicas and the in sync replicas of the partitions per topic
+	mockPartitions map[string][]mockPartition
+}
+
+type mockPartition struct {
+	replicas    []int32
+	isrReplicas []int32
 }
 
 func NewMockFromCluster(client client.Client, cluster *v1beta1.KafkaCluster) (KafkaClient, func(), error) {
@@ -42,7 +53,12 @@ func newEmptyMockClusterAdmin(failOps bool) *mockClusterAdmin {
 	return &mockClusterAdmin{
 		mockTopics: make(map[string]sarama.TopicDetail, 0),
 		mockACLs:   make(map[sarama.Resource]*sarama.ResourceAcls, 0),
+		mockScram:  make(map[string]map[sarama.ScramMechanismType]int32, 0),
+		mockQuotas: make(map[string]map[string]float64, 0),
 		failOps:    failOps,
+
+		mockReassignments: make(map[string]map[int32]*sarama.PartitionReplicaReassignmentsStatus, 0),
+		mockPartitions:    make(map[string][]mockPartition, 0),
 	}
 }
 
@@ -85,6 +101,10 @@ func newOpenedMockClient() *kafkaClient {
 
 func (m *mockClusterAdmin) Close() error { return nil }
 
+func (m *mockClusterAdmin) Controller() (*sarama.Broker, error) {
+	return nil, errors.New("no controller in mock")
+}
+
 func (m *mockClusterAdmin) DescribeCluster() ([]*sarama.Broker, int32, error) {
 	if m.failOps {
 		return []*sarama.Broker{}, 0, errors.New("bad describe cluster")
@@ -126,7 +146,22 @@ func (m *mockClusterAdmin) DescribeTopics(topics []string) ([]*sarama.TopicMetad
 			},
 		}, nil
 	default:
-		return []*sarama.TopicMetadata{}, nil
+		m.Lock()
+		defer m.Unlock()
+
+		partitions, ok := m.mockPartitions[topics[0]]
+		if !ok {
+			return []*sarama.TopicMetadata{}, nil
+		}
+		meta := &sarama.TopicMetadata{Name: topics[0], Err: sarama.ErrNoError}
+		for id, partition := range partitions {
+			meta.Partitions = append(meta.Partitions, &sarama.PartitionMetadata{
+				ID:       int32(id),
+				Replicas: partition.replicas,
+				Isr:      partition.isrReplicas,
+			})
+		}
+		return []*sarama.TopicMetadata{meta}, nil
 	}
 }
 
@@ -141,6 +176,14 @@ func (m *mockClusterAdmin) CreateTopic(name string, detail *sarama.TopicDetail,
 		return errors.New("already exists")
 	}
 	m.mockTopics[name] = *detail
+	// the replicas of the partitions are placed on the brokers starting from broker 0
+	replicas := make([]int32, 0, detail.ReplicationFactor)
+	for id := int32(0); id < int32(detail.ReplicationFactor); id++ {
+		replicas = append(replicas, id)
+	}
+	for i := int32(0); i < detail.NumPartitions; i++ {
+		m.mockPartitions[name] = append(m.mockPartitions[name], mockPartition{replicas: replicas, isrReplicas: replicas})
+	}
 	return nil
 }
 
@@ -153,6 +196,7 @@ func (m *mockClusterAdmin) DeleteTopic(name string) error {
 	}
 	if _, ok := m.mockTopics[name]; ok {
 		delete(m.mockTopics, name)
+		delete(m.mockPartitions, name)
 		return nil
 	} else {
 		return errors.New("does not exist")
@@ -199,9 +243,17 @@ func (m *mockClusterAdmin) ListAcls(filter sarama.AclFilter) ([]sarama.ResourceA
 	m.Lock()
 	defer m.Unlock()
 
-	acls := make([]sarama.ResourceAcls, len(m.mockACLs))
-	for _, acl := range m.mockACLs {
-		acls = append(acls, *acl)
+	acls := make([]sarama.ResourceAcls, 0, len(m.mockACLs))
+	for _, resourceAcls := range m.mockACLs {
+		matching := sarama.ResourceAcls{Resource: resourceAcls.Resource}
+		for _, acl := range resourceAcls.Acls {
+			if filter.Principal == nil || *filter.Principal == acl.Principal {
+				matching.Acls = append(matching.Acls, acl)
+			}
+		}
+		if len(matching.Acls) > 0 {
+			acls = append(acls, matching)
+		}
 	}
 	return acls, nil
 }
@@ -214,19 +266,280 @@ func (m *mockClusterAdmin) DeleteACL(filter sarama.AclFilter, validateOnly bool)
 		return []sarama.MatchingAcl{}, errors.New("bad create acl")
 	}
 	switch *filter.Principal {
-	case "test-user":
+	case "User:test-user":
 		return []sarama.MatchingAcl{sarama.MatchingAcl{}}, nil
-	case "with-error":
+	case "User:with-error":
 		return []sarama.MatchingAcl{sarama.MatchingAcl{Err: sarama.ErrUnknown}}, nil
 	default:
-		// for mock it's enough to erase the whole map
-		m.mockACLs = make(map[sarama.Resource]*sarama.ResourceAcls, 0)
-		return []sarama.MatchingAcl{sarama.MatchingAcl{}}, nil
+		matches := make([]sarama.MatchingAcl, 0)
+		for resource, resourceAcls := range m.mockACLs {
+			if !mockACLFilterMatchesResource(filter, resource) {
+				continue
+			}
+			kept := make([]*sarama.Acl, 0, len(resourceAcls.Acls))
+			for _, acl := range resourceAcls.Acls {
+				if mockACLFilterMatchesAcl(filter, acl) {
+					matches = append(matches, sarama.MatchingAcl{Resource: resource, Acl: *acl})
+					continue
+				}
+				kept = append(kept, acl)
+			}
+			if len(kept) == 0 {
+				delete(m.mockACLs, resource)
+				continue
+			}
+			resourceAcls.Acls = kept
+		}
+		return matches, nil
 	}
 }
 
+func mockACLFilterMatchesResource(filter sarama.AclFilter, resource sarama.Resource) bool {
+	return (filter.ResourceType == sarama.AclResourceAny || filter.ResourceType == resource.ResourceType) &&
+		(filter.ResourceName == nil || *filter.ResourceName == resource.ResourceName) &&
+		(filter.ResourcePatternTypeFilter == sarama.AclPatternAny || filter.ResourcePatternTypeFilter == resource.ResourcePatternType)
+}
+
+func mockACLFilterMatchesAcl(filter sarama.AclFilter, acl *sarama.Acl) bool {
+	return (filter.Principal == nil || *filter.Principal == acl.Principal) &&
+		(filter.Host == nil || *filter.Host == acl.Host) &&
+		(filter.Operation == sarama.AclOperationAny || filter.Operation == acl.Operation) &&
+		(filter.PermissionType == sarama.AclPermissionAny || filter.PermissionType == acl.PermissionType)
+}
+
+func (m *mockClusterAdmin) UpsertUserScramCredentials(upsert []sarama.AlterUserScramCredentialsUpsert) ([]*sarama.AlterUserScramCredentialsResult, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.failOps {
+		return nil, errors.New("bad upsert scram credentials")
+	}
+	results := make([]*sarama.AlterUserScramCredentialsResult, 0, len(upsert))
+	for _, u := range upsert {
+		if _, ok := m.mockScram[u.Name]; !ok {
+			m.mockScram[u.Name] = make(map[sarama.ScramMechanismType]int32)
+		}
+		m.mockScram[u.Name][u.Mechanism] = u.Iterations
+		results = append(results, &sarama.AlterUserScramCredentialsResult{User: u.Name})
+	}
+	return results, nil
+}
+
+func (m *mockClusterAdmin) DescribeUserScramCredentials(users []string) ([]*sarama.DescribeUserScramCredentialsResult, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.failOps {
+		return nil, errors.New("bad describe scram credentials")
+	}
+	results := make([]*sarama.DescribeUserScramCredentialsResult, 0, len(users))
+	for _, user := range users {
+		mechanisms, ok := m.mockScram[user]
+		if !ok {
+			results = append(results, &sarama.DescribeUserScramCredentialsResult{User: user, ErrorCode: errScramResourceNotFound})
+			continue
+		}
+		result := &sarama.DescribeUserScramCredentialsResult{User: user}
+		for mechanism, iterations := range mechanisms {
+			result.CredentialInfos = append(result.CredentialInfos, &sarama.UserScramCredentialsResponseInfo{
+				Mechanism:  mechanism,
+				Iterations: iterations,
+			})
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (m *mockClusterAdmin) DeleteUserScramCredentials(deletions []sarama.AlterUserScramCredentialsDelete) ([]*sarama.AlterUserScramCredentialsResult, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.failOps {
+		return nil, errors.New("bad delete scram credentials")
+	}
+	results := make([]*sarama.AlterUserScramCredentialsResult, 0, len(deletions))
+	for _, d := range deletions {
+		delete(m.mockScram[d.Name], d.Mechanism)
+		if len(m.mockScram[d.Name]) == 0 {
+			delete(m.mockScram, d.Name)
+		}
+		results = append(results, &sarama.AlterUserScramCredentialsResult{User: d.Name})
+	}
+	return results, nil
+}
+
+func (m *mockClusterAdmin) DescribeClientQuotas(components []sarama.QuotaFilterComponent, strict bool) ([]sarama.DescribeClientQuotasEntry, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.failOps {
+		return nil, errors.New("bad describe client quotas")
+	}
+	entries := make([]sarama.DescribeClientQuotasEntry, 0)
+	for _, component := range components {
+		values, ok := m.mockQuotas[component.Match]
+		if !ok {
+			continue
+		}
+		entry := sarama.DescribeClientQuotasEntry{
+			Entity: []sarama.QuotaEntityComponent{{EntityType: component.EntityType, Name: component.Match}},
+			Values: make(map[string]float64, len(values)),
+		}
+		for key, value := range values {
+			entry.Values[key] = value
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (m *mockClusterAdmin) AlterClientQuotas(entity []sarama.QuotaEntityComponent, op sarama.ClientQuotasOp, validateOnly bool) error {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.failOps {
+		return errors.New("bad alter client quotas")
+	}
+	for _, component := range entity {
+		if op.Remove {
+			delete(m.mockQuotas[component.Name], op.Key)
+			if len(m.mockQuotas[component.Name]) == 0 {
+				delete(m.mockQuotas, component.Name)
+			}
+			continue
+		}
+		if _, ok := m.mockQuotas[component.Name]; !ok {
+			m.mockQuotas[component.Name] = make(map[string]float64)
+		}
+		m.mockQuotas[component.Name][op.Key] = op.Value
+	}
+	return nil
+}
+
+func (m *mockClusterAdmin) IncrementalAlterConfig(resource sarama.ConfigResourceType, name string, entries map[string]sarama.IncrementalAlterConfigsEntry, validateOnly bool) error {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.failOps {
+		return errors.New("bad incremental alter config")
+	}
+	detail, ok := m.mockTopics[name]
+	if resource != sarama.TopicResource || !ok {
+		return nil
+	}
+	config := make(map[string]*string, len(detail.ConfigEntries))
+	for key, value := range detail.ConfigEntries {
+		config[key] = value
+	}
+	for key, entry := range entries {
+		if entry.Operation == sarama.IncrementalAlterConfigsOperationDelete {
+			delete(config, key)
+		} else {
+			config[key] = entry.Value
+		}
+	}
+	detail.ConfigEntries = config
+	m.mockTopics[name] = detail
+	return nil
+}
+
+func (m *mockClusterAdmin) AlterPartitionReassignments(topic string, assignment [][]int32) error {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.failOps {
+		return errors.New("bad alter partition reassignments")
+	}
+	reassignments := make(map[int32]*sarama.PartitionReplicaReassignmentsStatus, len(assignment))
+	for partition, replicas := range assignment {
+		reassignments[int32(partition)] = &sarama.PartitionReplicaReassignmentsStatus{
+			Replicas:       replicas,
+			AddingReplicas: replicas,
+		}
+	}
+	m.mockReassignments[topic] = reassignments
+	return nil
+}
+
+func (m *mockClusterAdmin) ListPartitionReassignments(topic string, partitions []int32) (map[string]map[int32]*sarama.PartitionReplicaReassignmentsStatus, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.failOps {
+		return nil, errors.New("bad list partition reassignments")
+	}
+	result := make(map[string]map[int32]*sarama.PartitionReplicaReassignmentsStatus)
+	if reassignments, ok := m.mockReassignments[topic]; ok {
+		result[topic] = reassignments
+	}
+	return result, nil
+}
+
+func (m *mockClusterAdmin) DescribeLogDirs(brokers []int32) (map[int32][]sarama.DescribeLogDirsResponseDirMetadata, error) {
+	if m.failOps {
+		return nil, errors.New("bad describe log dirs")
+	}
+	logDirs := make(map[int32][]sarama.DescribeLogDirsResponseDirMetadata, len(brokers))
+	for _, id := range brokers {
+		logDirs[id] = []sarama.DescribeLogDirsResponseDirMetadata{}
+	}
+	return logDirs, nil
+}
+
 func (m *mockClusterAdmin) DescribeConfig(resource sarama.ConfigResource) ([]sarama.ConfigEntry, error) {
-	return []sarama.ConfigEntry{}, nil
+	m.Lock()
+	defer m.Unlock()
+
+	if m.failOps {
+		return nil, errors.New("bad describe config")
+	}
+	entries := []sarama.ConfigEntry{}
+	if resource.Type == sarama.TopicResource {
+		for name, value := range m.mockTopics[resource.Name].ConfigEntries {
+			entries = append(entries, sarama.ConfigEntry{Name: name, Value: *value, Source: sarama.SourceTopic})
+		}
+	}
+	return entries, nil
+}
+
+func (m *mockClusterAdmin) Topics() ([]string, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.failOps {
+		return nil, errors.New("bad topics")
+	}
+	topics := make([]string, 0, len(m.mockPartitions))
+	for topic := range m.mockPartitions {
+		topics = append(topics, topic)
+	}
+	return topics, nil
+}
+
+func (m *mockClusterAdmin) Partitions(topic string) ([]int32, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	partitions := make([]int32, 0, len(m.mockPartitions[topic]))
+	for partition := range m.mockPartitions[topic] {
+		partitions = append(partitions, int32(partition))
+	}
+	return partitions, nil
+}
+
+func (m *mockClusterAdmin) Replicas(topic string, partition int32) ([]int32, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	return m.mockPartitions[topic][partition].replicas, nil
+}
+
+func (m *mockClusterAdmin) InSyncReplicas(topic string, partition int32) ([]int32, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	return m.mockPartitions[topic][partition].isrReplicas, nil
 }
 
 func shallowCopy(original map[string]sarama.TopicDetail) map[string]sarama.TopicDetail {
@@ -0,0 +1,122 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"github.com/Shopify/sarama"
+	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
+)
+
+// Client quota configuration keys understood by the brokers
+const (
+	producerByteRateQuotaKey  = "producer_byte_rate"
+	consumerByteRateQuotaKey  = "consumer_byte_rate"
+	requestPercentageQuotaKey = "request_percentage"
+)
+
+// userQuotaEntity returns the quota entity of the given user
+func userQuotaEntity(user string) []sarama.QuotaEntityComponent {
+	return []sarama.QuotaEntityComponent{{
+		EntityType: sarama.QuotaEntityUser,
+		MatchType:  sarama.QuotaMatchExact,
+		Name:       user,
+	}}
+}
+
+// userQuotasToMap converts the quotas to the representation used by the client quota admin API
+func userQuotasToMap(quotas *v1alpha1.UserQuotas) map[string]float64 {
+	out := make(map[string]float64)
+	if quotas == nil {
+		return out
+	}
+	if quotas.ProducerByteRate != nil {
+		out[producerByteRateQuotaKey] = float64(*quotas.ProducerByteRate)
+	}
+	if quotas.ConsumerByteRate != nil {
+		out[consumerByteRateQuotaKey] = float64(*quotas.ConsumerByteRate)
+	}
+	if quotas.RequestPercentage != nil {
+		out[requestPercentageQuotaKey] = float64(*quotas.RequestPercentage)
+	}
+	return out
+}
+
+// DescribeUserQuotas returns the client quotas set for the given user, nil is returned when
+// the user has no quotas
+func (k *kafkaClient) DescribeUserQuotas(user string) (*v1alpha1.UserQuotas, error) {
+	entries, err := k.admin.DescribeClientQuotas([]sarama.QuotaFilterComponent{{
+		EntityType: sarama.QuotaEntityUser,
+		MatchType:  sarama.QuotaMatchExact,
+		Match:      user,
+	}}, true)
+	if err != nil {
+		return nil, err
+	}
+	quotas := &v1alpha1.UserQuotas{}
+	for _, entry := range entries {
+		for key, value := range entry.Values {
+			switch key {
+			case producerByteRateQuotaKey:
+				rate := int64(value)
+				quotas.ProducerByteRate = &rate
+			case consumerByteRateQuotaKey:
+				rate := int64(value)
+				quotas.ConsumerByteRate = &rate
+			case requestPercentageQuotaKey:
+				percentage := int32(value)
+				quotas.RequestPercentage = &percentage
+			}
+		}
+	}
+	if quotas.IsEmpty() {
+		return nil, nil
+	}
+	return quotas, nil
+}
+
+// AlterUserQuotas sets the client quotas of the given user, quotas which are not
+// present in the desired state are removed
+func (k *kafkaClient) AlterUserQuotas(user string, quotas *v1alpha1.UserQuotas) error {
+	current, err := k.DescribeUserQuotas(user)
+	if err != nil {
+		return err
+	}
+	currentValues := userQuotasToMap(current)
+	desiredValues := userQuotasToMap(quotas)
+
+	entity := userQuotaEntity(user)
+	for key, value := range desiredValues {
+		if currentValue, ok := currentValues[key]; ok && currentValue == value {
+			continue
+		}
+		if err = k.admin.AlterClientQuotas(entity, sarama.ClientQuotasOp{Key: key, Value: value}, false); err != nil {
+			return err
+		}
+	}
+	for key := range currentValues {
+		if _, ok := desiredValues[key]; ok {
+			continue
+		}
+		if err = k.admin.AlterClientQuotas(entity, sarama.ClientQuotasOp{Key: key, Remove: true}, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteUserQuotas removes all client quotas of the given user
+func (k *kafkaClient) DeleteUserQuotas(user string) error {
+	return k.AlterUserQuotas(user, nil)
+}
@@ -0,0 +1,72 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
+	"github.com/banzaicloud/kafka-operator/pkg/util"
+)
+
+func TestUserQuotas(t *testing.T) {
+	client := newOpenedMockClient()
+
+	quotas, err := client.DescribeUserQuotas("test-user")
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if quotas != nil {
+		t.Error("Expected no quotas for new user, got:", quotas)
+	}
+
+	desired := &v1alpha1.UserQuotas{
+		ProducerByteRate:  util.Int64Pointer(1048576),
+		RequestPercentage: util.Int32Pointer(50),
+	}
+	if err = client.AlterUserQuotas("test-user", desired); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if quotas, err = client.DescribeUserQuotas("test-user"); err != nil {
+		t.Error("Expected no error, got:", err)
+	} else if !reflect.DeepEqual(quotas, desired) {
+		t.Errorf("Expected quotas %+v, got: %+v", desired, quotas)
+	}
+
+	// unset quotas are removed
+	desired = &v1alpha1.UserQuotas{
+		ConsumerByteRate: util.Int64Pointer(2097152),
+	}
+	if err = client.AlterUserQuotas("test-user", desired); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if quotas, _ = client.DescribeUserQuotas("test-user"); !reflect.DeepEqual(quotas, desired) {
+		t.Errorf("Expected quotas %+v, got: %+v", desired, quotas)
+	}
+
+	if err = client.DeleteUserQuotas("test-user"); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if quotas, _ = client.DescribeUserQuotas("test-user"); quotas != nil {
+		t.Error("Expected quotas to be deleted, got:", quotas)
+	}
+
+	client.admin, _ = newMockClusterAdminFailOps([]string{}, sarama.NewConfig())
+	if err = client.AlterUserQuotas("test-user", desired); err == nil {
+		t.Error("Expected error, got nil")
+	}
+}
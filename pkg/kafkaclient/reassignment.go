@@ -0,0 +1,280 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"emperror.dev/errors"
+	"github.com/Shopify/sarama"
+
+	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
+	"github.com/banzaicloud/kafka-operator/pkg/errorfactory"
+)
+
+// Configurations used to throttle the replication traffic of reassignments
+const (
+	LeaderThrottledRateConfig       = "leader.replication.throttled.rate"
+	FollowerThrottledRateConfig     = "follower.replication.throttled.rate"
+	LeaderThrottledReplicasConfig   = "leader.replication.throttled.replicas"
+	FollowerThrottledReplicasConfig = "follower.replication.throttled.replicas"
+)
+
+// DesiredReplicaAssignment computes the replica assignment of every partition of a topic.
+// Explicit assignments are taken as they are, the replicas of the other partitions are
+// extended with the least loaded brokers or shrunk from the end to match the replication
+// factor, so the preferred leaders are kept whenever possible.
+func DesiredReplicaAssignment(current [][]int32, brokerIDs []int32, replicationFactor int, explicit []v1alpha1.PartitionAssignment) ([][]int32, error) {
+	if replicationFactor > len(brokerIDs) {
+		return nil, fmt.Errorf("replication factor %d is larger than the number of brokers %d", replicationFactor, len(brokerIDs))
+	}
+	brokers := make(map[int32]struct{}, len(brokerIDs))
+	for _, id := range brokerIDs {
+		brokers[id] = struct{}{}
+	}
+
+	desired := make([][]int32, len(current))
+	pinned := make(map[int32]bool, len(explicit))
+	for _, assignment := range explicit {
+		if assignment.Partition < 0 || int(assignment.Partition) >= len(current) {
+			return nil, fmt.Errorf("partition %d of the assignment does not exist", assignment.Partition)
+		}
+		if err := validateReplicas(assignment.Replicas, brokers, replicationFactor); err != nil {
+			return nil, errors.WrapIf(err, fmt.Sprintf("invalid assignment of partition %d", assignment.Partition))
+		}
+		desired[assignment.Partition] = append([]int32{}, assignment.Replicas...)
+		pinned[assignment.Partition] = true
+	}
+
+	// count the replicas per broker to be able to place new replicas on the least loaded ones
+	load := make(map[int32]int, len(brokerIDs))
+	for partition, replicas := range current {
+		if pinned[int32(partition)] {
+			replicas = desired[partition]
+		}
+		for _, id := range replicas {
+			load[id]++
+		}
+	}
+
+	for partition, replicas := range current {
+		if pinned[int32(partition)] {
+			continue
+		}
+		replicas = append([]int32{}, replicas...)
+		for len(replicas) > replicationFactor {
+			removed := replicas[len(replicas)-1]
+			replicas = replicas[:len(replicas)-1]
+			load[removed]--
+		}
+		for len(replicas) < replicationFactor {
+			candidate := leastLoadedBroker(brokerIDs, replicas, load)
+			replicas = append(replicas, candidate)
+			load[candidate]++
+		}
+		desired[partition] = replicas
+	}
+	return desired, nil
+}
+
+func validateReplicas(replicas []int32, brokers map[int32]struct{}, replicationFactor int) error {
+	if len(replicas) != replicationFactor {
+		return fmt.Errorf("%d replicas are set instead of %d", len(replicas), replicationFactor)
+	}
+	seen := make(map[int32]struct{}, len(replicas))
+	for _, id := range replicas {
+		if _, ok := brokers[id]; !ok {
+			return fmt.Errorf("broker %d does not exist", id)
+		}
+		if _, ok := seen[id]; ok {
+			return fmt.Errorf("broker %d is set multiple times", id)
+		}
+		seen[id] = struct{}{}
+	}
+	return nil
+}
+
+// leastLoadedBroker returns the broker with the fewest replicas not holding any of the given
+// replicas yet, ties are broken by the lower broker id
+func leastLoadedBroker(brokerIDs []int32, replicas []int32, load map[int32]int) int32 {
+	candidates := make([]int32, 0, len(brokerIDs))
+	for _, id := range brokerIDs {
+		used := false
+		for _, replica := range replicas {
+			if replica == id {
+				used = true
+				break
+			}
+		}
+		if !used {
+			candidates = append(candidates, id)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if load[candidates[i]] != load[candidates[j]] {
+			return load[candidates[i]] < load[candidates[j]]
+		}
+		return candidates[i] < candidates[j]
+	})
+	return candidates[0]
+}
+
+// currentReplicaAssignment returns the replicas of every partition of the topic ordered by partition id
+func (k *kafkaClient) currentReplicaAssignment(topic string) ([][]int32, error) {
+	meta, err := k.admin.DescribeTopics([]string{topic})
+	if err != nil {
+		return nil, errorfactory.New(errorfactory.BrokersRequestError{}, err, "error describing topics")
+	}
+	if len(meta) == 0 {
+		return nil, errorfactory.New(errorfactory.TopicNotFound{}, errors.New("empty describe topic response"), fmt.Sprintf("could not find topic %s", topic))
+	}
+	if meta[0].Err != sarama.ErrNoError {
+		return nil, meta[0].Err
+	}
+	current := make([][]int32, len(meta[0].Partitions))
+	for _, partition := range meta[0].Partitions {
+		if partition == nil || int(partition.ID) >= len(current) {
+			continue
+		}
+		current[partition.ID] = partition.Replicas
+	}
+	return current, nil
+}
+
+// EnsureReplicaAssignment starts a partition reassignment when the replicas of the topic do not
+// match the desired replication factor or explicit assignments, the reassigned partitions are returned
+func (k *kafkaClient) EnsureReplicaAssignment(topic string, replicationFactor int32, explicit []v1alpha1.PartitionAssignment) ([]int32, error) {
+	current, err := k.currentReplicaAssignment(topic)
+	if err != nil {
+		return nil, err
+	}
+	brokerIDs := make([]int32, 0, len(k.brokers))
+	for _, broker := range k.brokers {
+		brokerIDs = append(brokerIDs, broker.ID())
+	}
+	sort.Slice(brokerIDs, func(i, j int) bool { return brokerIDs[i] < brokerIDs[j] })
+
+	desired, err := DesiredReplicaAssignment(current, brokerIDs, int(replicationFactor), explicit)
+	if err != nil {
+		return nil, errorfactory.New(errorfactory.InternalError{}, err, "could not compute replica assignment")
+	}
+
+	reassigned := make([]int32, 0)
+	for partition := range current {
+		if !int32SliceEqual(current[partition], desired[partition]) {
+			reassigned = append(reassigned, int32(partition))
+		}
+	}
+	if len(reassigned) == 0 {
+		return reassigned, nil
+	}
+	if err = k.admin.AlterPartitionReassignments(topic, desired); err != nil {
+		return nil, errorfactory.New(errorfactory.BrokersRequestError{}, err, "could not start partition reassignment")
+	}
+	return reassigned, nil
+}
+
+// GetReassignmentStatus returns the progress of the ongoing reassignment of the topic,
+// nil is returned when no partition of the topic is being reassigned
+func (k *kafkaClient) GetReassignmentStatus(topic string) (*v1alpha1.TopicReassignmentStatus, error) {
+	reassignments, err := k.admin.ListPartitionReassignments(topic, nil)
+	if err != nil {
+		return nil, errorfactory.New(errorfactory.BrokersRequestError{}, err, "could not list partition reassignments")
+	}
+	partitions := reassignments[topic]
+	if len(partitions) == 0 {
+		return nil, nil
+	}
+
+	status := &v1alpha1.TopicReassignmentStatus{}
+	brokerIDs := make([]int32, 0)
+	for partition, reassignment := range partitions {
+		status.Partitions = append(status.Partitions, partition)
+		brokerIDs = append(brokerIDs, reassignment.Replicas...)
+	}
+	sort.Slice(status.Partitions, func(i, j int) bool { return status.Partitions[i] < status.Partitions[j] })
+
+	logDirs, err := k.admin.DescribeLogDirs(brokerIDs)
+	if err != nil {
+		return nil, errorfactory.New(errorfactory.BrokersRequestError{}, err, "could not describe log dirs")
+	}
+	sizes := partitionSizes(logDirs, topic)
+	for partition, reassignment := range partitions {
+		// the largest replica is the reference the adding replicas are catching up to
+		var target int64
+		for _, id := range reassignment.Replicas {
+			if size := sizes[id][partition]; size > target {
+				target = size
+			}
+		}
+		for _, id := range reassignment.AddingReplicas {
+			if remaining := target - sizes[id][partition]; remaining > 0 {
+				status.BytesRemaining += remaining
+			}
+		}
+	}
+	return status, nil
+}
+
+// partitionSizes returns the size of the replicas of the topic per broker and partition
+func partitionSizes(logDirs map[int32][]sarama.DescribeLogDirsResponseDirMetadata, topic string) map[int32]map[int32]int64 {
+	sizes := make(map[int32]map[int32]int64, len(logDirs))
+	for id, dirs := range logDirs {
+		sizes[id] = make(map[int32]int64)
+		for _, dir := range dirs {
+			for _, t := range dir.Topics {
+				if t.Topic != topic {
+					continue
+				}
+				for _, partition := range t.Partitions {
+					if partition.Size > sizes[id][partition.PartitionID] {
+						sizes[id][partition.PartitionID] = partition.Size
+					}
+				}
+			}
+		}
+	}
+	return sizes
+}
+
+// SetReplicationThrottleRate sets the rate the replication traffic of throttled replicas is
+// limited to on every broker
+func (k *kafkaClient) SetReplicationThrottleRate(rate int64) error {
+	value := strconv.FormatInt(rate, 10)
+	for _, broker := range k.brokers {
+		err := k.admin.IncrementalAlterConfig(sarama.BrokerResource, strconv.Itoa(int(broker.ID())), map[string]sarama.IncrementalAlterConfigsEntry{
+			LeaderThrottledRateConfig:   {Operation: sarama.IncrementalAlterConfigsOperationSet, Value: &value},
+			FollowerThrottledRateConfig: {Operation: sarama.IncrementalAlterConfigsOperationSet, Value: &value},
+		}, false)
+		if err != nil {
+			return errorfactory.New(errorfactory.BrokersRequestError{}, err, fmt.Sprintf("could not set replication throttle rate of broker %d", broker.ID()))
+		}
+	}
+	return nil
+}
+
+func int32SliceEqual(a, b []int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,138 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
+)
+
+func TestDesiredReplicaAssignment(t *testing.T) {
+	brokers := []int32{0, 1, 2}
+	testCases := []struct {
+		testName          string
+		current           [][]int32
+		replicationFactor int
+		explicit          []v1alpha1.PartitionAssignment
+		expected          [][]int32
+		expectErr         bool
+	}{
+		{
+			testName:          "unchanged",
+			current:           [][]int32{{0, 1}, {1, 2}},
+			replicationFactor: 2,
+			expected:          [][]int32{{0, 1}, {1, 2}},
+		},
+		{
+			testName:          "increase places replicas on the least loaded brokers",
+			current:           [][]int32{{0}, {1}, {0}},
+			replicationFactor: 2,
+			expected:          [][]int32{{0, 2}, {1, 2}, {0, 1}},
+		},
+		{
+			testName:          "decrease keeps the preferred leaders",
+			current:           [][]int32{{0, 1, 2}, {2, 0, 1}},
+			replicationFactor: 1,
+			expected:          [][]int32{{0}, {2}},
+		},
+		{
+			testName:          "explicit assignment",
+			current:           [][]int32{{0}, {1}},
+			replicationFactor: 2,
+			explicit:          []v1alpha1.PartitionAssignment{{Partition: 1, Replicas: []int32{2, 0}}},
+			expected:          [][]int32{{0, 1}, {2, 0}},
+		},
+		{
+			testName:          "explicit assignment with wrong replica count",
+			current:           [][]int32{{0}, {1}},
+			replicationFactor: 2,
+			explicit:          []v1alpha1.PartitionAssignment{{Partition: 0, Replicas: []int32{0}}},
+			expectErr:         true,
+		},
+		{
+			testName:          "explicit assignment with unknown broker",
+			current:           [][]int32{{0}},
+			replicationFactor: 1,
+			explicit:          []v1alpha1.PartitionAssignment{{Partition: 0, Replicas: []int32{5}}},
+			expectErr:         true,
+		},
+		{
+			testName:          "explicit assignment of unknown partition",
+			current:           [][]int32{{0}},
+			replicationFactor: 1,
+			explicit:          []v1alpha1.PartitionAssignment{{Partition: 1, Replicas: []int32{0}}},
+			expectErr:         true,
+		},
+		{
+			testName:          "replication factor larger than the number of brokers",
+			current:           [][]int32{{0}},
+			replicationFactor: 4,
+			expectErr:         true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			desired, err := DesiredReplicaAssignment(test.current, brokers, test.replicationFactor, test.explicit)
+			if test.expectErr {
+				if err == nil {
+					t.Error("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Error("Expected no error, got:", err)
+			}
+			if !reflect.DeepEqual(desired, test.expected) {
+				t.Errorf("Expected assignment %v, got: %v", test.expected, desired)
+			}
+		})
+	}
+}
+
+func TestReplicaReassignment(t *testing.T) {
+	client := newOpenedMockClient()
+
+	status, err := client.GetReassignmentStatus("test-topic")
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	} else if status != nil {
+		t.Error("Expected no ongoing reassignment, got:", status)
+	}
+
+	reassigned, err := client.EnsureReplicaAssignment("test-topic", 1, nil)
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	} else if !reflect.DeepEqual(reassigned, []int32{0}) {
+		t.Error("Expected partition 0 to be reassigned, got:", reassigned)
+	}
+
+	status, err = client.GetReassignmentStatus("test-topic")
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	} else if status == nil || !reflect.DeepEqual(status.Partitions, []int32{0}) {
+		t.Error("Expected reassignment of partition 0, got:", status)
+	}
+
+	if err = client.SetReplicationThrottleRate(1048576); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+
+	if _, err = client.EnsureReplicaAssignment("test-topic", 2, nil); err == nil {
+		t.Error("Expected error for replication factor larger than the number of brokers, got nil")
+	}
+}
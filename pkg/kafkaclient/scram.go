@@ -0,0 +1,67 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+
+	"github.com/Shopify/sarama"
+	"github.com/xdg/scram"
+)
+
+// scramIterations is the iteration count used when upserting SCRAM credentials,
+// 4096 is the minimum accepted by Kafka
+const scramIterations = 4096
+
+// scramSaltLength is the length of the random salt used for SCRAM credentials
+const scramSaltLength = 32
+
+// xdgSCRAMClient implements the sarama.SCRAMClient interface
+type xdgSCRAMClient struct {
+	*scram.Client
+	*scram.ClientConversation
+	scram.HashGeneratorFcn
+}
+
+func (x *xdgSCRAMClient) Begin(userName, password, authzID string) (err error) {
+	x.Client, err = x.HashGeneratorFcn.NewClient(userName, password, authzID)
+	if err != nil {
+		return err
+	}
+	x.ClientConversation = x.Client.NewConversation()
+	return nil
+}
+
+func (x *xdgSCRAMClient) Step(challenge string) (response string, err error) {
+	response, err = x.ClientConversation.Step(challenge)
+	return
+}
+
+func (x *xdgSCRAMClient) Done() bool {
+	return x.ClientConversation.Done()
+}
+
+// scramClientGenerator returns the SCRAM client generator for the given SASL mechanism
+func scramClientGenerator(mechanism sarama.SASLMechanism) func() sarama.SCRAMClient {
+	switch mechanism {
+	case sarama.SASLTypeSCRAMSHA256:
+		return func() sarama.SCRAMClient { return &xdgSCRAMClient{HashGeneratorFcn: sha256.New} }
+	case sarama.SASLTypeSCRAMSHA512:
+		return func() sarama.SCRAMClient { return &xdgSCRAMClient{HashGeneratorFcn: sha512.New} }
+	default:
+		return nil
+	}
+}
@@ -17,10 +17,13 @@ package kafkaclient
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/Shopify/sarama"
+	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
 	"github.com/banzaicloud/kafka-operator/pkg/errorfactory"
+	properties "github.com/banzaicloud/kafka-operator/properties/pkg"
 )
 
 // CreateTopicOptions holds info about topic configuration
@@ -124,7 +127,8 @@ func (k *kafkaClient) EnsurePartitionCount(topic string, desired int32) (changed
 	}
 
 	if desired != int32(len(meta[0].Partitions)) {
-		// TODO (tinyzimmer): maybe let the user specify partition assignments
+		// the replicas of the new partitions are placed by kafka, explicit assignments
+		// are applied by EnsureReplicaAssignment afterwards
 		assn := make([][]int32, 0)
 		changed = true
 		err = k.admin.CreatePartitions(topic, desired, assn, false)
@@ -132,7 +136,96 @@ func (k *kafkaClient) EnsurePartitionCount(topic string, desired int32) (changed
 	return
 }
 
-// EnsureTopicConfig is an idempotent call to ensure topic configuration overrides
-func (k *kafkaClient) EnsureTopicConfig(topic string, desiredConf map[string]*string) error {
-	return k.admin.AlterConfig(sarama.TopicResource, topic, desiredConf, false)
+// TopicMetaToStatus summarizes the partition health of a topic
+func (k *kafkaClient) TopicMetaToStatus(meta *sarama.TopicMetadata) *v1alpha1.KafkaTopicStatus {
+	status := &v1alpha1.KafkaTopicStatus{
+		PartitionCount:     int32(len(meta.Partitions)),
+		LeaderDistribution: make(map[string]int32),
+	}
+	for i, partition := range meta.Partitions {
+		if replicas := int32(len(partition.Replicas)); i == 0 || replicas < status.ReplicationFactor {
+			status.ReplicationFactor = replicas
+		}
+		if partition.Leader < 0 {
+			status.OfflinePartitions++
+		} else {
+			status.LeaderDistribution[strconv.Itoa(int(partition.Leader))]++
+		}
+		if len(partition.Isr) < len(partition.Replicas) {
+			status.UnderReplicatedPartitions++
+		}
+	}
+	return status
+}
+
+// DescribeTopicConfig returns the effective configuration of a topic including the
+// broker defaults, sensitive values are left out
+func (k *kafkaClient) DescribeTopicConfig(topic string) (map[string]string, error) {
+	entries, err := k.admin.DescribeConfig(sarama.ConfigResource{Type: sarama.TopicResource, Name: topic})
+	if err != nil {
+		return nil, errorfactory.New(errorfactory.BrokersRequestError{}, err, fmt.Sprintf("could not describe config of topic %s", topic))
+	}
+	config := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if entry.Sensitive {
+			continue
+		}
+		config[entry.Name] = entry.Value
+	}
+	return config, nil
+}
+
+// DescribeTopicConfigOverrides returns the configurations which are overridden on the topic level
+func (k *kafkaClient) DescribeTopicConfigOverrides(topic string) (*properties.Properties, error) {
+	entries, err := k.admin.DescribeConfig(sarama.ConfigResource{Type: sarama.TopicResource, Name: topic})
+	if err != nil {
+		return nil, errorfactory.New(errorfactory.BrokersRequestError{}, err, fmt.Sprintf("could not describe config of topic %s", topic))
+	}
+	overrides := properties.NewProperties()
+	for _, entry := range entries {
+		if entry.Source != sarama.SourceTopic {
+			continue
+		}
+		if err = overrides.Set(entry.Name, entry.Value); err != nil {
+			return nil, err
+		}
+	}
+	return overrides, nil
+}
+
+// EnsureTopicConfig is an idempotent call to ensure topic configuration overrides. The overrides are
+// compared with the desired configuration and only the differences are applied incrementally,
+// overrides missing from the desired configuration are removed. The applied differences are returned.
+func (k *kafkaClient) EnsureTopicConfig(topic string, desiredConf map[string]*string) (properties.DiffResult, error) {
+	current, err := k.DescribeTopicConfigOverrides(topic)
+	if err != nil {
+		return nil, err
+	}
+	desired := properties.NewProperties()
+	for key, value := range desiredConf {
+		if value == nil {
+			continue
+		}
+		if err = desired.Set(key, *value); err != nil {
+			return nil, err
+		}
+	}
+
+	diff := current.Diff(desired)
+	if len(diff) == 0 {
+		return diff, nil
+	}
+	entries := make(map[string]sarama.IncrementalAlterConfigsEntry, len(diff))
+	for key, values := range diff {
+		if !values[1].IsValid() {
+			entries[key] = sarama.IncrementalAlterConfigsEntry{Operation: sarama.IncrementalAlterConfigsOperationDelete}
+			continue
+		}
+		value := values[1].Value()
+		entries[key] = sarama.IncrementalAlterConfigsEntry{Operation: sarama.IncrementalAlterConfigsOperationSet, Value: &value}
+	}
+	if err = k.admin.IncrementalAlterConfig(sarama.TopicResource, topic, entries, false); err != nil {
+		return nil, errorfactory.New(errorfactory.BrokersRequestError{}, err, fmt.Sprintf("could not alter config of topic %s", topic))
+	}
+	return diff, nil
 }
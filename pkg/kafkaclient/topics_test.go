@@ -15,6 +15,7 @@
 package kafkaclient
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/Shopify/sarama"
@@ -123,11 +124,32 @@ func TestDeleteTopic(t *testing.T) {
 
 func TestEnsureTopicConfig(t *testing.T) {
 	client := newOpenedMockClient()
-	if err := client.EnsureTopicConfig("test-topic", map[string]*string{}); err != nil {
+	if _, err := client.EnsureTopicConfig("test-topic", map[string]*string{}); err != nil {
 		t.Error("Expected no error, got:", err)
 	}
+
+	stale, retention := "delete", "604800000"
+	client.admin.CreateTopic("test-topic", &sarama.TopicDetail{ConfigEntries: map[string]*string{"cleanup.policy": &stale}}, false)
+	diff, err := client.EnsureTopicConfig("test-topic", map[string]*string{"retention.ms": &retention})
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	} else if !reflect.DeepEqual(diff.Keys(), []string{"cleanup.policy", "retention.ms"}) {
+		t.Error("Expected diff of cleanup.policy and retention.ms, got:", diff)
+	}
+	if overrides, _ := client.DescribeTopicConfigOverrides("test-topic"); overrides.Len() != 1 {
+		t.Error("Expected stale override to be removed, got:", overrides)
+	} else if value, _ := overrides.Get("retention.ms"); value.Value() != retention {
+		t.Error("Expected retention.ms override to be set, got:", overrides)
+	}
+
+	if diff, err = client.EnsureTopicConfig("test-topic", map[string]*string{"retention.ms": &retention}); err != nil {
+		t.Error("Expected no error, got:", err)
+	} else if len(diff) != 0 {
+		t.Error("Expected no diff for configured topic, got:", diff)
+	}
+
 	client.admin, _ = newMockClusterAdminFailOps([]string{}, sarama.NewConfig())
-	if err := client.EnsureTopicConfig("test-topic", map[string]*string{}); err == nil {
+	if _, err := client.EnsureTopicConfig("test-topic", map[string]*string{}); err == nil {
 		t.Error("Expected error, got nil")
 	}
 }
@@ -151,3 +173,49 @@ func TestEnsurePartitionCount(t *testing.T) {
 		t.Error("Expected error, got nil")
 	}
 }
+
+func TestTopicMetaToStatus(t *testing.T) {
+	client := newOpenedMockClient()
+
+	status := client.TopicMetaToStatus(&sarama.TopicMetadata{
+		Name: "test-topic",
+		Partitions: []*sarama.PartitionMetadata{
+			{ID: 0, Leader: 0, Replicas: []int32{0, 1, 2}, Isr: []int32{0, 1, 2}},
+			{ID: 1, Leader: 1, Replicas: []int32{1, 2}, Isr: []int32{1}},
+			{ID: 2, Leader: -1, Replicas: []int32{2, 0, 1}, Isr: []int32{}},
+			{ID: 3, Leader: 1, Replicas: []int32{1, 0, 2}, Isr: []int32{1, 0, 2}},
+		},
+	})
+	if status.PartitionCount != 4 {
+		t.Error("Expected partition count 4, got:", status.PartitionCount)
+	}
+	if status.ReplicationFactor != 2 {
+		t.Error("Expected replication factor 2, got:", status.ReplicationFactor)
+	}
+	if status.UnderReplicatedPartitions != 2 {
+		t.Error("Expected 2 under replicated partitions, got:", status.UnderReplicatedPartitions)
+	}
+	if status.OfflinePartitions != 1 {
+		t.Error("Expected 1 offline partition, got:", status.OfflinePartitions)
+	}
+	if expected := map[string]int32{"0": 1, "1": 2}; !reflect.DeepEqual(status.LeaderDistribution, expected) {
+		t.Errorf("Expected leader distribution %v, got: %v", expected, status.LeaderDistribution)
+	}
+}
+
+func TestDescribeTopicConfig(t *testing.T) {
+	client := newOpenedMockClient()
+
+	value := "604800000"
+	client.admin.CreateTopic("test-topic", &sarama.TopicDetail{ConfigEntries: map[string]*string{"retention.ms": &value}}, false)
+	if config, err := client.DescribeTopicConfig("test-topic"); err != nil {
+		t.Error("Expected no error, got:", err)
+	} else if !reflect.DeepEqual(config, map[string]string{"retention.ms": value}) {
+		t.Error("Expected retention.ms config, got:", config)
+	}
+
+	client.admin, _ = newMockClusterAdminFailOps([]string{}, sarama.NewConfig())
+	if _, err := client.DescribeTopicConfig("test-topic"); err == nil {
+		t.Error("Expected error on DescribeTopicConfig, got nil")
+	}
+}
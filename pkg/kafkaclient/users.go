@@ -15,52 +15,27 @@
 package kafkaclient
 
 import (
+	"crypto/rand"
 	"fmt"
 
 	"github.com/Shopify/sarama"
 	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
 	"github.com/banzaicloud/kafka-operator/pkg/errorfactory"
+	kafkautil "github.com/banzaicloud/kafka-operator/pkg/util/kafka"
 )
 
 // AclPatternTypeMapping maps patternType from v1alpha1.KafkaPatternType to sarama.AclResourcePatternType
 func AclPatternTypeMapping(patternType v1alpha1.KafkaPatternType) sarama.AclResourcePatternType {
-	switch patternType {
-	case v1alpha1.KafkaPatternTypeAny:
-		return sarama.AclPatternAny
-	case v1alpha1.KafkaPatternTypeMatch:
-		return sarama.AclPatternMatch
-	case v1alpha1.KafkaPatternTypeLiteral:
-		return sarama.AclPatternLiteral
-	case v1alpha1.KafkaPatternTypePrefixed:
-		return sarama.AclPatternPrefixed
-	default:
-		return sarama.AclPatternUnknown
-	}
-}
-
-// CreateUserACLs creates Kafka ACLs for the given access type and user
-// `literal` patternType will be used if patternType == ""
-func (k *kafkaClient) CreateUserACLs(accessType v1alpha1.KafkaAccessType, patternType v1alpha1.KafkaPatternType, dn string, topic string) (err error) {
-	userName := fmt.Sprintf("User:%s", dn)
-	if patternType == "" {
-		patternType = v1alpha1.KafkaPatternTypeDefault
-	}
-	aclPatternType := AclPatternTypeMapping(patternType)
-	if aclPatternType == sarama.AclPatternUnknown {
-		return errorfactory.New(errorfactory.InternalError{}, fmt.Errorf("unknown type: %s", patternType), "unrecognized pattern type")
-	}
-	switch accessType {
-	case v1alpha1.KafkaAccessTypeRead:
-		return k.createReadACLs(userName, topic, aclPatternType)
-	case v1alpha1.KafkaAccessTypeWrite:
-		return k.createWriteACLs(userName, topic, aclPatternType)
-	default:
-		return errorfactory.New(errorfactory.InternalError{}, fmt.Errorf("unknown type: %s", accessType), "unrecognized access type")
-	}
+	return kafkautil.AclPatternTypeMapping(patternType)
 }
 
 func (k *kafkaClient) ListUserACLs() ([]sarama.ResourceAcls, error) {
-	acls, err := k.admin.ListAcls(sarama.AclFilter{})
+	acls, err := k.admin.ListAcls(sarama.AclFilter{
+		ResourceType:              sarama.AclResourceAny,
+		ResourcePatternTypeFilter: sarama.AclPatternAny,
+		Operation:                 sarama.AclOperationAny,
+		PermissionType:            sarama.AclPermissionAny,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -69,9 +44,7 @@ func (k *kafkaClient) ListUserACLs() ([]sarama.ResourceAcls, error) {
 
 // DeleteUserACLs removes all ACLs for a given user
 func (k *kafkaClient) DeleteUserACLs(dn string) (err error) {
-	matches, err := k.admin.DeleteACL(sarama.AclFilter{
-		Principal: &dn,
-	}, false)
+	matches, err := k.admin.DeleteACL(userACLFilter(fmt.Sprintf("User:%s", dn)), false)
 	if err != nil {
 		return
 	}
@@ -83,101 +56,193 @@ func (k *kafkaClient) DeleteUserACLs(dn string) (err error) {
 	return
 }
 
-func (k *kafkaClient) createReadACLs(dn string, topic string, patternType sarama.AclResourcePatternType) (err error) {
-	if err = k.createCommonACLs(dn, topic, patternType); err != nil {
-		return
+// DescribeUserACLs returns the ACLs present in kafka for the given user
+func (k *kafkaClient) DescribeUserACLs(dn string) ([]kafkautil.UserACLEntry, error) {
+	principal := fmt.Sprintf("User:%s", dn)
+	resourceAcls, err := k.admin.ListAcls(userACLFilter(principal))
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]kafkautil.UserACLEntry, 0)
+	for _, resourceAcl := range resourceAcls {
+		for _, acl := range resourceAcl.Acls {
+			if acl == nil || acl.Principal != principal {
+				continue
+			}
+			entries = append(entries, kafkautil.UserACLEntry{Resource: resourceAcl.Resource, Acl: *acl})
+		}
 	}
+	return entries, nil
+}
 
-	// READ on topic
-	if err = k.admin.CreateACL(sarama.Resource{
-		ResourceType:        sarama.AclResourceTopic,
-		ResourceName:        topic,
-		ResourcePatternType: patternType,
-	}, sarama.Acl{
-		Principal:      dn,
-		Host:           "*",
-		Operation:      sarama.AclOperationRead,
-		PermissionType: sarama.AclPermissionAllow,
-	}); err != nil {
-		return
+// ReconcileUserACLs creates the desired ACLs of the user which are missing and removes
+// the ones present in kafka but not desired anymore, the corrected entries are returned
+func (k *kafkaClient) ReconcileUserACLs(dn string, desired []kafkautil.UserACLEntry) (created, deleted []kafkautil.UserACLEntry, err error) {
+	actualEntries, err := k.DescribeUserACLs(dn)
+	if err != nil {
+		return nil, nil, err
+	}
+	actual := make(map[kafkautil.UserACLEntry]struct{}, len(actualEntries))
+	for _, entry := range actualEntries {
+		actual[entry] = struct{}{}
 	}
 
-	// READ on groups
-	err = k.admin.CreateACL(sarama.Resource{
-		ResourceType:        sarama.AclResourceGroup,
-		ResourceName:        "*",
-		ResourcePatternType: sarama.AclPatternLiteral,
-	}, sarama.Acl{
-		Principal:      dn,
-		Host:           "*",
-		Operation:      sarama.AclOperationRead,
-		PermissionType: sarama.AclPermissionAllow,
-	})
+	desiredSet := make(map[kafkautil.UserACLEntry]struct{}, len(desired))
+	for _, entry := range desired {
+		desiredSet[entry] = struct{}{}
+		if _, ok := actual[entry]; ok {
+			continue
+		}
+		if err = k.admin.CreateACL(entry.Resource, entry.Acl); err != nil {
+			return created, deleted, err
+		}
+		created = append(created, entry)
+	}
 
-	return
+	for _, entry := range actualEntries {
+		if _, ok := desiredSet[entry]; ok {
+			continue
+		}
+		matches, err := k.admin.DeleteACL(exactACLFilter(entry), false)
+		if err != nil {
+			return created, deleted, err
+		}
+		for _, match := range matches {
+			if match.Err != sarama.ErrNoError {
+				return created, deleted, match.Err
+			}
+		}
+		deleted = append(deleted, entry)
+	}
+	return created, deleted, nil
 }
 
-func (k *kafkaClient) createWriteACLs(dn string, topic string, patternType sarama.AclResourcePatternType) (err error) {
-	if err = k.createCommonACLs(dn, topic, patternType); err != nil {
-		return
+// userACLFilter returns a filter matching every ACL of the given principal
+func userACLFilter(principal string) sarama.AclFilter {
+	return sarama.AclFilter{
+		ResourceType:              sarama.AclResourceAny,
+		ResourcePatternTypeFilter: sarama.AclPatternAny,
+		Principal:                 &principal,
+		Operation:                 sarama.AclOperationAny,
+		PermissionType:            sarama.AclPermissionAny,
 	}
+}
 
-	// WRITE on topic
-	if err = k.admin.CreateACL(sarama.Resource{
-		ResourceType:        sarama.AclResourceTopic,
-		ResourceName:        topic,
-		ResourcePatternType: patternType,
-	}, sarama.Acl{
-		Principal:      dn,
-		Host:           "*",
-		Operation:      sarama.AclOperationWrite,
-		PermissionType: sarama.AclPermissionAllow,
-	}); err != nil {
-		return
+// exactACLFilter returns a filter matching only the given ACL entry
+func exactACLFilter(entry kafkautil.UserACLEntry) sarama.AclFilter {
+	return sarama.AclFilter{
+		ResourceType:              entry.Resource.ResourceType,
+		ResourceName:              &entry.Resource.ResourceName,
+		ResourcePatternTypeFilter: entry.Resource.ResourcePatternType,
+		Principal:                 &entry.Acl.Principal,
+		Host:                      &entry.Acl.Host,
+		Operation:                 entry.Acl.Operation,
+		PermissionType:            entry.Acl.PermissionType,
 	}
+}
 
-	// CREATE on topic
-	err = k.admin.CreateACL(sarama.Resource{
-		ResourceType:        sarama.AclResourceTopic,
-		ResourceName:        topic,
-		ResourcePatternType: patternType,
-	}, sarama.Acl{
-		Principal:      dn,
-		Host:           "*",
-		Operation:      sarama.AclOperationCreate,
-		PermissionType: sarama.AclPermissionAllow,
-	})
+// errScramResourceNotFound is the RESOURCE_NOT_FOUND error code returned by the brokers
+// when the requested SCRAM credentials do not exist, sarama has no constant for it
+const errScramResourceNotFound = sarama.KError(91)
+
+// ScramMechanismMapping maps v1alpha1.UserAuthenticationType to sarama.ScramMechanismType
+func ScramMechanismMapping(authType v1alpha1.UserAuthenticationType) sarama.ScramMechanismType {
+	switch authType {
+	case v1alpha1.UserAuthenticationSCRAMSHA256:
+		return sarama.SCRAM_MECHANISM_SHA_256
+	case v1alpha1.UserAuthenticationSCRAMSHA512:
+		return sarama.SCRAM_MECHANISM_SHA_512
+	default:
+		return sarama.SCRAM_MECHANISM_UNKNOWN
+	}
+}
 
-	return
+// UpsertUserScramCredentials creates or updates the SCRAM credentials of the given user
+func (k *kafkaClient) UpsertUserScramCredentials(user string, authType v1alpha1.UserAuthenticationType, password string) error {
+	mechanism := ScramMechanismMapping(authType)
+	if mechanism == sarama.SCRAM_MECHANISM_UNKNOWN {
+		return errorfactory.New(errorfactory.InternalError{}, fmt.Errorf("unknown type: %s", authType), "unrecognized SCRAM mechanism")
+	}
+	salt := make([]byte, scramSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return errorfactory.New(errorfactory.InternalError{}, err, "could not generate salt for SCRAM credentials")
+	}
+	results, err := k.admin.UpsertUserScramCredentials([]sarama.AlterUserScramCredentialsUpsert{{
+		Name:       user,
+		Mechanism:  mechanism,
+		Iterations: scramIterations,
+		Salt:       salt,
+		Password:   []byte(password),
+	}})
+	if err != nil {
+		return err
+	}
+	for _, result := range results {
+		if result.ErrorCode != sarama.ErrNoError {
+			return result.ErrorCode
+		}
+	}
+	return nil
 }
 
-func (k *kafkaClient) createCommonACLs(dn string, topic string, patternType sarama.AclResourcePatternType) (err error) {
-	// DESCRIBE on topic
-	if err = k.admin.CreateACL(sarama.Resource{
-		ResourceType:        sarama.AclResourceTopic,
-		ResourceName:        topic,
-		ResourcePatternType: patternType,
-	}, sarama.Acl{
-		Principal:      dn,
-		Host:           "*",
-		Operation:      sarama.AclOperationDescribe,
-		PermissionType: sarama.AclPermissionAllow,
-	}); err != nil {
-		return
+// DescribeUserScramCredentials returns the SCRAM mechanisms the given user has credentials for
+func (k *kafkaClient) DescribeUserScramCredentials(user string) ([]*sarama.UserScramCredentialsResponseInfo, error) {
+	results, err := k.admin.DescribeUserScramCredentials([]string{user})
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]*sarama.UserScramCredentialsResponseInfo, 0)
+	for _, result := range results {
+		switch result.ErrorCode {
+		case sarama.ErrNoError:
+			infos = append(infos, result.CredentialInfos...)
+		case errScramResourceNotFound:
+			continue
+		default:
+			return nil, result.ErrorCode
+		}
 	}
+	return infos, nil
+}
 
-	// DESCRIBE_CONFIGS on topic
-	if err = k.admin.CreateACL(sarama.Resource{
-		ResourceType:        sarama.AclResourceTopic,
-		ResourceName:        topic,
-		ResourcePatternType: patternType,
-	}, sarama.Acl{
-		Principal:      dn,
-		Host:           "*",
-		Operation:      sarama.AclOperationDescribeConfigs,
-		PermissionType: sarama.AclPermissionAllow,
-	}); err != nil {
-		return
+// DeleteUserScramCredentials removes the SCRAM credentials of the given user for the given
+// mechanisms, all SCRAM credentials of the user are removed when no mechanism is given
+func (k *kafkaClient) DeleteUserScramCredentials(user string, mechanisms ...sarama.ScramMechanismType) error {
+	infos, err := k.DescribeUserScramCredentials(user)
+	if err != nil {
+		return err
 	}
-	return
+	deletions := make([]sarama.AlterUserScramCredentialsDelete, 0, len(infos))
+	for _, info := range infos {
+		if len(mechanisms) > 0 && !scramMechanismsContain(mechanisms, info.Mechanism) {
+			continue
+		}
+		deletions = append(deletions, sarama.AlterUserScramCredentialsDelete{
+			Name:      user,
+			Mechanism: info.Mechanism,
+		})
+	}
+	if len(deletions) == 0 {
+		return nil
+	}
+	results, err := k.admin.DeleteUserScramCredentials(deletions)
+	if err != nil {
+		return err
+	}
+	for _, result := range results {
+		if result.ErrorCode != sarama.ErrNoError && result.ErrorCode != errScramResourceNotFound {
+			return result.ErrorCode
+		}
+	}
+	return nil
+}
+
+// scramMechanismsContain returns true if the mechanism is one of the given mechanisms
+func scramMechanismsContain(mechanisms []sarama.ScramMechanismType, mechanism sarama.ScramMechanismType) bool {
+	for _, m := range mechanisms {
+		if m == mechanism {
+			return true
+		}
+	}
+	return false
 }
@@ -15,82 +15,165 @@
 package kafkaclient
 
 import (
+	"reflect"
+	"sort"
 	"testing"
 
 	"github.com/Shopify/sarama"
 	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
+	kafkautil "github.com/banzaicloud/kafka-operator/pkg/util/kafka"
 )
 
-func TestCreateUserACLs(t *testing.T) {
+func TestDeleteUserACLs(t *testing.T) {
 	client := newOpenedMockClient()
 
-	validAccessTypes := []v1alpha1.KafkaAccessType{
-		"read",
-		"write"}
-	invalidAccessTypes := []v1alpha1.KafkaAccessType{
-		"",
-		"helloWorld"}
-	allAccessTypes := append(validAccessTypes, invalidAccessTypes...)
-
-	validPatternTypes := []v1alpha1.KafkaPatternType{
-		"any",
-		"literal",
-		"match",
-		"prefixed",
-		""}
-	invalidPatternTypes := []v1alpha1.KafkaPatternType{
-		"helloWorld"}
-	allPatternTypes := append(validPatternTypes, invalidPatternTypes...)
-
-	// Test all valid combinations of accessType and patternType
-	for _, accessType := range validAccessTypes {
-		for _, patternType := range validPatternTypes {
-			if err := client.CreateUserACLs(accessType, patternType, "test-user", "test-topic"); err != nil {
-				t.Error("Expected no error, got:", err)
-			}
-		}
+	if err := client.DeleteUserACLs("test-user"); err != nil {
+		t.Error("Expected no error, got:", err)
 	}
-	// Test invalid accessTypes against all patternTypes
-	for _, accessType := range invalidAccessTypes {
-		for _, patternType := range allPatternTypes {
-			if err := client.CreateUserACLs(accessType, patternType, "test-user", "test-topic"); err == nil {
-				t.Error("Expected error, got nil")
-			}
-		}
+
+	if err := client.DeleteUserACLs("with-error"); err == nil {
+		t.Error("Expected error, got nil")
 	}
-	// Test invalid patternTypes against all accessTypes
-	for _, patternType := range invalidPatternTypes {
-		for _, accessType := range allAccessTypes {
-			if err := client.CreateUserACLs(accessType, patternType, "test-user", "test-topic"); err == nil {
-				t.Error("Expected error, got nil")
-			}
+
+	client.admin, _ = newMockClusterAdminFailOps([]string{}, sarama.NewConfig())
+	if err := client.DeleteUserACLs("test-userr"); err == nil {
+		t.Error("Expected error, got nil")
+	}
+}
+
+func TestUserScramCredentials(t *testing.T) {
+	client := newOpenedMockClient()
+
+	if err := client.UpsertUserScramCredentials("test-user", v1alpha1.UserAuthenticationTLS, "secret"); err == nil {
+		t.Error("Expected error for non SCRAM authentication type, got nil")
+	}
+
+	if err := client.UpsertUserScramCredentials("test-user", v1alpha1.UserAuthenticationSCRAMSHA512, "secret"); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+
+	infos, err := client.DescribeUserScramCredentials("test-user")
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if len(infos) != 1 || infos[0].Mechanism != sarama.SCRAM_MECHANISM_SHA_512 || infos[0].Iterations != scramIterations {
+		t.Error("Expected a single SCRAM-SHA-512 credential, got:", infos)
+	}
+
+	if infos, err = client.DescribeUserScramCredentials("unknown-user"); err != nil {
+		t.Error("Expected no error for unknown user, got:", err)
+	} else if len(infos) != 0 {
+		t.Error("Expected no credentials for unknown user, got:", infos)
+	}
+
+	if err := client.DeleteUserScramCredentials("test-user"); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if infos, _ = client.DescribeUserScramCredentials("test-user"); len(infos) != 0 {
+		t.Error("Expected credentials to be deleted, got:", infos)
+	}
+
+	// only the credentials of the given mechanisms are deleted
+	for _, authType := range []v1alpha1.UserAuthenticationType{v1alpha1.UserAuthenticationSCRAMSHA256, v1alpha1.UserAuthenticationSCRAMSHA512} {
+		if err := client.UpsertUserScramCredentials("test-user", authType, "secret"); err != nil {
+			t.Error("Expected no error, got:", err)
 		}
 	}
+	if err := client.DeleteUserScramCredentials("test-user", sarama.SCRAM_MECHANISM_SHA_256); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if infos, _ = client.DescribeUserScramCredentials("test-user"); len(infos) != 1 || infos[0].Mechanism != sarama.SCRAM_MECHANISM_SHA_512 {
+		t.Error("Expected only the SCRAM-SHA-512 credential to be kept, got:", infos)
+	}
+	if err := client.DeleteUserScramCredentials("test-user"); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+
+	// deleting credentials of a user without any is a no-op
+	if err := client.DeleteUserScramCredentials("test-user"); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
 
 	client.admin, _ = newMockClusterAdminFailOps([]string{}, sarama.NewConfig())
-	// Test all combinations of accessType and patternType
-	for _, accessType := range allAccessTypes {
-		for _, patternType := range allPatternTypes {
-			if err := client.CreateUserACLs(accessType, patternType, "test-user", "test-topic"); err == nil {
-				t.Error("Expected error, got nil")
-			}
-		}
+	if err := client.UpsertUserScramCredentials("test-user", v1alpha1.UserAuthenticationSCRAMSHA256, "secret"); err == nil {
+		t.Error("Expected error, got nil")
+	}
+	if err := client.DeleteUserScramCredentials("test-user"); err == nil {
+		t.Error("Expected error, got nil")
 	}
 }
 
-func TestDeleteUserACLs(t *testing.T) {
+func TestReconcileUserACLs(t *testing.T) {
 	client := newOpenedMockClient()
 
-	if err := client.DeleteUserACLs("test-user"); err != nil {
+	listACLStrings := func() []string {
+		entries, _ := client.DescribeUserACLs("reconcile-user")
+		out := kafkautil.ACLStrings(entries)
+		sort.Strings(out)
+		return out
+	}
+
+	readSpec := &v1alpha1.KafkaUserSpec{
+		TopicGrants: []v1alpha1.UserTopicGrant{{TopicName: "test-topic", AccessType: v1alpha1.KafkaAccessTypeRead}},
+	}
+	desired, _ := kafkautil.DesiredUserACLs("reconcile-user", readSpec)
+	created, deleted, err := client.ReconcileUserACLs("reconcile-user", desired)
+	if err != nil {
 		t.Error("Expected no error, got:", err)
 	}
+	if len(created) != len(desired) || len(deleted) != 0 {
+		t.Errorf("Expected %d created and no deleted ACLs, got: %v, %v", len(desired), created, deleted)
+	}
+	expected := kafkautil.ACLStrings(desired)
+	sort.Strings(expected)
+	if actual := listACLStrings(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected ACLs %v, got: %v", expected, actual)
+	}
 
-	if err := client.DeleteUserACLs("with-error"); err == nil {
-		t.Error("Expected error, got nil")
+	// switching to write access removes the read ACLs
+	writeSpec := &v1alpha1.KafkaUserSpec{
+		TopicGrants: []v1alpha1.UserTopicGrant{{TopicName: "test-topic", AccessType: v1alpha1.KafkaAccessTypeWrite}},
+	}
+	desired, _ = kafkautil.DesiredUserACLs("reconcile-user", writeSpec)
+	created, deleted, err = client.ReconcileUserACLs("reconcile-user", desired)
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	// Describe and DescribeConfigs are kept, Write and Create replace Read on the topic and groups
+	if len(created) != 2 || len(deleted) != 2 {
+		t.Errorf("Expected 2 created and 2 deleted ACLs, got: %v, %v", created, deleted)
+	}
+	expected = kafkautil.ACLStrings(desired)
+	sort.Strings(expected)
+	if actual := listACLStrings(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected ACLs %v, got: %v", expected, actual)
+	}
+
+	// ACLs added out-of-band for the principal are removed as well
+	if err = client.admin.CreateACL(sarama.Resource{
+		ResourceType:        sarama.AclResourceTopic,
+		ResourceName:        "other-topic",
+		ResourcePatternType: sarama.AclPatternLiteral,
+	}, sarama.Acl{
+		Principal:      "User:reconcile-user",
+		Host:           "*",
+		Operation:      sarama.AclOperationAll,
+		PermissionType: sarama.AclPermissionAllow,
+	}); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if _, deleted, err = client.ReconcileUserACLs("reconcile-user", nil); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if len(deleted) != 5 {
+		t.Error("Expected 5 deleted ACLs, got:", deleted)
+	}
+	if actual := listACLStrings(); len(actual) != 0 {
+		t.Error("Expected every ACL to be removed, got:", actual)
 	}
 
 	client.admin, _ = newMockClusterAdminFailOps([]string{}, sarama.NewConfig())
-	if err := client.DeleteUserACLs("test-userr"); err == nil {
+	if _, _, err = client.ReconcileUserACLs("reconcile-user", desired); err == nil {
 		t.Error("Expected error, got nil")
 	}
 }
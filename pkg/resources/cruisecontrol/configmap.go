@@ -20,12 +20,14 @@ import (
 	"strconv"
 
 	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
 	"github.com/banzaicloud/kafka-operator/api/v1beta1"
 	"github.com/banzaicloud/kafka-operator/pkg/resources/templates"
 	"github.com/banzaicloud/kafka-operator/pkg/util"
+	ccutils "github.com/banzaicloud/kafka-operator/pkg/util/cruisecontrol"
 	kafkautils "github.com/banzaicloud/kafka-operator/pkg/util/kafka"
 	zookeeperutils "github.com/banzaicloud/kafka-operator/pkg/util/zookeeper"
 	properties "github.com/banzaicloud/kafka-operator/properties/pkg"
@@ -43,6 +45,9 @@ func (r *Reconciler) configMap(clientPass, capacityConfig string, log logr.Logge
 	}
 	ccConfig.Merge(conf)
 
+	// Add the goals, anomaly detection and metric sampler settings of the CR overriding the base configuration
+	ccConfig.Merge(generateStructuredConfig(r.KafkaCluster.Spec.CruiseControlConfig, log))
+
 	bootstrapServers, err := kafkautils.GetBootstrapServersService(r.KafkaCluster)
 	if err != nil {
 		log.Error(err, "getting Kafka bootstrap servers for Cruise Control failed")
@@ -51,10 +56,12 @@ func (r *Reconciler) configMap(clientPass, capacityConfig string, log logr.Logge
 		log.Error(err, "setting bootstrap.servers in Cruise Control configuration failed", "config", bootstrapServers)
 	}
 
-	// Add Zookeeper configuration
-	zkConnect := zookeeperutils.PrepareConnectionAddress(r.KafkaCluster.Spec.ZKAddresses, r.KafkaCluster.Spec.GetZkPath())
-	if err = ccConfig.Set("zookeeper.connect", zkConnect); err != nil {
-		log.Error(err, "setting zookeeper.connect in Cruise Control configuration failed", "config", zkConnect)
+	// Add Zookeeper configuration, the cluster has no ZooKeeper in KRaft mode
+	if !r.KafkaCluster.Spec.KRaftMode {
+		zkConnect := zookeeperutils.PrepareConnectionAddress(r.KafkaCluster.Spec.ZKAddresses, r.KafkaCluster.Spec.GetZkPath())
+		if err = ccConfig.Set("zookeeper.connect", zkConnect); err != nil {
+			log.Error(err, "setting zookeeper.connect in Cruise Control configuration failed", "config", zkConnect)
+		}
 	}
 
 	// Add SSL configuration
@@ -104,6 +111,142 @@ func generateSSLConfig(l *v1beta1.ListenersConfig, clientPass string, log logr.L
 	return sslConf
 }
 
+// goalsProperties holds the properties of the goals of CruiseControl
+type goalsProperties struct {
+	Goals                 []string `properties:"goals,omitempty"`
+	DefaultGoals          []string `properties:"default.goals,omitempty"`
+	HardGoals             []string `properties:"hard.goals,omitempty"`
+	AnomalyDetectionGoals []string `properties:"anomaly.detection.goals,omitempty"`
+	SelfHealingGoals      []string `properties:"self.healing.goals,omitempty"`
+}
+
+// anomalyDetectionProperties holds the properties of the anomaly detectors and the self-healing of CruiseControl
+type anomalyDetectionProperties struct {
+	IntervalMs                          *int64 `properties:"anomaly.detection.interval.ms"`
+	GoalViolationIntervalMs             *int64 `properties:"goal.violation.detection.interval.ms"`
+	MetricAnomalyIntervalMs             *int64 `properties:"metric.anomaly.detection.interval.ms"`
+	DiskFailureIntervalMs               *int64 `properties:"disk.failure.detection.interval.ms"`
+	BrokerFailureAlertThresholdMs       *int64 `properties:"broker.failure.alert.threshold.ms"`
+	BrokerFailureSelfHealingThresholdMs *int64 `properties:"broker.failure.self.healing.threshold.ms"`
+	NotifierClass                       string `properties:"anomaly.notifier.class,omitempty"`
+	SelfHealingEnabled                  *bool  `properties:"self.healing.enabled"`
+	SelfHealingBrokerFailure            *bool  `properties:"self.healing.broker.failure.enabled"`
+	SelfHealingGoalViolation            *bool  `properties:"self.healing.goal.violation.enabled"`
+	SelfHealingMetricAnomaly            *bool  `properties:"self.healing.metric.anomaly.enabled"`
+	SelfHealingDiskFailure              *bool  `properties:"self.healing.disk.failure.enabled"`
+	SelfHealingTopicAnomaly             *bool  `properties:"self.healing.topic.anomaly.enabled"`
+}
+
+// metricSamplerProperties holds the properties of the metric sampling and the sample store of CruiseControl
+type metricSamplerProperties struct {
+	SamplingIntervalMs                  *int64 `properties:"metric.sampling.interval.ms"`
+	PartitionMetricsWindowMs            *int64 `properties:"partition.metrics.window.ms"`
+	NumPartitionMetricsWindows          *int32 `properties:"num.partition.metrics.windows"`
+	MinSamplesPerPartitionMetricsWindow *int32 `properties:"min.samples.per.partition.metrics.window"`
+	BrokerMetricsWindowMs               *int64 `properties:"broker.metrics.window.ms"`
+	NumBrokerMetricsWindows             *int32 `properties:"num.broker.metrics.windows"`
+	MinSamplesPerBrokerMetricsWindow    *int32 `properties:"min.samples.per.broker.metrics.window"`
+	PartitionSampleStoreTopic           string `properties:"partition.metric.sample.store.topic,omitempty"`
+	BrokerSampleStoreTopic              string `properties:"broker.metric.sample.store.topic,omitempty"`
+	SampleStoreTopicReplicationFactor   *int32 `properties:"sample.store.topic.replication.factor"`
+}
+
+// generateStructuredConfig renders the goals, anomaly detection and metric sampler settings of the CR,
+// the invalid settings are left out of the configuration
+func generateStructuredConfig(config v1beta1.CruiseControlConfig, log logr.Logger) *properties.Properties {
+	structuredConf := properties.NewProperties()
+
+	if config.Goals != nil {
+		goalsConf, err := generateGoalsConfig(config.Goals)
+		if err != nil {
+			log.Error(err, "setting the goals in Cruise Control configuration failed")
+		}
+		structuredConf.Merge(goalsConf)
+	}
+
+	if config.AnomalyDetection != nil {
+		anomalyDetectionConf, err := properties.Marshal(newAnomalyDetectionProperties(config.AnomalyDetection))
+		if err != nil {
+			log.Error(err, "setting the anomaly detection in Cruise Control configuration failed")
+		}
+		structuredConf.Merge(anomalyDetectionConf)
+	}
+
+	if config.MetricSampler != nil {
+		metricSamplerConf, err := properties.Marshal(newMetricSamplerProperties(config.MetricSampler))
+		if err != nil {
+			log.Error(err, "setting the metric sampler in Cruise Control configuration failed")
+		}
+		structuredConf.Merge(metricSamplerConf)
+	}
+
+	return structuredConf
+}
+
+// generateGoalsConfig renders the goals by their class names, the goals which can not be resolved are left out
+// as the goals are validated by the webhook of the KafkaCluster
+func generateGoalsConfig(config *v1beta1.CruiseControlGoalsConfig) (*properties.Properties, error) {
+	var goals goalsProperties
+	for _, list := range []struct {
+		goals  []string
+		target *[]string
+	}{
+		{config.Goals, &goals.Goals},
+		{config.DefaultGoals, &goals.DefaultGoals},
+		{config.HardGoals, &goals.HardGoals},
+		{config.AnomalyDetectionGoals, &goals.AnomalyDetectionGoals},
+		{config.SelfHealingGoals, &goals.SelfHealingGoals},
+	} {
+		*list.target, _ = ccutils.ResolveGoals(list.goals)
+	}
+
+	return properties.Marshal(goals)
+}
+
+func newAnomalyDetectionProperties(config *v1beta1.CruiseControlAnomalyDetectionConfig) anomalyDetectionProperties {
+	anomalyDetection := anomalyDetectionProperties{
+		IntervalMs:                          secondsToMs(config.IntervalSeconds),
+		GoalViolationIntervalMs:             secondsToMs(config.GoalViolationIntervalSeconds),
+		MetricAnomalyIntervalMs:             secondsToMs(config.MetricAnomalyIntervalSeconds),
+		DiskFailureIntervalMs:               secondsToMs(config.DiskFailureIntervalSeconds),
+		BrokerFailureAlertThresholdMs:       secondsToMs(config.BrokerFailureAlertThresholdSeconds),
+		BrokerFailureSelfHealingThresholdMs: secondsToMs(config.BrokerFailureSelfHealingThresholdSeconds),
+		NotifierClass:                       config.NotifierClass,
+	}
+	if selfHealing := config.SelfHealing; selfHealing != nil {
+		anomalyDetection.SelfHealingEnabled = selfHealing.Enabled
+		anomalyDetection.SelfHealingBrokerFailure = selfHealing.BrokerFailure
+		anomalyDetection.SelfHealingGoalViolation = selfHealing.GoalViolation
+		anomalyDetection.SelfHealingMetricAnomaly = selfHealing.MetricAnomaly
+		anomalyDetection.SelfHealingDiskFailure = selfHealing.DiskFailure
+		anomalyDetection.SelfHealingTopicAnomaly = selfHealing.TopicAnomaly
+	}
+	return anomalyDetection
+}
+
+func newMetricSamplerProperties(config *v1beta1.CruiseControlMetricSamplerConfig) metricSamplerProperties {
+	return metricSamplerProperties{
+		SamplingIntervalMs:                  secondsToMs(config.SamplingIntervalSeconds),
+		PartitionMetricsWindowMs:            secondsToMs(config.PartitionMetricsWindowSeconds),
+		NumPartitionMetricsWindows:          config.NumPartitionMetricsWindows,
+		MinSamplesPerPartitionMetricsWindow: config.MinSamplesPerPartitionMetricsWindow,
+		BrokerMetricsWindowMs:               secondsToMs(config.BrokerMetricsWindowSeconds),
+		NumBrokerMetricsWindows:             config.NumBrokerMetricsWindows,
+		MinSamplesPerBrokerMetricsWindow:    config.MinSamplesPerBrokerMetricsWindow,
+		PartitionSampleStoreTopic:           config.PartitionSampleStoreTopic,
+		BrokerSampleStoreTopic:              config.BrokerSampleStoreTopic,
+		SampleStoreTopicReplicationFactor:   config.SampleStoreTopicReplicationFactor,
+	}
+}
+
+func secondsToMs(seconds *int64) *int64 {
+	if seconds == nil {
+		return nil
+	}
+	ms := *seconds * 1000
+	return &ms
+}
+
 const (
 	storageConfigCPUDefaultValue   = "100"
 	storageConfigNWINDefaultValue  = "125000"
@@ -126,12 +269,25 @@ type Capacity struct {
 	NWOUT string            `json:"NW_OUT"`
 }
 
+// LiveBrokerCapacity holds the capacity of a broker observed on its volumes and on its node
+type LiveBrokerCapacity struct {
+	// Disks maps the mount paths of the broker to the capacity of their bound volumes in bytes
+	Disks map[string]int64
+	// CPU is the allocatable cpu of the node the broker runs on
+	CPU *resource.Quantity
+	// NetworkIn and NetworkOut are the bandwidth of the node the broker runs on in KB/s
+	NetworkIn  string
+	NetworkOut string
+}
+
 type JBODInvariantCapacityConfig struct {
 	Capacities []interface{} `json:"brokerCapacities"`
 }
 
-// generateCapacityConfig generates a CC capacity config with default values or returns the manually overridden value if it exists
-func GenerateCapacityConfig(kafkaCluster *v1beta1.KafkaCluster, log logr.Logger, config *corev1.ConfigMap) string {
+// GenerateCapacityConfig generates a CC capacity config with default values or returns the manually overridden value if it exists,
+// the live capacities of the brokers take precedence over the values derived from the spec
+func GenerateCapacityConfig(kafkaCluster *v1beta1.KafkaCluster, log logr.Logger, config *corev1.ConfigMap,
+	liveCapacities map[int32]LiveBrokerCapacity) string {
 	log.Info("Generating capacity config")
 
 	// If there is already a config added manually, use that one
@@ -165,6 +321,9 @@ func GenerateCapacityConfig(kafkaCluster *v1beta1.KafkaCluster, log logr.Logger,
 			},
 			Doc: defaultDoc,
 		}
+		if liveCapacity, ok := liveCapacities[broker.Id]; ok {
+			applyLiveCapacity(&brokerCapacity.Capacity, broker, kafkaCluster.Spec, liveCapacity, log)
+		}
 
 		log.Info("The following brokerCapacity was generated", "brokerCapacity", brokerCapacity)
 
@@ -183,6 +342,33 @@ func GenerateCapacityConfig(kafkaCluster *v1beta1.KafkaCluster, log logr.Logger,
 	return string(result)
 }
 
+// applyLiveCapacity overrides the capacity derived from the spec with the capacity observed on the volumes and the node of the broker
+func applyLiveCapacity(capacity *Capacity, broker v1beta1.Broker, kafkaClusterSpec v1beta1.KafkaClusterSpec, liveCapacity LiveBrokerCapacity, log logr.Logger) {
+	for mountPath, size := range liveCapacity.Disks {
+		capacity.DISK[mountPath+"/kafka"] = strconv.FormatInt(size, 10)
+	}
+
+	if liveCapacity.CPU != nil {
+		// the broker can not use more cpu than its limit even if its node has more
+		cpu := liveCapacity.CPU
+		if brokerConfig, err := broker.GetBrokerConfig(kafkaClusterSpec); err == nil {
+			if limit := brokerConfig.GetResources().Limits.Cpu(); !limit.IsZero() && limit.Cmp(*cpu) < 0 {
+				cpu = limit
+			}
+		} else {
+			log.V(warnLevel).Info("could not get cpu resource limits, using the allocatable cpu of the node", "brokerId", broker.Id)
+		}
+		capacity.CPU = strconv.Itoa(int(cpu.ScaledValue(-2)))
+	}
+
+	if liveCapacity.NetworkIn != "" {
+		capacity.NWIN = liveCapacity.NetworkIn
+	}
+	if liveCapacity.NetworkOut != "" {
+		capacity.NWOUT = liveCapacity.NetworkOut
+	}
+}
+
 func ensureContainsDefaultBrokerCapacity(data []byte, log logr.Logger) []byte {
 	config := JBODInvariantCapacityConfig{}
 	err := json.Unmarshal(data, &config)
@@ -17,6 +17,7 @@ package cruisecontrol
 import (
 	"encoding/json"
 	"reflect"
+	"strings"
 	"testing"
 
 	v1 "k8s.io/api/core/v1"
@@ -395,7 +396,7 @@ func TestGenerateCapacityConfig_JBOD(t *testing.T) {
 
 		t.Run(test.testName, func(t *testing.T) {
 			var actual CapacityConfig
-			err := json.Unmarshal([]byte(GenerateCapacityConfig(&test.kafkaCluster, log.NullLogger{}, nil)), &actual)
+			err := json.Unmarshal([]byte(GenerateCapacityConfig(&test.kafkaCluster, log.NullLogger{}, nil, nil)), &actual)
 			if err != nil {
 				t.Error(err, "could not actual unmarshal json")
 			}
@@ -732,7 +733,7 @@ func TestGenerateCapacityConfigWithUserProvidedInput(t *testing.T) {
 				},
 			}
 			var actual JBODInvariantCapacityConfig
-			err := json.Unmarshal([]byte(GenerateCapacityConfig(&kafkaCluster, log.NullLogger{}, nil)), &actual)
+			err := json.Unmarshal([]byte(GenerateCapacityConfig(&kafkaCluster, log.NullLogger{}, nil, nil)), &actual)
 			if err != nil {
 				t.Error(err, "could not actual unmarshal json")
 			}
@@ -749,3 +750,190 @@ func TestGenerateCapacityConfigWithUserProvidedInput(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateCapacityConfigWithLiveCapacity(t *testing.T) {
+	storage := resource.MustParse("10Gi")
+	cpuLimit := resource.MustParse("2000m")
+	smallNodeCPU := resource.MustParse("1500m")
+	largeNodeCPU := resource.MustParse("8")
+
+	kafkaCluster := v1beta1.KafkaCluster{
+		Spec: v1beta1.KafkaClusterSpec{
+			Brokers: []v1beta1.Broker{
+				{
+					Id: 0,
+					BrokerConfig: &v1beta1.BrokerConfig{
+						StorageConfigs: []v1beta1.StorageConfig{
+							{
+								MountPath: "/kafka-logs",
+								PvcSpec: &v1.PersistentVolumeClaimSpec{
+									Resources: v1.ResourceRequirements{
+										Requests: v1.ResourceList{"storage": storage},
+									},
+								},
+							},
+						},
+						Resources: &v1.ResourceRequirements{
+							Limits: v1.ResourceList{"cpu": cpuLimit},
+						},
+					},
+				},
+				{
+					Id: 1,
+					BrokerConfig: &v1beta1.BrokerConfig{
+						Resources: &v1.ResourceRequirements{
+							Limits: v1.ResourceList{"cpu": cpuLimit},
+						},
+					},
+				},
+				{
+					Id: 2,
+					BrokerConfig: &v1beta1.BrokerConfig{
+						Resources: &v1.ResourceRequirements{
+							Limits: v1.ResourceList{"cpu": cpuLimit},
+						},
+					},
+				},
+			},
+		},
+	}
+	liveCapacities := map[int32]LiveBrokerCapacity{
+		0: {
+			Disks:      map[string]int64{"/kafka-logs": 20 * 1024 * 1024 * 1024},
+			CPU:        &smallNodeCPU,
+			NetworkIn:  "250000",
+			NetworkOut: "500000",
+		},
+		1: {
+			CPU: &largeNodeCPU,
+		},
+	}
+
+	var actual CapacityConfig
+	err := json.Unmarshal([]byte(GenerateCapacityConfig(&kafkaCluster, log.NullLogger{}, nil, liveCapacities)), &actual)
+	if err != nil {
+		t.Fatal(err, "could not unmarshal actual json")
+	}
+	// the capacities of the brokers are followed by the default broker capacity
+	if len(actual.BrokerCapacities) != 4 {
+		t.Fatalf("expected 4 broker capacities, got %d", len(actual.BrokerCapacities))
+	}
+
+	expected := []Capacity{
+		{
+			// bound volume capacity, node allocatable cpu below the limit and node network labels
+			DISK:  map[string]string{"/kafka-logs/kafka": "21474836480"},
+			CPU:   "150",
+			NWIN:  "250000",
+			NWOUT: "500000",
+		},
+		{
+			// node allocatable cpu above the limit
+			DISK:  actual.BrokerCapacities[1].Capacity.DISK,
+			CPU:   "200",
+			NWIN:  "125000",
+			NWOUT: "125000",
+		},
+	}
+	for i, capacity := range expected {
+		if !reflect.DeepEqual(actual.BrokerCapacities[i].Capacity, capacity) {
+			t.Errorf("broker %d: expected %v, got %v", i, capacity, actual.BrokerCapacities[i].Capacity)
+		}
+	}
+
+	// brokers without live capacity keep the capacity derived from the spec
+	specOnly, err := json.Marshal(actual.BrokerCapacities[2].Capacity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var withoutLive CapacityConfig
+	err = json.Unmarshal([]byte(GenerateCapacityConfig(&kafkaCluster, log.NullLogger{}, nil, nil)), &withoutLive)
+	if err != nil {
+		t.Fatal(err, "could not unmarshal json without live capacity")
+	}
+	expectedSpecOnly, _ := json.Marshal(withoutLive.BrokerCapacities[2].Capacity)
+	if string(specOnly) != string(expectedSpecOnly) {
+		t.Errorf("broker 2: expected %s, got %s", expectedSpecOnly, specOnly)
+	}
+}
+
+func TestGenerateStructuredConfig(t *testing.T) {
+	interval, threshold, window := int64(300), int64(900), int64(3600)
+	enabled, disabled := true, false
+	replicationFactor := int32(3)
+	config := v1beta1.CruiseControlConfig{
+		Goals: &v1beta1.CruiseControlGoalsConfig{
+			Goals:        []string{"RackAwareGoal", "com.linkedin.kafka.cruisecontrol.analyzer.goals.ReplicaCapacityGoal", "PreferredLeaderElectionGoal"},
+			DefaultGoals: []string{"RackAwareGoal", "ReplicaCapacityGoal"},
+			HardGoals:    []string{"RackAwareGoal"},
+		},
+		AnomalyDetection: &v1beta1.CruiseControlAnomalyDetectionConfig{
+			IntervalSeconds:                          &interval,
+			BrokerFailureSelfHealingThresholdSeconds: &threshold,
+			NotifierClass:                            "com.linkedin.kafka.cruisecontrol.detector.notifier.SelfHealingNotifier",
+			SelfHealing:                              &v1beta1.CruiseControlSelfHealingConfig{Enabled: &enabled, BrokerFailure: &disabled},
+		},
+		MetricSampler: &v1beta1.CruiseControlMetricSamplerConfig{
+			PartitionMetricsWindowSeconds:     &window,
+			SampleStoreTopicReplicationFactor: &replicationFactor,
+		},
+	}
+
+	conf := generateStructuredConfig(config, log.NullLogger{})
+	for key, expected := range map[string]string{
+		"goals": "com.linkedin.kafka.cruisecontrol.analyzer.goals.RackAwareGoal," +
+			"com.linkedin.kafka.cruisecontrol.analyzer.goals.ReplicaCapacityGoal," +
+			"com.linkedin.kafka.cruisecontrol.analyzer.goals.PreferredLeaderElectionGoal",
+		"default.goals":                            "com.linkedin.kafka.cruisecontrol.analyzer.goals.RackAwareGoal,com.linkedin.kafka.cruisecontrol.analyzer.goals.ReplicaCapacityGoal",
+		"hard.goals":                               "com.linkedin.kafka.cruisecontrol.analyzer.goals.RackAwareGoal",
+		"anomaly.detection.interval.ms":            "300000",
+		"broker.failure.self.healing.threshold.ms": "900000",
+		"anomaly.notifier.class":                   "com.linkedin.kafka.cruisecontrol.detector.notifier.SelfHealingNotifier",
+		"self.healing.enabled":                     "true",
+		"self.healing.broker.failure.enabled":      "false",
+		"partition.metrics.window.ms":              "3600000",
+		"sample.store.topic.replication.factor":    "3",
+	} {
+		if property, found := conf.Get(key); !found || property.Value() != expected {
+			t.Errorf("Expected %s=%s, got: %s", key, expected, property.Value())
+		}
+	}
+	for _, key := range []string{"anomaly.detection.goals", "self.healing.goals", "self.healing.goal.violation.enabled", "metric.sampling.interval.ms"} {
+		if _, found := conf.Get(key); found {
+			t.Error("Expected unset property to be left out, got:", key)
+		}
+	}
+
+	// the goals which can not be resolved are left out while the custom goals are kept by their class names
+	config.Goals.Goals = append(config.Goals.Goals, "UnknownGoal", "com.example.CustomGoal")
+	config.Goals.HardGoals = []string{"UnknownGoal"}
+	conf = generateStructuredConfig(config, log.NullLogger{})
+	if property, found := conf.Get("goals"); !found || !strings.HasSuffix(property.Value(), "PreferredLeaderElectionGoal,com.example.CustomGoal") {
+		t.Error("Expected the resolvable goals to be kept, got:", property.Value())
+	}
+	if _, found := conf.Get("hard.goals"); found {
+		t.Error("Expected the unknown hard goal to be left out")
+	}
+	if _, found := conf.Get("default.goals"); !found {
+		t.Error("Expected the other goal lists to be kept")
+	}
+}
+
+func TestConfigMapZookeeperConnect(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{
+		Spec: v1beta1.KafkaClusterSpec{ZKAddresses: []string{"zookeeper-client.zookeeper:2181"}},
+	}
+	r := New(nil, cluster)
+
+	configMap := r.configMap("", "", log.Log).(*v1.ConfigMap)
+	if !strings.Contains(configMap.Data["cruisecontrol.properties"], "zookeeper.connect=zookeeper-client.zookeeper:2181") {
+		t.Error("Expected zookeeper.connect to be set, got:", configMap.Data["cruisecontrol.properties"])
+	}
+
+	// the cluster has no ZooKeeper in KRaft mode
+	cluster.Spec.KRaftMode = true
+	configMap = r.configMap("", "", log.Log).(*v1.ConfigMap)
+	if strings.Contains(configMap.Data["cruisecontrol.properties"], "zookeeper.connect") {
+		t.Error("Expected zookeeper.connect not to be set in KRaft mode, got:", configMap.Data["cruisecontrol.properties"])
+	}
+}
@@ -17,6 +17,7 @@ package cruisecontrol
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	"emperror.dev/errors"
 	"github.com/go-logr/logr"
@@ -30,6 +31,7 @@ import (
 	"github.com/banzaicloud/kafka-operator/pkg/errorfactory"
 	"github.com/banzaicloud/kafka-operator/pkg/k8sutil"
 	"github.com/banzaicloud/kafka-operator/pkg/resources"
+	kafkautils "github.com/banzaicloud/kafka-operator/pkg/util/kafka"
 	pkicommon "github.com/banzaicloud/kafka-operator/pkg/util/pki"
 )
 
@@ -118,7 +120,7 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 					)
 				}
 			}
-			capacityConfig := GenerateCapacityConfig(r.KafkaCluster, log, config)
+			capacityConfig := GenerateCapacityConfig(r.KafkaCluster, log, config, r.liveBrokerCapacities(log))
 
 			o = r.configMap(clientPass, capacityConfig, log)
 			err = k8sutil.Reconcile(log, r.Client, o, r.KafkaCluster)
@@ -166,3 +168,74 @@ func isBrokerDeletionInProgress(brokerState map[string]v1beta1.BrokerState) bool
 	}
 	return false
 }
+
+// liveBrokerCapacities collects the capacity of the brokers from their bound volumes and from the nodes they run on,
+// brokers whose capacity can not be determined fall back to the capacity derived from the spec
+func (r *Reconciler) liveBrokerCapacities(log logr.Logger) map[int32]LiveBrokerCapacity {
+	capacities := make(map[int32]LiveBrokerCapacity)
+	labels := client.MatchingLabels(kafkautils.LabelsForKafka(r.KafkaCluster.Name))
+
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	if err := r.Client.List(context.TODO(), pvcList, client.InNamespace(r.KafkaCluster.Namespace), labels); err != nil {
+		log.Error(err, "could not list broker volumes, using the storage requests for the disk capacity")
+	}
+	for _, pvc := range pvcList.Items {
+		brokerId, err := strconv.ParseInt(pvc.Labels["brokerId"], 10, 32)
+		if err != nil || pvc.Status.Phase != corev1.ClaimBound {
+			continue
+		}
+		size, ok := pvc.Status.Capacity[corev1.ResourceStorage]
+		mountPath := pvc.Annotations["mountPath"]
+		if !ok || mountPath == "" {
+			continue
+		}
+		capacity := capacities[int32(brokerId)]
+		if capacity.Disks == nil {
+			capacity.Disks = make(map[string]int64)
+		}
+		capacity.Disks[mountPath] = size.Value()
+		capacities[int32(brokerId)] = capacity
+	}
+
+	podList := &corev1.PodList{}
+	if err := r.Client.List(context.TODO(), podList, client.InNamespace(r.KafkaCluster.Namespace), labels); err != nil {
+		log.Error(err, "could not list broker pods, using the resource limits for the cpu capacity")
+	}
+	networkLabels := r.KafkaCluster.Spec.CruiseControlConfig.NetworkCapacityLabels
+	for _, pod := range podList.Items {
+		brokerId, err := strconv.ParseInt(pod.Labels["brokerId"], 10, 32)
+		if err != nil || pod.Spec.NodeName == "" {
+			continue
+		}
+		node := &corev1.Node{}
+		if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: pod.Spec.NodeName}, node); err != nil {
+			log.Error(err, "could not get node of broker, using the spec for the cpu and network capacity",
+				"brokerId", brokerId, "node", pod.Spec.NodeName)
+			continue
+		}
+		capacity := capacities[int32(brokerId)]
+		if cpu, ok := node.Status.Allocatable[corev1.ResourceCPU]; ok {
+			capacity.CPU = &cpu
+		}
+		if networkLabels != nil {
+			capacity.NetworkIn = nodeNetworkCapacity(node, networkLabels.Incoming, log)
+			capacity.NetworkOut = nodeNetworkCapacity(node, networkLabels.Outgoing, log)
+		}
+		capacities[int32(brokerId)] = capacity
+	}
+
+	return capacities
+}
+
+// nodeNetworkCapacity returns the bandwidth set in the given label of the node or an empty string if it is not a valid number
+func nodeNetworkCapacity(node *corev1.Node, label string, log logr.Logger) string {
+	value, ok := node.Labels[label]
+	if label == "" || !ok {
+		return ""
+	}
+	if _, err := strconv.ParseUint(value, 10, 64); err != nil {
+		log.V(warnLevel).Info("ignoring invalid network capacity node label", "node", node.Name, "label", label, "value", value)
+		return ""
+	}
+	return value
+}
@@ -43,8 +43,18 @@ func (r *Reconciler) allBrokerService() runtime.Object {
 		Spec: corev1.ServiceSpec{
 			Type:            corev1.ServiceTypeClusterIP,
 			SessionAffinity: corev1.ServiceAffinityNone,
-			Selector:        kafkautils.LabelsForKafka(r.KafkaCluster.Name),
+			Selector:        r.allBrokerServiceSelector(),
 			Ports:           usedPorts,
 		},
 	}
 }
+
+// allBrokerServiceSelector excludes the controller only nodes in KRaft mode
+// since those do not serve client requests
+func (r *Reconciler) allBrokerServiceSelector() map[string]string {
+	selector := kafkautils.LabelsForKafka(r.KafkaCluster.Name)
+	if r.KafkaCluster.Spec.KRaftMode {
+		selector[kafkautils.BrokerNodeLabelKey] = "true"
+	}
+	return selector
+}
@@ -38,29 +38,48 @@ func (r *Reconciler) getConfigProperties(bConfig *v1beta1.BrokerConfig, id int32
 	serverPass, clientPass string, superUsers []string, log logr.Logger) *properties.Properties {
 	config := properties.NewProperties()
 
+	// In KRaft mode the process roles define which listeners the node has to open
+	var processRoles []v1beta1.ProcessRole
+	if r.KafkaCluster.Spec.KRaftMode {
+		processRoles = bConfig.GetProcessRoles()
+	}
+	isBrokerNode := !r.KafkaCluster.Spec.KRaftMode || bConfig.IsBrokerNode()
+
 	// Add listener configuration
-	listenerConf := generateListenerSpecificConfig(&r.KafkaCluster.Spec.ListenersConfig, log)
+	listenerConf := generateListenerSpecificConfig(&r.KafkaCluster.Spec.ListenersConfig, processRoles, log)
 	config.Merge(listenerConf)
 
 	// Add listener configuration
-	advertisedListenerConf := generateAdvertisedListenerConfig(id, r.KafkaCluster.Spec.ListenersConfig, extListenerStatuses, intListenerStatuses, controllerIntListenerStatuses)
-	if len(advertisedListenerConf) > 0 {
-		if err := config.Set("advertised.listeners", advertisedListenerConf); err != nil {
-			log.Error(err, "setting advertised.listeners in broker configuration resulted an error")
+	if isBrokerNode {
+		advertisedControllerIntListenerStatuses := controllerIntListenerStatuses
+		// the KRaft controller listener must not be advertised
+		if r.KafkaCluster.Spec.KRaftMode {
+			advertisedControllerIntListenerStatuses = nil
+		}
+		advertisedListenerConf := generateAdvertisedListenerConfig(id, r.KafkaCluster.Spec.ListenersConfig, extListenerStatuses, intListenerStatuses, advertisedControllerIntListenerStatuses)
+		if len(advertisedListenerConf) > 0 {
+			if err := config.Set("advertised.listeners", advertisedListenerConf); err != nil {
+				log.Error(err, "setting advertised.listeners in broker configuration resulted an error")
+			}
 		}
 	}
 
-	// Add control plane listener
-	cclConf := generateControlPlaneListener(r.KafkaCluster.Spec.ListenersConfig.InternalListeners)
-	if cclConf != "" {
-		if err := config.Set("control.plane.listener.name", cclConf); err != nil {
-			log.Error(err, "setting control.plane.listener.name parameter in broker configuration resulted an error")
+	if r.KafkaCluster.Spec.KRaftMode {
+		// Add KRaft configuration
+		config.Merge(r.generateKRaftConfig(id, processRoles, controllerIntListenerStatuses, log))
+	} else {
+		// Add control plane listener
+		cclConf := generateControlPlaneListener(r.KafkaCluster.Spec.ListenersConfig.InternalListeners)
+		if cclConf != "" {
+			if err := config.Set("control.plane.listener.name", cclConf); err != nil {
+				log.Error(err, "setting control.plane.listener.name parameter in broker configuration resulted an error")
+			}
 		}
-	}
 
-	// Add Zookeeper configuration
-	if err := config.Set("zookeeper.connect", zookeeperutils.PrepareConnectionAddress(r.KafkaCluster.Spec.ZKAddresses, r.KafkaCluster.Spec.GetZkPath())); err != nil {
-		log.Error(err, "setting zookeeper.connect parameter in broker configuration resulted an error")
+		// Add Zookeeper configuration
+		if err := config.Set("zookeeper.connect", zookeeperutils.PrepareConnectionAddress(r.KafkaCluster.Spec.ZKAddresses, r.KafkaCluster.Spec.GetZkPath())); err != nil {
+			log.Error(err, "setting zookeeper.connect parameter in broker configuration resulted an error")
+		}
 	}
 
 	// Add SSL configuration
@@ -98,23 +117,29 @@ func (r *Reconciler) getConfigProperties(bConfig *v1beta1.BrokerConfig, id int32
 		}
 	}
 
-	// Add Cruise Control Metrics Reporter configuration
-	if err := config.Set("metric.reporters", "com.linkedin.kafka.cruisecontrol.metricsreporter.CruiseControlMetricsReporter"); err != nil {
-		log.Error(err, "setting metric.reporters in broker configuration resulted an error")
-	}
-	bootstrapServers, err := kafkautils.GetBootstrapServersService(r.KafkaCluster)
-	if err != nil {
-		log.Error(err, "getting Kafka bootstrap servers for Cruise Control failed")
-	}
-	if err := config.Set("cruise.control.metrics.reporter.bootstrap.servers", bootstrapServers); err != nil {
-		log.Error(err, "setting cruise.control.metrics.reporter.bootstrap.servers in broker configuration resulted an error")
-	}
-	if err := config.Set("cruise.control.metrics.reporter.kubernetes.mode", true); err != nil {
-		log.Error(err, "setting cruise.control.metrics.reporter.kubernetes.mode in broker configuration resulted an error")
+	// Add Cruise Control Metrics Reporter configuration, controller only nodes do not host any partitions
+	if isBrokerNode {
+		if err := config.Set("metric.reporters", "com.linkedin.kafka.cruisecontrol.metricsreporter.CruiseControlMetricsReporter"); err != nil {
+			log.Error(err, "setting metric.reporters in broker configuration resulted an error")
+		}
+		bootstrapServers, err := kafkautils.GetBootstrapServersService(r.KafkaCluster)
+		if err != nil {
+			log.Error(err, "getting Kafka bootstrap servers for Cruise Control failed")
+		}
+		if err := config.Set("cruise.control.metrics.reporter.bootstrap.servers", bootstrapServers); err != nil {
+			log.Error(err, "setting cruise.control.metrics.reporter.bootstrap.servers in broker configuration resulted an error")
+		}
+		if err := config.Set("cruise.control.metrics.reporter.kubernetes.mode", true); err != nil {
+			log.Error(err, "setting cruise.control.metrics.reporter.kubernetes.mode in broker configuration resulted an error")
+		}
 	}
 
 	// Kafka Broker configuration
-	if err := config.Set("broker.id", id); err != nil {
+	if r.KafkaCluster.Spec.KRaftMode {
+		if err := config.Set("node.id", id); err != nil {
+			log.Error(err, "setting node.id in broker configuration resulted an error")
+		}
+	} else if err := config.Set("broker.id", id); err != nil {
 		log.Error(err, "setting broker.id in broker configuration resulted an error")
 	}
 
@@ -212,11 +237,25 @@ func generateControlPlaneListener(iListeners []v1beta1.InternalListenerConfig) s
 	return controlPlaneListener
 }
 
-func generateListenerSpecificConfig(l *v1beta1.ListenersConfig, log logr.Logger) *properties.Properties {
+// generateListenerSpecificConfig generates the listener related configuration, processRoles are only
+// set in KRaft mode where broker only nodes must not listen on the controller listener and controller
+// only nodes must listen only on that one
+func generateListenerSpecificConfig(l *v1beta1.ListenersConfig, processRoles []v1beta1.ProcessRole, log logr.Logger) *properties.Properties {
 	var interBrokerListenerName string
 	var securityProtocolMapConfig []string
 	var listenerConfig []string
 
+	kraftMode := len(processRoles) > 0
+	isBrokerNode, isControllerNode := !kraftMode, !kraftMode
+	for _, role := range processRoles {
+		switch role {
+		case v1beta1.ProcessRoleBroker:
+			isBrokerNode = true
+		case v1beta1.ProcessRoleController:
+			isControllerNode = true
+		}
+	}
+
 	for _, iListener := range l.InternalListeners {
 		if iListener.UsedForInnerBrokerCommunication {
 			if interBrokerListenerName == "" {
@@ -228,21 +267,28 @@ func generateListenerSpecificConfig(l *v1beta1.ListenersConfig, log logr.Logger)
 		UpperedListenerType := iListener.Type.ToUpperString()
 		UpperedListenerName := strings.ToUpper(iListener.Name)
 		securityProtocolMapConfig = append(securityProtocolMapConfig, fmt.Sprintf("%s:%s", UpperedListenerName, UpperedListenerType))
-		listenerConfig = append(listenerConfig, fmt.Sprintf("%s://:%d", UpperedListenerName, iListener.ContainerPort))
+		isKRaftControllerListener := kraftMode && iListener.UsedForControllerCommunication
+		if (isKRaftControllerListener && isControllerNode) || (!isKRaftControllerListener && isBrokerNode) {
+			listenerConfig = append(listenerConfig, fmt.Sprintf("%s://:%d", UpperedListenerName, iListener.ContainerPort))
+		}
 	}
 	for _, eListener := range l.ExternalListeners {
 		UpperedListenerType := eListener.Type.ToUpperString()
 		UpperedListenerName := strings.ToUpper(eListener.Name)
 		securityProtocolMapConfig = append(securityProtocolMapConfig, fmt.Sprintf("%s:%s", UpperedListenerName, UpperedListenerType))
-		listenerConfig = append(listenerConfig, fmt.Sprintf("%s://:%d", UpperedListenerName, eListener.ContainerPort))
+		if isBrokerNode {
+			listenerConfig = append(listenerConfig, fmt.Sprintf("%s://:%d", UpperedListenerName, eListener.ContainerPort))
+		}
 	}
 
 	config := properties.NewProperties()
 	if err := config.Set("listener.security.protocol.map", securityProtocolMapConfig); err != nil {
 		log.Error(err, "setting listener.security.protocol.map parameter in broker configuration resulted an error")
 	}
-	if err := config.Set("inter.broker.listener.name", interBrokerListenerName); err != nil {
-		log.Error(err, "setting inter.broker.listener.name parameter in broker configuration resulted an error")
+	if isBrokerNode {
+		if err := config.Set("inter.broker.listener.name", interBrokerListenerName); err != nil {
+			log.Error(err, "setting inter.broker.listener.name parameter in broker configuration resulted an error")
+		}
 	}
 	if err := config.Set("listeners", listenerConfig); err != nil {
 		log.Error(err, "setting listeners parameter in broker configuration resulted an error")
@@ -251,10 +297,74 @@ func generateListenerSpecificConfig(l *v1beta1.ListenersConfig, log logr.Logger)
 	return config
 }
 
+// generateKRaftConfig generates the process roles and the controller quorum related configuration
+// of a node. The quorum voters are the brokers with the controller role reachable on the internal
+// listener used for controller communication.
+func (r *Reconciler) generateKRaftConfig(id int32, processRoles []v1beta1.ProcessRole,
+	controllerIntListenerStatuses map[string]v1beta1.ListenerStatusList, log logr.Logger) *properties.Properties {
+	config := properties.NewProperties()
+
+	roles := make([]string, 0, len(processRoles))
+	for _, role := range processRoles {
+		roles = append(roles, string(role))
+	}
+	if err := config.Set("process.roles", roles); err != nil {
+		log.Error(err, "setting process.roles parameter in broker configuration resulted an error")
+	}
+
+	controllerListener := generateControlPlaneListener(r.KafkaCluster.Spec.ListenersConfig.InternalListeners)
+	if controllerListener == "" {
+		log.Error(errors.New("no internal listener is used for controller communication"),
+			"KRaft mode requires a controller listener", "brokerId", id)
+		return config
+	}
+	if err := config.Set("controller.listener.names", controllerListener); err != nil {
+		log.Error(err, "setting controller.listener.names parameter in broker configuration resulted an error")
+	}
+
+	voters, err := r.generateQuorumVoters(controllerIntListenerStatuses)
+	if err != nil {
+		log.Error(err, "generating controller.quorum.voters parameter failed", "brokerId", id)
+	}
+	if err := config.Set("controller.quorum.voters", voters); err != nil {
+		log.Error(err, "setting controller.quorum.voters parameter in broker configuration resulted an error")
+	}
+
+	return config
+}
+
+// generateQuorumVoters returns the controller quorum voters in the id@host:port format
+func (r *Reconciler) generateQuorumVoters(controllerIntListenerStatuses map[string]v1beta1.ListenerStatusList) ([]string, error) {
+	voters := make([]string, 0)
+	for _, broker := range r.KafkaCluster.Spec.Brokers {
+		brokerConfig, err := broker.GetBrokerConfig(r.KafkaCluster.Spec)
+		if err != nil {
+			return nil, err
+		}
+		if !brokerConfig.IsControllerNode() {
+			continue
+		}
+		for _, statuses := range controllerIntListenerStatuses {
+			for _, status := range statuses {
+				if status.Name == fmt.Sprintf("broker-%d", broker.Id) {
+					voters = append(voters, fmt.Sprintf("%d@%s", broker.Id, status.Address))
+					break
+				}
+			}
+		}
+	}
+	if len(voters) == 0 {
+		return nil, errors.New("there is no broker with controller process role")
+	}
+	return voters, nil
+}
+
 func (r Reconciler) generateBrokerConfig(id int32, brokerConfig *v1beta1.BrokerConfig, extListenerStatuses,
 	intListenerStatuses, controllerIntListenerStatuses map[string]v1beta1.ListenerStatusList,
 	serverPass, clientPass string, superUsers []string, log logr.Logger) string {
-	finalBrokerConfig := getBrokerReadOnlyConfig(id, r.KafkaCluster, log)
+	// The protocol is pinned during Kafka upgrades unless the read-only configuration sets it
+	finalBrokerConfig := pinnedProtocolConfig(r.KafkaCluster.Status.UpgradeStatus)
+	finalBrokerConfig.Merge(getBrokerReadOnlyConfig(id, r.KafkaCluster, log))
 
 	// Get operator generated configuration
 	opGenConf := r.getConfigProperties(brokerConfig, id, extListenerStatuses, intListenerStatuses, controllerIntListenerStatuses, serverPass, clientPass, superUsers, log)
@@ -300,3 +300,144 @@ zookeeper.connect=example.zk:2181/`,
 		})
 	}
 }
+
+func TestGenerateBrokerConfigKRaft(t *testing.T) {
+	tests := []struct {
+		testName       string
+		processRoles   []v1beta1.ProcessRole
+		expectedConfig string
+	}{
+		{
+			testName:     "combinedRoles",
+			processRoles: nil,
+			expectedConfig: `advertised.listeners=INTERNAL://kafka-0.kafka.svc.cluster.local:9092
+controller.listener.names=CONTROLLER
+controller.quorum.voters=0@kafka-0.kafka.svc.cluster.local:9093,1@kafka-1.kafka.svc.cluster.local:9093
+cruise.control.metrics.reporter.bootstrap.servers=kafka-all-broker.kafka.svc.cluster.local:9092
+cruise.control.metrics.reporter.kubernetes.mode=true
+inter.broker.listener.name=INTERNAL
+listener.security.protocol.map=INTERNAL:PLAINTEXT,CONTROLLER:PLAINTEXT
+listeners=INTERNAL://:9092,CONTROLLER://:9093
+metric.reporters=com.linkedin.kafka.cruisecontrol.metricsreporter.CruiseControlMetricsReporter
+node.id=0
+process.roles=broker,controller`,
+		},
+		{
+			testName:     "controllerOnly",
+			processRoles: []v1beta1.ProcessRole{v1beta1.ProcessRoleController},
+			expectedConfig: `controller.listener.names=CONTROLLER
+controller.quorum.voters=0@kafka-0.kafka.svc.cluster.local:9093,1@kafka-1.kafka.svc.cluster.local:9093
+listener.security.protocol.map=INTERNAL:PLAINTEXT,CONTROLLER:PLAINTEXT
+listeners=CONTROLLER://:9093
+node.id=0
+process.roles=controller`,
+		},
+		{
+			testName:     "brokerOnly",
+			processRoles: []v1beta1.ProcessRole{v1beta1.ProcessRoleBroker},
+			expectedConfig: `advertised.listeners=INTERNAL://kafka-0.kafka.svc.cluster.local:9092
+controller.listener.names=CONTROLLER
+controller.quorum.voters=1@kafka-1.kafka.svc.cluster.local:9093
+cruise.control.metrics.reporter.bootstrap.servers=kafka-all-broker.kafka.svc.cluster.local:9092
+cruise.control.metrics.reporter.kubernetes.mode=true
+inter.broker.listener.name=INTERNAL
+listener.security.protocol.map=INTERNAL:PLAINTEXT,CONTROLLER:PLAINTEXT
+listeners=INTERNAL://:9092
+metric.reporters=com.linkedin.kafka.cruisecontrol.metricsreporter.CruiseControlMetricsReporter
+node.id=0
+process.roles=broker`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.testName, func(t *testing.T) {
+			r := Reconciler{
+				Scheme: scheme.Scheme,
+				Reconciler: resources.Reconciler{
+					KafkaCluster: &v1beta1.KafkaCluster{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "kafka",
+							Namespace: "kafka",
+						},
+						Spec: v1beta1.KafkaClusterSpec{
+							KRaftMode: true,
+							ListenersConfig: v1beta1.ListenersConfig{
+								InternalListeners: []v1beta1.InternalListenerConfig{
+									{
+										CommonListenerSpec: v1beta1.CommonListenerSpec{
+											Type:          v1beta1.SecurityProtocolPlaintext,
+											Name:          "internal",
+											ContainerPort: 9092,
+										},
+										UsedForInnerBrokerCommunication: true,
+									},
+									{
+										CommonListenerSpec: v1beta1.CommonListenerSpec{
+											Type:          v1beta1.SecurityProtocolPlaintext,
+											Name:          "controller",
+											ContainerPort: 9093,
+										},
+										UsedForControllerCommunication: true,
+									},
+								},
+							},
+							Brokers: []v1beta1.Broker{
+								{
+									Id: 0,
+									BrokerConfig: &v1beta1.BrokerConfig{
+										ProcessRoles: test.processRoles,
+									},
+								},
+								{
+									Id: 1,
+									BrokerConfig: &v1beta1.BrokerConfig{
+										ProcessRoles: []v1beta1.ProcessRole{v1beta1.ProcessRoleController},
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+
+			intListenerStatus := map[string]v1beta1.ListenerStatusList{
+				"internal": {
+					{
+						Name:    "broker-0",
+						Address: "kafka-0.kafka.svc.cluster.local:9092",
+					},
+				},
+			}
+			controllerListenerStatus := map[string]v1beta1.ListenerStatusList{
+				"controller": {
+					{
+						Name:    "broker-0",
+						Address: "kafka-0.kafka.svc.cluster.local:9093",
+					},
+					{
+						Name:    "broker-1",
+						Address: "kafka-1.kafka.svc.cluster.local:9093",
+					},
+				},
+			}
+
+			generatedConfig := r.generateBrokerConfig(0, r.KafkaCluster.Spec.Brokers[0].BrokerConfig, map[string]v1beta1.ListenerStatusList{}, intListenerStatus, controllerListenerStatus, "", "", []string{}, logf.NullLogger{})
+
+			generated, err := properties.NewFromString(generatedConfig)
+			if err != nil {
+				t.Fatalf("failed parsing generated configuration as Properties: %s", generatedConfig)
+			}
+
+			expected, err := properties.NewFromString(test.expectedConfig)
+			if err != nil {
+				t.Fatalf("failed parsing expected configuration as Properties: %s", expected)
+			}
+
+			if !expected.Equal(generated) {
+				t.Errorf("the expected config is:\n%s\nreceived:\n%s\n", test.expectedConfig, generatedConfig)
+			}
+		})
+	}
+}
@@ -26,12 +26,44 @@ import (
 	"github.com/banzaicloud/kafka-operator/api/v1beta1"
 	"github.com/banzaicloud/kafka-operator/pkg/errorfactory"
 	"github.com/banzaicloud/kafka-operator/pkg/k8sutil"
+	"github.com/banzaicloud/kafka-operator/pkg/kafkaclient"
 	"github.com/banzaicloud/kafka-operator/pkg/util"
 	"github.com/banzaicloud/kafka-operator/pkg/util/kafka"
 
 	properties "github.com/banzaicloud/kafka-operator/properties/pkg"
 )
 
+// describeConfigUpdateModes returns the dynamic update modes of the broker configs described by the running brokers,
+// nothing is returned while none of the brokers has been created yet
+func (r *Reconciler) describeConfigUpdateModes() (kafka.ConfigUpdateModes, error) {
+	if len(r.KafkaCluster.Status.BrokersState) == 0 {
+		return nil, nil
+	}
+	kClient, close, err := r.kafkaClientProvider.NewFromCluster(r.Client, r.KafkaCluster)
+	if err != nil {
+		return nil, errorfactory.New(errorfactory.BrokersUnreachable{}, err, "could not connect to kafka brokers")
+	}
+	defer close()
+
+	return r.configUpdateModes(kClient)
+}
+
+// configUpdateModes returns the dynamic update modes of the broker configs described by the first running broker
+func (r *Reconciler) configUpdateModes(kClient kafkaclient.KafkaClient) (kafka.ConfigUpdateModes, error) {
+	err := errors.New("no running broker to describe")
+	for _, broker := range r.KafkaCluster.Spec.Brokers {
+		if _, ok := r.KafkaCluster.Status.BrokersState[strconv.Itoa(int(broker.Id))]; !ok {
+			continue
+		}
+		var entries []sarama.ConfigEntry
+		// controller only nodes can not be described
+		if entries, err = kClient.DescribeBrokerConfig(broker.Id); err == nil {
+			return kafka.NewConfigUpdateModes(entries), nil
+		}
+	}
+	return nil, errors.WrapIf(err, "could not describe the broker configs")
+}
+
 func (r *Reconciler) reconcilePerBrokerDynamicConfig(brokerId int32, brokerConfig *v1beta1.BrokerConfig, configMap *corev1.ConfigMap, log logr.Logger) error {
 	kClient, close, err := r.kafkaClientProvider.NewFromCluster(r.Client, r.KafkaCluster)
 	if err != nil {
@@ -49,13 +81,28 @@ func (r *Reconciler) reconcilePerBrokerDynamicConfig(brokerId int32, brokerConfi
 		return nil
 	}
 
-	// overwrite configs from configmap
-	configsFromConfigMap, err := properties.NewFromString(configMap.Data[kafka.ConfigPropertyName])
+	// the dynamic update modes of the configs are described by the broker itself
+	brokerConfigEntries, err := kClient.DescribeBrokerConfig(brokerId)
 	if err != nil {
-		return errors.WrapIf(err, "could not parse broker configuration from configmap")
+		return errors.WrapIfWithDetails(err, "could not describe broker config", "brokerId", brokerId)
 	}
-	for _, perBrokerConfig := range kafka.PerBrokerConfigs {
-		if configProperty, ok := configsFromConfigMap.Get(perBrokerConfig); ok {
+	configModes := kafka.NewConfigUpdateModes(brokerConfigEntries)
+
+	// overwrite configs from configmap
+	if configMap != nil {
+		configsFromConfigMap, err := properties.NewFromString(configMap.Data[kafka.ConfigPropertyName])
+		if err != nil {
+			return errors.WrapIf(err, "could not parse broker configuration from configmap")
+		}
+		clusterWideConfig, err := properties.NewFromString(r.KafkaCluster.Spec.ClusterWideConfig)
+		if err != nil {
+			return errors.WrapIf(err, "could not parse cluster-wide broker config")
+		}
+		clusterWideDynamicConfig, err := r.clusterWideDynamicConfig(configModes)
+		if err != nil {
+			return err
+		}
+		for _, configProperty := range brokerDynamicConfigs(configModes, configsFromConfigMap, fullPerBrokerConfig, clusterWideConfig, clusterWideDynamicConfig) {
 			fullPerBrokerConfig.Put(configProperty)
 		}
 	}
@@ -76,8 +123,10 @@ func (r *Reconciler) reconcilePerBrokerDynamicConfig(brokerId int32, brokerConfi
 			}
 		}
 
+		configChange := perBrokerConfigChange(brokerConfigEntries, fullPerBrokerConfig)
+
 		// validate the config
-		err := kClient.AlterPerBrokerConfig(brokerId, util.ConvertPropertiesToMapStringPointer(fullPerBrokerConfig), true)
+		err := kClient.AlterPerBrokerConfig(brokerId, configChange, true)
 		if err != nil {
 			statusErr := k8sutil.UpdateBrokerStatus(r.Client, []string{strconv.Itoa(int(brokerId))}, r.KafkaCluster, v1beta1.PerBrokerConfigError, log)
 			if statusErr != nil {
@@ -87,7 +136,7 @@ func (r *Reconciler) reconcilePerBrokerDynamicConfig(brokerId int32, brokerConfi
 		}
 
 		// alter the config
-		err = kClient.AlterPerBrokerConfig(brokerId, util.ConvertPropertiesToMapStringPointer(fullPerBrokerConfig), false)
+		err = kClient.AlterPerBrokerConfig(brokerId, configChange, false)
 		if err != nil {
 			return errors.WrapIfWithDetails(err, "could not alter broker config", "brokerId", brokerId)
 		}
@@ -135,9 +184,13 @@ func (r *Reconciler) reconcileClusterWideDynamicConfig() error {
 		return errors.WrapIf(err, "could not convert current cluster-wide config to properties")
 	}
 
-	parsedClusterWideConfig, err := properties.NewFromString(r.KafkaCluster.Spec.ClusterWideConfig)
+	configModes, err := r.configUpdateModes(kClient)
+	if err != nil {
+		return err
+	}
+	parsedClusterWideConfig, err := r.clusterWideDynamicConfig(configModes)
 	if err != nil {
-		return errors.WrapIf(err, "could not parse cluster-wide broker config")
+		return err
 	}
 
 	if !currentClusterWideConfig.Equal(parsedClusterWideConfig) {
@@ -155,6 +208,63 @@ func (r *Reconciler) reconcileClusterWideDynamicConfig() error {
 	return nil
 }
 
+// clusterWideDynamicConfig returns the cluster wide defaults of the brokers, the cluster wide configs of the
+// read only config are applied without restarting the brokers unless they are overridden by the cluster wide config
+func (r *Reconciler) clusterWideDynamicConfig(configModes kafka.ConfigUpdateModes) (*properties.Properties, error) {
+	readOnlyConfig, err := properties.NewFromString(r.KafkaCluster.Spec.ReadOnlyConfig)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not parse read-only broker config")
+	}
+	clusterWideConfig, err := properties.NewFromString(r.KafkaCluster.Spec.ClusterWideConfig)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not parse cluster-wide broker config")
+	}
+	dynamicConfig := configModes.DynamicConfigs(readOnlyConfig, v1beta1.ConfigUpdateModeClusterWide)
+	dynamicConfig.Merge(clusterWideConfig)
+	return dynamicConfig, nil
+}
+
+// brokerDynamicConfigs returns the dynamically updatable configs of the broker which have to be set for the broker,
+// the cluster wide configs are only set when the broker overrides the cluster wide defaults and they are not set
+// explicitly either in the per-broker or in the cluster wide config
+func brokerDynamicConfigs(configModes kafka.ConfigUpdateModes, brokerConfig, perBrokerConfig, clusterWideConfig, clusterWideDynamicConfig *properties.Properties) []properties.Property {
+	var dynamicConfigs []properties.Property
+	for _, key := range brokerConfig.Keys() {
+		configProperty, _ := brokerConfig.Get(key)
+		switch configModes.GetConfigUpdateMode(key) {
+		case v1beta1.ConfigUpdateModePerBroker:
+			dynamicConfigs = append(dynamicConfigs, configProperty)
+		case v1beta1.ConfigUpdateModeClusterWide:
+			if _, ok := perBrokerConfig.Get(key); ok {
+				continue
+			}
+			if _, ok := clusterWideConfig.Get(key); ok {
+				continue
+			}
+			if clusterWideProperty, ok := clusterWideDynamicConfig.Get(key); ok && clusterWideProperty.Value() == configProperty.Value() {
+				continue
+			}
+			dynamicConfigs = append(dynamicConfigs, configProperty)
+		}
+	}
+	return dynamicConfigs
+}
+
+// perBrokerConfigChange returns the config change setting the per-broker configs incrementally, the dynamic
+// overrides of the broker which are no longer desired are removed apart from the ones set by the reassignments
+func perBrokerConfigChange(brokerConfigEntries []sarama.ConfigEntry, perBrokerConfig *properties.Properties) map[string]*string {
+	configChange := util.ConvertPropertiesToMapStringPointer(perBrokerConfig)
+	for _, entry := range brokerConfigEntries {
+		if entry.Source != sarama.SourceDynamicBroker || kafka.IsReassignmentManagedConfig(entry.Name) {
+			continue
+		}
+		if _, ok := perBrokerConfig.Get(entry.Name); !ok {
+			configChange[entry.Name] = nil
+		}
+	}
+	return configChange
+}
+
 func shouldUpdatePerBrokerConfig(response []*sarama.ConfigEntry, brokerConfig *properties.Properties) bool {
 	if brokerConfig == nil {
 		return false
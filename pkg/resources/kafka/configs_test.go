@@ -15,10 +15,12 @@
 package kafka
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/Shopify/sarama"
 
+	"github.com/banzaicloud/kafka-operator/pkg/util/kafka"
 	properties "github.com/banzaicloud/kafka-operator/properties/pkg"
 )
 
@@ -95,3 +97,66 @@ config2=value3
 		}
 	}
 }
+
+func TestBrokerDynamicConfigs(t *testing.T) {
+	parse := func(config string) *properties.Properties {
+		p, err := properties.NewFromString(config)
+		if err != nil {
+			t.Fatalf("failed to parse Properties from string: %s", config)
+		}
+		return p
+	}
+	brokerConfig := parse(`broker.id=0
+ssl.client.auth=required
+log.retention.ms=1000
+num.io.threads=16
+message.max.bytes=2000000
+min.insync.replicas=2
+`)
+	perBrokerConfig := parse("min.insync.replicas=1")
+	clusterWideConfig := parse("message.max.bytes=1000000")
+	clusterWideDynamicConfig := parse(`log.retention.ms=1000
+message.max.bytes=1000000
+`)
+
+	configModes := kafka.NewConfigUpdateModes([]sarama.ConfigEntry{
+		{Name: "broker.id", ReadOnly: true},
+		{Name: "ssl.client.auth"},
+		{Name: "log.retention.ms"},
+		{Name: "num.io.threads"},
+		{Name: "message.max.bytes"},
+		{Name: "min.insync.replicas"},
+	})
+
+	var keys []string
+	for _, configProperty := range brokerDynamicConfigs(configModes, brokerConfig, perBrokerConfig, clusterWideConfig, clusterWideDynamicConfig) {
+		keys = append(keys, configProperty.Key())
+	}
+	// read-only configs, cluster wide configs matching the defaults and the explicitly set ones are not set for the broker
+	expected := []string{"ssl.client.auth", "num.io.threads"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected dynamic broker configs %v, got: %v", expected, keys)
+	}
+}
+
+func TestPerBrokerConfigChange(t *testing.T) {
+	perBrokerConfig, err := properties.NewFromString("ssl.client.auth=required")
+	if err != nil {
+		t.Fatal(err)
+	}
+	brokerConfigEntries := []sarama.ConfigEntry{
+		{Name: "ssl.client.auth", Value: "none", Source: sarama.SourceDynamicBroker},
+		{Name: "num.io.threads", Value: "16", Source: sarama.SourceDynamicBroker},
+		{Name: "log.retention.ms", Value: "1000", Source: sarama.SourceStaticBroker},
+		{Name: "leader.replication.throttled.rate", Value: "1000", Source: sarama.SourceDynamicBroker},
+	}
+
+	configChange := perBrokerConfigChange(brokerConfigEntries, perBrokerConfig)
+	// the dynamic overrides no longer desired are removed while the throttles of the reassignments are kept
+	if len(configChange) != 2 || configChange["ssl.client.auth"] == nil || *configChange["ssl.client.auth"] != "required" {
+		t.Error("Expected the per-broker config to be set, got:", configChange)
+	}
+	if value, ok := configChange["num.io.threads"]; !ok || value != nil {
+		t.Error("Expected the dynamic override no longer desired to be removed, got:", configChange)
+	}
+}
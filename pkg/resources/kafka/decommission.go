@@ -0,0 +1,107 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"fmt"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+	"github.com/banzaicloud/kafka-operator/pkg/errorfactory"
+	"github.com/banzaicloud/kafka-operator/pkg/k8sutil"
+	"github.com/banzaicloud/kafka-operator/pkg/kafkaclient"
+	"github.com/banzaicloud/kafka-operator/pkg/util"
+)
+
+// startBrokerDecommission marks the removed brokers as draining, their removal is blocked when the remaining
+// brokers could not keep the replication factor of every topic
+func (r *Reconciler) startBrokerDecommission(log logr.Logger, kClient kafkaclient.KafkaClient, removedBrokers []corev1.Pod) error {
+	var brokerIds []string
+	var brokerIdsInt []int32
+	for _, pod := range removedBrokers {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		if _, ok := r.KafkaCluster.Status.BrokersState[pod.Labels["brokerId"]]; !ok {
+			continue
+		}
+		brokerIds = append(brokerIds, pod.Labels["brokerId"])
+		brokerIdsInt = append(brokerIdsInt, util.ConvertStringToInt32(pod.Labels["brokerId"]))
+	}
+	if len(brokerIds) == 0 {
+		return nil
+	}
+
+	topics, err := kClient.TopicsBelowReplicationFactorWithout(brokerIdsInt)
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "could not check the replication factor of the topics", "brokerIds", brokerIds)
+	}
+	if len(topics) > 0 {
+		blocked := v1beta1.DecommissionStatus{
+			State:        v1beta1.DecommissionBlocked,
+			ErrorMessage: fmt.Sprintf("topics would fall below their replication factor: %s", strings.Join(topics, ",")),
+		}
+		if err := r.updateDecommissionStatus(log, brokerIds, blocked); err != nil {
+			return err
+		}
+		return errorfactory.New(errorfactory.BrokerDecommissionBlocked{}, errors.New("topics would fall below their replication factor"),
+			"broker removal blocked", "brokerIds", brokerIds, "topics", topics)
+	}
+
+	var brokersToDrain []string
+	for _, brokerId := range brokerIds {
+		if r.KafkaCluster.Status.BrokersState[brokerId].DecommissionStatus.State != v1beta1.DecommissionDraining {
+			brokersToDrain = append(brokersToDrain, brokerId)
+		}
+	}
+	return r.updateDecommissionStatus(log, brokersToDrain, v1beta1.DecommissionStatus{State: v1beta1.DecommissionDraining})
+}
+
+// isBrokerDrained returns true when the metadata of the topics shows no replica hosted by the broker
+func (r *Reconciler) isBrokerDrained(log logr.Logger, kClient kafkaclient.KafkaClient, brokerId string) (bool, error) {
+	replicaCount, err := kClient.BrokerReplicaCount(util.ConvertStringToInt32(brokerId))
+	if err != nil {
+		return false, errors.WrapIfWithDetails(err, "could not count the replicas of the broker", "brokerId", brokerId)
+	}
+	if replicaCount == 0 {
+		return true, nil
+	}
+	log.Info("removed broker still hosts replicas", "brokerId", brokerId, "replicaCount", replicaCount)
+	draining := v1beta1.DecommissionStatus{
+		State:        v1beta1.DecommissionDraining,
+		ReplicaCount: int32(replicaCount),
+		ErrorMessage: "broker still hosts replicas",
+	}
+	if r.KafkaCluster.Status.BrokersState[brokerId].DecommissionStatus != draining {
+		if err := r.updateDecommissionStatus(log, []string{brokerId}, draining); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+func (r *Reconciler) updateDecommissionStatus(log logr.Logger, brokerIds []string, status v1beta1.DecommissionStatus) error {
+	if len(brokerIds) == 0 {
+		return nil
+	}
+	if err := k8sutil.UpdateBrokerStatus(r.Client, brokerIds, r.KafkaCluster, status, log); err != nil {
+		return errorfactory.New(errorfactory.StatusUpdateError{}, err, "could not update decommission status", "brokerIds", brokerIds)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	//nolint:staticcheck
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+	"github.com/banzaicloud/kafka-operator/pkg/errorfactory"
+	"github.com/banzaicloud/kafka-operator/pkg/kafkaclient"
+	"github.com/banzaicloud/kafka-operator/pkg/scale"
+)
+
+// mockClientProvider returns the same mocked Kafka client on every connection so that its topics are kept
+type mockClientProvider struct {
+	client kafkaclient.KafkaClient
+}
+
+func (p *mockClientProvider) NewFromCluster(client.Client, *v1beta1.KafkaCluster) (kafkaclient.KafkaClient, func(), error) {
+	return p.client, func() {}, nil
+}
+
+func newMockKafkaClient(t *testing.T) kafkaclient.KafkaClient {
+	kClient, _, err := kafkaclient.NewMockFromCluster(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kClient
+}
+
+func createTopic(t *testing.T, kClient kafkaclient.KafkaClient, name string, replicationFactor int16) {
+	if err := kClient.CreateTopic(&kafkaclient.CreateTopicOptions{Name: name, Partitions: 1, ReplicationFactor: replicationFactor}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func removedBrokerPod(brokerId string) corev1.Pod {
+	return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "kafka-" + brokerId, Labels: map[string]string{"brokerId": brokerId}}}
+}
+
+func TestStartBrokerDecommission(t *testing.T) {
+	r := newGracefulRestartReconciler(t)
+	logger := log.Log
+	removed := []corev1.Pod{removedBrokerPod("0")}
+
+	// the mock cluster has a single broker with id 0 hosting every replica
+	kClient := newMockKafkaClient(t)
+	createTopic(t, kClient, "decommission-topic", 1)
+	err := r.startBrokerDecommission(logger, kClient, removed)
+	if _, ok := errors.Cause(err).(errorfactory.BrokerDecommissionBlocked); !ok {
+		t.Error("Expected the removal to be blocked, got:", err)
+	}
+	if state := r.KafkaCluster.Status.BrokersState["0"].DecommissionStatus; state.State != v1beta1.DecommissionBlocked ||
+		!strings.Contains(state.ErrorMessage, "decommission-topic") {
+		t.Error("Expected blocked decommission status with the topics, got:", state)
+	}
+
+	if err := kClient.DeleteTopic("decommission-topic", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.startBrokerDecommission(logger, kClient, removed); err != nil {
+		t.Error("Expected the broker to be drained, got:", err)
+	}
+	if state := r.KafkaCluster.Status.BrokersState["0"].DecommissionStatus; state.State != v1beta1.DecommissionDraining || state.ErrorMessage != "" {
+		t.Error("Expected draining decommission status, got:", state)
+	}
+}
+
+func TestIsBrokerDrained(t *testing.T) {
+	r := newGracefulRestartReconciler(t)
+	logger := log.Log
+
+	kClient := newMockKafkaClient(t)
+	createTopic(t, kClient, "decommission-topic", 1)
+	if drained, err := r.isBrokerDrained(logger, kClient, "0"); err != nil || drained {
+		t.Error("Expected broker hosting replicas not to be drained, got:", drained, err)
+	}
+	if state := r.KafkaCluster.Status.BrokersState["0"].DecommissionStatus; state.ReplicaCount != 1 {
+		t.Error("Expected the replicas still hosted by the broker to be reported, got:", state)
+	}
+
+	if err := kClient.DeleteTopic("decommission-topic", false); err != nil {
+		t.Fatal(err)
+	}
+	if drained, err := r.isBrokerDrained(logger, kClient, "0"); err != nil || !drained {
+		t.Error("Expected broker without replicas to be drained, got:", drained, err)
+	}
+}
+
+// newPodDeleteReconciler returns a reconciler of a cluster whose broker 0 has been removed and downscaled
+func newPodDeleteReconciler(t *testing.T, kClient kafkaclient.KafkaClient, policy v1beta1.PVCRetentionPolicy) *Reconciler {
+	scheme := runtime.NewScheme()
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			HeadlessServiceEnabled:          true,
+			RemovedBrokerPVCRetentionPolicy: policy,
+		},
+		Status: v1beta1.KafkaClusterStatus{
+			BrokersState: map[string]v1beta1.BrokerState{
+				"0": {GracefulActionState: v1beta1.GracefulActionState{CruiseControlState: v1beta1.GracefulDownscaleSucceeded}},
+			},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka-0", Namespace: "kafka", Labels: map[string]string{"app": "kafka", "kafka_cr": "kafka", "brokerId": "0"}},
+		Spec: corev1.PodSpec{Volumes: []corev1.Volume{{
+			Name:         kafkaDataVolumeMount + "-0",
+			VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "kafka-0-storage"}},
+		}}},
+	}
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "kafka-0-storage", Namespace: "kafka"}}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, pod, pvc).Build()
+	scale.MockNewCruiseControlScaler()
+	return New(k8sClient, k8sClient, scheme, cluster, &mockClientProvider{client: kClient})
+}
+
+func podDeleteObjectExists(t *testing.T, r *Reconciler, name string, obj client.Object) bool {
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "kafka"}, obj)
+	if err != nil && !apierrors.IsNotFound(err) {
+		t.Fatal(err)
+	}
+	return err == nil
+}
+
+func TestReconcileKafkaPodDeleteRetainsVolumes(t *testing.T) {
+	for _, policy := range []v1beta1.PVCRetentionPolicy{v1beta1.PVCRetentionPolicyRetain, v1beta1.PVCRetentionPolicyDelete} {
+		r := newPodDeleteReconciler(t, newMockKafkaClient(t), policy)
+		if err := r.reconcileKafkaPodDelete(log.Log); err != nil {
+			t.Fatalf("%s: %v", policy, err)
+		}
+		if podDeleteObjectExists(t, r, "kafka-0", &corev1.Pod{}) {
+			t.Errorf("%s: expected the pod of the removed broker to be deleted", policy)
+		}
+		if _, ok := r.KafkaCluster.Status.BrokersState["0"]; ok {
+			t.Errorf("%s: expected the state of the removed broker to be deleted", policy)
+		}
+		retained := podDeleteObjectExists(t, r, "kafka-0-storage", &corev1.PersistentVolumeClaim{})
+		if retained != (policy == v1beta1.PVCRetentionPolicyRetain) {
+			t.Errorf("%s: unexpected volume retention, retained: %v", policy, retained)
+		}
+	}
+}
+
+func TestReconcileKafkaPodDeleteBlocked(t *testing.T) {
+	kClient := newMockKafkaClient(t)
+	createTopic(t, kClient, "decommission-topic", 1)
+	r := newPodDeleteReconciler(t, kClient, v1beta1.PVCRetentionPolicyDelete)
+
+	err := r.reconcileKafkaPodDelete(log.Log)
+	if _, ok := errors.Cause(err).(errorfactory.BrokerDecommissionBlocked); !ok {
+		t.Fatal("Expected the removal to be blocked, got:", err)
+	}
+	if state := r.KafkaCluster.Status.BrokersState["0"].DecommissionStatus; state.State != v1beta1.DecommissionBlocked {
+		t.Error("Expected blocked decommission status, got:", state)
+	}
+	if !podDeleteObjectExists(t, r, "kafka-0", &corev1.Pod{}) || !podDeleteObjectExists(t, r, "kafka-0-storage", &corev1.PersistentVolumeClaim{}) {
+		t.Error("Expected the pod and the volume of the blocked broker to be kept")
+	}
+}
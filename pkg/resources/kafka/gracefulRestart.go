@@ -0,0 +1,171 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"strconv"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+	"github.com/banzaicloud/kafka-operator/pkg/errorfactory"
+	"github.com/banzaicloud/kafka-operator/pkg/k8sutil"
+	"github.com/banzaicloud/kafka-operator/pkg/kafkaclient"
+	"github.com/banzaicloud/kafka-operator/pkg/scale"
+	"github.com/banzaicloud/kafka-operator/pkg/util/kafka"
+)
+
+// gracefulRestartRequired returns true when the broker of the pod has to be demoted before it is restarted
+func (r *Reconciler) gracefulRestartRequired(pod *corev1.Pod) bool {
+	return r.KafkaCluster.Spec.RollingUpgradeConfig.GracefulRestart &&
+		r.KafkaCluster.Status.CruiseControlTopicStatus == v1beta1.CruiseControlTopicReady &&
+		// controller only nodes do not lead partitions
+		(!r.KafkaCluster.Spec.KRaftMode || pod.Labels[kafka.BrokerNodeLabelKey] == "true")
+}
+
+// reconcileGracefulRestart restores the leadership of a broker which has been restarted gracefully
+func (r *Reconciler) reconcileGracefulRestart(log logr.Logger, pod *corev1.Pod) error {
+	restartState := r.KafkaCluster.Status.BrokersState[pod.Labels["brokerId"]].GracefulRestartState
+	if restartState.State != v1beta1.GracefulRestartPodRestarting && restartState.State != v1beta1.GracefulRestartLeaderElectionRunning {
+		return nil
+	}
+
+	kClient, close, err := r.kafkaClientProvider.NewFromCluster(r.Client, r.KafkaCluster)
+	if err != nil {
+		return errorfactory.New(errorfactory.BrokersUnreachable{}, err, "could not connect to kafka brokers")
+	}
+	defer close()
+	cc, err := scale.NewCruiseControlScaler(r.Client, r.KafkaCluster)
+	if err != nil {
+		return errorfactory.New(errorfactory.CruiseControlNotReady{}, err, "could not create cruise control client")
+	}
+	return r.restoreBrokerLeadership(log, pod, kClient, cc)
+}
+
+// demoteBroker moves the leadership of the partitions away from the broker before it is restarted, an error
+// is returned until the broker does not lead partitions anymore
+func (r *Reconciler) demoteBroker(log logr.Logger, brokerId string, kClient kafkaclient.KafkaClient, cc scale.CruiseControlScaler) error {
+	restartState := r.KafkaCluster.Status.BrokersState[brokerId].GracefulRestartState
+	if restartState.State == v1beta1.GracefulRestartDemotionRunning {
+		id, err := strconv.Atoi(brokerId)
+		if err != nil {
+			return errors.WrapIfWithDetails(err, "could not parse broker id", "brokerId", brokerId)
+		}
+		leaderCount, err := kClient.BrokerLeaderCount(int32(id))
+		if err != nil {
+			return errors.WrapIf(err, "could not count the partitions led by the broker")
+		}
+		if leaderCount == 0 {
+			log.Info("broker has been demoted", "brokerId", brokerId)
+			return nil
+		}
+
+		taskState, err := cc.GetCCTaskState(restartState.CruiseControlTaskId)
+		if err != nil {
+			return errorfactory.New(errorfactory.CruiseControlNotReady{}, err, "could not get the state of the demotion task", "taskId", restartState.CruiseControlTaskId)
+		}
+		if taskState == v1beta1.CruiseControlTaskActive || taskState == v1beta1.CruiseControlTaskInExecution {
+			return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New("broker demotion is in progress"),
+				"rolling upgrade in progress", "brokerId", brokerId, "leaderCount", leaderCount)
+		}
+		// partitions without other in-sync replicas can not be moved away from the broker, these are unavailable
+		// during the restart anyway so the restart is not held back by them
+		log.Info("broker still leads partitions after its demotion", "brokerId", brokerId, "leaderCount", leaderCount, "taskState", taskState)
+		restartState.ErrorMessage = "broker could not be fully demoted, it leads " + strconv.Itoa(leaderCount) + " partitions"
+		return r.updateGracefulRestartState(log, brokerId, restartState)
+	}
+
+	taskId, _, err := cc.DemoteBrokers([]string{brokerId}, nil)
+	if err != nil {
+		return errorfactory.New(errorfactory.CruiseControlNotReady{}, err, "could not demote broker", "brokerId", brokerId)
+	}
+	log.Info("demoting broker before its restart", "brokerId", brokerId, "taskId", taskId)
+	err = r.updateGracefulRestartState(log, brokerId, v1beta1.GracefulRestartState{
+		State:               v1beta1.GracefulRestartDemotionRunning,
+		CruiseControlTaskId: taskId,
+		TaskStarted:         time.Now().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+	return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New("broker demotion is in progress"), "rolling upgrade in progress", "brokerId", brokerId)
+}
+
+// restoreBrokerLeadership runs a preferred leader election once the restarted broker has rejoined the ISR, an error
+// is returned until the leadership of the broker has been restored
+func (r *Reconciler) restoreBrokerLeadership(log logr.Logger, pod *corev1.Pod, kClient kafkaclient.KafkaClient, cc scale.CruiseControlScaler) error {
+	brokerId := pod.Labels["brokerId"]
+	restartState := r.KafkaCluster.Status.BrokersState[brokerId].GracefulRestartState
+
+	switch restartState.State {
+	case v1beta1.GracefulRestartPodRestarting:
+		if !k8sutil.IsPodReady(pod) {
+			return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New("restarted broker is not ready yet"), "rolling upgrade in progress", "brokerId", brokerId)
+		}
+		id, err := strconv.Atoi(brokerId)
+		if err != nil {
+			return errors.WrapIfWithDetails(err, "could not parse broker id", "brokerId", brokerId)
+		}
+		inSync, err := kClient.BrokerReplicasInSync(int32(id))
+		if err != nil {
+			return errors.WrapIf(err, "health check failed")
+		}
+		if !inSync {
+			return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New("restarted broker has not rejoined the ISR yet"), "rolling upgrade in progress", "brokerId", brokerId)
+		}
+
+		taskId, err := cc.RunPreferedLeaderElectionInCluster()
+		if err != nil {
+			return errorfactory.New(errorfactory.CruiseControlNotReady{}, err, "could not run preferred leader election", "brokerId", brokerId)
+		}
+		log.Info("restoring leadership of the restarted broker", "brokerId", brokerId, "taskId", taskId)
+		err = r.updateGracefulRestartState(log, brokerId, v1beta1.GracefulRestartState{
+			State:               v1beta1.GracefulRestartLeaderElectionRunning,
+			CruiseControlTaskId: taskId,
+			TaskStarted:         time.Now().Format(time.RFC3339),
+			ErrorMessage:        restartState.ErrorMessage,
+		})
+		if err != nil {
+			return err
+		}
+		return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New("preferred leader election is in progress"), "rolling upgrade in progress", "brokerId", brokerId)
+	case v1beta1.GracefulRestartLeaderElectionRunning:
+		taskState, err := cc.GetCCTaskState(restartState.CruiseControlTaskId)
+		if err != nil {
+			return errorfactory.New(errorfactory.CruiseControlNotReady{}, err, "could not get the state of the leader election task", "taskId", restartState.CruiseControlTaskId)
+		}
+		switch taskState {
+		case v1beta1.CruiseControlTaskActive, v1beta1.CruiseControlTaskInExecution:
+			return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New("preferred leader election is in progress"), "rolling upgrade in progress", "brokerId", brokerId)
+		case v1beta1.CruiseControlTaskCompletedWithError:
+			// kafka moves the leadership back to the preferred replicas eventually when auto.leader.rebalance.enable is set
+			restartState.ErrorMessage = "preferred leader election completed with error"
+		}
+		log.Info("broker has been restarted gracefully", "brokerId", brokerId)
+		restartState.State = v1beta1.GracefulRestartSucceeded
+		return r.updateGracefulRestartState(log, brokerId, restartState)
+	}
+	return nil
+}
+
+func (r *Reconciler) updateGracefulRestartState(log logr.Logger, brokerId string, state v1beta1.GracefulRestartState) error {
+	if err := k8sutil.UpdateBrokerStatus(r.Client, []string{brokerId}, r.KafkaCluster, state, log); err != nil {
+		return errorfactory.New(errorfactory.StatusUpdateError{}, err, "could not update broker graceful restart state", "brokerId", brokerId)
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	//nolint:staticcheck
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
+	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+	"github.com/banzaicloud/kafka-operator/pkg/errorfactory"
+	"github.com/banzaicloud/kafka-operator/pkg/kafkaclient"
+	"github.com/banzaicloud/kafka-operator/pkg/scale"
+)
+
+type fakeRestartKafkaClient struct {
+	kafkaclient.KafkaClient
+	leaderCount int
+	inSync      bool
+}
+
+func (c *fakeRestartKafkaClient) BrokerLeaderCount(int32) (int, error) {
+	return c.leaderCount, nil
+}
+
+func (c *fakeRestartKafkaClient) BrokerReplicasInSync(int32) (bool, error) {
+	return c.inSync, nil
+}
+
+type fakeRestartScaler struct {
+	scale.CruiseControlScaler
+	taskState v1beta1.CruiseControlUserTaskState
+	demoted   []string
+	elections int
+}
+
+func (cc *fakeRestartScaler) DemoteBrokers(brokerIDs []string, options map[string]string) (string, *v1alpha1.RebalanceProposalSummary, error) {
+	cc.demoted = append(cc.demoted, brokerIDs...)
+	return "demote-task", nil, nil
+}
+
+func (cc *fakeRestartScaler) RunPreferedLeaderElectionInCluster() (string, error) {
+	cc.elections++
+	return "election-task", nil
+}
+
+func (cc *fakeRestartScaler) GetCCTaskState(string) (v1beta1.CruiseControlUserTaskState, error) {
+	return cc.taskState, nil
+}
+
+func newGracefulRestartReconciler(t *testing.T) *Reconciler {
+	scheme := runtime.NewScheme()
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			RollingUpgradeConfig: v1beta1.RollingUpgradeConfig{GracefulRestart: true},
+		},
+		Status: v1beta1.KafkaClusterStatus{
+			CruiseControlTopicStatus: v1beta1.CruiseControlTopicReady,
+			BrokersState:             map[string]v1beta1.BrokerState{"0": {}},
+		},
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+	return New(client, client, scheme, cluster, kafkaclient.NewMockProvider())
+}
+
+func restartState(r *Reconciler) v1beta1.GracefulRestartState {
+	return r.KafkaCluster.Status.BrokersState["0"].GracefulRestartState
+}
+
+func expectRollingUpgradeInProgress(t *testing.T, step string, err error) {
+	t.Helper()
+	if _, ok := err.(errorfactory.ReconcileRollingUpgrade); !ok {
+		t.Errorf("%s: expected rolling upgrade in progress, got: %v", step, err)
+	}
+}
+
+func TestDemoteBroker(t *testing.T) {
+	r := newGracefulRestartReconciler(t)
+	kClient := &fakeRestartKafkaClient{leaderCount: 3}
+	cc := &fakeRestartScaler{taskState: v1beta1.CruiseControlTaskInExecution}
+	logger := log.Log
+
+	expectRollingUpgradeInProgress(t, "demotion started", r.demoteBroker(logger, "0", kClient, cc))
+	if len(cc.demoted) != 1 || cc.demoted[0] != "0" {
+		t.Error("Expected broker 0 to be demoted, got:", cc.demoted)
+	}
+	if state := restartState(r); state.State != v1beta1.GracefulRestartDemotionRunning || state.CruiseControlTaskId != "demote-task" {
+		t.Error("Expected demotion running state with the task id, got:", state)
+	}
+
+	expectRollingUpgradeInProgress(t, "demotion running", r.demoteBroker(logger, "0", kClient, cc))
+	if len(cc.demoted) != 1 {
+		t.Error("Expected the running demotion not to be repeated, got:", cc.demoted)
+	}
+
+	kClient.leaderCount = 0
+	if err := r.demoteBroker(logger, "0", kClient, cc); err != nil {
+		t.Error("Expected demoted broker to be restarted, got:", err)
+	}
+
+	// the broker is restarted once the demotion task finished even if it could not be fully demoted
+	kClient.leaderCount = 1
+	cc.taskState = v1beta1.CruiseControlTaskCompleted
+	if err := r.demoteBroker(logger, "0", kClient, cc); err != nil {
+		t.Error("Expected broker to be restarted after the demotion finished, got:", err)
+	}
+	if state := restartState(r); state.ErrorMessage == "" {
+		t.Error("Expected the partitions still led by the broker to be reported, got:", state)
+	}
+
+	// the cluster status is persisted
+	stored := &v1beta1.KafkaCluster{}
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: "kafka", Namespace: "kafka"}, stored); err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status.BrokersState["0"].GracefulRestartState.State != v1beta1.GracefulRestartDemotionRunning {
+		t.Error("Expected graceful restart state to be stored, got:", stored.Status.BrokersState["0"])
+	}
+}
+
+func TestRestoreBrokerLeadership(t *testing.T) {
+	r := newGracefulRestartReconciler(t)
+	kClient := &fakeRestartKafkaClient{}
+	cc := &fakeRestartScaler{taskState: v1beta1.CruiseControlTaskActive}
+	logger := log.Log
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "kafka-0", Labels: map[string]string{"brokerId": "0"}}}
+
+	if err := r.restoreBrokerLeadership(logger, pod, kClient, cc); err != nil {
+		t.Error("Expected nothing to be done for a broker which has not been restarted gracefully, got:", err)
+	}
+
+	if err := r.updateGracefulRestartState(logger, "0", v1beta1.GracefulRestartState{State: v1beta1.GracefulRestartPodRestarting}); err != nil {
+		t.Fatal(err)
+	}
+	expectRollingUpgradeInProgress(t, "pod not ready", r.restoreBrokerLeadership(logger, pod, kClient, cc))
+
+	pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	expectRollingUpgradeInProgress(t, "broker out of the ISR", r.restoreBrokerLeadership(logger, pod, kClient, cc))
+	if cc.elections != 0 {
+		t.Error("Expected no leader election before the broker rejoined the ISR")
+	}
+
+	kClient.inSync = true
+	expectRollingUpgradeInProgress(t, "leader election started", r.restoreBrokerLeadership(logger, pod, kClient, cc))
+	if state := restartState(r); cc.elections != 1 || state.State != v1beta1.GracefulRestartLeaderElectionRunning || state.CruiseControlTaskId != "election-task" {
+		t.Error("Expected leader election to be started, got:", state)
+	}
+
+	expectRollingUpgradeInProgress(t, "leader election running", r.restoreBrokerLeadership(logger, pod, kClient, cc))
+
+	cc.taskState = v1beta1.CruiseControlTaskCompleted
+	if err := r.restoreBrokerLeadership(logger, pod, kClient, cc); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if state := restartState(r); state.State != v1beta1.GracefulRestartSucceeded || state.ErrorMessage != "" {
+		t.Error("Expected graceful restart to succeed, got:", state)
+	}
+	if cc.elections != 1 {
+		t.Error("Expected a single leader election, got:", cc.elections)
+	}
+}
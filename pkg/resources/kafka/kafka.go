@@ -174,10 +174,19 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 		}
 	}
 
-	// Handle Pod delete
-	err := r.reconcileKafkaPodDelete(log)
-	if err != nil {
-		return errors.WrapIf(err, "failed to reconcile resource")
+	// The members of the KRaft controller quorum can not be changed on a running cluster
+	if r.KafkaCluster.Spec.KRaftMode {
+		if err := r.checkControllerQuorumMembership(); err != nil {
+			return err
+		}
+	}
+
+	// Handle Pod delete, a blocked decommission only holds back the removal of the brokers
+	decommissionErr := r.reconcileKafkaPodDelete(log)
+	if decommissionErr != nil {
+		if _, ok := errors.Cause(decommissionErr).(errorfactory.BrokerDecommissionBlocked); !ok {
+			return errors.WrapIf(decommissionErr, "failed to reconcile resource")
+		}
 	}
 
 	extListenerStatuses, err := r.createExternalListenerStatuses(log)
@@ -205,6 +214,20 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 		return err
 	}
 
+	if r.KafkaCluster.Spec.RackAwareness != nil {
+		if err := r.placeNewBrokersInRacks(log); err != nil {
+			return err
+		}
+	}
+
+	// a blocked downgrade only holds back the roll of the affected brokers
+	blockedBrokers, downgradeErr := r.reconcileKafkaUpgrade(log)
+	if downgradeErr != nil {
+		if _, ok := errors.Cause(downgradeErr).(errorfactory.KafkaVersionDowngrade); !ok {
+			return downgradeErr
+		}
+	}
+
 	brokersVolumes := make(map[string][]*corev1.PersistentVolumeClaim, len(r.KafkaCluster.Spec.Brokers))
 	for _, broker := range r.KafkaCluster.Spec.Brokers {
 		brokerConfig, err := broker.GetBrokerConfig(r.KafkaCluster.Spec)
@@ -228,6 +251,13 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 		}
 	}
 
+	configModes, err := r.describeConfigUpdateModes()
+	if err != nil {
+		log.Info("could not describe the dynamic update modes of the broker configs, config changes are applied by restarting the brokers", "error", err.Error())
+	}
+
+	var pendingRestarts []v1beta1.PendingBrokerRestart
+	var rollingUpgradeErr error
 	reorderedBrokers := r.reorderBrokers(log, r.KafkaCluster.Spec.Brokers)
 	for _, broker := range reorderedBrokers {
 		brokerConfig, err := broker.GetBrokerConfig(r.KafkaCluster.Spec)
@@ -238,14 +268,14 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 		var configMap *corev1.ConfigMap
 		if r.KafkaCluster.Spec.RackAwareness == nil {
 			configMap = r.configMap(broker.Id, brokerConfig, extListenerStatuses, intListenerStatuses, controllerIntListenerStatuses, serverPass, clientPass, superUsers, log)
-			err := k8sutil.Reconcile(log, r.Client, configMap, r.KafkaCluster)
+			err := k8sutil.ReconcileBrokerConfigMap(log, r.Client, configMap, r.KafkaCluster, configModes)
 			if err != nil {
 				return errors.WrapIfWithDetails(err, "failed to reconcile resource", "resource", configMap.GetObjectKind().GroupVersionKind())
 			}
 		} else if brokerState, ok := r.KafkaCluster.Status.BrokersState[strconv.Itoa(int(broker.Id))]; ok {
 			if brokerState.RackAwarenessState != "" {
 				configMap = r.configMap(broker.Id, brokerConfig, extListenerStatuses, intListenerStatuses, controllerIntListenerStatuses, serverPass, clientPass, superUsers, log)
-				err := k8sutil.Reconcile(log, r.Client, configMap, r.KafkaCluster)
+				err := k8sutil.ReconcileBrokerConfigMap(log, r.Client, configMap, r.KafkaCluster, configModes)
 				if err != nil {
 					return errors.WrapIfWithDetails(err, "failed to reconcile resource", "resource", configMap.GetObjectKind().GroupVersionKind())
 				}
@@ -265,22 +295,60 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 			}
 		}
 		o := r.pod(broker.Id, brokerConfig, pvcs, log)
+		pendingRestart, err := r.pendingRestart(o.(*corev1.Pod))
+		if err != nil {
+			return err
+		}
+		if pendingRestart != nil {
+			pendingRestarts = append(pendingRestarts, *pendingRestart)
+		}
+		// the rolling upgrade is held at a previous broker, the rest of the brokers are only checked for a pending restart
+		if rollingUpgradeErr != nil || blockedBrokers[broker.Id] {
+			continue
+		}
 		err = r.reconcileKafkaPod(log, o.(*corev1.Pod), brokerConfig)
 		if err != nil {
+			if _, ok := errors.Cause(err).(errorfactory.ReconcileRollingUpgrade); ok {
+				rollingUpgradeErr = err
+				continue
+			}
 			return err
 		}
 		if err = r.updateStatusWithDockerImageAndVersion(broker.Id, brokerConfig, log); err != nil {
 			return err
 		}
+		// controller only nodes are not accessible through the admin API
+		if r.KafkaCluster.Spec.KRaftMode && !brokerConfig.IsBrokerNode() {
+			continue
+		}
 		if err = r.reconcilePerBrokerDynamicConfig(broker.Id, brokerConfig, configMap, log); err != nil {
 			return err
 		}
 	}
 
+	if err = r.updatePendingRestarts(log, pendingRestarts, rollingUpgradeErr != nil); err != nil {
+		return err
+	}
+	if rollingUpgradeErr != nil {
+		return rollingUpgradeErr
+	}
+
 	if err = r.reconcileClusterWideDynamicConfig(); err != nil {
 		return err
 	}
 
+	if downgradeErr != nil {
+		return downgradeErr
+	}
+
+	if err = r.advanceKafkaUpgrade(log); err != nil {
+		return err
+	}
+
+	if decommissionErr != nil {
+		return decommissionErr
+	}
+
 	log.V(1).Info("Reconciled")
 
 	return nil
@@ -307,8 +375,22 @@ OUTERLOOP:
 	}
 
 	if len(deletedBrokers) > 0 {
+		var kClient kafkaclient.KafkaClient
 		if !arePodsAlreadyDeleted(deletedBrokers, log) {
-			cc := scale.NewCruiseControlScaler(r.KafkaCluster.Namespace, r.KafkaCluster.Spec.GetKubernetesClusterDomain(), r.KafkaCluster.Spec.CruiseControlConfig.CruiseControlEndpoint, r.KafkaCluster.Name)
+			var close func()
+			kClient, close, err = r.kafkaClientProvider.NewFromCluster(r.Client, r.KafkaCluster)
+			if err != nil {
+				return errorfactory.New(errorfactory.BrokersUnreachable{}, err, "could not connect to kafka brokers")
+			}
+			defer close()
+			if err := r.startBrokerDecommission(log, kClient, deletedBrokers); err != nil {
+				return err
+			}
+
+			cc, err := scale.NewCruiseControlScaler(r.Client, r.KafkaCluster)
+			if err != nil {
+				return errorfactory.New(errorfactory.CruiseControlNotReady{}, err, "could not create cruise control client")
+			}
 			liveBrokers, err := cc.GetLiveKafkaBrokersFromCruiseControl(generateBrokerIdsFromPodSlice(deletedBrokers))
 
 			if err != nil {
@@ -360,6 +442,15 @@ OUTERLOOP:
 				continue
 			}
 
+			// the broker is only removed once all of its replicas have been moved away
+			drained, err := r.isBrokerDrained(log, kClient, broker.Labels["brokerId"])
+			if err != nil {
+				return err
+			}
+			if !drained {
+				continue
+			}
+
 			err = r.Client.Delete(context.TODO(), &broker)
 			if err != nil {
 				return errors.WrapIfWithDetails(err, "could not delete broker", "id", broker.Labels["brokerId"])
@@ -389,8 +480,12 @@ OUTERLOOP:
 				}
 				log.V(1).Info("service for broker deleted", "service name", serviceName, "brokerId", broker.Labels["brokerId"])
 			}
+			retainVolumes := r.KafkaCluster.Spec.GetRemovedBrokerPVCRetentionPolicy() == v1beta1.PVCRetentionPolicyRetain
+			if retainVolumes {
+				log.Info("pvcs of the removed broker are retained", "brokerId", broker.Labels["brokerId"])
+			}
 			for _, volume := range broker.Spec.Volumes {
-				if strings.HasPrefix(volume.Name, kafkaDataVolumeMount) {
+				if !retainVolumes && strings.HasPrefix(volume.Name, kafkaDataVolumeMount) {
 					err = r.Client.Delete(context.TODO(), &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
 						Name:      volume.PersistentVolumeClaim.ClaimName,
 						Namespace: r.KafkaCluster.Namespace,
@@ -527,7 +622,9 @@ func (r *Reconciler) reconcileKafkaPod(log logr.Logger, desiredPod *corev1.Pod,
 		if val, ok := r.KafkaCluster.Status.BrokersState[desiredPod.Labels["brokerId"]]; ok && val.GracefulActionState.CruiseControlState != v1beta1.GracefulUpscaleSucceeded {
 			gracefulActionState := v1beta1.GracefulActionState{ErrorMessage: "CruiseControl not yet ready", CruiseControlState: v1beta1.GracefulUpscaleSucceeded}
 
-			if r.KafkaCluster.Status.CruiseControlTopicStatus == v1beta1.CruiseControlTopicReady {
+			// controller only nodes do not host partitions so there is nothing to rebalance
+			if r.KafkaCluster.Status.CruiseControlTopicStatus == v1beta1.CruiseControlTopicReady &&
+				(!r.KafkaCluster.Spec.KRaftMode || bConfig.IsBrokerNode()) {
 				gracefulActionState = v1beta1.GracefulActionState{ErrorMessage: "", CruiseControlState: v1beta1.GracefulUpscaleRequired}
 			}
 			statusErr = k8sutil.UpdateBrokerStatus(r.Client, []string{desiredPod.Labels["brokerId"]}, r.KafkaCluster, gracefulActionState, log)
@@ -585,19 +682,10 @@ func (r *Reconciler) updateStatusWithDockerImageAndVersion(brokerId int32, broke
 }
 
 func (r *Reconciler) handleRollingUpgrade(log logr.Logger, desiredPod, currentPod *corev1.Pod, desiredType reflect.Type) error {
-	//Since toleration does not support patchStrategy:"merge,retainKeys",
-	//we need to add all toleration from the current pod if the toleration is set in the CR
-	if len(desiredPod.Spec.Tolerations) > 0 {
-		desiredPod.Spec.Tolerations = append(desiredPod.Spec.Tolerations, currentPod.Spec.Tolerations...)
-		uniqueTolerations := make([]corev1.Toleration, 0, len(desiredPod.Spec.Tolerations))
-		keys := make(map[corev1.Toleration]bool)
-		for _, t := range desiredPod.Spec.Tolerations {
-			if _, value := keys[t]; !value {
-				keys[t] = true
-				uniqueTolerations = append(uniqueTolerations, t)
-			}
-		}
-		desiredPod.Spec.Tolerations = uniqueTolerations
+	mergeTolerations(desiredPod, currentPod)
+	brokerId := currentPod.Labels["brokerId"]
+	if err := r.reconcileGracefulRestart(log, currentPod); err != nil {
+		return err
 	}
 	// Check if the resource actually updated
 	patchResult, err := patch.DefaultPatchMaker.Calculate(currentPod, desiredPod)
@@ -606,9 +694,14 @@ func (r *Reconciler) handleRollingUpgrade(log logr.Logger, desiredPod, currentPo
 		log.Error(err, "could not match objects", "kind", desiredType)
 	case patchResult.IsEmpty():
 		if !k8sutil.IsPodContainsTerminatedContainer(currentPod) &&
-			r.KafkaCluster.Status.BrokersState[currentPod.Labels["brokerId"]].ConfigurationState == v1beta1.ConfigInSync &&
+			r.KafkaCluster.Status.BrokersState[brokerId].ConfigurationState == v1beta1.ConfigInSync &&
 			!k8sutil.IsPodContainsEvictedContainer(currentPod) {
 			log.V(1).Info("resource is in sync")
+			// the restart of a demoted broker is not required anymore, its leadership is restored as it would be after the restart
+			if restartState := r.KafkaCluster.Status.BrokersState[brokerId].GracefulRestartState; restartState.State == v1beta1.GracefulRestartDemotionRunning {
+				restartState.State = v1beta1.GracefulRestartPodRestarting
+				return r.updateGracefulRestartState(log, brokerId, restartState)
+			}
 			return nil
 		}
 	default:
@@ -623,11 +716,20 @@ func (r *Reconciler) handleRollingUpgrade(log logr.Logger, desiredPod, currentPo
 		return errors.WrapIf(err, "could not apply last state to annotation")
 	}
 
+	gracefulRestart := false
+	restartGated := false
+	var restartingBrokers []string
 	if !k8sutil.IsPodContainsTerminatedContainer(currentPod) {
+		if err := r.checkRollingUpgradeControl(log, brokerId); err != nil {
+			return err
+		}
 		if r.KafkaCluster.Status.State != v1beta1.KafkaClusterRollingUpgrading {
 			if err := k8sutil.UpdateCRStatus(r.Client, r.KafkaCluster, v1beta1.KafkaClusterRollingUpgrading, log); err != nil {
 				return errorfactory.New(errorfactory.StatusUpdateError{}, err, "setting state to rolling upgrade failed")
 			}
+			if err := r.startRollingUpgrade(log); err != nil {
+				return err
+			}
 		}
 
 		if r.KafkaCluster.Status.State == v1beta1.KafkaClusterRollingUpgrading {
@@ -638,13 +740,20 @@ func (r *Reconciler) handleRollingUpgrade(log logr.Logger, desiredPod, currentPo
 			if err != nil {
 				return errors.WrapIf(err, "failed to reconcile resource")
 			}
-			for _, pod := range podList.Items {
-				pod := pod
-				if k8sutil.IsMarkedForDeletion(pod.ObjectMeta) {
-					return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New("pod is still terminating"), "rolling upgrade in progress")
-				}
-				if k8sutil.IsPodContainsPendingContainer(&pod) {
-					return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New("pod is still creating"), "rolling upgrade in progress")
+			restartingBrokers, err = r.concurrentlyRestartingBrokers(currentPod, podList.Items)
+			if err != nil {
+				return err
+			}
+			restartGated = true
+
+			// Restart a controller quorum member only when all the other members are up to keep the majority
+			if r.KafkaCluster.Spec.KRaftMode && currentPod.Labels[kafka.ControllerNodeLabelKey] == "true" {
+				for _, pod := range podList.Items {
+					pod := pod
+					if pod.Labels[kafka.ControllerNodeLabelKey] == "true" && pod.Name != currentPod.Name && !k8sutil.IsPodReady(&pod) {
+						return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New("controller quorum member is not ready"),
+							"rolling upgrade in progress", "brokerId", pod.Labels["brokerId"])
+					}
 				}
 			}
 
@@ -655,21 +764,28 @@ func (r *Reconciler) handleRollingUpgrade(log logr.Logger, desiredPod, currentPo
 				return errorfactory.New(errorfactory.BrokersUnreachable{}, err, "could not connect to kafka brokers")
 			}
 			defer close()
-			offlineReplicaCount, err := kClient.OfflineReplicaCount()
-			if err != nil {
-				return errors.WrapIf(err, "health check failed")
-			}
-			replicasInSync, err := kClient.AllReplicaInSync()
+			safe, err := r.isRestartSafe(log, kClient, brokerId, restartingBrokers)
 			if err != nil {
 				return errors.WrapIf(err, "health check failed")
 			}
 
-			if offlineReplicaCount > 0 || !replicasInSync {
+			if !safe {
 				errorCount++
 			}
 			if errorCount >= r.KafkaCluster.Spec.RollingUpgradeConfig.FailureThreshold {
 				return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New("cluster is not healthy"), "rolling upgrade in progress")
 			}
+
+			if r.gracefulRestartRequired(currentPod) {
+				cc, err := scale.NewCruiseControlScaler(r.Client, r.KafkaCluster)
+				if err != nil {
+					return errorfactory.New(errorfactory.CruiseControlNotReady{}, err, "could not create cruise control client")
+				}
+				if err := r.demoteBroker(log, brokerId, kClient, cc); err != nil {
+					return err
+				}
+				gracefulRestart = true
+			}
 		}
 	}
 
@@ -678,6 +794,21 @@ func (r *Reconciler) handleRollingUpgrade(log logr.Logger, desiredPod, currentPo
 		return errorfactory.New(errorfactory.APIFailure{}, err, "deleting resource failed", "kind", desiredType)
 	}
 
+	if gracefulRestart {
+		restartState := r.KafkaCluster.Status.BrokersState[brokerId].GracefulRestartState
+		restartState.State = v1beta1.GracefulRestartPodRestarting
+		if err := r.updateGracefulRestartState(log, brokerId, restartState); err != nil {
+			return err
+		}
+	}
+
+	if restartGated {
+		// the next broker is restarted once the replicas of this one and of the ones restarting alongside it are back in sync
+		if err := r.recordBrokerRestart(log, brokerId, append(restartingBrokers, brokerId)); err != nil {
+			return err
+		}
+	}
+
 	// Print terminated container's statuses
 	if k8sutil.IsPodContainsTerminatedContainer(currentPod) {
 		for _, containerState := range currentPod.Status.ContainerStatuses {
@@ -984,7 +1115,54 @@ func getServiceFromExternalListener(client client.Client, cluster *v1beta1.Kafka
 	return foundLBService, nil
 }
 
+// placeNewBrokersInRacks pins the brokers added to the cluster to the racks having the fewest brokers so that
+// the brokers stay balanced across racks. The volumes of a broker follow it into the rack when their storage class
+// binds volumes only once the pod is scheduled (WaitForFirstConsumer).
+// Brokers with a node affinity are left to the scheduler.
+func (r *Reconciler) placeNewBrokersInRacks(log logr.Logger) error {
+	brokerRacks := k8sutil.BrokerRacks(r.KafkaCluster)
+	var newBrokerIDs []int32
+	for _, broker := range r.KafkaCluster.Spec.Brokers {
+		if _, ok := r.KafkaCluster.Status.BrokersState[strconv.Itoa(int(broker.Id))]; ok {
+			continue
+		}
+		if _, ok := brokerRacks[broker.Id]; ok {
+			continue
+		}
+		brokerConfig, err := broker.GetBrokerConfig(r.KafkaCluster.Spec)
+		if err != nil {
+			return errors.WrapIf(err, "failed to determine broker config")
+		}
+		if brokerConfig.Affinity != nil && brokerConfig.Affinity.NodeAffinity != nil {
+			continue
+		}
+		newBrokerIDs = append(newBrokerIDs, broker.Id)
+	}
+	if len(newBrokerIDs) == 0 {
+		return nil
+	}
+
+	racks, err := k8sutil.AvailableRacks(r.DirectClient, r.KafkaCluster.Spec.RackAwareness.Labels)
+	if err != nil {
+		return err
+	}
+	placed := k8sutil.PlaceBrokersInRacks(r.KafkaCluster, racks, newBrokerIDs)
+	if len(placed) == 0 {
+		log.Info("no schedulable node carries every rack awareness label, leaving the placement of new brokers to the scheduler")
+		return nil
+	}
+	if err := k8sutil.UpdateCr(r.KafkaCluster, r.Client); err != nil {
+		return errorfactory.New(errorfactory.APIFailure{}, err, "pinning new brokers to racks failed")
+	}
+	log.Info("new brokers pinned to racks", "brokerIds", placed)
+	return nil
+}
+
 func (r *Reconciler) reorderBrokers(log logr.Logger, brokers []v1beta1.Broker) []v1beta1.Broker {
+	if r.KafkaCluster.Spec.KRaftMode {
+		return r.reorderKRaftNodes(log, brokers)
+	}
+
 	kClient, close, err := r.kafkaClientProvider.NewFromCluster(r.Client, r.KafkaCluster)
 	if err != nil {
 		log.Info("could not create Kafka client, thus could not determine controller")
@@ -1013,6 +1191,83 @@ func (r *Reconciler) reorderBrokers(log logr.Logger, brokers []v1beta1.Broker) [
 	return reorderedBrokers
 }
 
+// reorderKRaftNodes moves the controller quorum members to the end of the list, so they are
+// handled after the plain brokers in KRaft mode where the active controller can not be determined
+// through the admin API
+func (r *Reconciler) reorderKRaftNodes(log logr.Logger, brokers []v1beta1.Broker) []v1beta1.Broker {
+	reorderedBrokers := make([]v1beta1.Broker, 0, len(brokers))
+	controllerNodes := make([]v1beta1.Broker, 0)
+	for i := range brokers {
+		brokerConfig, err := brokers[i].GetBrokerConfig(r.KafkaCluster.Spec)
+		if err != nil {
+			log.Info("could not determine process roles of broker", "brokerId", brokers[i].Id)
+			return brokers
+		}
+		if brokerConfig.IsControllerNode() {
+			controllerNodes = append(controllerNodes, brokers[i])
+		} else {
+			reorderedBrokers = append(reorderedBrokers, brokers[i])
+		}
+	}
+	return append(reorderedBrokers, controllerNodes...)
+}
+
+// checkControllerQuorumMembership makes sure that the members of an already formed KRaft controller
+// quorum are not changed, since the static controller.quorum.voters configuration can not be altered
+// on a running cluster. Removing a member, changing its process roles or adding a new member is refused.
+func (r *Reconciler) checkControllerQuorumMembership() error {
+	podList := &corev1.PodList{}
+	matchingLabels := client.MatchingLabels(kafka.LabelsForKafka(r.KafkaCluster.Name))
+	err := r.Client.List(context.TODO(), podList, client.ListOption(client.InNamespace(r.KafkaCluster.Namespace)), client.ListOption(matchingLabels))
+	if err != nil {
+		return errorfactory.New(errorfactory.APIFailure{}, err, "listing broker pods failed")
+	}
+	return checkControllerQuorumMembership(podList.Items, r.KafkaCluster)
+}
+
+func checkControllerQuorumMembership(pods []corev1.Pod, cluster *v1beta1.KafkaCluster) error {
+	desiredControllers := make(map[string]bool, len(cluster.Spec.Brokers))
+	for _, broker := range cluster.Spec.Brokers {
+		brokerConfig, err := broker.GetBrokerConfig(cluster.Spec)
+		if err != nil {
+			return errors.WrapIf(err, "failed to reconcile resource")
+		}
+		desiredControllers[strconv.Itoa(int(broker.Id))] = brokerConfig.IsControllerNode()
+	}
+
+	quorumFormed := false
+	existingBrokers := make(map[string]bool, len(pods))
+	for _, pod := range pods {
+		pod := pod
+		brokerId := pod.Labels["brokerId"]
+		existingBrokers[brokerId] = true
+		isController := pod.Labels[kafka.ControllerNodeLabelKey] == "true"
+		if isController && k8sutil.IsPodReady(&pod) {
+			quorumFormed = true
+		}
+		desiredController, desired := desiredControllers[brokerId]
+		switch {
+		case isController && !desiredController:
+			return errorfactory.New(errorfactory.FatalReconcileError{}, errors.New("controller quorum members can not be changed"),
+				"broker can not be removed from the controller quorum", "brokerId", brokerId)
+		case !isController && desired && desiredController:
+			return errorfactory.New(errorfactory.FatalReconcileError{}, errors.New("controller quorum members can not be changed"),
+				"broker can not join the controller quorum", "brokerId", brokerId)
+		}
+	}
+
+	if !quorumFormed {
+		return nil
+	}
+	for brokerId, desiredController := range desiredControllers {
+		if _, ok := cluster.Status.BrokersState[brokerId]; desiredController && !existingBrokers[brokerId] && !ok {
+			return errorfactory.New(errorfactory.FatalReconcileError{}, errors.New("controller quorum members can not be changed"),
+				"new broker can not join the formed controller quorum", "brokerId", brokerId)
+		}
+	}
+	return nil
+}
+
 func generateServicePortForIListeners(listeners []v1beta1.InternalListenerConfig) []corev1.ServicePort {
 	var usedPorts []corev1.ServicePort
 	for _, iListener := range listeners {
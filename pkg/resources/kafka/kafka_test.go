@@ -18,7 +18,11 @@ import (
 	"reflect"
 	"testing"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+	"github.com/banzaicloud/kafka-operator/pkg/util/kafka"
 )
 
 func TestGetBrokersWithPendingOrRunningCCTask(t *testing.T) {
@@ -290,3 +294,99 @@ func TestGetBrokersWithPendingOrRunningCCTask(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckControllerQuorumMembership(t *testing.T) {
+	controllerPod := func(brokerId string, ready bool) corev1.Pod {
+		status := corev1.ConditionFalse
+		if ready {
+			status = corev1.ConditionTrue
+		}
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "kafka-" + brokerId,
+				Labels: map[string]string{"brokerId": brokerId, kafka.ControllerNodeLabelKey: "true"},
+			},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+			},
+		}
+	}
+	brokerPod := func(brokerId string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "kafka-" + brokerId,
+				Labels: map[string]string{"brokerId": brokerId, kafka.BrokerNodeLabelKey: "true"},
+			},
+		}
+	}
+	controller := &v1beta1.BrokerConfig{ProcessRoles: []v1beta1.ProcessRole{v1beta1.ProcessRoleController}}
+	broker := &v1beta1.BrokerConfig{ProcessRoles: []v1beta1.ProcessRole{v1beta1.ProcessRoleBroker}}
+
+	testCases := []struct {
+		testName    string
+		brokers     []v1beta1.Broker
+		pods        []corev1.Pod
+		expectError bool
+	}{
+		{
+			testName: "new cluster",
+			brokers: []v1beta1.Broker{
+				{Id: 0, BrokerConfig: controller},
+				{Id: 1, BrokerConfig: controller},
+				{Id: 2, BrokerConfig: broker},
+			},
+			pods:        []corev1.Pod{controllerPod("0", false)},
+			expectError: false,
+		},
+		{
+			testName: "broker only node added",
+			brokers: []v1beta1.Broker{
+				{Id: 0, BrokerConfig: controller},
+				{Id: 1, BrokerConfig: broker},
+				{Id: 2, BrokerConfig: broker},
+			},
+			pods:        []corev1.Pod{controllerPod("0", true), brokerPod("1")},
+			expectError: false,
+		},
+		{
+			testName: "controller removed",
+			brokers: []v1beta1.Broker{
+				{Id: 0, BrokerConfig: controller},
+				{Id: 2, BrokerConfig: broker},
+			},
+			pods:        []corev1.Pod{controllerPod("0", true), controllerPod("1", true), brokerPod("2")},
+			expectError: true,
+		},
+		{
+			testName: "broker promoted to controller",
+			brokers: []v1beta1.Broker{
+				{Id: 0, BrokerConfig: controller},
+				{Id: 2, BrokerConfig: controller},
+			},
+			pods:        []corev1.Pod{controllerPod("0", true), brokerPod("2")},
+			expectError: true,
+		},
+		{
+			testName: "controller added to formed quorum",
+			brokers: []v1beta1.Broker{
+				{Id: 0, BrokerConfig: controller},
+				{Id: 1, BrokerConfig: controller},
+			},
+			pods:        []corev1.Pod{controllerPod("0", true)},
+			expectError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		cluster := &v1beta1.KafkaCluster{
+			Spec: v1beta1.KafkaClusterSpec{
+				KRaftMode: true,
+				Brokers:   test.brokers,
+			},
+		}
+		err := checkControllerQuorumMembership(test.pods, cluster)
+		if test.expectError != (err != nil) {
+			t.Errorf("%s: expected error: %v, got: %v", test.testName, test.expectError, err)
+		}
+	}
+}
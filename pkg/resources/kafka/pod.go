@@ -93,7 +93,7 @@ if [[ -n "$ENVOY_SIDECAR_STATUS" ]]; then
   done
 fi
 touch /var/run/wait/do-not-exit-yet
-/opt/kafka/bin/kafka-server-start.sh /config/broker-config
+` + generateStorageFormatCommand(r.KafkaCluster) + `/opt/kafka/bin/kafka-server-start.sh /config/broker-config
 rm /var/run/wait/do-not-exit-yet`}
 
 	pod := &corev1.Pod{
@@ -102,6 +102,7 @@ rm /var/run/wait/do-not-exit-yet`}
 			util.MergeLabels(
 				kafkautils.LabelsForKafka(r.KafkaCluster.Name),
 				map[string]string{"brokerId": fmt.Sprintf("%d", id)},
+				kafkautils.LabelsForBrokerRoles(r.KafkaCluster, brokerConfig),
 			),
 			brokerConfig.GetBrokerAnnotations(),
 			r.KafkaCluster,
@@ -112,7 +113,7 @@ rm /var/run/wait/do-not-exit-yet`}
 			Affinity:        getAffinity(brokerConfig, r.KafkaCluster),
 			Containers: []corev1.Container{
 				{
-					Name:  "kafka",
+					Name:  kafkaContainerName,
 					Image: util.GetBrokerImage(brokerConfig, r.KafkaCluster.Spec.GetClusterImage()),
 					Lifecycle: &corev1.Lifecycle{
 						PreStop: &corev1.Handler{
@@ -181,6 +182,16 @@ fi`},
 	return pod
 }
 
+// generateStorageFormatCommand returns the command formatting the log directories with the cluster id
+// before the first start of a node in KRaft mode, already formatted directories are left untouched
+func generateStorageFormatCommand(cluster *v1beta1.KafkaCluster) string {
+	if !cluster.Spec.KRaftMode {
+		return ""
+	}
+	return fmt.Sprintf("/opt/kafka/bin/kafka-storage.sh format --cluster-id %s --config /config/broker-config --ignore-formatted\n",
+		kafkautils.GetClusterID(cluster))
+}
+
 func getInitContainers(brokerConfigInitContainers []corev1.Container, kafkaClusterSpec v1beta1.KafkaClusterSpec) []corev1.Container {
 	initContainers := make([]corev1.Container, 0, len(brokerConfigInitContainers))
 	initContainers = append(initContainers, brokerConfigInitContainers...)
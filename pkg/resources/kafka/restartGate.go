@@ -0,0 +1,103 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/kafka-operator/pkg/errorfactory"
+	"github.com/banzaicloud/kafka-operator/pkg/k8sutil"
+	"github.com/banzaicloud/kafka-operator/pkg/kafkaclient"
+	"github.com/banzaicloud/kafka-operator/pkg/util"
+)
+
+// concurrentlyRestartingBrokers returns the brokers whose pods are being restarted, it returns an error when the
+// broker of the given pod can not be restarted alongside them. Brokers are restarted one by one unless concurrent
+// rack restarts are enabled, in which case the brokers of the same rack can be restarted together.
+func (r *Reconciler) concurrentlyRestartingBrokers(currentPod *corev1.Pod, pods []corev1.Pod) ([]string, error) {
+	var restarting []string
+	for _, pod := range pods {
+		pod := pod
+		var reason string
+		switch {
+		case k8sutil.IsMarkedForDeletion(pod.ObjectMeta):
+			reason = "pod is still terminating"
+		case k8sutil.IsPodContainsPendingContainer(&pod):
+			reason = "pod is still creating"
+		default:
+			continue
+		}
+		if pod.Name == currentPod.Name || !r.sameRack(currentPod.Labels["brokerId"], pod.Labels["brokerId"]) {
+			return nil, errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New(reason), "rolling upgrade in progress")
+		}
+		restarting = append(restarting, pod.Labels["brokerId"])
+	}
+	return restarting, nil
+}
+
+// sameRack returns whether the given brokers can be restarted at the same time as they are in the same rack
+func (r *Reconciler) sameRack(brokerId, otherBrokerId string) bool {
+	if !r.KafkaCluster.Spec.RollingUpgradeConfig.ConcurrentRackRestart || r.KafkaCluster.Spec.RackAwareness == nil {
+		return false
+	}
+	racks := k8sutil.BrokerRacks(r.KafkaCluster)
+	rack, ok := racks[util.ConvertStringToInt32(brokerId)]
+	if !ok {
+		return false
+	}
+	otherRack, ok := racks[util.ConvertStringToInt32(otherBrokerId)]
+	return ok && rack == otherRack
+}
+
+// isRestartSafe returns whether the given broker can be restarted alongside the brokers being restarted. The replicas
+// of the brokers restarted last have to be back in sync and none of the partitions hosted by the restarting brokers
+// may fall below its min.insync.replicas, partitions of other brokers are not considered.
+func (r *Reconciler) isRestartSafe(log logr.Logger, kClient kafkaclient.KafkaClient, brokerId string, restartingBrokers []string) (bool, error) {
+	for _, restarted := range r.KafkaCluster.Status.RollingUpgrade.RestartedBrokers {
+		if restarted == brokerId || util.StringSliceContains(restartingBrokers, restarted) {
+			continue
+		}
+		// brokers removed from the cluster do not host replicas anymore
+		if _, ok := r.KafkaCluster.Status.BrokersState[restarted]; !ok {
+			continue
+		}
+		inSync, err := kClient.BrokerReplicasInSync(util.ConvertStringToInt32(restarted))
+		if err != nil {
+			return false, err
+		}
+		if !inSync {
+			log.Info("replicas of the broker restarted last are not in sync yet", "brokerId", restarted)
+			return false, nil
+		}
+	}
+
+	stopped := make([]int32, 0, len(restartingBrokers)+1)
+	stopped = append(stopped, util.ConvertStringToInt32(brokerId))
+	for _, id := range restartingBrokers {
+		stopped = append(stopped, util.ConvertStringToInt32(id))
+	}
+	unavailable, err := kClient.PartitionsBelowMinISRWithout(stopped)
+	if err != nil {
+		return false, err
+	}
+	if len(unavailable) > 0 {
+		log.Info("restarting the broker would leave partitions below min.insync.replicas",
+			"brokerId", brokerId, "restartingBrokerIds", restartingBrokers, "partitions", unavailable)
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,136 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+	"github.com/banzaicloud/kafka-operator/pkg/kafkaclient"
+)
+
+type fakeRestartGateKafkaClient struct {
+	kafkaclient.KafkaClient
+	outOfSync   map[int32]bool
+	unavailable []string
+	stopped     []int32
+}
+
+func (c *fakeRestartGateKafkaClient) BrokerReplicasInSync(brokerID int32) (bool, error) {
+	return !c.outOfSync[brokerID], nil
+}
+
+func (c *fakeRestartGateKafkaClient) PartitionsBelowMinISRWithout(brokerIDs []int32) ([]string, error) {
+	c.stopped = brokerIDs
+	return c.unavailable, nil
+}
+
+func newRestartGateReconciler(t *testing.T, concurrentRackRestart bool) *Reconciler {
+	r := newGracefulRestartReconciler(t)
+	r.KafkaCluster.Spec.RollingUpgradeConfig.ConcurrentRackRestart = concurrentRackRestart
+	r.KafkaCluster.Spec.RackAwareness = &v1beta1.RackAwareness{Labels: []string{"topology.kubernetes.io/zone"}}
+	r.KafkaCluster.Spec.Brokers = []v1beta1.Broker{
+		{Id: 0, ReadOnlyConfig: "broker.rack=zone-a"},
+		{Id: 1, ReadOnlyConfig: "broker.rack=zone-a"},
+		{Id: 2, ReadOnlyConfig: "broker.rack=zone-b"},
+	}
+	r.KafkaCluster.Status.BrokersState = map[string]v1beta1.BrokerState{"0": {}, "1": {}, "2": {}}
+	return r
+}
+
+func brokerPod(brokerId string) corev1.Pod {
+	return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "kafka-" + brokerId, Labels: map[string]string{"brokerId": brokerId}}}
+}
+
+func terminatingBrokerPod(brokerId string) corev1.Pod {
+	pod := brokerPod(brokerId)
+	now := metav1.Now()
+	pod.DeletionTimestamp = &now
+	return pod
+}
+
+func TestConcurrentlyRestartingBrokers(t *testing.T) {
+	current := brokerPod("0")
+
+	r := newRestartGateReconciler(t, false)
+	pods := []corev1.Pod{current, terminatingBrokerPod("1"), brokerPod("2")}
+	_, err := r.concurrentlyRestartingBrokers(&current, pods)
+	expectRollingUpgradeInProgress(t, "concurrent rack restart disabled", err)
+
+	r = newRestartGateReconciler(t, true)
+	restarting, err := r.concurrentlyRestartingBrokers(&current, pods)
+	if err != nil {
+		t.Error("Expected brokers of the same rack to be restarted together, got:", err)
+	}
+	if len(restarting) != 1 || restarting[0] != "1" {
+		t.Error("Expected broker 1 to be restarting, got:", restarting)
+	}
+
+	pods = []corev1.Pod{current, brokerPod("1"), terminatingBrokerPod("2")}
+	_, err = r.concurrentlyRestartingBrokers(&current, pods)
+	expectRollingUpgradeInProgress(t, "restart in an other rack", err)
+
+	pods = []corev1.Pod{terminatingBrokerPod("0"), brokerPod("1"), brokerPod("2")}
+	_, err = r.concurrentlyRestartingBrokers(&current, pods)
+	expectRollingUpgradeInProgress(t, "current pod terminating", err)
+
+	r.KafkaCluster.Spec.RackAwareness = nil
+	pods = []corev1.Pod{current, terminatingBrokerPod("1"), brokerPod("2")}
+	_, err = r.concurrentlyRestartingBrokers(&current, pods)
+	expectRollingUpgradeInProgress(t, "rack awareness disabled", err)
+}
+
+func TestIsRestartSafe(t *testing.T) {
+	r := newRestartGateReconciler(t, true)
+	logger := log.Log
+
+	kClient := &fakeRestartGateKafkaClient{outOfSync: map[int32]bool{0: true}}
+	if safe, err := r.isRestartSafe(logger, kClient, "2", nil); err != nil || !safe {
+		t.Error("Expected restart to be safe when no broker was restarted, got:", safe, err)
+	}
+	if len(kClient.stopped) != 1 || kClient.stopped[0] != 2 {
+		t.Error("Expected partitions of broker 2 to be checked, got:", kClient.stopped)
+	}
+
+	// only the replicas of the broker restarted last are waited for
+	r.KafkaCluster.Status.RollingUpgrade.RestartedBrokers = []string{"0"}
+	if safe, err := r.isRestartSafe(logger, kClient, "2", nil); err != nil || safe {
+		t.Error("Expected restart to wait for the replicas of broker 0, got:", safe, err)
+	}
+	kClient.outOfSync = map[int32]bool{1: true}
+	if safe, err := r.isRestartSafe(logger, kClient, "2", nil); err != nil || !safe {
+		t.Error("Expected lagging replicas of other brokers to be ignored, got:", safe, err)
+	}
+
+	// the brokers restarting in the same rack are not waited for but are considered stopped
+	r.KafkaCluster.Status.RollingUpgrade.RestartedBrokers = []string{"0"}
+	kClient.outOfSync = map[int32]bool{0: true}
+	if safe, err := r.isRestartSafe(logger, kClient, "1", []string{"0"}); err != nil || !safe {
+		t.Error("Expected broker 1 to be restarted alongside broker 0, got:", safe, err)
+	}
+	if len(kClient.stopped) != 2 {
+		t.Error("Expected partitions of brokers 0 and 1 to be checked, got:", kClient.stopped)
+	}
+
+	kClient.outOfSync = nil
+	kClient.unavailable = []string{"topic-0"}
+	if safe, err := r.isRestartSafe(logger, kClient, "2", nil); err != nil || safe {
+		t.Error("Expected restart to be blocked by partitions going below min.insync.replicas, got:", safe, err)
+	}
+}
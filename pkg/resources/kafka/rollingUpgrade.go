@@ -0,0 +1,224 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"reflect"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/k8s-objectmatcher/patch"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+	"github.com/banzaicloud/kafka-operator/pkg/errorfactory"
+	"github.com/banzaicloud/kafka-operator/pkg/k8sutil"
+	"github.com/banzaicloud/kafka-operator/pkg/util"
+	"github.com/banzaicloud/kafka-operator/pkg/util/kafka"
+)
+
+const kafkaContainerName = "kafka"
+
+// checkRollingUpgradeControl returns an error holding the restart of the given broker when the rolling upgrade is
+// paused, aborted or waits for its next step
+func (r *Reconciler) checkRollingUpgradeControl(log logr.Logger, brokerId string) error {
+	// a demoted broker is restarted regardless of the control to restore its leadership
+	if r.KafkaCluster.Status.BrokersState[brokerId].GracefulRestartState.State == v1beta1.GracefulRestartDemotionRunning {
+		return nil
+	}
+	status := r.KafkaCluster.Status.RollingUpgrade
+	rollingUpgradeConfig := r.KafkaCluster.Spec.RollingUpgradeConfig
+	switch rollingUpgradeConfig.GetControl() {
+	case v1beta1.RollingUpgradePause:
+		return r.holdRollingUpgrade(log, brokerId, v1beta1.RollingUpgradePaused, "rolling upgrade is paused")
+	case v1beta1.RollingUpgradeStep:
+		if rollingUpgradeConfig.Step <= status.Step {
+			return r.holdRollingUpgrade(log, brokerId, v1beta1.RollingUpgradeWaitingForStep, "rolling upgrade waits for the next step")
+		}
+	case v1beta1.RollingUpgradeAbort:
+		if status.Phase != v1beta1.RollingUpgradeAborted {
+			// the progress of the rolling upgrade is forgotten, it starts over once the control is changed
+			aborted := v1beta1.RollingUpgradeStatus{
+				LastSuccess:    status.LastSuccess,
+				Phase:          v1beta1.RollingUpgradeAborted,
+				PendingBrokers: status.PendingBrokers,
+				Step:           status.Step,
+			}
+			if err := k8sutil.UpdateRollingUpgradeStatus(r.Client, r.KafkaCluster, aborted, log); err != nil {
+				return errorfactory.New(errorfactory.StatusUpdateError{}, err, "updating rolling upgrade status failed")
+			}
+		}
+		if r.KafkaCluster.Status.State == v1beta1.KafkaClusterRollingUpgrading {
+			if err := k8sutil.UpdateCRStatus(r.Client, r.KafkaCluster, v1beta1.KafkaClusterRunning, log); err != nil {
+				return errorfactory.New(errorfactory.StatusUpdateError{}, err, "setting state to running failed")
+			}
+		}
+		return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New("rolling upgrade is aborted"), "rolling upgrade held", "brokerId", brokerId)
+	}
+	return nil
+}
+
+// holdRollingUpgrade records the phase of the held rolling upgrade and returns the error holding the restart of the broker
+func (r *Reconciler) holdRollingUpgrade(log logr.Logger, brokerId string, phase v1beta1.RollingUpgradePhase, reason string) error {
+	if r.KafkaCluster.Status.RollingUpgrade.Phase != phase {
+		status := r.KafkaCluster.Status.RollingUpgrade
+		status.Phase = phase
+		if err := k8sutil.UpdateRollingUpgradeStatus(r.Client, r.KafkaCluster, status, log); err != nil {
+			return errorfactory.New(errorfactory.StatusUpdateError{}, err, "updating rolling upgrade status failed")
+		}
+	}
+	return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New(reason), "rolling upgrade held", "brokerId", brokerId)
+}
+
+// startRollingUpgrade forgets the progress of the previous rolling upgrade
+func (r *Reconciler) startRollingUpgrade(log logr.Logger) error {
+	status := r.KafkaCluster.Status.RollingUpgrade
+	status.Phase = v1beta1.RollingUpgradeInProgress
+	status.CompletedBrokers = nil
+	status.RestartedBrokers = nil
+	if err := k8sutil.UpdateRollingUpgradeStatus(r.Client, r.KafkaCluster, status, log); err != nil {
+		return errorfactory.New(errorfactory.StatusUpdateError{}, err, "updating rolling upgrade status failed")
+	}
+	return nil
+}
+
+// recordBrokerRestart records the broker restarted by the rolling upgrade, restartedBrokers are the brokers whose
+// replicas have to rejoin the ISR before the next broker is restarted
+func (r *Reconciler) recordBrokerRestart(log logr.Logger, brokerId string, restartedBrokers []string) error {
+	status := r.KafkaCluster.Status.RollingUpgrade
+	status.Phase = v1beta1.RollingUpgradeInProgress
+	status.RestartedBrokers = restartedBrokers
+	if !util.StringSliceContains(status.CompletedBrokers, brokerId) {
+		status.CompletedBrokers = append(status.CompletedBrokers, brokerId)
+	}
+	if r.KafkaCluster.Spec.RollingUpgradeConfig.GetControl() == v1beta1.RollingUpgradeStep {
+		status.Step = r.KafkaCluster.Spec.RollingUpgradeConfig.Step
+	}
+	if err := k8sutil.UpdateRollingUpgradeStatus(r.Client, r.KafkaCluster, status, log); err != nil {
+		return errorfactory.New(errorfactory.StatusUpdateError{}, err, "updating rolling upgrade status failed")
+	}
+	return nil
+}
+
+// updatePendingRestarts records the brokers waiting to be restarted, the phase of the rolling upgrade is cleared once
+// it has not been held by any broker
+func (r *Reconciler) updatePendingRestarts(log logr.Logger, pending []v1beta1.PendingBrokerRestart, held bool) error {
+	status := r.KafkaCluster.Status.RollingUpgrade
+	status.PendingBrokers = nil
+	if len(pending) > 0 {
+		status.PendingBrokers = pending
+	}
+	if !held {
+		status.Phase = ""
+	}
+	if reflect.DeepEqual(status, r.KafkaCluster.Status.RollingUpgrade) {
+		return nil
+	}
+	if err := k8sutil.UpdateRollingUpgradeStatus(r.Client, r.KafkaCluster, status, log); err != nil {
+		return errorfactory.New(errorfactory.StatusUpdateError{}, err, "updating rolling upgrade status failed")
+	}
+	return nil
+}
+
+// pendingRestart returns why the broker of the desired pod has to be restarted, nil is returned when the broker
+// is not running or it is being restarted already
+func (r *Reconciler) pendingRestart(desiredPod *corev1.Pod) (*v1beta1.PendingBrokerRestart, error) {
+	brokerId := desiredPod.Labels["brokerId"]
+	podList := &corev1.PodList{}
+	matchingLabels := client.MatchingLabels(
+		util.MergeLabels(
+			kafka.LabelsForKafka(r.KafkaCluster.Name),
+			map[string]string{"brokerId": brokerId},
+		),
+	)
+	if err := r.Client.List(context.TODO(), podList, client.InNamespace(desiredPod.Namespace), matchingLabels); err != nil {
+		return nil, errorfactory.New(errorfactory.APIFailure{}, err, "getting resource failed", "kind", reflect.TypeOf(desiredPod))
+	}
+	if len(podList.Items) != 1 {
+		return nil, nil
+	}
+	currentPod := &podList.Items[0]
+	if k8sutil.IsMarkedForDeletion(currentPod.ObjectMeta) || k8sutil.IsPodContainsTerminatedContainer(currentPod) {
+		return nil, nil
+	}
+	reasons, err := r.restartReasons(desiredPod, currentPod)
+	if err != nil || len(reasons) == 0 {
+		return nil, err
+	}
+	return &v1beta1.PendingBrokerRestart{BrokerId: brokerId, Reasons: reasons}, nil
+}
+
+// restartReasons returns why the current pod of the broker has to be replaced by the desired one
+func (r *Reconciler) restartReasons(desiredPod, currentPod *corev1.Pod) ([]v1beta1.RestartReason, error) {
+	var reasons []v1beta1.RestartReason
+	desiredImage := kafkaContainerImage(desiredPod)
+	currentImage := kafkaContainerImage(currentPod)
+	if desiredImage != currentImage {
+		reasons = append(reasons, v1beta1.RestartReasonImage)
+	}
+	if r.KafkaCluster.Status.BrokersState[currentPod.Labels["brokerId"]].ConfigurationState == v1beta1.ConfigOutOfSync {
+		reasons = append(reasons, v1beta1.RestartReasonConfig)
+	}
+
+	// the image is already accounted for, any other difference is a change of the pod spec
+	desired := desiredPod.DeepCopy()
+	mergeTolerations(desired, currentPod)
+	setKafkaContainerImage(desired, currentImage)
+	patchResult, err := patch.DefaultPatchMaker.Calculate(currentPod, desired)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not match objects")
+	}
+	if !patchResult.IsEmpty() {
+		reasons = append(reasons, v1beta1.RestartReasonPodSpec)
+	}
+	return reasons, nil
+}
+
+// mergeTolerations adds the tolerations of the current pod to the desired one when it has any, as
+// tolerations do not support patchStrategy:"merge,retainKeys"
+func mergeTolerations(desiredPod, currentPod *corev1.Pod) {
+	if len(desiredPod.Spec.Tolerations) == 0 {
+		return
+	}
+	desiredPod.Spec.Tolerations = append(desiredPod.Spec.Tolerations, currentPod.Spec.Tolerations...)
+	uniqueTolerations := make([]corev1.Toleration, 0, len(desiredPod.Spec.Tolerations))
+	keys := make(map[corev1.Toleration]bool)
+	for _, t := range desiredPod.Spec.Tolerations {
+		if _, value := keys[t]; !value {
+			keys[t] = true
+			uniqueTolerations = append(uniqueTolerations, t)
+		}
+	}
+	desiredPod.Spec.Tolerations = uniqueTolerations
+}
+
+func kafkaContainerImage(pod *corev1.Pod) string {
+	for _, container := range pod.Spec.Containers {
+		if container.Name == kafkaContainerName {
+			return container.Image
+		}
+	}
+	return ""
+}
+
+func setKafkaContainerImage(pod *corev1.Pod, image string) {
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name == kafkaContainerName {
+			pod.Spec.Containers[i].Image = image
+		}
+	}
+}
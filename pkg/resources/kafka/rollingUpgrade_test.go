@@ -0,0 +1,115 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+)
+
+func TestCheckRollingUpgradeControl(t *testing.T) {
+	r := newGracefulRestartReconciler(t)
+	logger := log.Log
+
+	if err := r.checkRollingUpgradeControl(logger, "0"); err != nil {
+		t.Error("Expected the broker to be restarted by default, got:", err)
+	}
+
+	r.KafkaCluster.Spec.RollingUpgradeConfig.Control = v1beta1.RollingUpgradePause
+	expectRollingUpgradeInProgress(t, "paused", r.checkRollingUpgradeControl(logger, "0"))
+	if phase := r.KafkaCluster.Status.RollingUpgrade.Phase; phase != v1beta1.RollingUpgradePaused {
+		t.Error("Expected rolling upgrade to be paused, got:", phase)
+	}
+
+	// a demoted broker is restarted even if the rolling upgrade is paused
+	r.KafkaCluster.Status.BrokersState["0"] = v1beta1.BrokerState{
+		GracefulRestartState: v1beta1.GracefulRestartState{State: v1beta1.GracefulRestartDemotionRunning},
+	}
+	if err := r.checkRollingUpgradeControl(logger, "0"); err != nil {
+		t.Error("Expected the demoted broker to be restarted, got:", err)
+	}
+	r.KafkaCluster.Status.BrokersState["0"] = v1beta1.BrokerState{}
+
+	r.KafkaCluster.Spec.RollingUpgradeConfig.Control = v1beta1.RollingUpgradeStep
+	r.KafkaCluster.Spec.RollingUpgradeConfig.Step = 1
+	if err := r.checkRollingUpgradeControl(logger, "0"); err != nil {
+		t.Error("Expected the broker to be restarted on the next step, got:", err)
+	}
+	if err := r.recordBrokerRestart(logger, "0", []string{"0"}); err != nil {
+		t.Fatal(err)
+	}
+	expectRollingUpgradeInProgress(t, "step taken", r.checkRollingUpgradeControl(logger, "0"))
+	status := r.KafkaCluster.Status.RollingUpgrade
+	if status.Phase != v1beta1.RollingUpgradeWaitingForStep || status.Step != 1 {
+		t.Error("Expected rolling upgrade to wait for step 2, got:", status)
+	}
+	if len(status.CompletedBrokers) != 1 || status.CompletedBrokers[0] != "0" {
+		t.Error("Expected broker 0 to be completed, got:", status.CompletedBrokers)
+	}
+
+	r.KafkaCluster.Status.State = v1beta1.KafkaClusterRollingUpgrading
+	r.KafkaCluster.Spec.RollingUpgradeConfig.Control = v1beta1.RollingUpgradeAbort
+	expectRollingUpgradeInProgress(t, "aborted", r.checkRollingUpgradeControl(logger, "0"))
+	status = r.KafkaCluster.Status.RollingUpgrade
+	if status.Phase != v1beta1.RollingUpgradeAborted || len(status.CompletedBrokers) != 0 || len(status.RestartedBrokers) != 0 {
+		t.Error("Expected the progress of the aborted rolling upgrade to be forgotten, got:", status)
+	}
+	if r.KafkaCluster.Status.State != v1beta1.KafkaClusterRunning {
+		t.Error("Expected aborted cluster to be running, got:", r.KafkaCluster.Status.State)
+	}
+
+	if err := r.updatePendingRestarts(logger, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	if phase := r.KafkaCluster.Status.RollingUpgrade.Phase; phase != "" {
+		t.Error("Expected phase to be cleared once nothing holds the rolling upgrade, got:", phase)
+	}
+}
+
+func TestMergeTolerations(t *testing.T) {
+	toleration := corev1.Toleration{Key: "dedicated", Value: "kafka", Effect: corev1.TaintEffectNoSchedule}
+	nodeToleration := corev1.Toleration{Key: "node.kubernetes.io/not-ready", Operator: corev1.TolerationOpExists}
+	currentPod := &corev1.Pod{Spec: corev1.PodSpec{Tolerations: []corev1.Toleration{toleration, nodeToleration}}}
+
+	desiredPod := &corev1.Pod{}
+	mergeTolerations(desiredPod, currentPod)
+	if len(desiredPod.Spec.Tolerations) != 0 {
+		t.Error("Expected no toleration to be added when none is desired, got:", desiredPod.Spec.Tolerations)
+	}
+
+	desiredPod.Spec.Tolerations = []corev1.Toleration{toleration}
+	mergeTolerations(desiredPod, currentPod)
+	if len(desiredPod.Spec.Tolerations) != 2 {
+		t.Error("Expected the tolerations of the current pod to be merged without duplicates, got:", desiredPod.Spec.Tolerations)
+	}
+}
+
+func TestKafkaContainerImage(t *testing.T) {
+	pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{
+		{Name: "envoy", Image: "envoy:1.18"},
+		{Name: kafkaContainerName, Image: "kafka:2.8.0"},
+	}}}
+	setKafkaContainerImage(pod, "kafka:3.0.0")
+	if image := kafkaContainerImage(pod); image != "kafka:3.0.0" {
+		t.Error("Expected the kafka container image to be set, got:", image)
+	}
+	if image := pod.Spec.Containers[0].Image; image != "envoy:1.18" {
+		t.Error("Expected the other containers to be left untouched, got:", image)
+	}
+}
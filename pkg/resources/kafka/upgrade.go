@@ -0,0 +1,207 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"fmt"
+	"strconv"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+
+	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+	"github.com/banzaicloud/kafka-operator/pkg/errorfactory"
+	"github.com/banzaicloud/kafka-operator/pkg/k8sutil"
+	"github.com/banzaicloud/kafka-operator/pkg/util"
+	"github.com/banzaicloud/kafka-operator/pkg/util/kafka"
+	properties "github.com/banzaicloud/kafka-operator/properties/pkg"
+)
+
+const (
+	interBrokerProtocolVersionConfig = "inter.broker.protocol.version"
+	logMessageFormatVersionConfig    = "log.message.format.version"
+)
+
+// reconcileKafkaUpgrade detects the Kafka version changes of the brokers. An upgrade first rolls the brokers to
+// the new version with the protocol of the previous version pinned. A downgrade to an older protocol is blocked as
+// the brokers can not read the data written with a newer protocol, the returned brokers must not be rolled and a
+// KafkaVersionDowngrade error is returned along with them.
+func (r *Reconciler) reconcileKafkaUpgrade(log logr.Logger) (map[int32]bool, error) {
+	upgrade := r.KafkaCluster.Status.UpgradeStatus
+
+	var blocked *v1beta1.KafkaUpgradeStatus
+	blockedBrokers := make(map[int32]bool)
+	var fromVersion, toVersion string
+	for _, broker := range r.KafkaCluster.Spec.Brokers {
+		desired, running, err := r.brokerVersions(broker)
+		if err != nil {
+			return nil, err
+		}
+		if desired == "" || running == "" {
+			continue
+		}
+		// rolling back to the version the upgrade started from is safe until the protocol is bumped
+		if newerProtocol(running, desired, log) &&
+			(upgrade == nil || upgrade.Phase != v1beta1.KafkaUpgradeRollingBrokers || newerProtocol(upgrade.FromVersion, desired, log)) {
+			if blocked == nil {
+				blocked = downgradeBlockedStatus(broker.Id, running, desired)
+			}
+			blockedBrokers[broker.Id] = true
+			continue
+		}
+		if fromVersion == "" || newerVersion(fromVersion, running, log) {
+			fromVersion = running
+		}
+		if toVersion == "" || newerVersion(desired, toVersion, log) {
+			toVersion = desired
+		}
+	}
+
+	if blocked != nil {
+		return blockedBrokers, r.blockKafkaDowngrade(log, blocked)
+	}
+
+	if upgrade != nil && upgrade.Phase == v1beta1.KafkaUpgradeRollingBrokers {
+		if toVersion != "" && toVersion != upgrade.ToVersion {
+			updated := upgrade.DeepCopy()
+			updated.ToVersion = toVersion
+			return nil, r.updateKafkaUpgradeStatus(log, updated)
+		}
+		return nil, nil
+	}
+	if upgrade != nil && upgrade.Phase == v1beta1.KafkaUpgradeBumpingProtocol && !newerVersion(toVersion, upgrade.ToVersion, log) {
+		return nil, nil
+	}
+
+	if toVersion == "" || !newerVersion(toVersion, fromVersion, log) {
+		if upgrade != nil && upgrade.Phase == v1beta1.KafkaUpgradeDowngradeBlocked {
+			// the downgrade has been reverted
+			return nil, r.updateKafkaUpgradeStatus(log, nil)
+		}
+		return nil, nil
+	}
+
+	started := &v1beta1.KafkaUpgradeStatus{
+		Phase:       v1beta1.KafkaUpgradeRollingBrokers,
+		FromVersion: fromVersion,
+		ToVersion:   toVersion,
+	}
+	// the protocol has to be pinned only when it changes with the version
+	if kafka.ProtocolVersion(fromVersion) != kafka.ProtocolVersion(toVersion) {
+		started.ProtocolVersion = kafka.ProtocolVersion(fromVersion)
+	}
+	log.Info("Kafka upgrade started", "fromVersion", fromVersion, "toVersion", toVersion)
+	return nil, r.updateKafkaUpgradeStatus(log, started)
+}
+
+// advanceKafkaUpgrade moves the upgrade to its next phase once every broker has been rolled,
+// it must be called after every broker has been reconciled
+func (r *Reconciler) advanceKafkaUpgrade(log logr.Logger) error {
+	upgrade := r.KafkaCluster.Status.UpgradeStatus
+	if upgrade == nil || (upgrade.Phase != v1beta1.KafkaUpgradeRollingBrokers && upgrade.Phase != v1beta1.KafkaUpgradeBumpingProtocol) {
+		return nil
+	}
+
+	for _, broker := range r.KafkaCluster.Spec.Brokers {
+		brokerState := r.KafkaCluster.Status.BrokersState[strconv.Itoa(int(broker.Id))]
+		if brokerState.ConfigurationState != v1beta1.ConfigInSync {
+			return nil
+		}
+		desired, running, err := r.brokerVersions(broker)
+		if err != nil {
+			return err
+		}
+		if desired != "" && (running == "" || newerVersion(desired, running, log) || newerVersion(running, desired, log)) {
+			return nil
+		}
+	}
+
+	advanced := upgrade.DeepCopy()
+	switch {
+	case upgrade.Phase == v1beta1.KafkaUpgradeRollingBrokers && upgrade.ProtocolVersion != "":
+		// the brokers are rolled again with the protocol of the new version
+		advanced.Phase = v1beta1.KafkaUpgradeBumpingProtocol
+	default:
+		advanced.Phase = v1beta1.KafkaUpgradeCompleted
+		advanced.ProtocolVersion = ""
+	}
+	log.Info("Kafka upgrade phase changed", "phase", advanced.Phase, "toVersion", advanced.ToVersion)
+	return r.updateKafkaUpgradeStatus(log, advanced)
+}
+
+// pinnedProtocolConfig returns the protocol configuration the brokers are pinned to during an upgrade,
+// protocol versions set in the read-only configuration take precedence
+func pinnedProtocolConfig(upgrade *v1beta1.KafkaUpgradeStatus) *properties.Properties {
+	config := properties.NewProperties()
+	if upgrade == nil || upgrade.Phase != v1beta1.KafkaUpgradeRollingBrokers || upgrade.ProtocolVersion == "" {
+		return config
+	}
+	_ = config.Set(interBrokerProtocolVersionConfig, upgrade.ProtocolVersion)
+	_ = config.Set(logMessageFormatVersionConfig, upgrade.ProtocolVersion)
+	return config
+}
+
+// brokerVersions returns the Kafka version of the image of the broker and the version the broker reports
+func (r *Reconciler) brokerVersions(broker v1beta1.Broker) (string, string, error) {
+	brokerConfig, err := broker.GetBrokerConfig(r.KafkaCluster.Spec)
+	if err != nil {
+		return "", "", errors.WrapIf(err, "failed to determine broker config")
+	}
+	desired := kafka.VersionFromImage(util.GetBrokerImage(brokerConfig, r.KafkaCluster.Spec.GetClusterImage()))
+	running := r.KafkaCluster.Status.BrokersState[strconv.Itoa(int(broker.Id))].Version
+	return desired, running, nil
+}
+
+func downgradeBlockedStatus(brokerId int32, running, desired string) *v1beta1.KafkaUpgradeStatus {
+	return &v1beta1.KafkaUpgradeStatus{
+		Phase:       v1beta1.KafkaUpgradeDowngradeBlocked,
+		FromVersion: running,
+		ToVersion:   desired,
+		Message:     fmt.Sprintf("broker %d runs Kafka %s, downgrading it to %s is not supported", brokerId, running, desired),
+	}
+}
+
+func (r *Reconciler) blockKafkaDowngrade(log logr.Logger, blocked *v1beta1.KafkaUpgradeStatus) error {
+	if upgrade := r.KafkaCluster.Status.UpgradeStatus; upgrade == nil || *upgrade != *blocked {
+		if err := r.updateKafkaUpgradeStatus(log, blocked); err != nil {
+			return err
+		}
+	}
+	return errorfactory.New(errorfactory.KafkaVersionDowngrade{}, errors.New(blocked.Message), "Kafka version downgrade blocked",
+		"version", blocked.FromVersion, "requestedVersion", blocked.ToVersion)
+}
+
+func (r *Reconciler) updateKafkaUpgradeStatus(log logr.Logger, upgrade *v1beta1.KafkaUpgradeStatus) error {
+	if err := k8sutil.UpdateKafkaUpgradeStatus(r.Client, r.KafkaCluster, upgrade, log); err != nil {
+		return errorfactory.New(errorfactory.StatusUpdateError{}, err, "updating Kafka upgrade status failed")
+	}
+	return nil
+}
+
+// newerVersion returns whether version a is newer than version b, invalid versions are never newer
+func newerVersion(a, b string, log logr.Logger) bool {
+	result, err := kafka.CompareVersions(a, b)
+	if err != nil {
+		log.V(1).Info("could not compare Kafka versions", "error", err.Error())
+		return false
+	}
+	return result > 0
+}
+
+// newerProtocol returns whether the protocol (major.minor) of version a is newer than the one of version b,
+// versions which only differ in their patch version share the protocol and can be rolled back and forth
+func newerProtocol(a, b string, log logr.Logger) bool {
+	return newerVersion(kafka.ProtocolVersion(a), kafka.ProtocolVersion(b), log)
+}
@@ -0,0 +1,242 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"strings"
+	"testing"
+
+	"emperror.dev/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	//nolint:staticcheck
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+	"github.com/banzaicloud/kafka-operator/pkg/errorfactory"
+	"github.com/banzaicloud/kafka-operator/pkg/kafkaclient"
+)
+
+func newUpgradeReconciler(t *testing.T, image, runningVersion string) *Reconciler {
+	scheme := runtime.NewScheme()
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			ClusterImage: image,
+			Brokers: []v1beta1.Broker{
+				{Id: 0, BrokerConfig: &v1beta1.BrokerConfig{}},
+				{Id: 1, BrokerConfig: &v1beta1.BrokerConfig{}},
+			},
+		},
+		Status: v1beta1.KafkaClusterStatus{
+			BrokersState: map[string]v1beta1.BrokerState{
+				"0": {Version: runningVersion, ConfigurationState: v1beta1.ConfigInSync},
+				"1": {Version: runningVersion, ConfigurationState: v1beta1.ConfigInSync},
+			},
+		},
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+	return New(client, client, scheme, cluster, kafkaclient.NewMockProvider())
+}
+
+func setBrokerVersions(r *Reconciler, version string, configState v1beta1.ConfigurationState) {
+	for id, state := range r.KafkaCluster.Status.BrokersState {
+		state.Version = version
+		state.ConfigurationState = configState
+		r.KafkaCluster.Status.BrokersState[id] = state
+	}
+}
+
+func TestKafkaUpgradePhases(t *testing.T) {
+	r := newUpgradeReconciler(t, "ghcr.io/banzaicloud/kafka:2.13-2.8.0", "2.7.0")
+	logger := log.Log
+
+	if _, err := r.reconcileKafkaUpgrade(logger); err != nil {
+		t.Fatal(err)
+	}
+	upgrade := r.KafkaCluster.Status.UpgradeStatus
+	if upgrade == nil || upgrade.Phase != v1beta1.KafkaUpgradeRollingBrokers ||
+		upgrade.FromVersion != "2.7.0" || upgrade.ToVersion != "2.8.0" || upgrade.ProtocolVersion != "2.7" {
+		t.Fatal("Expected the brokers to be rolled with the protocol pinned to 2.7, got:", upgrade)
+	}
+
+	config := r.generateBrokerConfig(0, &v1beta1.BrokerConfig{}, nil, nil, nil, "", "", nil, logger)
+	for _, key := range []string{interBrokerProtocolVersionConfig, logMessageFormatVersionConfig} {
+		if !containsLine(config, key+"=2.7") {
+			t.Errorf("Expected %s to be pinned, got config:\n%s", key, config)
+		}
+	}
+
+	// not every broker runs the new version yet
+	r.KafkaCluster.Status.BrokersState["0"] = v1beta1.BrokerState{Version: "2.8.0", ConfigurationState: v1beta1.ConfigInSync}
+	if err := r.advanceKafkaUpgrade(logger); err != nil {
+		t.Fatal(err)
+	}
+	if phase := r.KafkaCluster.Status.UpgradeStatus.Phase; phase != v1beta1.KafkaUpgradeRollingBrokers {
+		t.Fatal("Expected the upgrade to wait for every broker, got:", phase)
+	}
+
+	setBrokerVersions(r, "2.8.0", v1beta1.ConfigInSync)
+	if err := r.advanceKafkaUpgrade(logger); err != nil {
+		t.Fatal(err)
+	}
+	if phase := r.KafkaCluster.Status.UpgradeStatus.Phase; phase != v1beta1.KafkaUpgradeBumpingProtocol {
+		t.Fatal("Expected the protocol to be bumped, got:", phase)
+	}
+	config = r.generateBrokerConfig(0, &v1beta1.BrokerConfig{}, nil, nil, nil, "", "", nil, logger)
+	if containsLine(config, interBrokerProtocolVersionConfig+"=2.7") {
+		t.Errorf("Expected the protocol not to be pinned, got config:\n%s", config)
+	}
+
+	// the brokers are rolled with the new protocol
+	setBrokerVersions(r, "2.8.0", v1beta1.ConfigOutOfSync)
+	if err := r.advanceKafkaUpgrade(logger); err != nil {
+		t.Fatal(err)
+	}
+	if phase := r.KafkaCluster.Status.UpgradeStatus.Phase; phase != v1beta1.KafkaUpgradeBumpingProtocol {
+		t.Fatal("Expected the upgrade to wait for the roll, got:", phase)
+	}
+	setBrokerVersions(r, "2.8.0", v1beta1.ConfigInSync)
+	if err := r.advanceKafkaUpgrade(logger); err != nil {
+		t.Fatal(err)
+	}
+	if phase := r.KafkaCluster.Status.UpgradeStatus.Phase; phase != v1beta1.KafkaUpgradeCompleted {
+		t.Fatal("Expected the upgrade to be completed, got:", phase)
+	}
+	if _, err := r.reconcileKafkaUpgrade(logger); err != nil {
+		t.Fatal(err)
+	}
+	if phase := r.KafkaCluster.Status.UpgradeStatus.Phase; phase != v1beta1.KafkaUpgradeCompleted {
+		t.Fatal("Expected no new upgrade, got:", phase)
+	}
+}
+
+func TestKafkaUpgradeRespectsReadOnlyProtocol(t *testing.T) {
+	r := newUpgradeReconciler(t, "ghcr.io/banzaicloud/kafka:2.13-2.8.0", "2.7.0")
+	r.KafkaCluster.Spec.ReadOnlyConfig = interBrokerProtocolVersionConfig + "=2.6\n"
+	logger := log.Log
+
+	if _, err := r.reconcileKafkaUpgrade(logger); err != nil {
+		t.Fatal(err)
+	}
+	config := r.generateBrokerConfig(0, &v1beta1.BrokerConfig{}, nil, nil, nil, "", "", nil, logger)
+	if !containsLine(config, interBrokerProtocolVersionConfig+"=2.6") || !containsLine(config, logMessageFormatVersionConfig+"=2.7") {
+		t.Errorf("Expected the read-only protocol to take precedence, got config:\n%s", config)
+	}
+}
+
+func TestKafkaPatchUpgradeSkipsProtocolBump(t *testing.T) {
+	r := newUpgradeReconciler(t, "ghcr.io/banzaicloud/kafka:2.13-2.8.1", "2.8.0")
+	logger := log.Log
+
+	if _, err := r.reconcileKafkaUpgrade(logger); err != nil {
+		t.Fatal(err)
+	}
+	if upgrade := r.KafkaCluster.Status.UpgradeStatus; upgrade == nil || upgrade.ProtocolVersion != "" {
+		t.Fatal("Expected the protocol not to be pinned for a patch upgrade, got:", upgrade)
+	}
+	setBrokerVersions(r, "2.8.1", v1beta1.ConfigInSync)
+	if err := r.advanceKafkaUpgrade(logger); err != nil {
+		t.Fatal(err)
+	}
+	if phase := r.KafkaCluster.Status.UpgradeStatus.Phase; phase != v1beta1.KafkaUpgradeCompleted {
+		t.Fatal("Expected the upgrade to be completed without a protocol bump, got:", phase)
+	}
+}
+
+func TestKafkaDowngradeBlocked(t *testing.T) {
+	r := newUpgradeReconciler(t, "ghcr.io/banzaicloud/kafka:2.13-2.7.0", "2.8.0")
+	logger := log.Log
+
+	// only one of the brokers is downgraded
+	r.KafkaCluster.Spec.ClusterImage = "ghcr.io/banzaicloud/kafka:2.13-2.8.0"
+	r.KafkaCluster.Spec.Brokers[1].BrokerConfig.Image = "ghcr.io/banzaicloud/kafka:2.13-2.7.0"
+
+	blockedBrokers, err := r.reconcileKafkaUpgrade(logger)
+	if _, ok := errors.Cause(err).(errorfactory.KafkaVersionDowngrade); !ok {
+		t.Fatal("Expected the downgrade to be blocked, got:", err)
+	}
+	if len(blockedBrokers) != 1 || !blockedBrokers[1] {
+		t.Fatal("Expected only the roll of the downgraded broker to be blocked, got:", blockedBrokers)
+	}
+	if upgrade := r.KafkaCluster.Status.UpgradeStatus; upgrade == nil || upgrade.Phase != v1beta1.KafkaUpgradeDowngradeBlocked {
+		t.Fatal("Expected the blocked downgrade to be reported, got:", upgrade)
+	}
+
+	// reverting the image unblocks the reconciliation
+	r.KafkaCluster.Spec.Brokers[1].BrokerConfig.Image = ""
+	if _, err := r.reconcileKafkaUpgrade(logger); err != nil {
+		t.Fatal(err)
+	}
+	if upgrade := r.KafkaCluster.Status.UpgradeStatus; upgrade != nil {
+		t.Fatal("Expected the blocked downgrade to be cleared, got:", upgrade)
+	}
+}
+
+func TestKafkaPatchDowngradeAllowed(t *testing.T) {
+	r := newUpgradeReconciler(t, "ghcr.io/banzaicloud/kafka:2.13-2.8.0", "2.8.1")
+	logger := log.Log
+
+	blockedBrokers, err := r.reconcileKafkaUpgrade(logger)
+	if err != nil {
+		t.Fatal("Expected the patch downgrade to be allowed, got:", err)
+	}
+	if len(blockedBrokers) != 0 {
+		t.Fatal("Expected no broker to be blocked, got:", blockedBrokers)
+	}
+	if upgrade := r.KafkaCluster.Status.UpgradeStatus; upgrade != nil {
+		t.Fatal("Expected no upgrade to be started, got:", upgrade)
+	}
+}
+
+func TestKafkaUpgradeRollback(t *testing.T) {
+	r := newUpgradeReconciler(t, "ghcr.io/banzaicloud/kafka:2.13-2.8.0", "2.7.0")
+	logger := log.Log
+
+	if _, err := r.reconcileKafkaUpgrade(logger); err != nil {
+		t.Fatal(err)
+	}
+	r.KafkaCluster.Status.BrokersState["0"] = v1beta1.BrokerState{Version: "2.8.0", ConfigurationState: v1beta1.ConfigInSync}
+
+	// rolling back while the protocol is still pinned is allowed
+	r.KafkaCluster.Spec.ClusterImage = "ghcr.io/banzaicloud/kafka:2.13-2.7.0"
+	if _, err := r.reconcileKafkaUpgrade(logger); err != nil {
+		t.Fatal("Expected the rollback to be allowed, got:", err)
+	}
+	if upgrade := r.KafkaCluster.Status.UpgradeStatus; upgrade.Phase != v1beta1.KafkaUpgradeRollingBrokers || upgrade.ToVersion != "2.7.0" {
+		t.Fatal("Expected the upgrade to target the previous version, got:", upgrade)
+	}
+	if err := r.advanceKafkaUpgrade(logger); err != nil {
+		t.Fatal(err)
+	}
+	if phase := r.KafkaCluster.Status.UpgradeStatus.Phase; phase != v1beta1.KafkaUpgradeRollingBrokers {
+		t.Fatal("Expected the upgrade to wait for the broker running the newer version, got:", phase)
+	}
+}
+
+func containsLine(config, line string) bool {
+	for _, l := range strings.Split(config, "\n") {
+		if l == line {
+			return true
+		}
+	}
+	return false
+}
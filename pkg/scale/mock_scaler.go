@@ -15,7 +15,9 @@
 package scale
 
 import (
+	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
 	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+	"github.com/banzaicloud/kafka-operator/pkg/cruisecontrol"
 )
 
 type mockCruiseControlScaler struct{}
@@ -28,11 +30,15 @@ func (mc *mockCruiseControlScaler) GetBrokerIDWithLeastPartition() (string, erro
 	return "", nil
 }
 
-func (mc *mockCruiseControlScaler) UpScaleCluster(brokerIds []string) (string, string, error) {
+func (mc *mockCruiseControlScaler) GetBrokerIDWithLeastPartitionAmong(brokerIds []string) (string, error) {
+	return "", nil
+}
+
+func (mc *mockCruiseControlScaler) UpScaleCluster(brokerIds []string, options map[string]string) (string, string, error) {
 	return "", "", nil
 }
 
-func (mc *mockCruiseControlScaler) DownsizeCluster(brokerIds []string) (string, string, error) {
+func (mc *mockCruiseControlScaler) DownsizeCluster(brokerIds []string, options map[string]string) (string, string, error) {
 	return "", "", nil
 }
 
@@ -48,6 +54,30 @@ func (mc *mockCruiseControlScaler) RunPreferedLeaderElectionInCluster() (string,
 	return "", nil
 }
 
+func (mc *mockCruiseControlScaler) RebalanceWithGoals(goals []string, options map[string]string) (string, *v1alpha1.RebalanceProposalSummary, error) {
+	return "", nil, nil
+}
+
+func (mc *mockCruiseControlScaler) DemoteBrokers(brokerIds []string, options map[string]string) (string, *v1alpha1.RebalanceProposalSummary, error) {
+	return "", nil, nil
+}
+
+func (mc *mockCruiseControlScaler) ProposeRebalance(goals []string, options map[string]string, uTaskId string) (string, *cruisecontrol.OptimizationResult, error) {
+	return "", nil, nil
+}
+
+func (mc *mockCruiseControlScaler) ProposeUpScale(brokerIds []string, options map[string]string, uTaskId string) (string, *cruisecontrol.OptimizationResult, error) {
+	return "", nil, nil
+}
+
+func (mc *mockCruiseControlScaler) ProposeDownsize(brokerIds []string, options map[string]string, uTaskId string) (string, *cruisecontrol.OptimizationResult, error) {
+	return "", nil, nil
+}
+
+func (mc *mockCruiseControlScaler) ProposeDemotion(brokerIds []string, options map[string]string, uTaskId string) (string, *cruisecontrol.OptimizationResult, error) {
+	return "", nil, nil
+}
+
 func (mc *mockCruiseControlScaler) KillCCTask() error {
 	return nil
 }
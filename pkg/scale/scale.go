@@ -15,34 +15,22 @@
 package scale
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
-	"fmt"
-	"io"
-	"io/ioutil"
-	"net/http"
+	"strconv"
 	"strings"
 
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
+	banzaicloudv1alpha1 "github.com/banzaicloud/kafka-operator/api/v1alpha1"
 	banzaicloudv1beta1 "github.com/banzaicloud/kafka-operator/api/v1beta1"
+	"github.com/banzaicloud/kafka-operator/pkg/cruisecontrol"
 	bcutil "github.com/banzaicloud/kafka-operator/pkg/util"
 )
 
-const (
-	basePath                = "kafkacruisecontrol"
-	removeBrokerAction      = "remove_broker"
-	addBrokerAction         = "add_broker"
-	getTaskListAction       = "user_tasks"
-	kafkaClusterStateAction = "kafka_cluster_state"
-	clusterLoadAction       = "load"
-	rebalanceAction         = "rebalance"
-	killProposalAction      = "stop_proposal_execution"
-	serviceNameTemplate     = "%s-cruisecontrol-svc"
-	brokerAlive             = "ALIVE"
-)
+const preferredLeaderElectionGoal = "PreferredLeaderElectionGoal"
 
-var errCruiseControlNotReturned200 = errors.New("non 200 response from cruise-control")
 var newCruiseControlScaler = createNewDefaultCruiseControlScaler
 
 var log = logf.Log.WithName("cruise-control-methods")
@@ -50,157 +38,57 @@ var log = logf.Log.WithName("cruise-control-methods")
 type CruiseControlScaler interface {
 	GetLiveKafkaBrokersFromCruiseControl(brokerIDs []string) ([]string, error)
 	GetBrokerIDWithLeastPartition() (string, error)
-	UpScaleCluster(brokerIDs []string) (string, string, error)
-	DownsizeCluster(brokerIDs []string) (string, string, error)
+	GetBrokerIDWithLeastPartitionAmong(brokerIDs []string) (string, error)
+	UpScaleCluster(brokerIDs []string, options map[string]string) (string, string, error)
+	DownsizeCluster(brokerIDs []string, options map[string]string) (string, string, error)
 	RebalanceDisks(brokerIDsWithMountPath map[string][]string) (string, string, error)
 	RebalanceCluster() (string, error)
 	RunPreferedLeaderElectionInCluster() (string, error)
+	RebalanceWithGoals(goals []string, options map[string]string) (string, *banzaicloudv1alpha1.RebalanceProposalSummary, error)
+	DemoteBrokers(brokerIDs []string, options map[string]string) (string, *banzaicloudv1alpha1.RebalanceProposalSummary, error)
+	ProposeRebalance(goals []string, options map[string]string, uTaskID string) (string, *cruisecontrol.OptimizationResult, error)
+	ProposeUpScale(brokerIDs []string, options map[string]string, uTaskID string) (string, *cruisecontrol.OptimizationResult, error)
+	ProposeDownsize(brokerIDs []string, options map[string]string, uTaskID string) (string, *cruisecontrol.OptimizationResult, error)
+	ProposeDemotion(brokerIDs []string, options map[string]string, uTaskID string) (string, *cruisecontrol.OptimizationResult, error)
 	KillCCTask() error
 	GetCCTaskState(uTaskID string) (banzaicloudv1beta1.CruiseControlUserTaskState, error)
 }
 
 type cruiseControlScaler struct {
 	CruiseControlScaler
-	namespace               string
-	kubernetesClusterDomain string
-	endpoint                string
-	clusterName             string
+	client cruisecontrol.Client
 }
 
-func NewCruiseControlScaler(namespace, kubernetesClusterDomain, endpoint, clusterName string) CruiseControlScaler {
-	return newCruiseControlScaler(namespace, kubernetesClusterDomain, endpoint, clusterName)
+// NewCruiseControlScaler returns a scaler talking to the cruise control of the given cluster
+func NewCruiseControlScaler(k8sclient client.Client, cluster *banzaicloudv1beta1.KafkaCluster) (CruiseControlScaler, error) {
+	return newCruiseControlScaler(k8sclient, cluster)
 }
 
 func MockNewCruiseControlScaler() {
 	newCruiseControlScaler = createMockCruiseControlScaler
 }
 
-func createNewDefaultCruiseControlScaler(namespace, kubernetesClusterDomain, endpoint, clusterName string) CruiseControlScaler {
-	return &cruiseControlScaler{
-		namespace:               namespace,
-		kubernetesClusterDomain: kubernetesClusterDomain,
-		endpoint:                endpoint,
-		clusterName:             clusterName,
-	}
-}
-
-func createMockCruiseControlScaler(namespace, kubernetesClusterDomain, endpoint, clusterName string) CruiseControlScaler {
-	return &mockCruiseControlScaler{}
-}
-
-func (cc *cruiseControlScaler) generateUrlForCC(action string, options map[string]string) string {
-	optionURL := ""
-	for option, value := range options {
-		optionURL = optionURL + option + "=" + value + "&"
-	}
-	if cc.endpoint != "" {
-		return "http://" + cc.endpoint + "/" + basePath + "/" + action + "?" + strings.TrimSuffix(optionURL, "&")
-	}
-	return "http://" + fmt.Sprintf(serviceNameTemplate, cc.clusterName) + "." + cc.namespace + ".svc." + cc.kubernetesClusterDomain + ":8090/" + basePath + "/" + action + "?" + strings.TrimSuffix(optionURL, "&")
-}
-
-func (cc *cruiseControlScaler) postCruiseControl(action string, options map[string]string) (*http.Response, error) {
-	requestURL := cc.generateUrlForCC(action, options)
-	rsp, err := http.Post(requestURL, "text/plain", nil)
+func createNewDefaultCruiseControlScaler(k8sclient client.Client, cluster *banzaicloudv1beta1.KafkaCluster) (CruiseControlScaler, error) {
+	ccClient, err := cruisecontrol.NewFromCluster(k8sclient, cluster)
 	if err != nil {
-		log.Error(err, "error during talking to cruise-control")
 		return nil, err
 	}
-	if rsp.StatusCode != 200 && rsp.StatusCode != 202 {
-		ccErr, parseErr := parseCCErrorFromResp(rsp.Body)
-		if parseErr != nil {
-			return nil, parseErr
-		}
-		closeErr := rsp.Body.Close()
-		if closeErr != nil {
-			return nil, closeErr
-		}
-		log.Error(errCruiseControlNotReturned200, "non-200 response returned by cruise-control",
-			"request", requestURL, "status", rsp.Status, "error message", ccErr)
-		return rsp, errCruiseControlNotReturned200
-	}
-	return rsp, nil
+	return &cruiseControlScaler{client: ccClient}, nil
 }
 
-func (cc *cruiseControlScaler) getCruiseControl(action string, options map[string]string) (*http.Response, error) {
-	requestURL := cc.generateUrlForCC(action, options)
-	rsp, err := http.Get(requestURL)
-	if err != nil {
-		log.Error(err, "error during talking to cruise-control")
-		return nil, err
-	}
-	if rsp.StatusCode != 200 {
-		ccErr, parseErr := parseCCErrorFromResp(rsp.Body)
-		if parseErr != nil {
-			return nil, parseErr
-		}
-		closeErr := rsp.Body.Close()
-		if closeErr != nil {
-			return nil, closeErr
-		}
-		log.Error(errCruiseControlNotReturned200, "non-200 response returned by cruise-control",
-			"request", requestURL, "status", rsp.Status, "error message", ccErr)
-		return rsp, errCruiseControlNotReturned200
-	}
-	return rsp, nil
-}
-
-func parseCCErrorFromResp(input io.Reader) (string, error) {
-	var errorFromResponse struct {
-		ErrorMessage string
-	}
-
-	body, err := ioutil.ReadAll(input)
-	if err != nil {
-		return "", err
-	}
-	err = json.Unmarshal(body, &errorFromResponse)
-	if err != nil {
-		return "", err
-	}
-
-	return errorFromResponse.ErrorMessage, err
+func createMockCruiseControlScaler(k8sclient client.Client, cluster *banzaicloudv1beta1.KafkaCluster) (CruiseControlScaler, error) {
+	return &mockCruiseControlScaler{}, nil
 }
 
 func (cc *cruiseControlScaler) isKafkaBrokerDiskReady(brokerIDsWithMountPath map[string][]string) (bool, error) {
-	options := map[string]string{
-		"json": "true",
-	}
-
-	rsp, err := cc.getCruiseControl(kafkaClusterStateAction, options)
-	if err != nil {
-		keyVals := []interface{}{
-			"namespace", cc.namespace,
-			"clusterName", cc.clusterName,
-			//"brokerId", brokerIDs,
-			//"path", mountPath,
-		}
-		log.Error(err, "can't check if broker disk is ready as Cruise Control not ready", keyVals...)
-		return false, err
-	}
-
-	body, err := ioutil.ReadAll(rsp.Body)
-	if err != nil {
-		return false, err
-	}
-
-	err = rsp.Body.Close()
-	if err != nil {
-		return false, err
-	}
-
-	var response struct {
-		KafkaBrokerState struct {
-			OnlineLogDirsByBrokerID map[string][]string
-		}
-	}
-	err = json.Unmarshal(body, &response)
+	state, err := cc.client.KafkaClusterState(context.TODO())
 	if err != nil {
+		log.Error(err, "can't check if broker disk is ready as Cruise Control not ready")
 		return false, err
 	}
 
 	for brokerID, volumeMounts := range brokerIDsWithMountPath {
-		if ccOnlineLogDirs, ok := response.KafkaBrokerState.OnlineLogDirsByBrokerID[brokerID]; ok {
+		if ccOnlineLogDirs, ok := state.KafkaBrokerState.OnlineLogDirsByBrokerID[brokerID]; ok {
 			for _, volumeMount := range volumeMounts {
 				match := false
 				for _, ccOnlineLogDir := range ccOnlineLogDirs {
@@ -224,43 +112,17 @@ func (cc *cruiseControlScaler) isKafkaBrokerDiskReady(brokerIDsWithMountPath map
 
 // Get brokers status from CC from a provided list of broker ids
 func (cc *cruiseControlScaler) GetLiveKafkaBrokersFromCruiseControl(brokerIDs []string) ([]string, error) {
-	options := map[string]string{
-		"json": "true",
-	}
-
-	rsp, err := cc.getCruiseControl(clusterLoadAction, options)
+	load, err := cc.client.Load(context.TODO())
 	if err != nil {
 		log.Error(err, "can't work with cruise-control because it is not ready")
 		return nil, err
 	}
 
-	body, err := ioutil.ReadAll(rsp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	err = rsp.Body.Close()
-	if err != nil {
-		return nil, err
-	}
-
-	var response struct {
-		Brokers []struct {
-			Broker      float64
-			BrokerState string
-		}
-	}
-
-	err = json.Unmarshal(body, &response)
-	if err != nil {
-		return nil, err
-	}
-
 	aliveBrokers := make([]string, 0, len(brokerIDs))
 
-	for _, broker := range response.Brokers {
-		bIDStr := fmt.Sprintf("%g", broker.Broker)
-		if broker.BrokerState == brokerAlive && bcutil.StringSliceContains(brokerIDs, bIDStr) {
+	for _, broker := range load.Brokers {
+		bIDStr := strconv.Itoa(int(broker.Broker))
+		if broker.BrokerState == cruisecontrol.BrokerStateAlive && bcutil.StringSliceContains(brokerIDs, bIDStr) {
 			aliveBrokers = append(aliveBrokers, bIDStr)
 			log.Info("broker is available in cruise-control", "brokerID", bIDStr)
 		}
@@ -268,45 +130,27 @@ func (cc *cruiseControlScaler) GetLiveKafkaBrokersFromCruiseControl(brokerIDs []
 	return aliveBrokers, nil
 }
 
-// GetBrokerIDWithLeastPartition returns
+// GetBrokerIDWithLeastPartition returns the id of the broker holding the least replicas
 func (cc *cruiseControlScaler) GetBrokerIDWithLeastPartition() (string, error) {
-	brokerWithLeastPartition := ""
+	return cc.GetBrokerIDWithLeastPartitionAmong(nil)
+}
 
-	options := map[string]string{
-		"json": "true",
-	}
+// GetBrokerIDWithLeastPartitionAmong returns the id of the broker holding the least replicas out of the given brokers,
+// all brokers are considered when no broker is given
+func (cc *cruiseControlScaler) GetBrokerIDWithLeastPartitionAmong(brokerIDs []string) (string, error) {
+	brokerWithLeastPartition := ""
 
-	rsp, err := cc.getCruiseControl(kafkaClusterStateAction, options)
+	state, err := cc.client.KafkaClusterState(context.TODO())
 	if err != nil {
 		log.Error(err, "can't work with cruise-control because it is not ready")
 		return brokerWithLeastPartition, err
 	}
 
-	body, err := ioutil.ReadAll(rsp.Body)
-	if err != nil {
-		return brokerWithLeastPartition, err
-	}
-
-	err = rsp.Body.Close()
-	if err != nil {
-		return brokerWithLeastPartition, err
-	}
-
-	var response struct {
-		KafkaBrokerState struct {
-			ReplicaCountByBrokerID map[string]float64
+	replicaCount := int32(99999)
+	for brokerID, replica := range state.KafkaBrokerState.ReplicaCountByBrokerID {
+		if len(brokerIDs) > 0 && !bcutil.StringSliceContains(brokerIDs, brokerID) {
+			continue
 		}
-	}
-
-	err = json.Unmarshal(body, &response)
-	if err != nil {
-		return brokerWithLeastPartition, err
-	}
-
-	replicaCountByBroker := response.KafkaBrokerState.ReplicaCountByBrokerID
-	replicaCount := float64(99999)
-
-	for brokerID, replica := range replicaCountByBroker {
 		if replicaCount > replica {
 			replicaCount = replica
 			brokerWithLeastPartition = brokerID
@@ -315,8 +159,8 @@ func (cc *cruiseControlScaler) GetBrokerIDWithLeastPartition() (string, error) {
 	return brokerWithLeastPartition, nil
 }
 
-// UpScaleCluster upscales Kafka cluster
-func (cc *cruiseControlScaler) UpScaleCluster(brokerIDs []string) (string, string, error) {
+// UpScaleCluster upscales Kafka cluster, the options are passed to CC as request parameters
+func (cc *cruiseControlScaler) UpScaleCluster(brokerIDs []string, options map[string]string) (string, string, error) {
 	liveBrokers, err := cc.GetLiveKafkaBrokersFromCruiseControl(brokerIDs)
 	if err != nil {
 		return "", "", err
@@ -327,50 +171,34 @@ func (cc *cruiseControlScaler) UpScaleCluster(brokerIDs []string) (string, strin
 		return "", "", errors.New("broker(s) not yet ready in cruise-control")
 	}
 
-	options := map[string]string{
-		"json":     "true",
-		"dryrun":   "false",
-		"brokerid": strings.Join(brokerIDs, ","),
-	}
-
-	uResp, err := cc.postCruiseControl(addBrokerAction, options)
-	if err != nil && err != errCruiseControlNotReturned200 {
+	rsp, err := cc.client.AddBrokers(context.TODO(), cruisecontrol.BrokerOperationRequest{
+		OperationRequest: cruisecontrol.OperationRequest{Options: options},
+		BrokerIDs:        brokerIDs,
+	})
+	if err != nil {
 		log.Error(err, "can't upscale cluster gracefully since post to cruise-control failed")
 		return "", "", err
 	}
-	defer uResp.Body.Close()
 
 	log.Info("Initiated upscale in cruise control")
 
-	uTaskID := uResp.Header.Get("User-Task-Id")
-	startTimeStamp := uResp.Header.Get("Date")
-
-	return uTaskID, startTimeStamp, nil
+	return rsp.TaskID, rsp.Date, nil
 }
 
-// DownsizeCluster downscales Kafka cluster
-func (cc *cruiseControlScaler) DownsizeCluster(brokerIDs []string) (string, string, error) {
-	options := map[string]string{
-		"brokerid": strings.Join(brokerIDs, ","),
-		"dryrun":   "false",
-		"json":     "true",
-	}
-
-	var dResp *http.Response
-
-	dResp, err := cc.postCruiseControl(removeBrokerAction, options)
-	if err != nil && err != errCruiseControlNotReturned200 {
+// DownsizeCluster downscales Kafka cluster, the options are passed to CC as request parameters
+func (cc *cruiseControlScaler) DownsizeCluster(brokerIDs []string, options map[string]string) (string, string, error) {
+	rsp, err := cc.client.RemoveBrokers(context.TODO(), cruisecontrol.BrokerOperationRequest{
+		OperationRequest: cruisecontrol.OperationRequest{Options: options},
+		BrokerIDs:        brokerIDs,
+	})
+	if err != nil {
 		log.Error(err, "downsize cluster gracefully failed since CC returned non 200")
 		return "", "", err
 	}
 
-	defer dResp.Body.Close()
-
 	log.Info("Initiated downsize in cruise control")
-	uTaskID := dResp.Header.Get("User-Task-Id")
-	startTimeStamp := dResp.Header.Get("Date")
 
-	return uTaskID, startTimeStamp, nil
+	return rsp.TaskID, rsp.Date, nil
 }
 
 // RebalanceDisks rebalances Kafka broker replicas between disks using CC
@@ -383,81 +211,113 @@ func (cc *cruiseControlScaler) RebalanceDisks(brokerIDsWithMountPath map[string]
 		return "", "", errors.New("broker disk is not ready yet")
 	}
 
-	options := map[string]string{
-		"dryrun":         "false",
-		"json":           "true",
-		"rebalance_disk": "true",
-	}
-
-	rResp, err := cc.postCruiseControl(rebalanceAction, options)
-	if err != nil && err != errCruiseControlNotReturned200 {
+	rsp, err := cc.client.Rebalance(context.TODO(), cruisecontrol.RebalanceRequest{RebalanceDisk: true})
+	if err != nil {
 		log.Error(err, "can't rebalance brokers disk gracefully since post to cruise-control failed")
 		return "", "", err
 	}
 
-	rResp.Body.Close()
-
 	log.Info("Initiated disk rebalance in cruise control")
-	uTaskID := rResp.Header.Get("User-Task-Id")
-	startTimeStamp := rResp.Header.Get("Date")
 
-	return uTaskID, startTimeStamp, nil
+	return rsp.TaskID, rsp.Date, nil
 }
 
 // RebalanceCluster rebalances Kafka cluster using CC
 func (cc *cruiseControlScaler) RebalanceCluster() (string, error) {
-	options := map[string]string{
-		"dryrun": "false",
-		"json":   "true",
-	}
+	uTaskID, _, err := cc.RebalanceWithGoals(nil, nil)
+	return uTaskID, err
+}
+
+// RunPreferedLeaderElectionInCluster runs leader election in  Kafka cluster using CC
+func (cc *cruiseControlScaler) RunPreferedLeaderElectionInCluster() (string, error) {
+	uTaskID, _, err := cc.RebalanceWithGoals([]string{preferredLeaderElectionGoal}, nil)
+	return uTaskID, err
+}
 
-	dResp, err := cc.postCruiseControl(rebalanceAction, options)
+// RebalanceWithGoals rebalances Kafka cluster using CC optimizing for the given goals, the default
+// goals of CC are used when no goals are given. The options are passed to CC as request parameters.
+func (cc *cruiseControlScaler) RebalanceWithGoals(goals []string, options map[string]string) (string, *banzaicloudv1alpha1.RebalanceProposalSummary, error) {
+	rsp, err := cc.client.Rebalance(context.TODO(), cruisecontrol.RebalanceRequest{
+		OperationRequest: cruisecontrol.OperationRequest{Goals: goals, Options: options},
+	})
 	if err != nil {
 		log.Error(err, "can't rebalance cluster gracefully since post to cruise-control failed")
-		return "", err
+		return "", nil, err
 	}
-	defer dResp.Body.Close()
-	log.Info("Initiated rebalance in cruise control")
 
-	uTaskID := dResp.Header.Get("User-Task-Id")
+	log.Info("Initiated rebalance in cruise control", "goals", goals)
 
-	return uTaskID, nil
+	return rsp.TaskID, rsp.Result.ProposalSummary(), nil
 }
 
-// RunPreferedLeaderElectionInCluster runs leader election in  Kafka cluster using CC
-func (cc *cruiseControlScaler) RunPreferedLeaderElectionInCluster() (string, error) {
-	options := map[string]string{
-		"dryrun": "false",
-		"json":   "true",
-		"goals":  "PreferredLeaderElectionGoal",
-	}
-
-	dResp, err := cc.postCruiseControl(rebalanceAction, options)
+// DemoteBrokers moves the leadership of the partitions away from the given brokers using CC
+func (cc *cruiseControlScaler) DemoteBrokers(brokerIDs []string, options map[string]string) (string, *banzaicloudv1alpha1.RebalanceProposalSummary, error) {
+	rsp, err := cc.client.DemoteBrokers(context.TODO(), cruisecontrol.BrokerOperationRequest{
+		OperationRequest: cruisecontrol.OperationRequest{Options: options},
+		BrokerIDs:        brokerIDs,
+	})
 	if err != nil {
-		log.Error(err, "can't rebalance cluster gracefully since post to cruise-control failed")
-		return "", err
+		log.Error(err, "can't demote brokers since post to cruise-control failed")
+		return "", nil, err
 	}
-	defer dResp.Body.Close()
 
-	log.Info("Initiated rebalance in cruise control")
+	log.Info("Initiated broker demotion in cruise control", "brokerIDs", brokerIDs)
 
-	uTaskID := dResp.Header.Get("User-Task-Id")
+	return rsp.TaskID, rsp.Result.ProposalSummary(), nil
+}
 
-	return uTaskID, nil
+// ProposeRebalance returns the proposal of a rebalance optimizing for the given goals without executing it,
+// nil is returned as the result while CC is still computing the proposal, the request has to be repeated
+// with the returned task id then
+func (cc *cruiseControlScaler) ProposeRebalance(goals []string, options map[string]string, uTaskID string) (string, *cruisecontrol.OptimizationResult, error) {
+	rsp, err := cc.client.Rebalance(context.TODO(), cruisecontrol.RebalanceRequest{
+		OperationRequest: cruisecontrol.OperationRequest{DryRun: true, Goals: goals, Options: options, TaskID: uTaskID},
+	})
+	return proposal(rsp, err)
 }
 
-// KillCCTask kills the specified CC task
-func (cc *cruiseControlScaler) KillCCTask() error {
-	options := map[string]string{
-		"json": "true",
+// ProposeUpScale returns the proposal of moving replicas to the given brokers without executing it
+func (cc *cruiseControlScaler) ProposeUpScale(brokerIDs []string, options map[string]string, uTaskID string) (string, *cruisecontrol.OptimizationResult, error) {
+	rsp, err := cc.client.AddBrokers(context.TODO(), proposalForBrokers(brokerIDs, options, uTaskID))
+	return proposal(rsp, err)
+}
+
+// ProposeDownsize returns the proposal of moving replicas away from the given brokers without executing it
+func (cc *cruiseControlScaler) ProposeDownsize(brokerIDs []string, options map[string]string, uTaskID string) (string, *cruisecontrol.OptimizationResult, error) {
+	rsp, err := cc.client.RemoveBrokers(context.TODO(), proposalForBrokers(brokerIDs, options, uTaskID))
+	return proposal(rsp, err)
+}
+
+// ProposeDemotion returns the proposal of moving leaders away from the given brokers without executing it
+func (cc *cruiseControlScaler) ProposeDemotion(brokerIDs []string, options map[string]string, uTaskID string) (string, *cruisecontrol.OptimizationResult, error) {
+	rsp, err := cc.client.DemoteBrokers(context.TODO(), proposalForBrokers(brokerIDs, options, uTaskID))
+	return proposal(rsp, err)
+}
+
+func proposalForBrokers(brokerIDs []string, options map[string]string, uTaskID string) cruisecontrol.BrokerOperationRequest {
+	return cruisecontrol.BrokerOperationRequest{
+		OperationRequest: cruisecontrol.OperationRequest{DryRun: true, Options: options, TaskID: uTaskID},
+		BrokerIDs:        brokerIDs,
 	}
+}
 
-	resp, err := cc.postCruiseControl(killProposalAction, options)
+func proposal(rsp *cruisecontrol.OperationResponse, err error) (string, *cruisecontrol.OptimizationResult, error) {
 	if err != nil {
+		log.Error(err, "can't get proposal since post to cruise-control failed")
+		return "", nil, err
+	}
+	if rsp.Result == nil {
+		log.Info("Cruise control is computing the proposal", "taskID", rsp.TaskID)
+	}
+	return rsp.TaskID, rsp.Result, nil
+}
+
+// KillCCTask kills the specified CC task
+func (cc *cruiseControlScaler) KillCCTask() error {
+	if err := cc.client.StopProposalExecution(context.TODO()); err != nil {
 		log.Error(err, "can't kill running tasks since post to cruise-control failed")
 		return err
 	}
-	defer resp.Body.Close()
 	log.Info("Task killed")
 
 	return nil
@@ -465,42 +325,13 @@ func (cc *cruiseControlScaler) KillCCTask() error {
 
 // GetCCTaskState checks whether the given CC Task ID finished or not
 func (cc *cruiseControlScaler) GetCCTaskState(uTaskID string) (banzaicloudv1beta1.CruiseControlUserTaskState, error) {
-	gResp, err := cc.getCruiseControl(getTaskListAction, map[string]string{
-		"json":          "true",
-		"user_task_ids": uTaskID,
-	})
+	tasks, err := cc.client.UserTasks(context.TODO(), uTaskID)
 	if err != nil {
 		log.Error(err, "can't get task list from cruise-control")
 		return "", err
 	}
 
-	var taskLists struct {
-		UserTasks []struct {
-			Status     string
-			UserTaskID string
-		}
-	}
-
-	body, err := ioutil.ReadAll(gResp.Body)
-	if err != nil {
-		return "", err
-	}
-
-	err = gResp.Body.Close()
-	if err != nil {
-		return "", err
-	}
-
-	err = json.Unmarshal(body, &taskLists)
-	if err != nil {
-		return "", err
-	}
-	// No cc task found with this UID
-	if len(taskLists.UserTasks) == 0 {
-		return banzaicloudv1beta1.CruiseControlTaskNotFound, nil
-	}
-
-	for _, task := range taskLists.UserTasks {
+	for _, task := range tasks.UserTasks {
 		if task.UserTaskID == uTaskID {
 			log.Info("Cruise control task state", "state", task.Status, "taskID", uTaskID)
 			return banzaicloudv1beta1.CruiseControlUserTaskState(task.Status), nil
@@ -0,0 +1,127 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"testing"
+
+	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+	"github.com/banzaicloud/kafka-operator/pkg/cruisecontrol"
+)
+
+func newTestScaler(server *cruisecontrol.FakeServer) *cruiseControlScaler {
+	return &cruiseControlScaler{client: cruisecontrol.New(server.ClientConfig())}
+}
+
+func TestProposeRebalance(t *testing.T) {
+	server := cruisecontrol.NewFakeServer()
+	defer server.Close()
+	server.Proposal = nil
+	cc := newTestScaler(server)
+
+	taskID, result, err := cc.ProposeRebalance([]string{"RackAwareGoal"}, map[string]string{"excluded_topics": "test"}, "")
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	} else if taskID != "task-1" || result != nil {
+		t.Errorf("Expected pending proposal of task-1, got: %s %v", taskID, result)
+	}
+	request := server.Requests[0]
+	if request.Action != "rebalance" || request.Params.Get("dryrun") != "true" ||
+		request.Params.Get("goals") != "RackAwareGoal" || request.Params.Get("excluded_topics") != "test" {
+		t.Error("Expected dry run rebalance request with goals and options, got:", request)
+	}
+
+	// the proposal is computed by the time it is requested the second time
+	server.Proposal = &cruisecontrol.OptimizationResult{Summary: cruisecontrol.OptimizationSummary{NumReplicaMovements: 12}}
+	if _, result, err = cc.ProposeRebalance([]string{"RackAwareGoal"}, nil, taskID); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	} else if result == nil || result.Summary.NumReplicaMovements != 12 {
+		t.Error("Expected computed proposal, got:", result)
+	}
+	if polled := server.Requests[1].TaskID; polled != "task-1" {
+		t.Error("Expected proposal to be polled with its task id, got:", polled)
+	}
+
+	if _, _, err = cc.ProposeDownsize([]string{"1", "2"}, nil, ""); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if request = server.Requests[2]; request.Action != "remove_broker" || request.Params.Get("brokerid") != "1,2" {
+		t.Error("Expected remove broker request for the brokers, got:", request)
+	}
+}
+
+func TestCCTaskState(t *testing.T) {
+	server := cruisecontrol.NewFakeServer()
+	defer server.Close()
+	cc := newTestScaler(server)
+
+	taskID, _, err := cc.RebalanceWithGoals(nil, nil)
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if state, err := cc.GetCCTaskState(taskID); err != nil {
+		t.Error("Expected no error, got:", err)
+	} else if state != v1beta1.CruiseControlTaskActive {
+		t.Error("Expected active task, got:", state)
+	}
+
+	if err = cc.KillCCTask(); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if state, err := cc.GetCCTaskState(taskID); err != nil {
+		t.Error("Expected no error, got:", err)
+	} else if state != v1beta1.CruiseControlTaskCompletedWithError {
+		t.Error("Expected stopped task to be completed with error, got:", state)
+	}
+
+	if state, err := cc.GetCCTaskState("unknown"); err != nil {
+		t.Error("Expected no error, got:", err)
+	} else if state != v1beta1.CruiseControlTaskNotFound {
+		t.Error("Expected unknown task not to be found, got:", state)
+	}
+}
+
+func TestBrokerStateQueries(t *testing.T) {
+	server := cruisecontrol.NewFakeServer()
+	defer server.Close()
+	server.ClusterState.KafkaBrokerState.ReplicaCountByBrokerID = map[string]int32{"0": 10, "1": 3, "2": 5}
+	server.ClusterState.KafkaBrokerState.OnlineLogDirsByBrokerID = map[string][]string{"0": {"/kafka-logs/kafka", "/kafka-logs2/kafka"}}
+	cc := newTestScaler(server)
+
+	if brokerID, err := cc.GetBrokerIDWithLeastPartition(); err != nil {
+		t.Error("Expected no error, got:", err)
+	} else if brokerID != "1" {
+		t.Error("Expected broker 1 to hold the least replicas, got:", brokerID)
+	}
+	if brokerID, err := cc.GetBrokerIDWithLeastPartitionAmong([]string{"0", "2"}); err != nil {
+		t.Error("Expected no error, got:", err)
+	} else if brokerID != "2" {
+		t.Error("Expected broker 2 to hold the least replicas among 0 and 2, got:", brokerID)
+	}
+
+	if ready, err := cc.isKafkaBrokerDiskReady(map[string][]string{"0": {"/kafka-logs", "/kafka-logs2"}}); err != nil {
+		t.Error("Expected no error, got:", err)
+	} else if !ready {
+		t.Error("Expected the disks of broker 0 to be ready")
+	}
+	if ready, err := cc.isKafkaBrokerDiskReady(map[string][]string{"0": {"/kafka-logs3"}}); err != nil {
+		t.Error("Expected no error, got:", err)
+	} else if ready {
+		t.Error("Expected the new disk of broker 0 not to be ready")
+	}
+	if action := server.Requests[0].Action; action != "kafka_cluster_state" {
+		t.Error("Expected the broker state to be read from the kafka cluster state, got:", action)
+	}
+}
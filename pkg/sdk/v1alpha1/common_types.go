@@ -20,12 +20,34 @@ type KafkaAccessType string
 // KafkaPatternType hold the Resource Pattern Type of kafka ACL
 type KafkaPatternType string
 
+// KafkaResourceType is the type of resource an ACL applies to
+type KafkaResourceType string
+
+// KafkaACLOperation is an operation an ACL allows or denies
+// +kubebuilder:validation:Enum={"all","read","write","create","delete","alter","describe","clusterAction","describeConfigs","alterConfigs","idempotentWrite"}
+type KafkaACLOperation string
+
+// KafkaPermissionType defines whether an ACL allows or denies the operation
+type KafkaPermissionType string
+
 // TopicState defines the state of a KafkaTopic
 type TopicState string
 
+// TopicDeletionPolicy defines what happens to the topic in kafka when its KafkaTopic is deleted
+type TopicDeletionPolicy string
+
+// RebalanceOperation is a cruise control operation which can be requested with a KafkaRebalance
+type RebalanceOperation string
+
+// RebalanceState defines the state of a KafkaRebalance
+type RebalanceState string
+
 // UserState defines the state of a KafkaUser
 type UserState string
 
+// UserAuthenticationType defines how a KafkaUser authenticates to the kafka cluster
+type UserAuthenticationType string
+
 // ClusterReference states a reference to a cluster for topic/user
 // provisioning
 type ClusterReference struct {
@@ -44,8 +66,79 @@ const (
 	KafkaPatternTypeMatch    KafkaPatternType = "match"
 	KafkaPatternTypePrefixed KafkaPatternType = "prefixed"
 	KafkaPatternTypeDefault  KafkaPatternType = "literal"
+	// Resource types ACLs can be granted on
+	KafkaResourceTypeTopic           KafkaResourceType = "topic"
+	KafkaResourceTypeGroup           KafkaResourceType = "group"
+	KafkaResourceTypeTransactionalID KafkaResourceType = "transactionalId"
+	KafkaResourceTypeCluster         KafkaResourceType = "cluster"
+	// ACL operations. More info: https://kafka.apache.org/documentation/#operations_resources_and_protocols
+	KafkaACLOperationAll             KafkaACLOperation = "all"
+	KafkaACLOperationRead            KafkaACLOperation = "read"
+	KafkaACLOperationWrite           KafkaACLOperation = "write"
+	KafkaACLOperationCreate          KafkaACLOperation = "create"
+	KafkaACLOperationDelete          KafkaACLOperation = "delete"
+	KafkaACLOperationAlter           KafkaACLOperation = "alter"
+	KafkaACLOperationDescribe        KafkaACLOperation = "describe"
+	KafkaACLOperationClusterAction   KafkaACLOperation = "clusterAction"
+	KafkaACLOperationDescribeConfigs KafkaACLOperation = "describeConfigs"
+	KafkaACLOperationAlterConfigs    KafkaACLOperation = "alterConfigs"
+	KafkaACLOperationIdempotentWrite KafkaACLOperation = "idempotentWrite"
+	// KafkaPermissionTypeAllow states that the ACL allows the operations
+	KafkaPermissionTypeAllow KafkaPermissionType = "allow"
+	// KafkaPermissionTypeDeny states that the ACL denies the operations
+	KafkaPermissionTypeDeny KafkaPermissionType = "deny"
 	// TopicStateCreated describes the status of a KafkaTopic as created
 	TopicStateCreated TopicState = "created"
+	// TopicDeletionPolicyDelete deletes the topic from kafka together with its KafkaTopic
+	TopicDeletionPolicyDelete TopicDeletionPolicy = "Delete"
+	// TopicDeletionPolicyRetain keeps the topic in kafka when its KafkaTopic is deleted
+	TopicDeletionPolicyRetain TopicDeletionPolicy = "Retain"
+	// TopicDeletionPolicyOrphan keeps the topic in kafka when its KafkaTopic is deleted and
+	// keeps the KafkaTopic when the referenced KafkaCluster is deleted
+	TopicDeletionPolicyOrphan TopicDeletionPolicy = "Orphan"
+	// AdoptTopicAnnotation lets a KafkaTopic take ownership of a topic which already exists in the
+	// kafka cluster, the partition count and replication factor of the spec must match the topic
+	AdoptTopicAnnotation string = "kafka.banzaicloud.io/adopt-topic"
+	// DiscoveredTopicLabel marks the KafkaTopics generated by the operator for unmanaged topics
+	DiscoveredTopicLabel string = "kafka.banzaicloud.io/discovered-topic"
+	// TopicConditionReady states that the topic exists and all of its partitions have a leader
+	TopicConditionReady string = "Ready"
+	// TopicConditionUnderReplicated states that some replicas of the topic are out of sync or offline
+	TopicConditionUnderReplicated string = "UnderReplicated"
+	// TopicConditionConfigDrift states that the configuration of the topic differs from its spec
+	TopicConditionConfigDrift string = "ConfigDrift"
+	// RebalanceOperationRebalance moves replicas and leaders according to the goals
+	RebalanceOperationRebalance RebalanceOperation = "Rebalance"
+	// RebalanceOperationLeaderElection moves the leadership of the partitions to their preferred leaders
+	RebalanceOperationLeaderElection RebalanceOperation = "LeaderElection"
+	// RebalanceOperationDemoteBrokers moves the leadership of the partitions away from the given brokers
+	RebalanceOperationDemoteBrokers RebalanceOperation = "DemoteBrokers"
+	// RebalanceOperationAddBrokers moves replicas to the given brokers
+	RebalanceOperationAddBrokers RebalanceOperation = "AddBrokers"
+	// RebalanceOperationRemoveBrokers moves all replicas away from the given brokers
+	RebalanceOperationRemoveBrokers RebalanceOperation = "RemoveBrokers"
+	// RebalanceStateProposalPending states that cruise control is computing the dry run proposal of the operation
+	RebalanceStateProposalPending RebalanceState = "ProposalPending"
+	// RebalanceStateProposalReady states that the proposal of the operation is waiting for approval
+	RebalanceStateProposalReady RebalanceState = "ProposalReady"
+	// RebalanceStatePending states that the operation has not been submitted to cruise control yet
+	RebalanceStatePending RebalanceState = "Pending"
+	// RebalanceStateActive states that cruise control is computing the proposal of the operation
+	RebalanceStateActive RebalanceState = "Active"
+	// RebalanceStateInExecution states that cruise control is executing the proposal of the operation
+	RebalanceStateInExecution RebalanceState = "InExecution"
+	// RebalanceStateCompleted states that the operation has been completed successfully
+	RebalanceStateCompleted RebalanceState = "Completed"
+	// RebalanceStateCompletedWithError states that the operation has been completed with an error
+	RebalanceStateCompletedWithError RebalanceState = "CompletedWithError"
+	// RebalanceStateCancelled states that the operation has been stopped on request
+	RebalanceStateCancelled RebalanceState = "Cancelled"
+	// RebalanceStateFailed states that the task of the operation is lost by cruise control
+	RebalanceStateFailed RebalanceState = "Failed"
+	// ApproveRebalanceAnnotation lets the operation requested by a KafkaRebalance be executed when set to true
+	ApproveRebalanceAnnotation string = "kafka.banzaicloud.io/approve-rebalance"
+	// CancelRebalanceAnnotation stops the operation requested by a KafkaRebalance when set to true
+	CancelRebalanceAnnotation string = "kafka.banzaicloud.io/cancel-rebalance"
 	// UserStateCreated describes the status of a KafkaUser as created
 	UserStateCreated UserState = "created"
 	// TLSJKSKeyStore is where a JKS keystore is stored in a user secret when requested
@@ -66,6 +159,19 @@ const (
 	PeerCertKey string = "peerCert"
 	// PeerPrivateKeyKey stores the peer private key
 	PeerPrivateKeyKey string = "peerKey"
-	// PasswordKey stores the JKS password
+	// PasswordKey stores the JKS password, or the SCRAM password of a KafkaUser
 	PasswordKey string = "password"
+	// UsernameKey stores the SCRAM username of a KafkaUser
+	UsernameKey string = "username"
+	// SASLMechanismKey stores the SASL mechanism a KafkaUser has to use
+	SASLMechanismKey string = "sasl.mechanism"
+	// UserAuthenticationTLS states that the user authenticates with a client certificate
+	UserAuthenticationTLS UserAuthenticationType = "tls"
+	// UserAuthenticationSCRAMSHA256 states that the user authenticates with SCRAM-SHA-256 credentials
+	UserAuthenticationSCRAMSHA256 UserAuthenticationType = "scram-sha-256"
+	// UserAuthenticationSCRAMSHA512 states that the user authenticates with SCRAM-SHA-512 credentials
+	UserAuthenticationSCRAMSHA512 UserAuthenticationType = "scram-sha-512"
+	// RotateCredentialsAnnotation triggers the regeneration of the SCRAM password of a KafkaUser,
+	// the annotation is removed once the new credentials are in place
+	RotateCredentialsAnnotation string = "kafka.banzaicloud.io/rotate-credentials"
 )
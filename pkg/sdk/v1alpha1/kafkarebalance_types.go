@@ -0,0 +1,142 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KafkaRebalanceSpec defines the cruise control operation requested on a kafka cluster
+// +k8s:openapi-gen=true
+type KafkaRebalanceSpec struct {
+	ClusterRef ClusterReference `json:"clusterRef"`
+	// Operation is the cruise control operation to run, defaults to Rebalance
+	// +kubebuilder:validation:Enum={"Rebalance","LeaderElection","DemoteBrokers","AddBrokers","RemoveBrokers"}
+	Operation RebalanceOperation `json:"operation,omitempty"`
+	// Goals are the cruise control goals the rebalance is optimized for, the default
+	// goals of cruise control are used when empty
+	Goals []string `json:"goals,omitempty"`
+	// BrokerIDs are the brokers the DemoteBrokers, AddBrokers and RemoveBrokers operations are run for
+	BrokerIDs []int32 `json:"brokerIds,omitempty"`
+	// Options are passed to cruise control as additional request parameters, e.g. excluded_topics
+	Options map[string]string `json:"options,omitempty"`
+	// RequireApproval holds back the execution of the operation until the proposal computed by cruise
+	// control is approved with the kafka.banzaicloud.io/approve-rebalance annotation
+	RequireApproval bool `json:"requireApproval,omitempty"`
+}
+
+// IsBrokerOperation returns true if the operation is run for the brokers listed in the spec
+func (spec *KafkaRebalanceSpec) IsBrokerOperation() bool {
+	switch spec.GetOperation() {
+	case RebalanceOperationDemoteBrokers, RebalanceOperationAddBrokers, RebalanceOperationRemoveBrokers:
+		return true
+	}
+	return false
+}
+
+// GetOperation returns the requested cruise control operation, defaults to Rebalance
+func (spec *KafkaRebalanceSpec) GetOperation() RebalanceOperation {
+	if spec.Operation == "" {
+		return RebalanceOperationRebalance
+	}
+	return spec.Operation
+}
+
+// KafkaRebalanceStatus defines the observed state of KafkaRebalance
+// +k8s:openapi-gen=true
+type KafkaRebalanceStatus struct {
+	State RebalanceState `json:"state,omitempty"`
+	// TaskID is the id of the cruise control user task executing the operation
+	TaskID string `json:"taskId,omitempty"`
+	// StartedAt is the time the operation has been submitted to cruise control
+	StartedAt string `json:"startedAt,omitempty"`
+	// FinishedAt is the time the operation has been found finished or cancelled
+	FinishedAt string `json:"finishedAt,omitempty"`
+	// Message describes the reason of the current state
+	Message string `json:"message,omitempty"`
+	// Summary is the summary of the proposal cruise control has computed for the operation
+	Summary *RebalanceProposalSummary `json:"summary,omitempty"`
+	// Proposal is the summary of the dry run proposal waiting for approval
+	Proposal *RebalanceProposalSummary `json:"proposal,omitempty"`
+	// ProposalTaskID is the id of the cruise control user task computing the dry run proposal
+	ProposalTaskID string `json:"proposalTaskId,omitempty"`
+	// ProposalGeneration is the generation of the spec the proposal has been computed for
+	ProposalGeneration int64 `json:"proposalGeneration,omitempty"`
+}
+
+// IsFinished returns true if the operation is not going to be progressed anymore
+func (s *KafkaRebalanceStatus) IsFinished() bool {
+	switch s.State {
+	case RebalanceStateCompleted, RebalanceStateCompletedWithError, RebalanceStateCancelled, RebalanceStateFailed:
+		return true
+	}
+	return false
+}
+
+// RebalanceProposalSummary describes the proposal computed by cruise control
+type RebalanceProposalSummary struct {
+	NumReplicaMovements            int32  `json:"numReplicaMovements"`
+	NumLeaderMovements             int32  `json:"numLeaderMovements"`
+	DataToMoveMB                   int64  `json:"dataToMoveMB"`
+	NumIntraBrokerReplicaMovements int32  `json:"numIntraBrokerReplicaMovements,omitempty"`
+	IntraBrokerDataToMoveMB        int64  `json:"intraBrokerDataToMoveMB,omitempty"`
+	MonitoredPartitionsPercentage  string `json:"monitoredPartitionsPercentage,omitempty"`
+	ProvisionStatus                string `json:"provisionStatus,omitempty"`
+	// ExcludedTopics are the topics which have been excluded from the replica movements
+	ExcludedTopics []string `json:"excludedTopics,omitempty"`
+	// ViolatedGoals are the goals which are still violated after the proposal is executed
+	ViolatedGoals []string `json:"violatedGoals,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// KafkaRebalance is the Schema for the kafkarebalances API
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:JSONPath=".spec.clusterRef.name",name="Cluster",type="string"
+// +kubebuilder:printcolumn:JSONPath=".spec.operation",name="Operation",type="string"
+// +kubebuilder:printcolumn:JSONPath=".status.state",name="State",type="string"
+// +kubebuilder:printcolumn:JSONPath=".status.taskId",name="Task",type="string",priority=1
+// +kubebuilder:printcolumn:JSONPath=".metadata.creationTimestamp",name="Age",type="date"
+type KafkaRebalance struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KafkaRebalanceSpec   `json:"spec,omitempty"`
+	Status KafkaRebalanceStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// KafkaRebalanceList contains a list of KafkaRebalance
+type KafkaRebalanceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KafkaRebalance `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KafkaRebalance{}, &KafkaRebalanceList{})
+}
+
+// IsApproved returns true if the execution of the proposal has been approved
+func (r *KafkaRebalance) IsApproved() bool {
+	return r.GetAnnotations()[ApproveRebalanceAnnotation] == "true"
+}
+
+// IsCancellationRequested returns true if the operation has to be stopped in cruise control
+func (r *KafkaRebalance) IsCancellationRequested() bool {
+	return r.GetAnnotations()[CancelRebalanceAnnotation] == "true"
+}
@@ -26,12 +26,94 @@ type KafkaTopicSpec struct {
 	ReplicationFactor int32             `json:"replicationFactor"`
 	Config            map[string]string `json:"config,omitempty"`
 	ClusterRef        ClusterReference  `json:"clusterRef"`
+	// PartitionAssignments pins the replicas of the listed partitions to brokers, the first
+	// broker of a partition is its preferred leader. The replicas of the partitions which are
+	// not listed are placed by the operator when the replication factor changes.
+	PartitionAssignments []PartitionAssignment `json:"partitionAssignments,omitempty"`
+	// ReassignmentThrottleRate limits the replication traffic of the brokers in bytes/sec
+	// while partitions of the topic are reassigned, reassignments are not throttled when unset
+	// +kubebuilder:validation:Minimum=1
+	ReassignmentThrottleRate *int64 `json:"reassignmentThrottleRate,omitempty"`
+	// DeletionPolicy defines whether the topic is deleted from kafka together with the KafkaTopic,
+	// defaults to Delete. Retain keeps the topic in kafka, Orphan also keeps the KafkaTopic itself
+	// when the referenced KafkaCluster is deleted.
+	// +kubebuilder:validation:Enum={"Delete","Retain","Orphan"}
+	DeletionPolicy TopicDeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+// GetDeletionPolicy returns the deletion policy of the topic, defaults to Delete
+func (spec *KafkaTopicSpec) GetDeletionPolicy() TopicDeletionPolicy {
+	if spec.DeletionPolicy == "" {
+		return TopicDeletionPolicyDelete
+	}
+	return spec.DeletionPolicy
+}
+
+// PartitionAssignment defines the brokers holding the replicas of a partition
+type PartitionAssignment struct {
+	// +kubebuilder:validation:Minimum=0
+	Partition int32 `json:"partition"`
+	// +kubebuilder:validation:MinItems=1
+	Replicas []int32 `json:"replicas"`
 }
 
 // KafkaTopicStatus defines the observed state of KafkaTopic
 // +k8s:openapi-gen=true
 type KafkaTopicStatus struct {
 	State TopicState `json:"state"`
+	// Reassignment holds the progress of the ongoing partition reassignment of the topic
+	Reassignment *TopicReassignmentStatus `json:"reassignment,omitempty"`
+	// PartitionCount is the number of partitions of the topic in the kafka cluster
+	PartitionCount int32 `json:"partitionCount,omitempty"`
+	// ReplicationFactor is the smallest number of replicas assigned to a partition of the topic
+	ReplicationFactor int32 `json:"replicationFactor,omitempty"`
+	// UnderReplicatedPartitions is the number of partitions with replicas out of the ISR
+	UnderReplicatedPartitions int32 `json:"underReplicatedPartitions,omitempty"`
+	// OfflinePartitions is the number of partitions without a leader
+	OfflinePartitions int32 `json:"offlinePartitions,omitempty"`
+	// LeaderDistribution is the number of partitions led by each broker, keyed by broker id
+	LeaderDistribution map[string]int32 `json:"leaderDistribution,omitempty"`
+	// EffectiveConfig is the configuration of the topic including the broker defaults
+	EffectiveConfig map[string]string `json:"effectiveConfig,omitempty"`
+	// ObservedGeneration is the generation of the spec the status has been last reconciled for
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// LastAppliedConfigChanges are the configuration overrides which have been last changed on the topic
+	LastAppliedConfigChanges []TopicConfigChange `json:"lastAppliedConfigChanges,omitempty"`
+	// LastConfigChangeAt is the time the configuration overrides of the topic have been last changed
+	LastConfigChangeAt string `json:"lastConfigChangeAt,omitempty"`
+	// Conditions describe the readiness, replication and configuration health of the topic
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// GetCondition returns the condition of the given type, nil is returned when it is not set
+func (s *KafkaTopicStatus) GetCondition(conditionType string) *metav1.Condition {
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == conditionType {
+			return &s.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// TopicConfigChange describes a change of a configuration override of a topic
+type TopicConfigChange struct {
+	Key string `json:"key"`
+	// Previous is the value of the override before the change, empty when it was not set
+	Previous string `json:"previous,omitempty"`
+	// Applied is the value the override has been set to, empty when it has been removed
+	Applied string `json:"applied,omitempty"`
+}
+
+// TopicReassignmentStatus describes the progress of a partition reassignment
+type TopicReassignmentStatus struct {
+	// Partitions are the partitions of the topic which are being reassigned
+	Partitions []int32 `json:"partitions"`
+	// BytesRemaining is the amount of data the new replicas still have to copy
+	BytesRemaining int64 `json:"bytesRemaining"`
+	// StartedAt is the time the reassignment has been started by the operator
+	StartedAt string `json:"startedAt,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -42,6 +124,11 @@ type KafkaTopicStatus struct {
 // KafkaTopic is the Schema for the kafkatopics API
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:JSONPath=".spec.name",name="Topic",type="string"
+// +kubebuilder:printcolumn:JSONPath=".status.partitionCount",name="Partitions",type="integer"
+// +kubebuilder:printcolumn:JSONPath=".status.replicationFactor",name="Replication factor",type="integer"
+// +kubebuilder:printcolumn:JSONPath=".status.conditions[?(@.type==\"Ready\")].status",name="Ready",type="string"
+// +kubebuilder:printcolumn:JSONPath=".metadata.creationTimestamp",name="Age",type="date"
 type KafkaTopic struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -62,3 +149,17 @@ type KafkaTopicList struct {
 func init() {
 	SchemeBuilder.Register(&KafkaTopic{}, &KafkaTopicList{})
 }
+
+// IsAdoptionRequested returns true if the KafkaTopic is allowed to take ownership of an existing topic
+func (t *KafkaTopic) IsAdoptionRequested() bool {
+	return t.GetAnnotations()[AdoptTopicAnnotation] == "true"
+}
+
+// GetDeletionPolicy returns the deletion policy of the topic, the topics discovered by the operator are kept in
+// kafka unless their deletion policy is set explicitly
+func (t *KafkaTopic) GetDeletionPolicy() TopicDeletionPolicy {
+	if t.Spec.DeletionPolicy == "" && t.GetLabels()[DiscoveredTopicLabel] == "true" {
+		return TopicDeletionPolicyRetain
+	}
+	return t.Spec.GetDeletionPolicy()
+}
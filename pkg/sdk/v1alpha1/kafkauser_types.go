@@ -29,6 +29,18 @@ type KafkaUserSpec struct {
 	IncludeJKS     bool             `json:"includeJKS,omitempty"`
 	CreateCert     *bool            `json:"createCert,omitempty"`
 	PKIBackendSpec *PKIBackendSpec  `json:"pkiBackendSpec,omitempty"`
+	// AuthenticationType defines how the user authenticates to the cluster, defaults to tls.
+	// In case of SCRAM the generated password is stored in the user secret and
+	// the certificate related fields are ignored.
+	// +kubebuilder:validation:Enum={"tls","scram-sha-256","scram-sha-512"}
+	AuthenticationType UserAuthenticationType `json:"authenticationType,omitempty"`
+	// ACLs are fine-grained ACL entries of the user applied next to the ones derived from the
+	// topicGrants. If any of them is on a group resource, read topicGrants no longer
+	// allow reading every consumer group.
+	ACLs []UserACL `json:"acls,omitempty"`
+	// Quotas throttle the clients of the user, unset values fall back to the
+	// defaultUserQuotas of the referenced cluster
+	Quotas *UserQuotas `json:"quotas,omitempty"`
 }
 
 type PKIBackendSpec struct {
@@ -37,6 +49,65 @@ type PKIBackendSpec struct {
 	PKIBackend string `json:"pkiBackend"`
 }
 
+// UserQuotas defines the client quotas enforced by the brokers for a user
+type UserQuotas struct {
+	// ProducerByteRate is the maximum produce throughput of the user in bytes/sec
+	// +kubebuilder:validation:Minimum=1
+	ProducerByteRate *int64 `json:"producerByteRate,omitempty"`
+	// ConsumerByteRate is the maximum fetch throughput of the user in bytes/sec
+	// +kubebuilder:validation:Minimum=1
+	ConsumerByteRate *int64 `json:"consumerByteRate,omitempty"`
+	// RequestPercentage is the percentage of time a user may use the request handler
+	// and network threads of a broker
+	// +kubebuilder:validation:Minimum=1
+	RequestPercentage *int32 `json:"requestPercentage,omitempty"`
+}
+
+// IsEmpty returns true if none of the quotas is set
+func (q *UserQuotas) IsEmpty() bool {
+	return q == nil || (q.ProducerByteRate == nil && q.ConsumerByteRate == nil && q.RequestPercentage == nil)
+}
+
+// UserACL is a single ACL entry of a KafkaUser
+type UserACL struct {
+	// +kubebuilder:validation:Enum={"topic","group","transactionalId","cluster"}
+	ResourceType KafkaResourceType `json:"resourceType"`
+	// ResourceName is the name or pattern of the resource, ignored for the cluster resource
+	ResourceName string `json:"resourceName,omitempty"`
+	// +kubebuilder:validation:Enum={"literal","match","prefixed","any"}
+	PatternType KafkaPatternType `json:"patternType,omitempty"`
+	// +kubebuilder:validation:MinItems=1
+	Operations []KafkaACLOperation `json:"operations"`
+	// +kubebuilder:validation:Enum={"allow","deny"}
+	PermissionType KafkaPermissionType `json:"permissionType,omitempty"`
+	// Host restricts the ACL to clients connecting from the given host, defaults to all hosts
+	Host string `json:"host,omitempty"`
+}
+
+// GetPatternType returns the pattern type of the ACL, defaults to literal
+func (acl *UserACL) GetPatternType() KafkaPatternType {
+	if acl.PatternType == "" {
+		return KafkaPatternTypeDefault
+	}
+	return acl.PatternType
+}
+
+// GetPermissionType returns the permission type of the ACL, defaults to allow
+func (acl *UserACL) GetPermissionType() KafkaPermissionType {
+	if acl.PermissionType == "" {
+		return KafkaPermissionTypeAllow
+	}
+	return acl.PermissionType
+}
+
+// GetHost returns the host the ACL applies to, defaults to all hosts
+func (acl *UserACL) GetHost() string {
+	if acl.Host == "" {
+		return "*"
+	}
+	return acl.Host
+}
+
 // UserTopicGrant is the desired permissions for the KafkaUser
 type UserTopicGrant struct {
 	TopicName string `json:"topicName"`
@@ -51,6 +122,10 @@ type UserTopicGrant struct {
 type KafkaUserStatus struct {
 	State UserState `json:"state"`
 	ACLs  []string  `json:"acls,omitempty"`
+	// CredentialsRotatedAt holds the time of the last SCRAM credentials rotation
+	CredentialsRotatedAt string `json:"credentialsRotatedAt,omitempty"`
+	// Quotas holds the effective client quotas of the user
+	Quotas *UserQuotas `json:"quotas,omitempty"`
 }
 
 //KafkaUser is the Schema for the kafka users API
@@ -85,3 +160,27 @@ func (spec *KafkaUserSpec) GetIfCertShouldBeCreated() bool {
 	}
 	return true
 }
+
+// GetAuthenticationType returns the authentication type of the user, defaults to tls
+func (spec *KafkaUserSpec) GetAuthenticationType() UserAuthenticationType {
+	if spec.AuthenticationType == "" {
+		return UserAuthenticationTLS
+	}
+	return spec.AuthenticationType
+}
+
+// HasGroupACLs returns true if any of the fine-grained ACLs of the user is on a consumer group
+func (spec *KafkaUserSpec) HasGroupACLs() bool {
+	for _, acl := range spec.ACLs {
+		if acl.ResourceType == KafkaResourceTypeGroup {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSCRAM returns true if the user authenticates with SCRAM credentials
+func (spec *KafkaUserSpec) IsSCRAM() bool {
+	authType := spec.GetAuthenticationType()
+	return authType == UserAuthenticationSCRAMSHA256 || authType == UserAuthenticationSCRAMSHA512
+}
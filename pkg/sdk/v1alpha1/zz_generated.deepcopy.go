@@ -19,7 +19,8 @@
 package v1alpha1
 
 import (
-	"github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	metav1 "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -38,13 +39,130 @@ func (in *ClusterReference) DeepCopy() *ClusterReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaRebalance) DeepCopyInto(out *KafkaRebalance) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaRebalance.
+func (in *KafkaRebalance) DeepCopy() *KafkaRebalance {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaRebalance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaRebalance) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaRebalanceList) DeepCopyInto(out *KafkaRebalanceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KafkaRebalance, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaRebalanceList.
+func (in *KafkaRebalanceList) DeepCopy() *KafkaRebalanceList {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaRebalanceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaRebalanceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaRebalanceSpec) DeepCopyInto(out *KafkaRebalanceSpec) {
+	*out = *in
+	out.ClusterRef = in.ClusterRef
+	if in.Goals != nil {
+		in, out := &in.Goals, &out.Goals
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BrokerIDs != nil {
+		in, out := &in.BrokerIDs, &out.BrokerIDs
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaRebalanceSpec.
+func (in *KafkaRebalanceSpec) DeepCopy() *KafkaRebalanceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaRebalanceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaRebalanceStatus) DeepCopyInto(out *KafkaRebalanceStatus) {
+	*out = *in
+	if in.Summary != nil {
+		in, out := &in.Summary, &out.Summary
+		*out = new(RebalanceProposalSummary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Proposal != nil {
+		in, out := &in.Proposal, &out.Proposal
+		*out = new(RebalanceProposalSummary)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaRebalanceStatus.
+func (in *KafkaRebalanceStatus) DeepCopy() *KafkaRebalanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaRebalanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KafkaTopic) DeepCopyInto(out *KafkaTopic) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaTopic.
@@ -108,6 +226,18 @@ func (in *KafkaTopicSpec) DeepCopyInto(out *KafkaTopicSpec) {
 		}
 	}
 	out.ClusterRef = in.ClusterRef
+	if in.PartitionAssignments != nil {
+		in, out := &in.PartitionAssignments, &out.PartitionAssignments
+		*out = make([]PartitionAssignment, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ReassignmentThrottleRate != nil {
+		in, out := &in.ReassignmentThrottleRate, &out.ReassignmentThrottleRate
+		*out = new(int64)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaTopicSpec.
@@ -123,6 +253,37 @@ func (in *KafkaTopicSpec) DeepCopy() *KafkaTopicSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KafkaTopicStatus) DeepCopyInto(out *KafkaTopicStatus) {
 	*out = *in
+	if in.Reassignment != nil {
+		in, out := &in.Reassignment, &out.Reassignment
+		*out = new(TopicReassignmentStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LeaderDistribution != nil {
+		in, out := &in.LeaderDistribution, &out.LeaderDistribution
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.EffectiveConfig != nil {
+		in, out := &in.EffectiveConfig, &out.EffectiveConfig
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LastAppliedConfigChanges != nil {
+		in, out := &in.LastAppliedConfigChanges, &out.LastAppliedConfigChanges
+		*out = make([]TopicConfigChange, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaTopicStatus.
@@ -218,6 +379,18 @@ func (in *KafkaUserSpec) DeepCopyInto(out *KafkaUserSpec) {
 		*out = new(PKIBackendSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ACLs != nil {
+		in, out := &in.ACLs, &out.ACLs
+		*out = make([]UserACL, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Quotas != nil {
+		in, out := &in.Quotas, &out.Quotas
+		*out = new(UserQuotas)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaUserSpec.
@@ -238,6 +411,11 @@ func (in *KafkaUserStatus) DeepCopyInto(out *KafkaUserStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Quotas != nil {
+		in, out := &in.Quotas, &out.Quotas
+		*out = new(UserQuotas)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaUserStatus.
@@ -255,7 +433,7 @@ func (in *PKIBackendSpec) DeepCopyInto(out *PKIBackendSpec) {
 	*out = *in
 	if in.IssuerRef != nil {
 		in, out := &in.IssuerRef, &out.IssuerRef
-		*out = new(v1.ObjectReference)
+		*out = new(metav1.ObjectReference)
 		**out = **in
 	}
 }
@@ -270,6 +448,136 @@ func (in *PKIBackendSpec) DeepCopy() *PKIBackendSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PartitionAssignment) DeepCopyInto(out *PartitionAssignment) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PartitionAssignment.
+func (in *PartitionAssignment) DeepCopy() *PartitionAssignment {
+	if in == nil {
+		return nil
+	}
+	out := new(PartitionAssignment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RebalanceProposalSummary) DeepCopyInto(out *RebalanceProposalSummary) {
+	*out = *in
+	if in.ExcludedTopics != nil {
+		in, out := &in.ExcludedTopics, &out.ExcludedTopics
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ViolatedGoals != nil {
+		in, out := &in.ViolatedGoals, &out.ViolatedGoals
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RebalanceProposalSummary.
+func (in *RebalanceProposalSummary) DeepCopy() *RebalanceProposalSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(RebalanceProposalSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopicConfigChange) DeepCopyInto(out *TopicConfigChange) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopicConfigChange.
+func (in *TopicConfigChange) DeepCopy() *TopicConfigChange {
+	if in == nil {
+		return nil
+	}
+	out := new(TopicConfigChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopicReassignmentStatus) DeepCopyInto(out *TopicReassignmentStatus) {
+	*out = *in
+	if in.Partitions != nil {
+		in, out := &in.Partitions, &out.Partitions
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopicReassignmentStatus.
+func (in *TopicReassignmentStatus) DeepCopy() *TopicReassignmentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TopicReassignmentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserACL) DeepCopyInto(out *UserACL) {
+	*out = *in
+	if in.Operations != nil {
+		in, out := &in.Operations, &out.Operations
+		*out = make([]KafkaACLOperation, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserACL.
+func (in *UserACL) DeepCopy() *UserACL {
+	if in == nil {
+		return nil
+	}
+	out := new(UserACL)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserQuotas) DeepCopyInto(out *UserQuotas) {
+	*out = *in
+	if in.ProducerByteRate != nil {
+		in, out := &in.ProducerByteRate, &out.ProducerByteRate
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ConsumerByteRate != nil {
+		in, out := &in.ConsumerByteRate, &out.ConsumerByteRate
+		*out = new(int64)
+		**out = **in
+	}
+	if in.RequestPercentage != nil {
+		in, out := &in.RequestPercentage, &out.RequestPercentage
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserQuotas.
+func (in *UserQuotas) DeepCopy() *UserQuotas {
+	if in == nil {
+		return nil
+	}
+	out := new(UserQuotas)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UserTopicGrant) DeepCopyInto(out *UserTopicGrant) {
 	*out = *in
@@ -58,6 +58,53 @@ type PKIBackend string
 // CruiseControlVolumeState holds information about the state of volume rebalance
 type CruiseControlVolumeState string
 
+// SelfHealingAction is the CruiseControl operation recovering the offline replicas of a lost broker
+type SelfHealingAction string
+
+// SelfHealingState is the state of a self-healing incident
+type SelfHealingState string
+
+// CruiseControlOperationType is the type of a graceful operation queued for CruiseControl
+type CruiseControlOperationType string
+
+// CruiseControlTaskOutcome is the result of a finished CruiseControl task
+type CruiseControlTaskOutcome string
+
+// RestartState holds information about the state of the graceful restart of a broker
+type RestartState string
+
+// KafkaUpgradePhase is the phase of a Kafka version upgrade
+type KafkaUpgradePhase string
+
+// RollingUpgradeControl controls the progress of the rolling upgrade
+// +kubebuilder:validation:Enum=Resume;Pause;Step;Abort
+type RollingUpgradeControl string
+
+// RollingUpgradePhase is the phase of the rolling upgrade
+type RollingUpgradePhase string
+
+// RestartReason states why a broker has to be restarted by the rolling upgrade
+type RestartReason string
+
+// ConfigUpdateMode is the dynamic update mode of a broker config as defined by Kafka
+type ConfigUpdateMode string
+
+// DecommissionState is the state of the removal of a broker from the cluster
+type DecommissionState string
+
+// PVCRetentionPolicy states what happens with the volumes of the brokers removed from the cluster
+// +kubebuilder:validation:Enum=Delete;Retain
+type PVCRetentionPolicy string
+
+// IsInProgress returns true while the broker is demoted, restarted or its leadership is being restored
+func (r RestartState) IsInProgress() bool {
+	return r == GracefulRestartDemotionRunning || r == GracefulRestartPodRestarting || r == GracefulRestartLeaderElectionRunning
+}
+
+// ProcessRole is the role a Kafka node fulfills when the cluster runs in KRaft mode
+// +kubebuilder:validation:Enum=broker;controller
+type ProcessRole string
+
 func (r CruiseControlState) IsUpscale() bool {
 	return r == GracefulUpscaleRequired || r == GracefulUpscaleSucceeded || r == GracefulUpscaleRunning
 }
@@ -112,6 +159,13 @@ func (r SecurityProtocol) Equal(s SecurityProtocol) bool {
 	return r.ToUpperString() == s.ToUpperString()
 }
 
+const (
+	// ProcessRoleBroker marks a node which hosts partitions and serves clients
+	ProcessRoleBroker ProcessRole = "broker"
+	// ProcessRoleController marks a node which is a member of the KRaft controller quorum
+	ProcessRoleController ProcessRole = "controller"
+)
+
 const (
 	// PKIBackendCertManager invokes cert-manager for user certificate management
 	PKIBackendCertManager PKIBackend = "cert-manager"
@@ -134,6 +188,10 @@ type GracefulActionState struct {
 	CruiseControlState CruiseControlState `json:"cruiseControlState"`
 	// VolumeStates holds the information about the CC disk rebalance states and tasks
 	VolumeStates map[string]VolumeState `json:"volumeStates,omitempty"`
+	// FailedAttempts is the number of consecutive failed or timed out CC tasks of the operation
+	FailedAttempts int32 `json:"failedAttempts,omitempty"`
+	// RetryAfter is the time the failed operation is resubmitted to CC after
+	RetryAfter string `json:"retryAfter,omitempty"`
 }
 
 type VolumeState struct {
@@ -145,6 +203,10 @@ type VolumeState struct {
 	TaskStarted string `json:"TaskStarted,omitempty"`
 	// CruiseControlVolumeState holds the information about the CC disk rebalance state
 	CruiseControlVolumeState CruiseControlVolumeState `json:"cruiseControlVolumeState"`
+	// FailedAttempts is the number of consecutive failed or timed out disk rebalance CC tasks
+	FailedAttempts int32 `json:"failedAttempts,omitempty"`
+	// RetryAfter is the time the failed disk rebalance is resubmitted to CC after
+	RetryAfter string `json:"retryAfter,omitempty"`
 }
 
 // BrokerState holds information about broker state
@@ -163,6 +225,46 @@ type BrokerState struct {
 	Version string `json:"version,omitempty"`
 	// Image specifies the current docker image of the broker
 	Image string `json:"image,omitempty"`
+	// GracefulRestartState holds info about the progress of the graceful restart of the broker
+	GracefulRestartState GracefulRestartState `json:"gracefulRestartState,omitempty"`
+	// ConfigChanges holds the keys of the last change of the broker config grouped by their dynamic update mode
+	ConfigChanges ConfigChanges `json:"configChanges,omitempty"`
+	// DecommissionStatus holds info about the removal of the broker from the cluster
+	DecommissionStatus DecommissionStatus `json:"decommissionStatus,omitempty"`
+}
+
+// DecommissionStatus holds information about the removal of a broker from the cluster
+type DecommissionStatus struct {
+	// State is the phase of the removal
+	State DecommissionState `json:"state,omitempty"`
+	// ReplicaCount is the number of partition replicas the broker still hosts
+	ReplicaCount int32 `json:"replicaCount,omitempty"`
+	// ErrorMessage holds why the broker can not be removed yet
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// ConfigChanges holds the changed keys of the broker config grouped by their dynamic update mode
+type ConfigChanges struct {
+	// ReadOnly keys are only applied by restarting the broker
+	ReadOnly []string `json:"readOnly,omitempty"`
+	// PerBroker keys are updated on the broker without a restart
+	PerBroker []string `json:"perBroker,omitempty"`
+	// ClusterWide keys are updated on the broker or as cluster wide defaults without a restart
+	ClusterWide []string `json:"clusterWide,omitempty"`
+	// Unknown keys are not described by the running brokers, they are only applied by restarting the broker
+	Unknown []string `json:"unknown,omitempty"`
+}
+
+// GracefulRestartState holds information about the graceful restart of a broker
+type GracefulRestartState struct {
+	// State is the phase of the graceful restart
+	State RestartState `json:"state,omitempty"`
+	// CruiseControlTaskId holds the id of the demotion or the leader election task ran by CC
+	CruiseControlTaskId string `json:"cruiseControlTaskId,omitempty"`
+	// TaskStarted hold the time when the execution of the phase started
+	TaskStarted string `json:"TaskStarted,omitempty"`
+	// ErrorMessage holds the information what went wrong during the graceful restart
+	ErrorMessage string `json:"errorMessage,omitempty"`
 }
 
 const (
@@ -188,6 +290,104 @@ const (
 	// GracefulDownscaleSucceeded states that the broker downscaled gracefully
 	GracefulDownscaleSucceeded CruiseControlState = "GracefulDownscaleSucceeded"
 
+	// Graceful restart states
+	// GracefulRestartDemotionRunning states that the leadership of the broker is moved away before its restart
+	GracefulRestartDemotionRunning RestartState = "GracefulRestartDemotionRunning"
+	// GracefulRestartPodRestarting states that the demoted broker is restarted and it has not rejoined the ISR yet
+	GracefulRestartPodRestarting RestartState = "GracefulRestartPodRestarting"
+	// GracefulRestartLeaderElectionRunning states that the leadership of the restarted broker is being restored
+	GracefulRestartLeaderElectionRunning RestartState = "GracefulRestartLeaderElectionRunning"
+	// GracefulRestartSucceeded states that the broker has been restarted gracefully
+	GracefulRestartSucceeded RestartState = "GracefulRestartSucceeded"
+
+	// SelfHealingActionFixOfflineReplicas moves the offline replicas of the cluster to healthy brokers
+	SelfHealingActionFixOfflineReplicas SelfHealingAction = "FixOfflineReplicas"
+	// SelfHealingActionRemoveBroker moves all the replicas of the lost broker to the other brokers
+	SelfHealingActionRemoveBroker SelfHealingAction = "RemoveBroker"
+
+	// CruiseControlOperationUpscale moves replicas to a newly added broker
+	CruiseControlOperationUpscale CruiseControlOperationType = "Upscale"
+	// CruiseControlOperationDownscale moves all the replicas off a broker being removed
+	CruiseControlOperationDownscale CruiseControlOperationType = "Downscale"
+	// CruiseControlOperationDiskRebalance moves replicas between the disks of a broker
+	CruiseControlOperationDiskRebalance CruiseControlOperationType = "DiskRebalance"
+
+	// CruiseControlTaskOutcomeCompleted states that the task has moved the replicas successfully
+	CruiseControlTaskOutcomeCompleted CruiseControlTaskOutcome = "Completed"
+	// CruiseControlTaskOutcomeFailed states that the task has completed with error or it has been lost by CruiseControl
+	CruiseControlTaskOutcomeFailed CruiseControlTaskOutcome = "Failed"
+	// CruiseControlTaskOutcomeTimedOut states that the task has been killed as it has not completed in time
+	CruiseControlTaskOutcomeTimedOut CruiseControlTaskOutcome = "TimedOut"
+
+	// KafkaUpgradeRollingBrokers states that the brokers are rolled to the new version with the
+	// inter broker protocol and the log message format pinned to the previous version
+	KafkaUpgradeRollingBrokers KafkaUpgradePhase = "RollingBrokers"
+	// KafkaUpgradeBumpingProtocol states that every broker runs the new version and the brokers are rolled
+	// again to bump the inter broker protocol and the log message format
+	KafkaUpgradeBumpingProtocol KafkaUpgradePhase = "BumpingProtocol"
+	// KafkaUpgradeCompleted states that the brokers run the new version with the new protocol
+	KafkaUpgradeCompleted KafkaUpgradePhase = "Completed"
+	// KafkaUpgradeDowngradeBlocked states that the brokers are not rolled as the requested version has an older
+	// protocol than the one the brokers run
+	KafkaUpgradeDowngradeBlocked KafkaUpgradePhase = "DowngradeBlocked"
+
+	// RollingUpgradeResume restarts the brokers one after the other
+	RollingUpgradeResume RollingUpgradeControl = "Resume"
+	// RollingUpgradePause holds the rolling upgrade, the brokers being restarted are finished but no other broker is restarted
+	RollingUpgradePause RollingUpgradeControl = "Pause"
+	// RollingUpgradeStep restarts a single broker each time the step of the rolling upgrade config is increased
+	RollingUpgradeStep RollingUpgradeControl = "Step"
+	// RollingUpgradeAbort stops the rolling upgrade and forgets its progress, the brokers are not restarted
+	// until the control is changed
+	RollingUpgradeAbort RollingUpgradeControl = "Abort"
+
+	// RollingUpgradeInProgress states that the brokers are being restarted
+	RollingUpgradeInProgress RollingUpgradePhase = "InProgress"
+	// RollingUpgradePaused states that the rolling upgrade is held by the user
+	RollingUpgradePaused RollingUpgradePhase = "Paused"
+	// RollingUpgradeWaitingForStep states that the rolling upgrade waits for the step to be increased
+	RollingUpgradeWaitingForStep RollingUpgradePhase = "WaitingForStep"
+	// RollingUpgradeAborted states that the rolling upgrade has been aborted by the user
+	RollingUpgradeAborted RollingUpgradePhase = "Aborted"
+
+	// RestartReasonImage states that the image of the broker has changed
+	RestartReasonImage RestartReason = "Image"
+	// RestartReasonConfig states that the read only config of the broker has changed
+	RestartReasonConfig RestartReason = "Config"
+	// RestartReasonPodSpec states that the pod of the broker has changed apart from its image
+	RestartReasonPodSpec RestartReason = "PodSpec"
+
+	// ConfigUpdateModeReadOnly states that the config can only be updated by restarting the broker
+	ConfigUpdateModeReadOnly ConfigUpdateMode = "read-only"
+	// ConfigUpdateModePerBroker states that the config can be updated dynamically for each broker
+	ConfigUpdateModePerBroker ConfigUpdateMode = "per-broker"
+	// ConfigUpdateModeClusterWide states that the config can be updated dynamically as a cluster wide default
+	// and for each broker
+	ConfigUpdateModeClusterWide ConfigUpdateMode = "cluster-wide"
+
+	// DecommissionDraining states that the replicas of the broker are moved to the remaining brokers, the broker
+	// is only removed once it hosts no replicas
+	DecommissionDraining DecommissionState = "Draining"
+	// DecommissionBlocked states that the broker is not removed as some topics would fall below their replication factor
+	DecommissionBlocked DecommissionState = "Blocked"
+
+	// PVCRetentionPolicyDelete deletes the volumes of the removed brokers
+	PVCRetentionPolicyDelete PVCRetentionPolicy = "Delete"
+	// PVCRetentionPolicyRetain keeps the volumes of the removed brokers, they are reused when a broker is added
+	// with the same id
+	PVCRetentionPolicyRetain PVCRetentionPolicy = "Retain"
+
+	// SelfHealingDetected states that the broker has offline replicas, it is healed after the grace period
+	SelfHealingDetected SelfHealingState = "Detected"
+	// SelfHealingInProgress states that the CruiseControl task healing the broker is running
+	SelfHealingInProgress SelfHealingState = "InProgress"
+	// SelfHealingRecovered states that the broker has recovered within the grace period
+	SelfHealingRecovered SelfHealingState = "Recovered"
+	// SelfHealingSucceeded states that the replicas of the broker have been recovered by CruiseControl
+	SelfHealingSucceeded SelfHealingState = "Succeeded"
+	// SelfHealingFailed states that the CruiseControl task healing the broker has failed
+	SelfHealingFailed SelfHealingState = "Failed"
+
 	// Disk rebalance cruise control states
 	// GracefulDiskRebalanceRequired states that the broker volume needs a CC disk rebalance
 	GracefulDiskRebalanceRequired CruiseControlVolumeState = "GracefulDiskRebalanceRequired"
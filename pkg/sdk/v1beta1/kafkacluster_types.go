@@ -16,6 +16,7 @@ package v1beta1
 
 import (
 	"strings"
+	"time"
 
 	"emperror.dev/errors"
 	"github.com/imdario/mergo"
@@ -46,10 +47,16 @@ type KafkaClusterSpec struct {
 	ListenersConfig        ListenersConfig `json:"listenersConfig"`
 	// ZKAddresses specifies the ZooKeeper connection string
 	// in the form hostname:port where host and port are the host and port of a ZooKeeper server.
-	ZKAddresses []string `json:"zkAddresses"`
+	ZKAddresses []string `json:"zkAddresses,omitempty"`
 	// ZKPath specifies the ZooKeeper chroot path as part
 	// of its ZooKeeper connection string which puts its data under some path in the global ZooKeeper namespace.
-	ZKPath               string                  `json:"zkPath,omitempty"`
+	ZKPath string `json:"zkPath,omitempty"`
+	// KRaftMode specifies whether the cluster runs without ZooKeeper using a KRaft controller quorum
+	// formed by the brokers which have the controller process role. ZKAddresses and ZKPath are ignored in this mode.
+	KRaftMode bool `json:"kRaftMode,omitempty"`
+	// ClusterID is used to format the storage of the nodes when KRaftMode is enabled.
+	// If left empty it is derived from the UID of the KafkaCluster resource.
+	ClusterID            string                  `json:"clusterId,omitempty"`
 	RackAwareness        *RackAwareness          `json:"rackAwareness,omitempty"`
 	ClusterImage         string                  `json:"clusterImage,omitempty"`
 	ReadOnlyConfig       string                  `json:"readOnlyConfig,omitempty"`
@@ -75,6 +82,32 @@ type KafkaClusterSpec struct {
 	// Add the "+" suffix to append.
 	Envs                    []corev1.EnvVar `json:"envs,omitempty"`
 	KubernetesClusterDomain string          `json:"kubernetesClusterDomain,omitempty"`
+	// DefaultUserQuotas are applied to every KafkaUser of the cluster, quotas set on the
+	// KafkaUser itself take precedence
+	DefaultUserQuotas *UserQuotas `json:"defaultUserQuotas,omitempty"`
+	// RemovedBrokerPVCRetentionPolicy states whether the volumes of the brokers removed from the cluster are deleted
+	// or retained, defaults to Delete
+	// +optional
+	RemovedBrokerPVCRetentionPolicy PVCRetentionPolicy `json:"removedBrokerPvcRetentionPolicy,omitempty"`
+}
+
+// UserQuotas defines the default client quotas enforced by the brokers for the users of the cluster
+type UserQuotas struct {
+	// ProducerByteRate is the maximum produce throughput of the user in bytes/sec
+	// +kubebuilder:validation:Minimum=1
+	ProducerByteRate *int64 `json:"producerByteRate,omitempty"`
+	// ConsumerByteRate is the maximum fetch throughput of the user in bytes/sec
+	// +kubebuilder:validation:Minimum=1
+	ConsumerByteRate *int64 `json:"consumerByteRate,omitempty"`
+	// RequestPercentage is the percentage of time a user may use the request handler
+	// and network threads of a broker
+	// +kubebuilder:validation:Minimum=1
+	RequestPercentage *int32 `json:"requestPercentage,omitempty"`
+}
+
+// IsEmpty returns true if none of the quotas is set
+func (q *UserQuotas) IsEmpty() bool {
+	return q == nil || (q.ProducerByteRate == nil && q.ConsumerByteRate == nil && q.RequestPercentage == nil)
 }
 
 // KafkaClusterStatus defines the observed state of KafkaCluster
@@ -85,17 +118,121 @@ type KafkaClusterStatus struct {
 	RollingUpgrade           RollingUpgradeStatus     `json:"rollingUpgradeStatus,omitempty"`
 	AlertCount               int                      `json:"alertCount"`
 	ListenerStatuses         ListenerStatuses         `json:"listenerStatuses,omitempty"`
+	// SelfHealingIncidents records the brokers which have been lost and their recovery, the most recent ones are kept
+	SelfHealingIncidents []SelfHealingIncident `json:"selfHealingIncidents,omitempty"`
+	// CruiseControlOperationQueue lists the pending graceful operations in the order they are submitted to CC,
+	// a single CC task runs at a time and the next batch is submitted once it has finished
+	CruiseControlOperationQueue []CruiseControlOperation `json:"cruiseControlOperationQueue,omitempty"`
+	// CruiseControlTaskHistory records the most recent finished CC tasks of the graceful operations, the oldest first
+	CruiseControlTaskHistory []CruiseControlTaskRecord `json:"cruiseControlTaskHistory,omitempty"`
+	// UpgradeStatus holds the progress of the last Kafka version upgrade
+	UpgradeStatus *KafkaUpgradeStatus `json:"upgradeStatus,omitempty"`
+}
+
+// KafkaUpgradeStatus describes the progress of a Kafka version upgrade
+type KafkaUpgradeStatus struct {
+	Phase KafkaUpgradePhase `json:"phase"`
+	// FromVersion is the oldest version the brokers ran when the upgrade started
+	FromVersion string `json:"fromVersion"`
+	// ToVersion is the version the brokers are upgraded to
+	ToVersion string `json:"toVersion"`
+	// ProtocolVersion is the inter.broker.protocol.version and log.message.format.version the brokers
+	// are pinned to while they are rolled to the new version
+	ProtocolVersion string `json:"protocolVersion,omitempty"`
+	// Message describes why the upgrade is blocked
+	Message string `json:"message,omitempty"`
+}
+
+// CruiseControlOperation is a graceful operation of a broker waiting for its CC task
+type CruiseControlOperation struct {
+	BrokerId  string                     `json:"brokerId"`
+	Operation CruiseControlOperationType `json:"operation"`
+	// MountPaths are the volumes of the broker to be rebalanced by a disk rebalance
+	MountPaths []string `json:"mountPaths,omitempty"`
+	// EnqueuedAt is the time the operation has been queued at
+	EnqueuedAt string `json:"enqueuedAt"`
+	// RetryAfter is the time the operation is submitted after when its previous task has failed
+	RetryAfter string `json:"retryAfter,omitempty"`
+}
+
+// CruiseControlTaskRecord describes a finished CruiseControl task of a graceful operation
+type CruiseControlTaskRecord struct {
+	TaskId    string                     `json:"taskId"`
+	Operation CruiseControlOperationType `json:"operation"`
+	BrokerIds []string                   `json:"brokerIds"`
+	// StartedAt is the time CruiseControl has accepted the task at
+	StartedAt string `json:"startedAt,omitempty"`
+	// FinishedAt is the time the operator has noticed the end of the task at
+	FinishedAt string                   `json:"finishedAt"`
+	Outcome    CruiseControlTaskOutcome `json:"outcome"`
+	// ErrorMessage describes why the task has failed
+	ErrorMessage string `json:"errorMessage,omitempty"`
+	// Attempt is the number of times the operation has been submitted to CruiseControl
+	Attempt int32 `json:"attempt,omitempty"`
+}
+
+// SelfHealingIncident describes the loss of a broker and its recovery
+type SelfHealingIncident struct {
+	BrokerId string           `json:"brokerId"`
+	State    SelfHealingState `json:"state"`
+	// DetectedAt is the time the offline replicas of the broker have been first detected at
+	DetectedAt string `json:"detectedAt"`
+	// Action is the CruiseControl operation the broker has been healed with
+	Action SelfHealingAction `json:"action,omitempty"`
+	// CruiseControlTaskId holds the id of the task healing the broker
+	CruiseControlTaskId string `json:"cruiseControlTaskId,omitempty"`
+	// ReplacementBrokerId is the id of the broker which has replaced the lost one in the spec
+	ReplacementBrokerId string `json:"replacementBrokerId,omitempty"`
+	// FinishedAt is the time the incident has been closed at
+	FinishedAt string `json:"finishedAt,omitempty"`
+	Message    string `json:"message,omitempty"`
+}
+
+// IsOpen returns true until the broker has recovered, has been healed or its healing has failed
+func (i *SelfHealingIncident) IsOpen() bool {
+	return i.State == SelfHealingDetected || i.State == SelfHealingInProgress
 }
 
 // RollingUpgradeStatus defines status of rolling upgrade
 type RollingUpgradeStatus struct {
 	LastSuccess string `json:"lastSuccess"`
 	ErrorCount  int    `json:"errorCount"`
+	// RestartedBrokers are the brokers restarted last by the rolling upgrade, the next broker is restarted
+	// once their replicas have rejoined the ISR
+	RestartedBrokers []string `json:"restartedBrokers,omitempty"`
+	// Phase is the phase of the rolling upgrade as controlled by the rolling upgrade config
+	Phase RollingUpgradePhase `json:"phase,omitempty"`
+	// PendingBrokers are the brokers waiting to be restarted with the reasons of their restart
+	PendingBrokers []PendingBrokerRestart `json:"pendingBrokers,omitempty"`
+	// CompletedBrokers are the brokers restarted by the current rolling upgrade
+	CompletedBrokers []string `json:"completedBrokers,omitempty"`
+	// Step is the last step of the rolling upgrade taken when it is controlled step by step
+	Step int32 `json:"step,omitempty"`
+}
+
+// PendingBrokerRestart holds why a broker has to be restarted by the rolling upgrade
+type PendingBrokerRestart struct {
+	BrokerId string          `json:"brokerId"`
+	Reasons  []RestartReason `json:"reasons"`
 }
 
 // RollingUpgradeConfig defines the desired config of the RollingUpgrade
 type RollingUpgradeConfig struct {
 	FailureThreshold int `json:"failureThreshold"`
+	// GracefulRestart makes the operator demote the brokers through CruiseControl and wait until they lead no partitions
+	// before they are restarted, their leadership is restored with a preferred leader election once they rejoined the ISR
+	// +optional
+	GracefulRestart bool `json:"gracefulRestart,omitempty"`
+	// ConcurrentRackRestart lets the brokers of the same rack be restarted at the same time when rack awareness is enabled,
+	// a broker is only restarted alongside the others if none of their partitions falls below min.insync.replicas
+	// +optional
+	ConcurrentRackRestart bool `json:"concurrentRackRestart,omitempty"`
+	// Control pauses, resumes or aborts the rolling upgrade, or lets it restart the brokers step by step
+	// +optional
+	Control RollingUpgradeControl `json:"control,omitempty"`
+	// Step restarts the next broker each time it is increased while the control is set to Step
+	// +optional
+	Step int32 `json:"step,omitempty"`
 }
 
 // DisruptionBudget defines the configuration for PodDisruptionBudget
@@ -164,6 +301,10 @@ type BrokerConfig struct {
 	// Adding the "+" prefix to the name prepends the value to that environment variable instead of overwriting it.
 	// Add the "+" suffix to append.
 	Envs []corev1.EnvVar `json:"envs,omitempty"`
+	// ProcessRoles defines the roles of the node when KRaftMode is enabled.
+	// Roles set on the broker are merged with the ones set in its brokerConfigGroup.
+	// If left empty the node acts both as a broker and as a controller.
+	ProcessRoles []ProcessRole `json:"processRoles,omitempty"`
 }
 
 type NetworkConfig struct {
@@ -203,12 +344,213 @@ type CruiseControlConfig struct {
 	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
 	// SecurityContext allows to set security context for the CruiseControl container
 	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
+	// APIConfig defines how the operator connects to the REST API of CruiseControl
+	APIConfig *CruiseControlAPIConfig `json:"apiConfig,omitempty"`
+	// SelfHealing defines how the operator recovers the replicas of the brokers which have been lost
+	SelfHealing *SelfHealingConfig `json:"selfHealing,omitempty"`
+	// Goals defines the optimization goals of CruiseControl, they override the goals set in Config
+	Goals *CruiseControlGoalsConfig `json:"goals,omitempty"`
+	// AnomalyDetection defines the anomaly detectors of CruiseControl and its own self-healing, the
+	// settings override the ones set in Config
+	AnomalyDetection *CruiseControlAnomalyDetectionConfig `json:"anomalyDetection,omitempty"`
+	// MetricSampler defines how CruiseControl samples and stores the metrics of the cluster, the
+	// settings override the ones set in Config
+	MetricSampler *CruiseControlMetricSamplerConfig `json:"metricSampler,omitempty"`
+	// NetworkCapacityLabels names the node labels holding the network bandwidth of the nodes in KB/s,
+	// the labels of the node a broker runs on override the NetworkConfig of the broker
+	NetworkCapacityLabels *NetworkCapacityLabels `json:"networkCapacityLabels,omitempty"`
+}
+
+// NetworkCapacityLabels defines the node labels the network capacity of the brokers is read from
+type NetworkCapacityLabels struct {
+	Incoming string `json:"incoming,omitempty"`
+	Outgoing string `json:"outgoing,omitempty"`
+}
+
+// CruiseControlGoalsConfig defines the goals of CruiseControl by their class names, the goals shipped
+// with CruiseControl can be referred to by their simple names e.g. RackAwareGoal. The goals are validated
+// by the webhook of the KafkaCluster.
+type CruiseControlGoalsConfig struct {
+	// Goals are all the goals CruiseControl may optimize for, the other goal lists must be subsets of it
+	Goals []string `json:"goals,omitempty"`
+	// DefaultGoals are used by the requests which do not specify their goals
+	DefaultGoals []string `json:"defaultGoals,omitempty"`
+	// HardGoals must be satisfied by every proposal of CruiseControl
+	HardGoals []string `json:"hardGoals,omitempty"`
+	// AnomalyDetectionGoals are checked by the goal violation detector
+	AnomalyDetectionGoals []string `json:"anomalyDetectionGoals,omitempty"`
+	// SelfHealingGoals are used to fix the detected anomalies, the default goals are used when empty
+	SelfHealingGoals []string `json:"selfHealingGoals,omitempty"`
+}
+
+// CruiseControlAnomalyDetectionConfig defines the anomaly detectors of CruiseControl
+type CruiseControlAnomalyDetectionConfig struct {
+	// IntervalSeconds is the interval the anomaly detectors are run at
+	// +kubebuilder:validation:Minimum=1
+	IntervalSeconds *int64 `json:"intervalSeconds,omitempty"`
+	// GoalViolationIntervalSeconds overrides the interval of the goal violation detector
+	// +kubebuilder:validation:Minimum=1
+	GoalViolationIntervalSeconds *int64 `json:"goalViolationIntervalSeconds,omitempty"`
+	// MetricAnomalyIntervalSeconds overrides the interval of the metric anomaly detector
+	// +kubebuilder:validation:Minimum=1
+	MetricAnomalyIntervalSeconds *int64 `json:"metricAnomalyIntervalSeconds,omitempty"`
+	// DiskFailureIntervalSeconds overrides the interval of the disk failure detector
+	// +kubebuilder:validation:Minimum=1
+	DiskFailureIntervalSeconds *int64 `json:"diskFailureIntervalSeconds,omitempty"`
+	// BrokerFailureAlertThresholdSeconds is the time a broker has to be down for before it is reported
+	// +kubebuilder:validation:Minimum=0
+	BrokerFailureAlertThresholdSeconds *int64 `json:"brokerFailureAlertThresholdSeconds,omitempty"`
+	// BrokerFailureSelfHealingThresholdSeconds is the time a broker has to be down for before it is healed
+	// +kubebuilder:validation:Minimum=0
+	BrokerFailureSelfHealingThresholdSeconds *int64 `json:"brokerFailureSelfHealingThresholdSeconds,omitempty"`
+	// NotifierClass is the class of the anomaly notifier, self-healing requires the SelfHealingNotifier or its subclass
+	NotifierClass string `json:"notifierClass,omitempty"`
+	// SelfHealing toggles the self-healing of CruiseControl per anomaly type. Healing the broker failures
+	// both by CruiseControl and by the operator (see CruiseControlConfig.SelfHealing) is not recommended.
+	SelfHealing *CruiseControlSelfHealingConfig `json:"selfHealing,omitempty"`
+}
+
+// CruiseControlSelfHealingConfig toggles the self-healing of CruiseControl, the anomaly types which are
+// not set follow Enabled
+type CruiseControlSelfHealingConfig struct {
+	Enabled       *bool `json:"enabled,omitempty"`
+	BrokerFailure *bool `json:"brokerFailure,omitempty"`
+	GoalViolation *bool `json:"goalViolation,omitempty"`
+	MetricAnomaly *bool `json:"metricAnomaly,omitempty"`
+	DiskFailure   *bool `json:"diskFailure,omitempty"`
+	TopicAnomaly  *bool `json:"topicAnomaly,omitempty"`
+}
+
+// CruiseControlMetricSamplerConfig defines the metric sampling and the sample store of CruiseControl
+type CruiseControlMetricSamplerConfig struct {
+	// SamplingIntervalSeconds is the interval the metrics of the cluster are sampled at
+	// +kubebuilder:validation:Minimum=1
+	SamplingIntervalSeconds *int64 `json:"samplingIntervalSeconds,omitempty"`
+	// PartitionMetricsWindowSeconds is the length of the windows the partition metrics are aggregated in
+	// +kubebuilder:validation:Minimum=1
+	PartitionMetricsWindowSeconds *int64 `json:"partitionMetricsWindowSeconds,omitempty"`
+	// NumPartitionMetricsWindows is the number of partition metric windows kept
+	// +kubebuilder:validation:Minimum=1
+	NumPartitionMetricsWindows *int32 `json:"numPartitionMetricsWindows,omitempty"`
+	// MinSamplesPerPartitionMetricsWindow is the number of samples a partition metric window has to have to be valid
+	// +kubebuilder:validation:Minimum=1
+	MinSamplesPerPartitionMetricsWindow *int32 `json:"minSamplesPerPartitionMetricsWindow,omitempty"`
+	// BrokerMetricsWindowSeconds is the length of the windows the broker metrics are aggregated in
+	// +kubebuilder:validation:Minimum=1
+	BrokerMetricsWindowSeconds *int64 `json:"brokerMetricsWindowSeconds,omitempty"`
+	// NumBrokerMetricsWindows is the number of broker metric windows kept
+	// +kubebuilder:validation:Minimum=1
+	NumBrokerMetricsWindows *int32 `json:"numBrokerMetricsWindows,omitempty"`
+	// MinSamplesPerBrokerMetricsWindow is the number of samples a broker metric window has to have to be valid
+	// +kubebuilder:validation:Minimum=1
+	MinSamplesPerBrokerMetricsWindow *int32 `json:"minSamplesPerBrokerMetricsWindow,omitempty"`
+	// PartitionSampleStoreTopic is the topic the partition metric samples are stored in
+	PartitionSampleStoreTopic string `json:"partitionSampleStoreTopic,omitempty"`
+	// BrokerSampleStoreTopic is the topic the broker metric samples are stored in
+	BrokerSampleStoreTopic string `json:"brokerSampleStoreTopic,omitempty"`
+	// SampleStoreTopicReplicationFactor is the replication factor of the sample store topics
+	// +kubebuilder:validation:Minimum=1
+	SampleStoreTopicReplicationFactor *int32 `json:"sampleStoreTopicReplicationFactor,omitempty"`
+}
+
+// SelfHealingConfig defines the recovery of the offline replicas of the brokers which are lost permanently,
+// e.g. because their volumes or nodes are gone
+type SelfHealingConfig struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// GracePeriodSeconds is the time a broker has to have offline replicas for before it is healed, defaults to 600
+	// +kubebuilder:validation:Minimum=0
+	GracePeriodSeconds *int32 `json:"gracePeriodSeconds,omitempty"`
+	// Action is the CruiseControl operation recovering the offline replicas of the broker, defaults to FixOfflineReplicas
+	// +kubebuilder:validation:Enum=FixOfflineReplicas;RemoveBroker
+	Action SelfHealingAction `json:"action,omitempty"`
+	// ReplaceBroker replaces the lost broker in the spec with a broker of a fresh id once its replicas have been recovered
+	ReplaceBroker bool `json:"replaceBroker,omitempty"`
+}
+
+// IsEnabled returns true when the self-healing is enabled
+func (c *SelfHealingConfig) IsEnabled() bool {
+	return c != nil && c.Enabled
+}
+
+// GetGracePeriod returns the time a broker has to have offline replicas for before it is healed
+func (c *SelfHealingConfig) GetGracePeriod() time.Duration {
+	if c == nil || c.GracePeriodSeconds == nil {
+		return 10 * time.Minute
+	}
+	return time.Duration(*c.GracePeriodSeconds) * time.Second
+}
+
+// GetAction returns the CruiseControl operation recovering the offline replicas, defaults to FixOfflineReplicas
+func (c *SelfHealingConfig) GetAction() SelfHealingAction {
+	if c == nil || c.Action == "" {
+		return SelfHealingActionFixOfflineReplicas
+	}
+	return c.Action
+}
+
+// CruiseControlAPIConfig defines the connection of the operator to the REST API of CruiseControl
+type CruiseControlAPIConfig struct {
+	// TLS enables https towards CruiseControl
+	TLS bool `json:"tls,omitempty"`
+	// TLSSecretName is the name of the secret holding the CA certificate (ca.crt) CruiseControl is verified with
+	// and optionally the client certificate of the operator (tls.crt and tls.key), the system CAs are used when empty
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+	// InsecureSkipVerify disables the verification of the certificate of CruiseControl
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+	// BasicAuthSecretName is the name of the secret holding the username and password keys
+	// the operator authenticates to CruiseControl with
+	BasicAuthSecretName string `json:"basicAuthSecretName,omitempty"`
+	// RequestTimeoutSeconds is the timeout of the requests sent to CruiseControl, defaults to 30
+	// +kubebuilder:validation:Minimum=1
+	RequestTimeoutSeconds int32 `json:"requestTimeoutSeconds,omitempty"`
+}
+
+// GetRequestTimeoutSeconds returns the timeout of the requests sent to CruiseControl
+func (c *CruiseControlAPIConfig) GetRequestTimeoutSeconds() int32 {
+	if c == nil || c.RequestTimeoutSeconds == 0 {
+		return 30
+	}
+	return c.RequestTimeoutSeconds
 }
 
 // CruiseControlTaskSpec specifies the configuration of the CC Tasks
 type CruiseControlTaskSpec struct {
 	// RetryDurationMinutes describes the amount of time the Operator waits for the task
 	RetryDurationMinutes int `json:"RetryDurationMinutes"`
+	// RetryBackoffSeconds is the time the operator waits for before resubmitting a failed or timed out
+	// task, it is doubled after every consecutive failure of the operation. Defaults to 30.
+	// +kubebuilder:validation:Minimum=0
+	RetryBackoffSeconds *int32 `json:"retryBackoffSeconds,omitempty"`
+	// MaxRetryBackoffSeconds limits the backoff of the resubmitted tasks, defaults to 600
+	// +kubebuilder:validation:Minimum=0
+	MaxRetryBackoffSeconds int32 `json:"maxRetryBackoffSeconds,omitempty"`
+	// TaskHistoryLimit is the number of finished tasks kept in the status of the cluster, defaults to 20
+	// +kubebuilder:validation:Minimum=0
+	TaskHistoryLimit int32 `json:"taskHistoryLimit,omitempty"`
+	// UpscalePolicy configures how the brokers added to the cluster are batched into CC tasks, the tasks
+	// are executed one after the other
+	UpscalePolicy *GracefulScalingPolicy `json:"upscalePolicy,omitempty"`
+	// DownscalePolicy configures how the brokers removed from the cluster are batched into CC tasks, the tasks
+	// are executed one after the other
+	DownscalePolicy *GracefulScalingPolicy `json:"downscalePolicy,omitempty"`
+}
+
+// GracefulScalingPolicy defines the batching of the graceful upscale or downscale operations
+type GracefulScalingPolicy struct {
+	// MaxBrokersPerTask limits the number of brokers handled by a single CC task, all the
+	// pending brokers are handled together when unset
+	// +kubebuilder:validation:Minimum=0
+	MaxBrokersPerTask int32 `json:"maxBrokersPerTask,omitempty"`
+	// InterleaveDiskRebalance lets the pending disk rebalances be submitted between the tasks of the
+	// operation, by default they wait until all the queued brokers have been handled. The disk
+	// rebalances never run in parallel with the scaling tasks.
+	InterleaveDiskRebalance bool `json:"interleaveDiskRebalance,omitempty"`
+	// ReplicationThrottle limits the replication traffic of the brokers in bytes/sec while the
+	// replicas are moved, the throttle configured in CC is used when unset
+	// +kubebuilder:validation:Minimum=1
+	ReplicationThrottle *int64 `json:"replicationThrottle,omitempty"`
+	// ExcludedTopics is a regular expression of the topics whose replicas are not moved by the task
+	ExcludedTopics string `json:"excludedTopics,omitempty"`
 }
 
 // TopicConfig holds info for topic configuration regarding partitions and replicationFactor
@@ -282,6 +624,27 @@ type ListenersConfig struct {
 	InternalListeners  []InternalListenerConfig `json:"internalListeners"`
 	SSLSecrets         *SSLSecrets              `json:"sslSecrets,omitempty"`
 	ServiceAnnotations map[string]string        `json:"serviceAnnotations,omitempty"`
+	// AdminSASL holds the credentials the operator uses to authenticate to the brokers
+	// when the listener used for inner broker communication is sasl_ssl or sasl_plaintext.
+	// The referenced credentials must exist in the cluster before the operator can manage it.
+	AdminSASL *AdminSASLConfig `json:"adminSasl,omitempty"`
+}
+
+// AdminSASLConfig defines the SASL credentials used by the operator
+type AdminSASLConfig struct {
+	// SecretName is the name of the secret holding the username and password keys
+	SecretName string `json:"secretName"`
+	// Mechanism is the SASL mechanism used by the operator, defaults to SCRAM-SHA-512
+	// +kubebuilder:validation:Enum={"PLAIN","SCRAM-SHA-256","SCRAM-SHA-512"}
+	Mechanism string `json:"mechanism,omitempty"`
+}
+
+// GetMechanism returns the SASL mechanism used by the operator
+func (c *AdminSASLConfig) GetMechanism() string {
+	if c.Mechanism == "" {
+		return "SCRAM-SHA-512"
+	}
+	return c.Mechanism
 }
 
 // GetServiceAnnotations returns a copy of the ServiceAnnotations field.
@@ -458,6 +821,7 @@ type ListenerStatus struct {
 // +kubebuilder:printcolumn:JSONPath=".status.rollingUpgradeStatus.lastSuccess",name="Last successful upgrade",type="string"
 // +kubebuilder:printcolumn:JSONPath=".status.rollingUpgradeStatus.errorCount",name="Upgrade error count",type="string"
 // +kubebuilder:printcolumn:JSONPath=".metadata.creationTimestamp",name="Age",type="date"
+// +kubebuilder:webhook:failurePolicy="fail",sideEffects="None",name="kafkaclusters.kafka.banzaicloud.io",path="/validate",mutating=false,resources={"kafkaclusters"},verbs={"create","update"},groups={"kafka.banzaicloud.io"},versions={"v1beta1"},admissionReviewVersions={"v1beta1"}
 
 // KafkaCluster is the Schema for the kafkaclusters API
 type KafkaCluster struct {
@@ -552,6 +916,60 @@ func (kSpec *KafkaClusterSpec) GetClusterImage() string {
 	return "ghcr.io/banzaicloud/kafka:2.13-2.8.0"
 }
 
+// GetRemovedBrokerPVCRetentionPolicy returns the retention policy of the volumes of the removed brokers, defaults to delete
+func (kSpec *KafkaClusterSpec) GetRemovedBrokerPVCRetentionPolicy() PVCRetentionPolicy {
+	if kSpec.RemovedBrokerPVCRetentionPolicy != "" {
+		return kSpec.RemovedBrokerPVCRetentionPolicy
+	}
+	return PVCRetentionPolicyDelete
+}
+
+// GetControl returns the control of the rolling upgrade, defaults to resume
+func (rConfig *RollingUpgradeConfig) GetControl() RollingUpgradeControl {
+	if rConfig.Control != "" {
+		return rConfig.Control
+	}
+	return RollingUpgradeResume
+}
+
+// GetProcessRoles returns the deduplicated and sorted KRaft process roles of the broker,
+// defaults to the combined broker and controller roles
+func (bConfig *BrokerConfig) GetProcessRoles() []ProcessRole {
+	if bConfig == nil || len(bConfig.ProcessRoles) == 0 {
+		return []ProcessRole{ProcessRoleBroker, ProcessRoleController}
+	}
+	roles := make([]ProcessRole, 0, len(bConfig.ProcessRoles))
+	for _, role := range []ProcessRole{ProcessRoleBroker, ProcessRoleController} {
+		for _, r := range bConfig.ProcessRoles {
+			if r == role {
+				roles = append(roles, role)
+				break
+			}
+		}
+	}
+	return roles
+}
+
+// IsBrokerNode returns true if the broker has the broker process role
+func (bConfig *BrokerConfig) IsBrokerNode() bool {
+	for _, role := range bConfig.GetProcessRoles() {
+		if role == ProcessRoleBroker {
+			return true
+		}
+	}
+	return false
+}
+
+// IsControllerNode returns true if the broker is a member of the KRaft controller quorum
+func (bConfig *BrokerConfig) IsControllerNode() bool {
+	for _, role := range bConfig.GetProcessRoles() {
+		if role == ProcessRoleController {
+			return true
+		}
+	}
+	return false
+}
+
 func (cTaskSpec *CruiseControlTaskSpec) GetDurationMinutes() float64 {
 	if cTaskSpec.RetryDurationMinutes == 0 {
 		return 5
@@ -559,6 +977,49 @@ func (cTaskSpec *CruiseControlTaskSpec) GetDurationMinutes() float64 {
 	return float64(cTaskSpec.RetryDurationMinutes)
 }
 
+// GetRetryBackoff returns the time a failed operation is resubmitted after, the backoff is doubled for
+// every consecutive failure up to the configured maximum
+func (cTaskSpec *CruiseControlTaskSpec) GetRetryBackoff(failedAttempts int32) time.Duration {
+	backoff := 30 * time.Second
+	if cTaskSpec.RetryBackoffSeconds != nil {
+		backoff = time.Duration(*cTaskSpec.RetryBackoffSeconds) * time.Second
+	}
+	maxBackoff := 10 * time.Minute
+	if cTaskSpec.MaxRetryBackoffSeconds > 0 {
+		maxBackoff = time.Duration(cTaskSpec.MaxRetryBackoffSeconds) * time.Second
+	}
+	for i := int32(1); i < failedAttempts && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		return maxBackoff
+	}
+	return backoff
+}
+
+// GetTaskHistoryLimit returns the number of finished tasks kept in the status of the cluster
+func (cTaskSpec *CruiseControlTaskSpec) GetTaskHistoryLimit() int {
+	if cTaskSpec.TaskHistoryLimit == 0 {
+		return 20
+	}
+	return int(cTaskSpec.TaskHistoryLimit)
+}
+
+// GetScalingPolicy returns the batching policy of the given operation, an empty policy is returned when it is unset
+func (cTaskSpec *CruiseControlTaskSpec) GetScalingPolicy(operation CruiseControlOperationType) GracefulScalingPolicy {
+	var policy *GracefulScalingPolicy
+	switch operation {
+	case CruiseControlOperationUpscale:
+		policy = cTaskSpec.UpscalePolicy
+	case CruiseControlOperationDownscale:
+		policy = cTaskSpec.DownscalePolicy
+	}
+	if policy == nil {
+		return GracefulScalingPolicy{}
+	}
+	return *policy
+}
+
 //GetLoadBalancerSourceRanges returns LoadBalancerSourceRanges to use for Envoy generated LoadBalancer
 func (eConfig *EnvoyConfig) GetLoadBalancerSourceRanges() []string {
 	return eConfig.LoadBalancerSourceRanges
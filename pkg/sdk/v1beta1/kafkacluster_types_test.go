@@ -17,6 +17,7 @@ package v1beta1
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"gotest.tools/assert"
 
@@ -425,3 +426,63 @@ func TestGetBrokerConfigEnvs(t *testing.T) {
 		t.Error("Expected:", expected, "Got:", result)
 	}
 }
+
+func TestGetBrokerConfigProcessRoles(t *testing.T) {
+	broker := Broker{
+		Id:                0,
+		BrokerConfigGroup: "default",
+		BrokerConfig: &BrokerConfig{
+			ProcessRoles: []ProcessRole{ProcessRoleController},
+		},
+	}
+
+	spec := KafkaClusterSpec{
+		BrokerConfigGroups: map[string]BrokerConfig{
+			"default": {
+				ProcessRoles: []ProcessRole{ProcessRoleController, ProcessRoleBroker},
+			},
+		},
+	}
+
+	result, err := broker.GetBrokerConfig(spec)
+	if err != nil {
+		t.Error("Error GetBrokerConfig throw an unexpected error")
+	}
+	expected := []ProcessRole{ProcessRoleBroker, ProcessRoleController}
+	if !reflect.DeepEqual(result.GetProcessRoles(), expected) {
+		t.Error("Expected:", expected, "Got:", result.GetProcessRoles())
+	}
+
+	var emptyConfig *BrokerConfig
+	if !emptyConfig.IsBrokerNode() || !emptyConfig.IsControllerNode() {
+		t.Error("Expected combined roles by default, got:", emptyConfig.GetProcessRoles())
+	}
+}
+
+func TestCruiseControlTaskSpecGetRetryBackoff(t *testing.T) {
+	spec := CruiseControlTaskSpec{}
+	for failedAttempts, expected := range map[int32]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		3:  2 * time.Minute,
+		10: 10 * time.Minute,
+	} {
+		if backoff := spec.GetRetryBackoff(failedAttempts); backoff != expected {
+			t.Errorf("Expected backoff %s after %d failures, got: %s", expected, failedAttempts, backoff)
+		}
+	}
+
+	backoff := int32(40)
+	spec = CruiseControlTaskSpec{RetryBackoffSeconds: &backoff, MaxRetryBackoffSeconds: 100}
+	if got := spec.GetRetryBackoff(2); got != 80*time.Second {
+		t.Error("Expected doubled backoff, got:", got)
+	}
+	if got := spec.GetRetryBackoff(3); got != 100*time.Second {
+		t.Error("Expected backoff to be capped, got:", got)
+	}
+
+	backoff = 0
+	if got := spec.GetRetryBackoff(5); got != 0 {
+		t.Error("Expected no backoff, got:", got)
+	}
+}
@@ -25,6 +25,21 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdminSASLConfig) DeepCopyInto(out *AdminSASLConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdminSASLConfig.
+func (in *AdminSASLConfig) DeepCopy() *AdminSASLConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AdminSASLConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AlertManagerConfig) DeepCopyInto(out *AlertManagerConfig) {
 	*out = *in
@@ -161,6 +176,11 @@ func (in *BrokerConfig) DeepCopyInto(out *BrokerConfig) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ProcessRoles != nil {
+		in, out := &in.ProcessRoles, &out.ProcessRoles
+		*out = make([]ProcessRole, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerConfig.
@@ -182,6 +202,9 @@ func (in *BrokerState) DeepCopyInto(out *BrokerState) {
 		*out = make(ExternalListenerConfigNames, len(*in))
 		copy(*out, *in)
 	}
+	out.GracefulRestartState = in.GracefulRestartState
+	in.ConfigChanges.DeepCopyInto(&out.ConfigChanges)
+	out.DecommissionStatus = in.DecommissionStatus
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerState.
@@ -231,10 +254,110 @@ func (in *Config) DeepCopy() *Config {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigChanges) DeepCopyInto(out *ConfigChanges) {
+	*out = *in
+	if in.ReadOnly != nil {
+		in, out := &in.ReadOnly, &out.ReadOnly
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PerBroker != nil {
+		in, out := &in.PerBroker, &out.PerBroker
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClusterWide != nil {
+		in, out := &in.ClusterWide, &out.ClusterWide
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Unknown != nil {
+		in, out := &in.Unknown, &out.Unknown
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigChanges.
+func (in *ConfigChanges) DeepCopy() *ConfigChanges {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigChanges)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlAPIConfig) DeepCopyInto(out *CruiseControlAPIConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlAPIConfig.
+func (in *CruiseControlAPIConfig) DeepCopy() *CruiseControlAPIConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlAPIConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlAnomalyDetectionConfig) DeepCopyInto(out *CruiseControlAnomalyDetectionConfig) {
+	*out = *in
+	if in.IntervalSeconds != nil {
+		in, out := &in.IntervalSeconds, &out.IntervalSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.GoalViolationIntervalSeconds != nil {
+		in, out := &in.GoalViolationIntervalSeconds, &out.GoalViolationIntervalSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MetricAnomalyIntervalSeconds != nil {
+		in, out := &in.MetricAnomalyIntervalSeconds, &out.MetricAnomalyIntervalSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.DiskFailureIntervalSeconds != nil {
+		in, out := &in.DiskFailureIntervalSeconds, &out.DiskFailureIntervalSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.BrokerFailureAlertThresholdSeconds != nil {
+		in, out := &in.BrokerFailureAlertThresholdSeconds, &out.BrokerFailureAlertThresholdSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.BrokerFailureSelfHealingThresholdSeconds != nil {
+		in, out := &in.BrokerFailureSelfHealingThresholdSeconds, &out.BrokerFailureSelfHealingThresholdSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.SelfHealing != nil {
+		in, out := &in.SelfHealing, &out.SelfHealing
+		*out = new(CruiseControlSelfHealingConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlAnomalyDetectionConfig.
+func (in *CruiseControlAnomalyDetectionConfig) DeepCopy() *CruiseControlAnomalyDetectionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlAnomalyDetectionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CruiseControlConfig) DeepCopyInto(out *CruiseControlConfig) {
 	*out = *in
-	out.CruiseControlTaskSpec = in.CruiseControlTaskSpec
+	in.CruiseControlTaskSpec.DeepCopyInto(&out.CruiseControlTaskSpec)
 	if in.Resources != nil {
 		in, out := &in.Resources, &out.Resources
 		*out = new(v1.ResourceRequirements)
@@ -302,6 +425,36 @@ func (in *CruiseControlConfig) DeepCopyInto(out *CruiseControlConfig) {
 		*out = new(v1.SecurityContext)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.APIConfig != nil {
+		in, out := &in.APIConfig, &out.APIConfig
+		*out = new(CruiseControlAPIConfig)
+		**out = **in
+	}
+	if in.SelfHealing != nil {
+		in, out := &in.SelfHealing, &out.SelfHealing
+		*out = new(SelfHealingConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Goals != nil {
+		in, out := &in.Goals, &out.Goals
+		*out = new(CruiseControlGoalsConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AnomalyDetection != nil {
+		in, out := &in.AnomalyDetection, &out.AnomalyDetection
+		*out = new(CruiseControlAnomalyDetectionConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MetricSampler != nil {
+		in, out := &in.MetricSampler, &out.MetricSampler
+		*out = new(CruiseControlMetricSamplerConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NetworkCapacityLabels != nil {
+		in, out := &in.NetworkCapacityLabels, &out.NetworkCapacityLabels
+		*out = new(NetworkCapacityLabels)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlConfig.
@@ -314,9 +467,204 @@ func (in *CruiseControlConfig) DeepCopy() *CruiseControlConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlGoalsConfig) DeepCopyInto(out *CruiseControlGoalsConfig) {
+	*out = *in
+	if in.Goals != nil {
+		in, out := &in.Goals, &out.Goals
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DefaultGoals != nil {
+		in, out := &in.DefaultGoals, &out.DefaultGoals
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.HardGoals != nil {
+		in, out := &in.HardGoals, &out.HardGoals
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AnomalyDetectionGoals != nil {
+		in, out := &in.AnomalyDetectionGoals, &out.AnomalyDetectionGoals
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SelfHealingGoals != nil {
+		in, out := &in.SelfHealingGoals, &out.SelfHealingGoals
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlGoalsConfig.
+func (in *CruiseControlGoalsConfig) DeepCopy() *CruiseControlGoalsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlGoalsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlMetricSamplerConfig) DeepCopyInto(out *CruiseControlMetricSamplerConfig) {
+	*out = *in
+	if in.SamplingIntervalSeconds != nil {
+		in, out := &in.SamplingIntervalSeconds, &out.SamplingIntervalSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.PartitionMetricsWindowSeconds != nil {
+		in, out := &in.PartitionMetricsWindowSeconds, &out.PartitionMetricsWindowSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.NumPartitionMetricsWindows != nil {
+		in, out := &in.NumPartitionMetricsWindows, &out.NumPartitionMetricsWindows
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinSamplesPerPartitionMetricsWindow != nil {
+		in, out := &in.MinSamplesPerPartitionMetricsWindow, &out.MinSamplesPerPartitionMetricsWindow
+		*out = new(int32)
+		**out = **in
+	}
+	if in.BrokerMetricsWindowSeconds != nil {
+		in, out := &in.BrokerMetricsWindowSeconds, &out.BrokerMetricsWindowSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.NumBrokerMetricsWindows != nil {
+		in, out := &in.NumBrokerMetricsWindows, &out.NumBrokerMetricsWindows
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinSamplesPerBrokerMetricsWindow != nil {
+		in, out := &in.MinSamplesPerBrokerMetricsWindow, &out.MinSamplesPerBrokerMetricsWindow
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SampleStoreTopicReplicationFactor != nil {
+		in, out := &in.SampleStoreTopicReplicationFactor, &out.SampleStoreTopicReplicationFactor
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlMetricSamplerConfig.
+func (in *CruiseControlMetricSamplerConfig) DeepCopy() *CruiseControlMetricSamplerConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlMetricSamplerConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlOperation) DeepCopyInto(out *CruiseControlOperation) {
+	*out = *in
+	if in.MountPaths != nil {
+		in, out := &in.MountPaths, &out.MountPaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlOperation.
+func (in *CruiseControlOperation) DeepCopy() *CruiseControlOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlSelfHealingConfig) DeepCopyInto(out *CruiseControlSelfHealingConfig) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.BrokerFailure != nil {
+		in, out := &in.BrokerFailure, &out.BrokerFailure
+		*out = new(bool)
+		**out = **in
+	}
+	if in.GoalViolation != nil {
+		in, out := &in.GoalViolation, &out.GoalViolation
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MetricAnomaly != nil {
+		in, out := &in.MetricAnomaly, &out.MetricAnomaly
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DiskFailure != nil {
+		in, out := &in.DiskFailure, &out.DiskFailure
+		*out = new(bool)
+		**out = **in
+	}
+	if in.TopicAnomaly != nil {
+		in, out := &in.TopicAnomaly, &out.TopicAnomaly
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlSelfHealingConfig.
+func (in *CruiseControlSelfHealingConfig) DeepCopy() *CruiseControlSelfHealingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlSelfHealingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlTaskRecord) DeepCopyInto(out *CruiseControlTaskRecord) {
+	*out = *in
+	if in.BrokerIds != nil {
+		in, out := &in.BrokerIds, &out.BrokerIds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlTaskRecord.
+func (in *CruiseControlTaskRecord) DeepCopy() *CruiseControlTaskRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlTaskRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CruiseControlTaskSpec) DeepCopyInto(out *CruiseControlTaskSpec) {
 	*out = *in
+	if in.RetryBackoffSeconds != nil {
+		in, out := &in.RetryBackoffSeconds, &out.RetryBackoffSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.UpscalePolicy != nil {
+		in, out := &in.UpscalePolicy, &out.UpscalePolicy
+		*out = new(GracefulScalingPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DownscalePolicy != nil {
+		in, out := &in.DownscalePolicy, &out.DownscalePolicy
+		*out = new(GracefulScalingPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlTaskSpec.
@@ -329,6 +677,21 @@ func (in *CruiseControlTaskSpec) DeepCopy() *CruiseControlTaskSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DecommissionStatus) DeepCopyInto(out *DecommissionStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DecommissionStatus.
+func (in *DecommissionStatus) DeepCopy() *DecommissionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DecommissionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DisruptionBudget) DeepCopyInto(out *DisruptionBudget) {
 	*out = *in
@@ -473,6 +836,41 @@ func (in *GracefulActionState) DeepCopy() *GracefulActionState {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GracefulRestartState) DeepCopyInto(out *GracefulRestartState) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GracefulRestartState.
+func (in *GracefulRestartState) DeepCopy() *GracefulRestartState {
+	if in == nil {
+		return nil
+	}
+	out := new(GracefulRestartState)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GracefulScalingPolicy) DeepCopyInto(out *GracefulScalingPolicy) {
+	*out = *in
+	if in.ReplicationThrottle != nil {
+		in, out := &in.ReplicationThrottle, &out.ReplicationThrottle
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GracefulScalingPolicy.
+func (in *GracefulScalingPolicy) DeepCopy() *GracefulScalingPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(GracefulScalingPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IngressConfig) DeepCopyInto(out *IngressConfig) {
 	*out = *in
@@ -703,6 +1101,11 @@ func (in *KafkaClusterSpec) DeepCopyInto(out *KafkaClusterSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.DefaultUserQuotas != nil {
+		in, out := &in.DefaultUserQuotas, &out.DefaultUserQuotas
+		*out = new(UserQuotas)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaClusterSpec.
@@ -725,8 +1128,32 @@ func (in *KafkaClusterStatus) DeepCopyInto(out *KafkaClusterStatus) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
-	out.RollingUpgrade = in.RollingUpgrade
+	in.RollingUpgrade.DeepCopyInto(&out.RollingUpgrade)
 	in.ListenerStatuses.DeepCopyInto(&out.ListenerStatuses)
+	if in.SelfHealingIncidents != nil {
+		in, out := &in.SelfHealingIncidents, &out.SelfHealingIncidents
+		*out = make([]SelfHealingIncident, len(*in))
+		copy(*out, *in)
+	}
+	if in.CruiseControlOperationQueue != nil {
+		in, out := &in.CruiseControlOperationQueue, &out.CruiseControlOperationQueue
+		*out = make([]CruiseControlOperation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CruiseControlTaskHistory != nil {
+		in, out := &in.CruiseControlTaskHistory, &out.CruiseControlTaskHistory
+		*out = make([]CruiseControlTaskRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UpgradeStatus != nil {
+		in, out := &in.UpgradeStatus, &out.UpgradeStatus
+		*out = new(KafkaUpgradeStatus)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaClusterStatus.
@@ -739,6 +1166,21 @@ func (in *KafkaClusterStatus) DeepCopy() *KafkaClusterStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaUpgradeStatus) DeepCopyInto(out *KafkaUpgradeStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaUpgradeStatus.
+func (in *KafkaUpgradeStatus) DeepCopy() *KafkaUpgradeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaUpgradeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KafkaVersion) DeepCopyInto(out *KafkaVersion) {
 	*out = *in
@@ -860,6 +1302,11 @@ func (in *ListenersConfig) DeepCopyInto(out *ListenersConfig) {
 			(*out)[key] = val
 		}
 	}
+	if in.AdminSASL != nil {
+		in, out := &in.AdminSASL, &out.AdminSASL
+		*out = new(AdminSASLConfig)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenersConfig.
@@ -887,6 +1334,21 @@ func (in *MonitoringConfig) DeepCopy() *MonitoringConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkCapacityLabels) DeepCopyInto(out *NetworkCapacityLabels) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkCapacityLabels.
+func (in *NetworkCapacityLabels) DeepCopy() *NetworkCapacityLabels {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkCapacityLabels)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetworkConfig) DeepCopyInto(out *NetworkConfig) {
 	*out = *in
@@ -902,6 +1364,26 @@ func (in *NetworkConfig) DeepCopy() *NetworkConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PendingBrokerRestart) DeepCopyInto(out *PendingBrokerRestart) {
+	*out = *in
+	if in.Reasons != nil {
+		in, out := &in.Reasons, &out.Reasons
+		*out = make([]RestartReason, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PendingBrokerRestart.
+func (in *PendingBrokerRestart) DeepCopy() *PendingBrokerRestart {
+	if in == nil {
+		return nil
+	}
+	out := new(PendingBrokerRestart)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RackAwareness) DeepCopyInto(out *RackAwareness) {
 	*out = *in
@@ -940,6 +1422,23 @@ func (in *RollingUpgradeConfig) DeepCopy() *RollingUpgradeConfig {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RollingUpgradeStatus) DeepCopyInto(out *RollingUpgradeStatus) {
 	*out = *in
+	if in.RestartedBrokers != nil {
+		in, out := &in.RestartedBrokers, &out.RestartedBrokers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PendingBrokers != nil {
+		in, out := &in.PendingBrokers, &out.PendingBrokers
+		*out = make([]PendingBrokerRestart, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CompletedBrokers != nil {
+		in, out := &in.CompletedBrokers, &out.CompletedBrokers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollingUpgradeStatus.
@@ -972,6 +1471,41 @@ func (in *SSLSecrets) DeepCopy() *SSLSecrets {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelfHealingConfig) DeepCopyInto(out *SelfHealingConfig) {
+	*out = *in
+	if in.GracePeriodSeconds != nil {
+		in, out := &in.GracePeriodSeconds, &out.GracePeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelfHealingConfig.
+func (in *SelfHealingConfig) DeepCopy() *SelfHealingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SelfHealingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelfHealingIncident) DeepCopyInto(out *SelfHealingIncident) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelfHealingIncident.
+func (in *SelfHealingIncident) DeepCopy() *SelfHealingIncident {
+	if in == nil {
+		return nil
+	}
+	out := new(SelfHealingIncident)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StorageConfig) DeepCopyInto(out *StorageConfig) {
 	*out = *in
@@ -1007,6 +1541,36 @@ func (in *TopicConfig) DeepCopy() *TopicConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserQuotas) DeepCopyInto(out *UserQuotas) {
+	*out = *in
+	if in.ProducerByteRate != nil {
+		in, out := &in.ProducerByteRate, &out.ProducerByteRate
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ConsumerByteRate != nil {
+		in, out := &in.ConsumerByteRate, &out.ConsumerByteRate
+		*out = new(int64)
+		**out = **in
+	}
+	if in.RequestPercentage != nil {
+		in, out := &in.RequestPercentage, &out.RequestPercentage
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserQuotas.
+func (in *UserQuotas) DeepCopy() *UserQuotas {
+	if in == nil {
+		return nil
+	}
+	out := new(UserQuotas)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VaultConfig) DeepCopyInto(out *VaultConfig) {
 	*out = *in
@@ -25,6 +25,11 @@ func UseSSL(cluster *v1beta1.KafkaCluster) bool {
 	return cluster.Spec.ListenersConfig.InternalListeners[determineInternalListenerForInnerCom(cluster.Spec.ListenersConfig.InternalListeners)].Type.IsSSL()
 }
 
+// UseSASL returns true if the listener used for inner broker communication requires SASL authentication
+func UseSASL(cluster *v1beta1.KafkaCluster) bool {
+	return cluster.Spec.ListenersConfig.InternalListeners[determineInternalListenerForInnerCom(cluster.Spec.ListenersConfig.InternalListeners)].Type.IsSasl()
+}
+
 func determineInternalListenerForInnerCom(internalListeners []v1beta1.InternalListenerConfig) int {
 	for id, val := range internalListeners {
 		if val.UsedForInnerBrokerCommunication {
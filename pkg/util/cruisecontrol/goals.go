@@ -0,0 +1,118 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cruisecontrol
+
+import (
+	"strings"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+)
+
+const (
+	goalsPackage         = "com.linkedin.kafka.cruisecontrol.analyzer.goals."
+	kafkaAssignerPackage = "com.linkedin.kafka.cruisecontrol.analyzer.kafkaassigner."
+)
+
+// KnownGoals maps the simple names of the goals shipped with CruiseControl to their class names
+var KnownGoals = map[string]string{
+	"RackAwareGoal":                          goalsPackage + "RackAwareGoal",
+	"RackAwareDistributionGoal":              goalsPackage + "RackAwareDistributionGoal",
+	"MinTopicLeadersPerBrokerGoal":           goalsPackage + "MinTopicLeadersPerBrokerGoal",
+	"ReplicaCapacityGoal":                    goalsPackage + "ReplicaCapacityGoal",
+	"DiskCapacityGoal":                       goalsPackage + "DiskCapacityGoal",
+	"NetworkInboundCapacityGoal":             goalsPackage + "NetworkInboundCapacityGoal",
+	"NetworkOutboundCapacityGoal":            goalsPackage + "NetworkOutboundCapacityGoal",
+	"CpuCapacityGoal":                        goalsPackage + "CpuCapacityGoal",
+	"ReplicaDistributionGoal":                goalsPackage + "ReplicaDistributionGoal",
+	"PotentialNwOutGoal":                     goalsPackage + "PotentialNwOutGoal",
+	"DiskUsageDistributionGoal":              goalsPackage + "DiskUsageDistributionGoal",
+	"NetworkInboundUsageDistributionGoal":    goalsPackage + "NetworkInboundUsageDistributionGoal",
+	"NetworkOutboundUsageDistributionGoal":   goalsPackage + "NetworkOutboundUsageDistributionGoal",
+	"CpuUsageDistributionGoal":               goalsPackage + "CpuUsageDistributionGoal",
+	"TopicReplicaDistributionGoal":           goalsPackage + "TopicReplicaDistributionGoal",
+	"LeaderReplicaDistributionGoal":          goalsPackage + "LeaderReplicaDistributionGoal",
+	"LeaderBytesInDistributionGoal":          goalsPackage + "LeaderBytesInDistributionGoal",
+	"PreferredLeaderElectionGoal":            goalsPackage + "PreferredLeaderElectionGoal",
+	"IntraBrokerDiskCapacityGoal":            goalsPackage + "IntraBrokerDiskCapacityGoal",
+	"IntraBrokerDiskUsageDistributionGoal":   goalsPackage + "IntraBrokerDiskUsageDistributionGoal",
+	"KafkaAssignerDiskUsageDistributionGoal": kafkaAssignerPackage + "KafkaAssignerDiskUsageDistributionGoal",
+	"KafkaAssignerEvenRackAwareGoal":         kafkaAssignerPackage + "KafkaAssignerEvenRackAwareGoal",
+}
+
+// ResolveGoals returns the class names of the given goals, the goals shipped with CruiseControl may be given by
+// their simple names while the fully qualified class names, including the ones of custom goals, are kept unchanged.
+// The unknown simple names are left out and returned as an error.
+func ResolveGoals(goals []string) ([]string, error) {
+	if len(goals) == 0 {
+		return nil, nil
+	}
+
+	var unknown, classes []string
+	for _, goal := range goals {
+		goal = strings.TrimSpace(goal)
+		if class, ok := KnownGoals[goal]; ok {
+			classes = append(classes, class)
+		} else if strings.Contains(goal, ".") {
+			classes = append(classes, goal)
+		} else {
+			unknown = append(unknown, goal)
+		}
+	}
+	if len(unknown) > 0 {
+		return classes, errors.NewWithDetails("unknown cruise control goals", "goals", strings.Join(unknown, ","))
+	}
+	return classes, nil
+}
+
+// ValidateGoals checks that every goal of the config can be resolved and the goals of the other lists are listed
+// in the goals when those are given, as CruiseControl fails to start otherwise
+func ValidateGoals(config *v1beta1.CruiseControlGoalsConfig) error {
+	if config == nil {
+		return nil
+	}
+	goals, err := ResolveGoals(config.Goals)
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "invalid goals", "list", "goals")
+	}
+	allowed := make(map[string]bool, len(goals))
+	for _, goal := range goals {
+		allowed[goal] = true
+	}
+	for _, list := range []struct {
+		name  string
+		goals []string
+	}{
+		{"defaultGoals", config.DefaultGoals},
+		{"hardGoals", config.HardGoals},
+		{"anomalyDetectionGoals", config.AnomalyDetectionGoals},
+		{"selfHealingGoals", config.SelfHealingGoals},
+	} {
+		classes, err := ResolveGoals(list.goals)
+		if err != nil {
+			return errors.WrapIfWithDetails(err, "invalid goals", "list", list.name)
+		}
+		if len(goals) == 0 {
+			continue
+		}
+		for _, goal := range classes {
+			if !allowed[goal] {
+				return errors.NewWithDetails("goal is not listed in goals", "list", list.name, "goal", goal)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Shopify/sarama"
+
+	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
+	"github.com/banzaicloud/kafka-operator/pkg/errorfactory"
+)
+
+// AclPatternTypeMapping maps patternType from v1alpha1.KafkaPatternType to sarama.AclResourcePatternType
+func AclPatternTypeMapping(patternType v1alpha1.KafkaPatternType) sarama.AclResourcePatternType {
+	switch patternType {
+	case v1alpha1.KafkaPatternTypeAny:
+		return sarama.AclPatternAny
+	case v1alpha1.KafkaPatternTypeMatch:
+		return sarama.AclPatternMatch
+	case v1alpha1.KafkaPatternTypeLiteral:
+		return sarama.AclPatternLiteral
+	case v1alpha1.KafkaPatternTypePrefixed:
+		return sarama.AclPatternPrefixed
+	default:
+		return sarama.AclPatternUnknown
+	}
+}
+
+// clusterResourceName is the resource name of the cluster in ACLs
+const clusterResourceName = "kafka-cluster"
+
+// UserACLEntry is a single ACL of a user on a resource
+type UserACLEntry struct {
+	Resource sarama.Resource
+	Acl      sarama.Acl
+}
+
+// String returns the raw representation of the ACL used in the KafkaUser status
+func (e UserACLEntry) String() string {
+	resourceType := e.Resource.ResourceType
+	patternType := e.Resource.ResourcePatternType
+	operation := e.Acl.Operation
+	permissionType := e.Acl.PermissionType
+	return fmt.Sprintf("%s,%s,%s,%s,%s,%s,%s", e.Acl.Principal, resourceType.String(),
+		strings.ToUpper(patternType.String()), e.Resource.ResourceName, operation.String(), permissionType.String(), e.Acl.Host)
+}
+
+// AclResourceTypeMapping maps resourceType from v1alpha1.KafkaResourceType to sarama.AclResourceType
+func AclResourceTypeMapping(resourceType v1alpha1.KafkaResourceType) sarama.AclResourceType {
+	switch resourceType {
+	case v1alpha1.KafkaResourceTypeTopic:
+		return sarama.AclResourceTopic
+	case v1alpha1.KafkaResourceTypeGroup:
+		return sarama.AclResourceGroup
+	case v1alpha1.KafkaResourceTypeTransactionalID:
+		return sarama.AclResourceTransactionalID
+	case v1alpha1.KafkaResourceTypeCluster:
+		return sarama.AclResourceCluster
+	default:
+		return sarama.AclResourceUnknown
+	}
+}
+
+// AclOperationMapping maps operation from v1alpha1.KafkaACLOperation to sarama.AclOperation
+func AclOperationMapping(operation v1alpha1.KafkaACLOperation) sarama.AclOperation {
+	switch operation {
+	case v1alpha1.KafkaACLOperationAll:
+		return sarama.AclOperationAll
+	case v1alpha1.KafkaACLOperationRead:
+		return sarama.AclOperationRead
+	case v1alpha1.KafkaACLOperationWrite:
+		return sarama.AclOperationWrite
+	case v1alpha1.KafkaACLOperationCreate:
+		return sarama.AclOperationCreate
+	case v1alpha1.KafkaACLOperationDelete:
+		return sarama.AclOperationDelete
+	case v1alpha1.KafkaACLOperationAlter:
+		return sarama.AclOperationAlter
+	case v1alpha1.KafkaACLOperationDescribe:
+		return sarama.AclOperationDescribe
+	case v1alpha1.KafkaACLOperationClusterAction:
+		return sarama.AclOperationClusterAction
+	case v1alpha1.KafkaACLOperationDescribeConfigs:
+		return sarama.AclOperationDescribeConfigs
+	case v1alpha1.KafkaACLOperationAlterConfigs:
+		return sarama.AclOperationAlterConfigs
+	case v1alpha1.KafkaACLOperationIdempotentWrite:
+		return sarama.AclOperationIdempotentWrite
+	default:
+		return sarama.AclOperationUnknown
+	}
+}
+
+// AclPermissionTypeMapping maps permissionType from v1alpha1.KafkaPermissionType to sarama.AclPermissionType
+func AclPermissionTypeMapping(permissionType v1alpha1.KafkaPermissionType) sarama.AclPermissionType {
+	switch permissionType {
+	case v1alpha1.KafkaPermissionTypeAllow:
+		return sarama.AclPermissionAllow
+	case v1alpha1.KafkaPermissionTypeDeny:
+		return sarama.AclPermissionDeny
+	default:
+		return sarama.AclPermissionUnknown
+	}
+}
+
+// DesiredUserACLs returns every ACL of the user derived from its topic grants and fine-grained ACLs
+func DesiredUserACLs(dn string, spec *v1alpha1.KafkaUserSpec) ([]UserACLEntry, error) {
+	principal := fmt.Sprintf("User:%s", dn)
+	entries := make([]UserACLEntry, 0)
+	add := func(resource sarama.Resource, operation sarama.AclOperation, permissionType sarama.AclPermissionType, host string) {
+		entry := UserACLEntry{
+			Resource: resource,
+			Acl: sarama.Acl{
+				Principal:      principal,
+				Host:           host,
+				Operation:      operation,
+				PermissionType: permissionType,
+			},
+		}
+		for _, e := range entries {
+			if e == entry {
+				return
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	for _, grant := range spec.TopicGrants {
+		patternType := grant.PatternType
+		if patternType == "" {
+			patternType = v1alpha1.KafkaPatternTypeDefault
+		}
+		aclPatternType := AclPatternTypeMapping(patternType)
+		if aclPatternType == sarama.AclPatternUnknown {
+			return nil, errorfactory.New(errorfactory.InternalError{}, fmt.Errorf("unknown type: %s", patternType), "unrecognized pattern type")
+		}
+		topic := sarama.Resource{
+			ResourceType:        sarama.AclResourceTopic,
+			ResourceName:        grant.TopicName,
+			ResourcePatternType: aclPatternType,
+		}
+		add(topic, sarama.AclOperationDescribe, sarama.AclPermissionAllow, "*")
+		add(topic, sarama.AclOperationDescribeConfigs, sarama.AclPermissionAllow, "*")
+		switch grant.AccessType {
+		case v1alpha1.KafkaAccessTypeRead:
+			add(topic, sarama.AclOperationRead, sarama.AclPermissionAllow, "*")
+			// consumer groups are granted explicitly when the user has group ACLs
+			if !spec.HasGroupACLs() {
+				add(sarama.Resource{
+					ResourceType:        sarama.AclResourceGroup,
+					ResourceName:        "*",
+					ResourcePatternType: sarama.AclPatternLiteral,
+				}, sarama.AclOperationRead, sarama.AclPermissionAllow, "*")
+			}
+		case v1alpha1.KafkaAccessTypeWrite:
+			add(topic, sarama.AclOperationWrite, sarama.AclPermissionAllow, "*")
+			add(topic, sarama.AclOperationCreate, sarama.AclPermissionAllow, "*")
+		default:
+			return nil, errorfactory.New(errorfactory.InternalError{}, fmt.Errorf("unknown type: %s", grant.AccessType), "unrecognized access type")
+		}
+	}
+
+	for _, acl := range spec.ACLs {
+		resourceType := AclResourceTypeMapping(acl.ResourceType)
+		if resourceType == sarama.AclResourceUnknown {
+			return nil, errorfactory.New(errorfactory.InternalError{}, fmt.Errorf("unknown type: %s", acl.ResourceType), "unrecognized resource type")
+		}
+		patternType := AclPatternTypeMapping(acl.GetPatternType())
+		if patternType == sarama.AclPatternUnknown {
+			return nil, errorfactory.New(errorfactory.InternalError{}, fmt.Errorf("unknown type: %s", acl.PatternType), "unrecognized pattern type")
+		}
+		permissionType := AclPermissionTypeMapping(acl.GetPermissionType())
+		if permissionType == sarama.AclPermissionUnknown {
+			return nil, errorfactory.New(errorfactory.InternalError{}, fmt.Errorf("unknown type: %s", acl.PermissionType), "unrecognized permission type")
+		}
+		resource := sarama.Resource{
+			ResourceType:        resourceType,
+			ResourceName:        acl.ResourceName,
+			ResourcePatternType: patternType,
+		}
+		if resourceType == sarama.AclResourceCluster {
+			resource.ResourceName = clusterResourceName
+			resource.ResourcePatternType = sarama.AclPatternLiteral
+		}
+		for _, op := range acl.Operations {
+			operation := AclOperationMapping(op)
+			if operation == sarama.AclOperationUnknown {
+				return nil, errorfactory.New(errorfactory.InternalError{}, fmt.Errorf("unknown type: %s", op), "unrecognized operation")
+			}
+			add(resource, operation, permissionType, acl.GetHost())
+		}
+	}
+	return entries, nil
+}
+
+// ACLStrings converts ACL entries to raw strings for a CR status
+func ACLStrings(entries []UserACLEntry) []string {
+	out := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		out = append(out, entry.String())
+	}
+	return out
+}
@@ -0,0 +1,148 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
+)
+
+func TestDesiredUserACLsTopicGrantTypes(t *testing.T) {
+	validAccessTypes := []v1alpha1.KafkaAccessType{
+		"read",
+		"write"}
+	invalidAccessTypes := []v1alpha1.KafkaAccessType{
+		"",
+		"helloWorld"}
+	allAccessTypes := append(validAccessTypes, invalidAccessTypes...)
+
+	validPatternTypes := []v1alpha1.KafkaPatternType{
+		"any",
+		"literal",
+		"match",
+		"prefixed",
+		""}
+	invalidPatternTypes := []v1alpha1.KafkaPatternType{
+		"helloWorld"}
+	allPatternTypes := append(validPatternTypes, invalidPatternTypes...)
+
+	desiredACLs := func(accessType v1alpha1.KafkaAccessType, patternType v1alpha1.KafkaPatternType) error {
+		_, err := DesiredUserACLs("test-user", &v1alpha1.KafkaUserSpec{
+			TopicGrants: []v1alpha1.UserTopicGrant{
+				{TopicName: "test-topic", AccessType: accessType, PatternType: patternType},
+			},
+		})
+		return err
+	}
+
+	// Test all valid combinations of accessType and patternType
+	for _, accessType := range validAccessTypes {
+		for _, patternType := range validPatternTypes {
+			if err := desiredACLs(accessType, patternType); err != nil {
+				t.Error("Expected no error, got:", err)
+			}
+		}
+	}
+	// Test invalid accessTypes against all patternTypes
+	for _, accessType := range invalidAccessTypes {
+		for _, patternType := range allPatternTypes {
+			if err := desiredACLs(accessType, patternType); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		}
+	}
+	// Test invalid patternTypes against all accessTypes
+	for _, patternType := range invalidPatternTypes {
+		for _, accessType := range allAccessTypes {
+			if err := desiredACLs(accessType, patternType); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		}
+	}
+}
+
+func TestDesiredUserACLs(t *testing.T) {
+	spec := &v1alpha1.KafkaUserSpec{
+		TopicGrants: []v1alpha1.UserTopicGrant{
+			{TopicName: "test-topic", AccessType: v1alpha1.KafkaAccessTypeRead},
+		},
+	}
+	entries, err := DesiredUserACLs("test-user", spec)
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	expected := []string{
+		"User:test-user,Topic,LITERAL,test-topic,Describe,Allow,*",
+		"User:test-user,Topic,LITERAL,test-topic,DescribeConfigs,Allow,*",
+		"User:test-user,Topic,LITERAL,test-topic,Read,Allow,*",
+		"User:test-user,Group,LITERAL,*,Read,Allow,*",
+	}
+	if !reflect.DeepEqual(ACLStrings(entries), expected) {
+		t.Errorf("Expected ACLs %v, got: %v", expected, ACLStrings(entries))
+	}
+
+	// explicit group ACLs replace the wildcard group grant
+	spec.ACLs = []v1alpha1.UserACL{
+		{
+			ResourceType: v1alpha1.KafkaResourceTypeGroup,
+			ResourceName: "test-group",
+			PatternType:  v1alpha1.KafkaPatternTypePrefixed,
+			Operations:   []v1alpha1.KafkaACLOperation{v1alpha1.KafkaACLOperationRead},
+		},
+		{
+			ResourceType: v1alpha1.KafkaResourceTypeTransactionalID,
+			ResourceName: "test-tx",
+			Operations:   []v1alpha1.KafkaACLOperation{v1alpha1.KafkaACLOperationWrite, v1alpha1.KafkaACLOperationDescribe},
+		},
+		{
+			ResourceType: v1alpha1.KafkaResourceTypeCluster,
+			Operations:   []v1alpha1.KafkaACLOperation{v1alpha1.KafkaACLOperationIdempotentWrite},
+		},
+		{
+			ResourceType:   v1alpha1.KafkaResourceTypeTopic,
+			ResourceName:   "test-topic",
+			Operations:     []v1alpha1.KafkaACLOperation{v1alpha1.KafkaACLOperationRead},
+			PermissionType: v1alpha1.KafkaPermissionTypeDeny,
+			Host:           "10.0.0.1",
+		},
+	}
+	if entries, err = DesiredUserACLs("test-user", spec); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	expected = []string{
+		"User:test-user,Topic,LITERAL,test-topic,Describe,Allow,*",
+		"User:test-user,Topic,LITERAL,test-topic,DescribeConfigs,Allow,*",
+		"User:test-user,Topic,LITERAL,test-topic,Read,Allow,*",
+		"User:test-user,Group,PREFIXED,test-group,Read,Allow,*",
+		"User:test-user,TransactionalID,LITERAL,test-tx,Write,Allow,*",
+		"User:test-user,TransactionalID,LITERAL,test-tx,Describe,Allow,*",
+		"User:test-user,Cluster,LITERAL,kafka-cluster,IdempotentWrite,Allow,*",
+		"User:test-user,Topic,LITERAL,test-topic,Read,Deny,10.0.0.1",
+	}
+	if !reflect.DeepEqual(ACLStrings(entries), expected) {
+		t.Errorf("Expected ACLs %v, got: %v", expected, ACLStrings(entries))
+	}
+
+	spec.ACLs = []v1alpha1.UserACL{{ResourceType: "helloWorld", Operations: []v1alpha1.KafkaACLOperation{v1alpha1.KafkaACLOperationRead}}}
+	if _, err = DesiredUserACLs("test-user", spec); err == nil {
+		t.Error("Expected error for unknown resource type, got nil")
+	}
+	spec.ACLs = []v1alpha1.UserACL{{ResourceType: v1alpha1.KafkaResourceTypeTopic, Operations: []v1alpha1.KafkaACLOperation{"helloWorld"}}}
+	if _, err = DesiredUserACLs("test-user", spec); err == nil {
+		t.Error("Expected error for unknown operation, got nil")
+	}
+}
@@ -15,6 +15,8 @@
 package kafka
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
 	"strings"
 
@@ -22,10 +24,8 @@ import (
 	"github.com/go-logr/logr"
 
 	"github.com/banzaicloud/kafka-operator/api/v1beta1"
-	properties "github.com/banzaicloud/kafka-operator/properties/pkg"
 
 	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
-	"github.com/banzaicloud/kafka-operator/pkg/util"
 )
 
 const (
@@ -39,19 +39,12 @@ const (
 	//ConfigPropertyName name in the ConfigMap's Data field for the broker configuration
 	ConfigPropertyName            = "broker-config"
 	securityProtocolMapConfigName = "listener.security.protocol.map"
-)
-
-// PerBrokerConfigs configurations will not trigger rolling upgrade when updated
-var PerBrokerConfigs = []string{
-	// currently hardcoded in configmap.go
-	"ssl.client.auth",
 
-	// listener related config change will trigger rolling upgrade anyways due to pod spec change
-	"listeners",
-	"advertised.listeners",
-
-	securityProtocolMapConfigName,
-}
+	// BrokerNodeLabelKey label key for the pods which have the broker process role in KRaft mode
+	BrokerNodeLabelKey = "isBrokerNode"
+	// ControllerNodeLabelKey label key for the pods which are members of the KRaft controller quorum
+	ControllerNodeLabelKey = "isControllerNode"
+)
 
 // LabelsForKafka returns the labels for selecting the resources
 // belonging to the given kafka CR name.
@@ -59,78 +52,76 @@ func LabelsForKafka(name string) map[string]string {
 	return map[string]string{"app": "kafka", "kafka_cr": name}
 }
 
-// commonACLString is the raw representation of an ACL allowing Describe on a Topic
-var commonACLString = "User:%s,Topic,%s,%s,Describe,Allow,*"
-
-// createACLString is the raw representation of an ACL allowing Create on a Topic
-var createACLString = "User:%s,Topic,%s,%s,Create,Allow,*"
-
-// writeACLString is the raw representation of an ACL allowing Write on a Topic
-var writeACLString = "User:%s,Topic,%s,%s,Write,Allow,*"
-
-// readACLString is the raw representation of an ACL allowing Read on a Topic
-var readACLString = "User:%s,Topic,%s,%s,Read,Allow,*"
+// LabelsForBrokerRoles returns the process role labels of a broker pod,
+// returns nil when the cluster is not running in KRaft mode
+func LabelsForBrokerRoles(cluster *v1beta1.KafkaCluster, bConfig *v1beta1.BrokerConfig) map[string]string {
+	if !cluster.Spec.KRaftMode {
+		return nil
+	}
+	labels := make(map[string]string)
+	if bConfig.IsBrokerNode() {
+		labels[BrokerNodeLabelKey] = "true"
+	}
+	if bConfig.IsControllerNode() {
+		labels[ControllerNodeLabelKey] = "true"
+	}
+	return labels
+}
 
-// readGroupACLString is the raw representation of an ACL allowing Read on ConsumerGroups
-var readGroupACLString = "User:%s,Group,LITERAL,*,Read,Allow,*"
+// GetClusterID returns the cluster id used to format the storage of the nodes in KRaft mode.
+// When not set explicitly it is derived from the KafkaCluster UID in the format Kafka expects
+// (base64 url encoded 16 bytes)
+func GetClusterID(cluster *v1beta1.KafkaCluster) string {
+	if cluster.Spec.ClusterID != "" {
+		return cluster.Spec.ClusterID
+	}
+	sum := sha256.Sum256([]byte(cluster.GetUID()))
+	return base64.RawURLEncoding.EncodeToString(sum[:16])
+}
 
 // GrantsToACLStrings converts a user DN and a list of topic grants to raw strings
-// for a CR status
+// for a CR status, nil is returned when a grant is invalid
 func GrantsToACLStrings(dn string, grants []v1alpha1.UserTopicGrant) []string {
-	acls := make([]string, 0)
-	for _, x := range grants {
-		if x.PatternType == "" {
-			x.PatternType = v1alpha1.KafkaPatternTypeDefault
-		}
-		patternType := strings.ToUpper(string(x.PatternType))
-		cmn := fmt.Sprintf(commonACLString, dn, patternType, x.TopicName)
-		if !util.StringSliceContains(acls, cmn) {
-			acls = append(acls, cmn)
-		}
-		switch x.AccessType {
-		case v1alpha1.KafkaAccessTypeRead:
-			readACL := fmt.Sprintf(readACLString, dn, patternType, x.TopicName)
-			readGroupACL := fmt.Sprintf(readGroupACLString, dn)
-			for _, y := range []string{readACL, readGroupACL} {
-				if !util.StringSliceContains(acls, y) {
-					acls = append(acls, y)
-				}
-			}
-		case v1alpha1.KafkaAccessTypeWrite:
-			createACL := fmt.Sprintf(createACLString, dn, patternType, x.TopicName)
-			writeACL := fmt.Sprintf(writeACLString, dn, patternType, x.TopicName)
-			for _, y := range []string{createACL, writeACL} {
-				if !util.StringSliceContains(acls, y) {
-					acls = append(acls, y)
-				}
-			}
-		}
+	entries, err := DesiredUserACLs(dn, &v1alpha1.KafkaUserSpec{TopicGrants: grants})
+	if err != nil {
+		return nil
 	}
-	return acls
+	return ACLStrings(entries)
 }
 
-func ShouldRefreshOnlyPerBrokerConfigs(currentConfigs, desiredConfigs *properties.Properties, log logr.Logger) bool {
-	// Get the diff of the configuration
-	configDiff := currentConfigs.Diff(desiredConfigs)
-
-	// Return if there is no drift in the configuration
-	if len(configDiff) == 0 {
-		return true
+// EffectiveUserQuotas returns the quotas of the user with the unset values taken from
+// the cluster wide defaults, nil is returned when no quota applies to the user
+func EffectiveUserQuotas(cluster *v1beta1.KafkaCluster, user *v1alpha1.KafkaUser) *v1alpha1.UserQuotas {
+	quotas := &v1alpha1.UserQuotas{}
+	if defaults := cluster.Spec.DefaultUserQuotas; defaults != nil {
+		quotas.ProducerByteRate = defaults.ProducerByteRate
+		quotas.ConsumerByteRate = defaults.ConsumerByteRate
+		quotas.RequestPercentage = defaults.RequestPercentage
 	}
-
-	log.V(1).Info("configs have been changed", "configs", configDiff)
-
-	if diff, ok := configDiff[securityProtocolMapConfigName]; ok {
-		if listenersSecurityProtocolChanged(diff[0].Value(), diff[1].Value()) {
-			return false
+	if userQuotas := user.Spec.Quotas; userQuotas != nil {
+		if userQuotas.ProducerByteRate != nil {
+			quotas.ProducerByteRate = userQuotas.ProducerByteRate
+		}
+		if userQuotas.ConsumerByteRate != nil {
+			quotas.ConsumerByteRate = userQuotas.ConsumerByteRate
+		}
+		if userQuotas.RequestPercentage != nil {
+			quotas.RequestPercentage = userQuotas.RequestPercentage
 		}
 	}
-
-	for _, perBrokerConfig := range PerBrokerConfigs {
-		delete(configDiff, perBrokerConfig)
+	if quotas.IsEmpty() {
+		return nil
 	}
+	return quotas.DeepCopy()
+}
 
-	return len(configDiff) == 0
+// ShouldRefreshOnlyPerBrokerConfigs returns true when every changed config can be updated without restarting the broker
+func ShouldRefreshOnlyPerBrokerConfigs(changes v1beta1.ConfigChanges, log logr.Logger) bool {
+	if len(changes.ReadOnly) > 0 || len(changes.PerBroker) > 0 || len(changes.ClusterWide) > 0 || len(changes.Unknown) > 0 {
+		log.V(1).Info("configs have been changed", "readOnly", changes.ReadOnly, "perBroker", changes.PerBroker,
+			"clusterWide", changes.ClusterWide, "unknown", changes.Unknown)
+	}
+	return len(changes.ReadOnly) == 0 && len(changes.Unknown) == 0
 }
 
 // Security protocol cannot be updated for existing listener
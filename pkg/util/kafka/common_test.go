@@ -15,12 +15,14 @@
 package kafka
 
 import (
+	"reflect"
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
 	"github.com/banzaicloud/kafka-operator/api/v1beta1"
 	"github.com/banzaicloud/kafka-operator/pkg/util"
 	properties "github.com/banzaicloud/kafka-operator/properties/pkg"
@@ -77,6 +79,16 @@ ssl.client.auth=modified_value_4
 `,
 			Result: false,
 		},
+		{
+			Description: "only dynamically updatable configs changed",
+			CurrentConfigs: `log.retention.ms=1000
+listener.name.internal.ssl.cipher.suites=TLS_AES_128_GCM_SHA256
+`,
+			DesiredConfigs: `log.retention.ms=2000
+listener.name.internal.ssl.cipher.suites=TLS_AES_256_GCM_SHA384
+`,
+			Result: true,
+		},
 		{
 			Description:    "security protocol map can be changed as a per-broker config",
 			CurrentConfigs: "listener.security.protocol.map=listener1:protocol1,listener2:protocol2",
@@ -106,7 +118,8 @@ ssl.client.auth=modified_value_4
 		if err != nil {
 			t.Fatalf("failed to parse Properties from string: %s", testCase.DesiredConfigs)
 		}
-		if ShouldRefreshOnlyPerBrokerConfigs(current, desired, logger) != testCase.Result {
+		changes := testConfigUpdateModes().ClassifyConfigChanges(current, desired)
+		if ShouldRefreshOnlyPerBrokerConfigs(changes, logger) != testCase.Result {
 			t.Errorf("test case %d failed: %s", i, testCase.Description)
 		}
 	}
@@ -349,3 +362,88 @@ func TestGetBootstrapServers(t *testing.T) {
 		}
 	})
 }
+
+func TestGrantsToACLStrings(t *testing.T) {
+	grants := []v1alpha1.UserTopicGrant{
+		{TopicName: "read-topic", AccessType: v1alpha1.KafkaAccessTypeRead},
+		{TopicName: "write-", AccessType: v1alpha1.KafkaAccessTypeWrite, PatternType: v1alpha1.KafkaPatternTypePrefixed},
+		{TopicName: "other-topic", AccessType: v1alpha1.KafkaAccessTypeRead},
+	}
+	expected := []string{
+		"User:CN=test-user,Topic,LITERAL,read-topic,Describe,Allow,*",
+		"User:CN=test-user,Topic,LITERAL,read-topic,DescribeConfigs,Allow,*",
+		"User:CN=test-user,Topic,LITERAL,read-topic,Read,Allow,*",
+		"User:CN=test-user,Group,LITERAL,*,Read,Allow,*",
+		"User:CN=test-user,Topic,PREFIXED,write-,Describe,Allow,*",
+		"User:CN=test-user,Topic,PREFIXED,write-,DescribeConfigs,Allow,*",
+		"User:CN=test-user,Topic,PREFIXED,write-,Write,Allow,*",
+		"User:CN=test-user,Topic,PREFIXED,write-,Create,Allow,*",
+		"User:CN=test-user,Topic,LITERAL,other-topic,Describe,Allow,*",
+		"User:CN=test-user,Topic,LITERAL,other-topic,DescribeConfigs,Allow,*",
+		"User:CN=test-user,Topic,LITERAL,other-topic,Read,Allow,*",
+	}
+	if acls := GrantsToACLStrings("CN=test-user", grants); !reflect.DeepEqual(acls, expected) {
+		t.Errorf("Expected ACLs %v, got: %v", expected, acls)
+	}
+
+	if acls := GrantsToACLStrings("CN=test-user", nil); len(acls) != 0 {
+		t.Error("Expected no ACLs without grants, got:", acls)
+	}
+	invalid := []v1alpha1.UserTopicGrant{{TopicName: "test-topic", AccessType: "helloWorld"}}
+	if acls := GrantsToACLStrings("CN=test-user", invalid); acls != nil {
+		t.Error("Expected nil for invalid grants, got:", acls)
+	}
+}
+
+func TestEffectiveUserQuotas(t *testing.T) {
+	testCases := []struct {
+		testName   string
+		defaults   *v1beta1.UserQuotas
+		userQuotas *v1alpha1.UserQuotas
+		expected   *v1alpha1.UserQuotas
+	}{
+		{
+			testName: "no quotas",
+		},
+		{
+			testName: "cluster defaults only",
+			defaults: &v1beta1.UserQuotas{ProducerByteRate: util.Int64Pointer(1024)},
+			expected: &v1alpha1.UserQuotas{ProducerByteRate: util.Int64Pointer(1024)},
+		},
+		{
+			testName:   "user quotas only",
+			userQuotas: &v1alpha1.UserQuotas{RequestPercentage: util.Int32Pointer(25)},
+			expected:   &v1alpha1.UserQuotas{RequestPercentage: util.Int32Pointer(25)},
+		},
+		{
+			testName: "user quotas override cluster defaults",
+			defaults: &v1beta1.UserQuotas{
+				ProducerByteRate: util.Int64Pointer(1024),
+				ConsumerByteRate: util.Int64Pointer(2048),
+			},
+			userQuotas: &v1alpha1.UserQuotas{ConsumerByteRate: util.Int64Pointer(4096)},
+			expected: &v1alpha1.UserQuotas{
+				ProducerByteRate: util.Int64Pointer(1024),
+				ConsumerByteRate: util.Int64Pointer(4096),
+			},
+		},
+		{
+			testName:   "empty quotas",
+			defaults:   &v1beta1.UserQuotas{},
+			userQuotas: &v1alpha1.UserQuotas{},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			cluster := MinimalKafkaCluster.DeepCopy()
+			cluster.Spec.DefaultUserQuotas = test.defaults
+			user := &v1alpha1.KafkaUser{Spec: v1alpha1.KafkaUserSpec{Quotas: test.userQuotas}}
+
+			quotas := EffectiveUserQuotas(cluster, user)
+			if !reflect.DeepEqual(quotas, test.expected) {
+				t.Errorf("Expected quotas %+v, got %+v", test.expected, quotas)
+			}
+		})
+	}
+}
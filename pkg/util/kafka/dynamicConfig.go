@@ -0,0 +1,149 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"strings"
+
+	"github.com/Shopify/sarama"
+
+	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+	properties "github.com/banzaicloud/kafka-operator/properties/pkg"
+)
+
+const listenerConfigPrefix = "listener.name."
+
+// perBrokerConfigPrefixes and perBrokerConfigs hold the dynamic broker configs which Kafka only accepts for a
+// specific broker, every other dynamic config can also be set as a cluster wide default.
+// More info: https://kafka.apache.org/documentation/#dynamicbrokerconfigs
+var perBrokerConfigPrefixes = []string{"ssl.", "sasl."}
+
+var perBrokerConfigs = map[string]bool{
+	"listeners":                   true,
+	"advertised.listeners":        true,
+	securityProtocolMapConfigName: true,
+	"principal.builder.class":     true,
+	"connections.max.reauth.ms":   true,
+
+	"replica.alter.log.dirs.io.max.bytes.per.second": true,
+}
+
+// reassignmentManagedConfigs are set and removed on the brokers by the partition reassignments, they are not
+// updated dynamically from the broker config so that the throttles of an ongoing reassignment are kept
+var reassignmentManagedConfigs = map[string]bool{
+	"leader.replication.throttled.rate":   true,
+	"follower.replication.throttled.rate": true,
+}
+
+// ConfigUpdateModes holds the dynamic update mode of the broker configs described by a running broker
+type ConfigUpdateModes map[string]v1beta1.ConfigUpdateMode
+
+// NewConfigUpdateModes returns the dynamic update modes of the broker configs based on the config metadata
+// described by the broker. The configs holding secrets are read-only as they can only be updated dynamically
+// when the brokers have a password encoder secret.
+func NewConfigUpdateModes(entries []sarama.ConfigEntry) ConfigUpdateModes {
+	modes := make(ConfigUpdateModes, len(entries))
+	for _, entry := range entries {
+		switch {
+		case entry.ReadOnly || entry.Sensitive || reassignmentManagedConfigs[entry.Name]:
+			modes[entry.Name] = v1beta1.ConfigUpdateModeReadOnly
+		case isPerBrokerConfig(entry.Name):
+			modes[entry.Name] = v1beta1.ConfigUpdateModePerBroker
+		default:
+			modes[entry.Name] = v1beta1.ConfigUpdateModeClusterWide
+		}
+	}
+	return modes
+}
+
+func isPerBrokerConfig(key string) bool {
+	for _, prefix := range perBrokerConfigPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return perBrokerConfigs[key]
+}
+
+// Get returns the dynamic update mode of the given broker config, the listener prefixed configs have the update
+// mode of the config they override. The configs which are not described by the broker are unknown.
+func (m ConfigUpdateModes) Get(key string) (v1beta1.ConfigUpdateMode, bool) {
+	if mode, ok := m[key]; ok {
+		return mode, true
+	}
+	if strings.HasPrefix(key, listenerConfigPrefix) {
+		// listener.name.<listener>.<config>
+		parts := strings.SplitN(strings.TrimPrefix(key, listenerConfigPrefix), ".", 2)
+		if len(parts) == 2 {
+			return m.Get(parts[1])
+		}
+	}
+	return v1beta1.ConfigUpdateModeReadOnly, false
+}
+
+// GetConfigUpdateMode returns the dynamic update mode of the given broker config, unknown configs are read-only
+func (m ConfigUpdateModes) GetConfigUpdateMode(key string) v1beta1.ConfigUpdateMode {
+	mode, _ := m.Get(key)
+	return mode
+}
+
+// ClassifyConfigChanges groups the keys changed between the current and the desired broker config by their
+// dynamic update mode, the keys not described by the broker are only applied by restarting the broker
+func (m ConfigUpdateModes) ClassifyConfigChanges(currentConfigs, desiredConfigs *properties.Properties) v1beta1.ConfigChanges {
+	var changes v1beta1.ConfigChanges
+	configDiff := currentConfigs.Diff(desiredConfigs)
+	for _, key := range configDiff.Keys() {
+		mode, ok := m.Get(key)
+		if !ok {
+			changes.Unknown = append(changes.Unknown, key)
+			continue
+		}
+		// security protocol cannot be updated for an existing listener
+		if key == securityProtocolMapConfigName {
+			diff := configDiff[key]
+			if listenersSecurityProtocolChanged(diff[0].Value(), diff[1].Value()) {
+				mode = v1beta1.ConfigUpdateModeReadOnly
+			}
+		}
+		switch mode {
+		case v1beta1.ConfigUpdateModePerBroker:
+			changes.PerBroker = append(changes.PerBroker, key)
+		case v1beta1.ConfigUpdateModeClusterWide:
+			changes.ClusterWide = append(changes.ClusterWide, key)
+		default:
+			changes.ReadOnly = append(changes.ReadOnly, key)
+		}
+	}
+	return changes
+}
+
+// DynamicConfigs returns the configs having the given dynamic update mode
+func (m ConfigUpdateModes) DynamicConfigs(configs *properties.Properties, mode v1beta1.ConfigUpdateMode) *properties.Properties {
+	dynamicConfigs := properties.NewProperties()
+	for _, key := range configs.Keys() {
+		if m.GetConfigUpdateMode(key) != mode {
+			continue
+		}
+		if property, ok := configs.Get(key); ok {
+			dynamicConfigs.Put(property)
+		}
+	}
+	return dynamicConfigs
+}
+
+// IsReassignmentManagedConfig returns true when the broker config is managed by the partition reassignments
+func IsReassignmentManagedConfig(key string) bool {
+	return reassignmentManagedConfigs[key]
+}
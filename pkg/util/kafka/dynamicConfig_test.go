@@ -0,0 +1,119 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Shopify/sarama"
+
+	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+	properties "github.com/banzaicloud/kafka-operator/properties/pkg"
+)
+
+// testConfigUpdateModes returns the update modes of the configs as described by a broker
+func testConfigUpdateModes() ConfigUpdateModes {
+	return NewConfigUpdateModes([]sarama.ConfigEntry{
+		{Name: "broker.id", ReadOnly: true},
+		{Name: "log.dirs", ReadOnly: true},
+		{Name: "ssl.keystore.password", Sensitive: true},
+		{Name: "ssl.client.auth"},
+		{Name: "ssl.cipher.suites"},
+		{Name: "listener.security.protocol.map"},
+		{Name: "log.retention.ms"},
+		{Name: "max.connections"},
+		{Name: "num.io.threads"},
+		{Name: "leader.replication.throttled.rate"},
+	})
+}
+
+func TestGetConfigUpdateMode(t *testing.T) {
+	tests := map[string]v1beta1.ConfigUpdateMode{
+		"ssl.client.auth":                          v1beta1.ConfigUpdateModePerBroker,
+		"listener.name.internal.ssl.cipher.suites": v1beta1.ConfigUpdateModePerBroker,
+		"log.retention.ms":                         v1beta1.ConfigUpdateModeClusterWide,
+		"listener.name.external.max.connections":   v1beta1.ConfigUpdateModeClusterWide,
+		"ssl.keystore.password":                    v1beta1.ConfigUpdateModeReadOnly,
+		"broker.id":                                v1beta1.ConfigUpdateModeReadOnly,
+		"leader.replication.throttled.rate":        v1beta1.ConfigUpdateModeReadOnly,
+		"listener.name.internal":                   v1beta1.ConfigUpdateModeReadOnly,
+		"custom.plugin.config":                     v1beta1.ConfigUpdateModeReadOnly,
+	}
+	modes := testConfigUpdateModes()
+	for key, expected := range tests {
+		if mode := modes.GetConfigUpdateMode(key); mode != expected {
+			t.Errorf("expected update mode of %s to be %s, got: %s", key, expected, mode)
+		}
+	}
+	if _, ok := modes.Get("custom.plugin.config"); ok {
+		t.Error("Expected the config not described by the broker to be unknown")
+	}
+}
+
+func TestClassifyConfigChanges(t *testing.T) {
+	current, err := properties.NewFromString(`broker.id=0
+log.dirs=/kafka-logs/kafka
+log.retention.ms=1000
+listener.security.protocol.map=INTERNAL:PLAINTEXT
+ssl.client.auth=none
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	desired, err := properties.NewFromString(`broker.id=0
+log.dirs=/kafka-logs/kafka,/kafka-logs2/kafka
+log.retention.ms=2000
+listener.security.protocol.map=INTERNAL:SSL
+ssl.client.auth=required
+num.io.threads=16
+custom.plugin.config=value
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := v1beta1.ConfigChanges{
+		ReadOnly:    []string{"listener.security.protocol.map", "log.dirs"},
+		PerBroker:   []string{"ssl.client.auth"},
+		ClusterWide: []string{"log.retention.ms", "num.io.threads"},
+		Unknown:     []string{"custom.plugin.config"},
+	}
+	if changes := testConfigUpdateModes().ClassifyConfigChanges(current, desired); !reflect.DeepEqual(changes, expected) {
+		t.Errorf("expected config changes %+v, got: %+v", expected, changes)
+	}
+	// every change is unknown when the brokers could not be described
+	var modes ConfigUpdateModes
+	if changes := modes.ClassifyConfigChanges(current, desired); len(changes.Unknown) != 6 {
+		t.Error("Expected every changed config to be unknown, got:", changes)
+	}
+}
+
+func TestDynamicConfigs(t *testing.T) {
+	config, err := properties.NewFromString(`broker.id=0
+log.retention.ms=1000
+ssl.client.auth=none
+num.io.threads=16
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	modes := testConfigUpdateModes()
+	if keys := modes.DynamicConfigs(config, v1beta1.ConfigUpdateModeClusterWide).Keys(); !reflect.DeepEqual(keys, []string{"log.retention.ms", "num.io.threads"}) {
+		t.Error("Expected the cluster wide configs to be returned, got:", keys)
+	}
+	if keys := modes.DynamicConfigs(config, v1beta1.ConfigUpdateModePerBroker).Keys(); !reflect.DeepEqual(keys, []string{"ssl.client.auth"}) {
+		t.Error("Expected the per-broker configs to be returned, got:", keys)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"emperror.dev/errors"
+)
+
+type topicConfigType int
+
+const (
+	topicConfigString topicConfigType = iota
+	topicConfigInt
+	topicConfigLong
+	topicConfigDouble
+	topicConfigBoolean
+	topicConfigList
+)
+
+// topicConfigDefinition describes the type and the valid values of a topic configuration
+type topicConfigDefinition struct {
+	configType topicConfigType
+	// validValues restricts the value (or the elements of a list) to the given ones when set
+	validValues []string
+}
+
+// topicConfigDefinitions holds the topic level configurations supported by kafka.
+// More info: https://kafka.apache.org/documentation/#topicconfigs
+var topicConfigDefinitions = map[string]topicConfigDefinition{
+	"cleanup.policy":       {configType: topicConfigList, validValues: []string{"compact", "delete"}},
+	"compression.type":     {configType: topicConfigString, validValues: []string{"uncompressed", "zstd", "lz4", "snappy", "gzip", "producer"}},
+	"delete.retention.ms":  {configType: topicConfigLong},
+	"file.delete.delay.ms": {configType: topicConfigLong},
+	"flush.messages":       {configType: topicConfigLong},
+	"flush.ms":             {configType: topicConfigLong},
+	"follower.replication.throttled.replicas": {configType: topicConfigList},
+	"index.interval.bytes":                    {configType: topicConfigInt},
+	"leader.replication.throttled.replicas":   {configType: topicConfigList},
+	"max.compaction.lag.ms":                   {configType: topicConfigLong},
+	"max.message.bytes":                       {configType: topicConfigInt},
+	"message.downconversion.enable":           {configType: topicConfigBoolean},
+	"message.format.version":                  {configType: topicConfigString},
+	"message.timestamp.difference.max.ms":     {configType: topicConfigLong},
+	"message.timestamp.type":                  {configType: topicConfigString, validValues: []string{"CreateTime", "LogAppendTime"}},
+	"min.cleanable.dirty.ratio":               {configType: topicConfigDouble},
+	"min.compaction.lag.ms":                   {configType: topicConfigLong},
+	"min.insync.replicas":                     {configType: topicConfigInt},
+	"preallocate":                             {configType: topicConfigBoolean},
+	"retention.bytes":                         {configType: topicConfigLong},
+	"retention.ms":                            {configType: topicConfigLong},
+	"segment.bytes":                           {configType: topicConfigInt},
+	"segment.index.bytes":                     {configType: topicConfigInt},
+	"segment.jitter.ms":                       {configType: topicConfigLong},
+	"segment.ms":                              {configType: topicConfigLong},
+	"unclean.leader.election.enable":          {configType: topicConfigBoolean},
+}
+
+// ValidateTopicConfig checks that every key of the configuration is a known topic configuration
+// and that its value can be parsed as the type kafka expects
+func ValidateTopicConfig(config map[string]string) error {
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var combined error
+	for _, key := range keys {
+		definition, ok := topicConfigDefinitions[key]
+		if !ok {
+			combined = errors.Append(combined, fmt.Errorf("unknown topic config %q", key))
+			continue
+		}
+		if err := definition.validate(config[key]); err != nil {
+			combined = errors.Append(combined, errors.WrapIf(err, fmt.Sprintf("invalid value of topic config %q", key)))
+		}
+	}
+	return combined
+}
+
+func (d topicConfigDefinition) validate(value string) error {
+	var err error
+	switch d.configType {
+	case topicConfigInt:
+		_, err = strconv.ParseInt(value, 10, 32)
+	case topicConfigLong:
+		_, err = strconv.ParseInt(value, 10, 64)
+	case topicConfigDouble:
+		_, err = strconv.ParseFloat(value, 64)
+	case topicConfigBoolean:
+		if lower := strings.ToLower(value); lower != "true" && lower != "false" {
+			err = fmt.Errorf("%q is not a boolean", value)
+		}
+	case topicConfigList:
+		for _, element := range strings.Split(value, ",") {
+			if err = d.validateValue(strings.TrimSpace(element)); err != nil {
+				break
+			}
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	return d.validateValue(value)
+}
+
+func (d topicConfigDefinition) validateValue(value string) error {
+	if len(d.validValues) == 0 {
+		return nil
+	}
+	for _, valid := range d.validValues {
+		if value == valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("%q is not one of %s", value, strings.Join(d.validValues, ", "))
+}
@@ -0,0 +1,74 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"testing"
+)
+
+func TestValidateTopicConfig(t *testing.T) {
+	testCases := []struct {
+		Description string
+		Config      map[string]string
+		Valid       bool
+	}{
+		{
+			Description: "valid config",
+			Config: map[string]string{
+				"cleanup.policy":                 "compact,delete",
+				"compression.type":               "zstd",
+				"retention.ms":                   "-1",
+				"min.cleanable.dirty.ratio":      "0.5",
+				"unclean.leader.election.enable": "False",
+				"max.message.bytes":              "1048588",
+			},
+			Valid: true,
+		},
+		{
+			Description: "unknown key",
+			Config:      map[string]string{"retention.msec": "1000"},
+		},
+		{
+			Description: "invalid long",
+			Config:      map[string]string{"retention.ms": "one week"},
+		},
+		{
+			Description: "int out of range",
+			Config:      map[string]string{"max.message.bytes": "4294967296"},
+		},
+		{
+			Description: "invalid boolean",
+			Config:      map[string]string{"preallocate": "yes"},
+		},
+		{
+			Description: "invalid list element",
+			Config:      map[string]string{"cleanup.policy": "compact,archive"},
+		},
+		{
+			Description: "invalid enum",
+			Config:      map[string]string{"message.timestamp.type": "ProduceTime"},
+		},
+	}
+
+	for _, test := range testCases {
+		err := ValidateTopicConfig(test.Config)
+		if test.Valid && err != nil {
+			t.Errorf("%s: expected no error, got: %v", test.Description, err)
+		}
+		if !test.Valid && err == nil {
+			t.Errorf("%s: expected error, got nil", test.Description)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"emperror.dev/errors"
+)
+
+// the Kafka version is the last dash separated part of the image tag, e.g. ghcr.io/banzaicloud/kafka:2.13-2.8.0
+var imageKafkaVersionRegex = regexp.MustCompile(`^\d+\.\d+(\.\d+)?$`)
+
+// VersionFromImage returns the Kafka version in the tag of the given image or an empty string
+// if the tag does not end in a version
+func VersionFromImage(image string) string {
+	// the registry can contain a port, the tag follows the last path segment
+	name := image[strings.LastIndex(image, "/")+1:]
+	if i := strings.Index(name, "@"); i >= 0 {
+		name = name[:i]
+	}
+	i := strings.LastIndex(name, ":")
+	if i < 0 {
+		return ""
+	}
+	parts := strings.Split(name[i+1:], "-")
+	version := parts[len(parts)-1]
+	if !imageKafkaVersionRegex.MatchString(version) {
+		return ""
+	}
+	return version
+}
+
+// CompareVersions compares the given Kafka versions, it returns -1, 0 or 1 when a is older than,
+// the same as or newer than b, missing trailing parts are treated as zero
+func CompareVersions(a, b string) (int, error) {
+	aParts, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	bParts, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var aPart, bPart int
+		if i < len(aParts) {
+			aPart = aParts[i]
+		}
+		if i < len(bParts) {
+			bPart = bParts[i]
+		}
+		switch {
+		case aPart < bPart:
+			return -1, nil
+		case aPart > bPart:
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+// ProtocolVersion returns the inter.broker.protocol.version of the given Kafka version
+func ProtocolVersion(version string) string {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return version
+	}
+	return parts[0] + "." + parts[1]
+}
+
+func parseVersion(version string) ([]int, error) {
+	// pre-release and build suffixes like 2.8.0-SNAPSHOT are ignored
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	parts := strings.Split(version, ".")
+	parsed := make([]int, 0, len(parts))
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "invalid Kafka version", "version", version)
+		}
+		parsed = append(parsed, n)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,69 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import "testing"
+
+func TestVersionFromImage(t *testing.T) {
+	tests := map[string]string{
+		"ghcr.io/banzaicloud/kafka:2.13-2.8.0":       "2.8.0",
+		"registry:5000/banzaicloud/kafka:2.12-2.7.1": "2.7.1",
+		"kafka:3.0":                 "3.0",
+		"kafka:2.13-2.8.0@sha256:1": "2.8.0",
+		"kafka:latest":              "",
+		"kafka":                     "",
+		"registry:5000/kafka":       "",
+	}
+	for image, expected := range tests {
+		if version := VersionFromImage(image); version != expected {
+			t.Errorf("image %s: expected %q, got %q", image, expected, version)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"2.8.0", "2.8.0", 0},
+		{"2.8", "2.8.0", 0},
+		{"2.7.1", "2.8.0", -1},
+		{"2.10.0", "2.8.1", 1},
+		{"3.0.0-SNAPSHOT", "2.8.1", 1},
+	}
+	for _, test := range tests {
+		result, err := CompareVersions(test.a, test.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result != test.expected {
+			t.Errorf("%s compared to %s: expected %d, got %d", test.a, test.b, test.expected, result)
+		}
+	}
+
+	if _, err := CompareVersions("2.x", "2.8.0"); err == nil {
+		t.Error("expected an error for an invalid version")
+	}
+}
+
+func TestProtocolVersion(t *testing.T) {
+	if version := ProtocolVersion("2.8.1"); version != "2.8" {
+		t.Errorf("expected 2.8, got %s", version)
+	}
+	if version := ProtocolVersion("3"); version != "3" {
+		t.Errorf("expected 3, got %s", version)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package webhook
+
+import (
+	"fmt"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+	"github.com/banzaicloud/kafka-operator/pkg/k8sutil"
+	ccutils "github.com/banzaicloud/kafka-operator/pkg/util/cruisecontrol"
+)
+
+const missingZKAddressesErrMsg = "ZooKeeper addresses have to be given unless the cluster runs in KRaft mode"
+
+func validateKafkaCluster(cluster *v1beta1.KafkaCluster) *admissionv1beta1.AdmissionResponse {
+	log.Info(fmt.Sprintf("Doing pre-admission validation of kafka cluster %s", cluster.Name))
+
+	if k8sutil.IsMarkedForDeletion(cluster.ObjectMeta) {
+		return &admissionv1beta1.AdmissionResponse{
+			Allowed: true,
+		}
+	}
+
+	if !cluster.Spec.KRaftMode && len(cluster.Spec.ZKAddresses) == 0 {
+		return notAllowed(missingZKAddressesErrMsg, metav1.StatusReasonInvalid)
+	}
+
+	if err := ccutils.ValidateGoals(cluster.Spec.CruiseControlConfig.Goals); err != nil {
+		return notAllowed(fmt.Sprintf("Invalid cruise control goals: %s", err), metav1.StatusReasonInvalid)
+	}
+
+	return &admissionv1beta1.AdmissionResponse{
+		Allowed: true,
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright © 2021 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package webhook
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/kafka-operator/api/v1beta1"
+)
+
+func TestValidateKafkaCluster(t *testing.T) {
+	cluster := newMockCluster()
+
+	if res := validateKafkaCluster(cluster); res.Allowed {
+		t.Error("Expected not allowed cluster without ZooKeeper addresses, got allowed")
+	} else if res.Result.Reason != metav1.StatusReasonInvalid {
+		t.Error("Expected invalid cluster, got:", res.Result.Reason)
+	}
+
+	cluster.Spec.ZKAddresses = []string{"zookeeper-client.zookeeper:2181"}
+	if res := validateKafkaCluster(cluster); !res.Allowed {
+		t.Error("Expected allowed cluster with ZooKeeper addresses, got:", res.Result.Message)
+	}
+
+	// ZooKeeper is not used in KRaft mode
+	cluster.Spec.ZKAddresses = nil
+	cluster.Spec.KRaftMode = true
+	if res := validateKafkaCluster(cluster); !res.Allowed {
+		t.Error("Expected allowed cluster in KRaft mode, got:", res.Result.Message)
+	}
+
+	cluster.Spec.CruiseControlConfig.Goals = &v1beta1.CruiseControlGoalsConfig{
+		Goals:     []string{"RackAwareGoal", "com.example.CustomGoal"},
+		HardGoals: []string{"com.linkedin.kafka.cruisecontrol.analyzer.goals.RackAwareGoal", "com.example.CustomGoal"},
+	}
+	if res := validateKafkaCluster(cluster); !res.Allowed {
+		t.Error("Expected allowed cluster with known and custom goals, got:", res.Result.Message)
+	}
+
+	cluster.Spec.CruiseControlConfig.Goals.DefaultGoals = []string{"UnknownGoal"}
+	if res := validateKafkaCluster(cluster); res.Allowed {
+		t.Error("Expected not allowed cluster with unknown goal, got allowed")
+	}
+
+	cluster.Spec.CruiseControlConfig.Goals.DefaultGoals = []string{"CpuCapacityGoal"}
+	if res := validateKafkaCluster(cluster); res.Allowed {
+		t.Error("Expected not allowed cluster with default goal missing from the goals, got allowed")
+	}
+}
@@ -26,10 +26,12 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/banzaicloud/kafka-operator/api/v1alpha1"
+	"github.com/banzaicloud/kafka-operator/api/v1beta1"
 )
 
 var (
-	kafkaTopic = reflect.TypeOf(v1alpha1.KafkaTopic{}).Name()
+	kafkaTopic   = reflect.TypeOf(v1alpha1.KafkaTopic{}).Name()
+	kafkaCluster = reflect.TypeOf(v1beta1.KafkaCluster{}).Name()
 )
 
 func (s *webhookServer) validate(ar *admissionv1beta1.AdmissionReview) *admissionv1beta1.AdmissionResponse {
@@ -47,6 +49,14 @@ func (s *webhookServer) validate(ar *admissionv1beta1.AdmissionReview) *admissio
 		}
 		return s.validateKafkaTopic(&topic)
 
+	case kafkaCluster:
+		var cluster v1beta1.KafkaCluster
+		if err := json.Unmarshal(req.Object.Raw, &cluster); err != nil {
+			log.Error(err, "Could not unmarshal raw object")
+			return notAllowed(err.Error(), metav1.StatusReasonBadRequest)
+		}
+		return validateKafkaCluster(&cluster)
+
 	default:
 		return notAllowed(fmt.Sprintf("Unexpected resource kind: %s", req.Kind.Kind), metav1.StatusReasonBadRequest)
 	}
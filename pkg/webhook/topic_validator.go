@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/Shopify/sarama"
 	admissionv1beta1 "k8s.io/api/admission/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -27,6 +28,7 @@ import (
 	banzaicloudv1alpha1 "github.com/banzaicloud/kafka-operator/api/v1alpha1"
 	banzaicloudv1beta1 "github.com/banzaicloud/kafka-operator/api/v1beta1"
 	"github.com/banzaicloud/kafka-operator/pkg/k8sutil"
+	kafkautil "github.com/banzaicloud/kafka-operator/pkg/util/kafka"
 )
 
 const (
@@ -93,7 +95,11 @@ func (s *webhookServer) validateKafkaTopic(topic *v1alpha1.KafkaTopic) (res *adm
 		// Check if this is the correct CR for this topic
 		topicCR := &banzaicloudv1alpha1.KafkaTopic{}
 		if err := s.client.Get(context.TODO(), types.NamespacedName{Name: topic.Name, Namespace: topic.Namespace}, topicCR); err != nil {
-			if apierrors.IsNotFound(err) {
+			if !apierrors.IsNotFound(err) {
+				log.Error(err, "API failure while running topic validation")
+				return notAllowed("API failure while validating topic, please try again", metav1.StatusReasonServiceUnavailable)
+			}
+			if !topic.IsAdoptionRequested() {
 				// User is trying to overwrite an existing topic - bad user
 				log.Info("User attempted to create topic with name that already exists in the kafka cluster")
 				return notAllowed(
@@ -101,8 +107,9 @@ func (s *webhookServer) validateKafkaTopic(topic *v1alpha1.KafkaTopic) (res *adm
 					metav1.StatusReasonAlreadyExists,
 				)
 			}
-			log.Error(err, "API failure while running topic validation")
-			return notAllowed("API failure while validating topic, please try again", metav1.StatusReasonServiceUnavailable)
+			if res := s.validateTopicAdoption(topic, cluster, existing); res != nil {
+				return res
+			}
 		}
 
 		// make sure the user isn't trying to decrease partition count
@@ -111,20 +118,94 @@ func (s *webhookServer) validateKafkaTopic(topic *v1alpha1.KafkaTopic) (res *adm
 			return notAllowed("Kafka does not support decreasing partition count on an existing topic", metav1.StatusReasonInvalid)
 		}
 
-		// check if the user is trying to change the replication factor
-		if existing.ReplicationFactor != int16(topic.Spec.ReplicationFactor) {
-			log.Info(fmt.Sprintf("Spec is requesting replication factor change from %v to %v, rejecting", existing.ReplicationFactor, topic.Spec.ReplicationFactor))
-			return notAllowed("Kafka does not support changing the replication factor on an existing topic", metav1.StatusReasonInvalid)
-		}
+		// replication factor changes are carried out by reassigning the partitions of the topic
+	}
 
-		// the topic does not exist check if requesting a replication factor larger than the broker size
-	} else if int(topic.Spec.ReplicationFactor) > broker.NumBrokers() {
+	// check if the topic config contains only known configurations with valid values
+	if err := kafkautil.ValidateTopicConfig(topic.Spec.Config); err != nil {
+		log.Info(fmt.Sprintf("Spec is requesting an invalid topic config: %s", err))
+		return notAllowed(fmt.Sprintf("Invalid topic config: %s", err), metav1.StatusReasonBadRequest)
+	}
+
+	// check if requesting a replication factor larger than the broker size
+	if int(topic.Spec.ReplicationFactor) > broker.NumBrokers() {
 		log.Info(fmt.Sprintf("Spec is requesting replication factor of %v, larger than cluster size of %v", topic.Spec.ReplicationFactor, broker.NumBrokers()))
 		return notAllowed(invalidReplicationFactorErrMsg, metav1.StatusReasonBadRequest)
 	}
 
+	// check if the explicit partition assignments are valid
+	if msg := validatePartitionAssignments(topic, broker.Brokers()); msg != "" {
+		log.Info(fmt.Sprintf("Spec is requesting an invalid partition assignment: %s", msg))
+		return notAllowed(msg, metav1.StatusReasonBadRequest)
+	}
+
 	// everything looks a-okay
 	return &admissionv1beta1.AdmissionResponse{
 		Allowed: true,
 	}
 }
+
+// validatePartitionAssignments returns the reason the partition assignments of the topic are
+// rejected for, an empty string is returned when they are valid
+func validatePartitionAssignments(topic *v1alpha1.KafkaTopic, brokers map[int32]string) string {
+	partitions := make(map[int32]struct{}, len(topic.Spec.PartitionAssignments))
+	for _, assignment := range topic.Spec.PartitionAssignments {
+		if assignment.Partition >= topic.Spec.Partitions {
+			return fmt.Sprintf("Partition %d of the assignment does not exist", assignment.Partition)
+		}
+		if _, ok := partitions[assignment.Partition]; ok {
+			return fmt.Sprintf("Partition %d is assigned multiple times", assignment.Partition)
+		}
+		partitions[assignment.Partition] = struct{}{}
+		if len(assignment.Replicas) != int(topic.Spec.ReplicationFactor) {
+			return fmt.Sprintf("Partition %d is assigned to %d replicas instead of the replication factor %d",
+				assignment.Partition, len(assignment.Replicas), topic.Spec.ReplicationFactor)
+		}
+		replicas := make(map[int32]struct{}, len(assignment.Replicas))
+		for _, id := range assignment.Replicas {
+			if _, ok := brokers[id]; !ok {
+				return fmt.Sprintf("Broker %d of the assignment of partition %d does not exist", id, assignment.Partition)
+			}
+			if _, ok := replicas[id]; ok {
+				return fmt.Sprintf("Broker %d is assigned multiple times to partition %d", id, assignment.Partition)
+			}
+			replicas[id] = struct{}{}
+		}
+	}
+	return ""
+}
+
+// validateTopicAdoption checks if a KafkaTopic can take ownership of an existing topic, nil is returned
+// when the topic is not managed by another KafkaTopic and its layout matches the spec
+func (s *webhookServer) validateTopicAdoption(topic *v1alpha1.KafkaTopic, cluster *banzaicloudv1beta1.KafkaCluster, existing *sarama.TopicDetail) *admissionv1beta1.AdmissionResponse {
+	topics := &v1alpha1.KafkaTopicList{}
+	if err := s.client.List(context.TODO(), topics); err != nil {
+		log.Error(err, "API failure while running topic validation")
+		return notAllowed("API failure while validating topic, please try again", metav1.StatusReasonServiceUnavailable)
+	}
+	for _, other := range topics.Items {
+		otherClusterNamespace := other.Spec.ClusterRef.Namespace
+		if otherClusterNamespace == "" {
+			otherClusterNamespace = other.Namespace
+		}
+		if other.Spec.Name == topic.Spec.Name && other.Spec.ClusterRef.Name == cluster.Name && otherClusterNamespace == cluster.Namespace {
+			log.Info("User attempted to adopt a topic which is already managed by another KafkaTopic")
+			return notAllowed(
+				fmt.Sprintf("Topic '%s' is already managed by KafkaTopic '%s' in the namespace '%s'", topic.Spec.Name, other.Name, other.Namespace),
+				metav1.StatusReasonAlreadyExists,
+			)
+		}
+	}
+
+	if existing.NumPartitions != topic.Spec.Partitions || existing.ReplicationFactor != int16(topic.Spec.ReplicationFactor) {
+		log.Info(fmt.Sprintf("Spec of the adopted topic does not match the existing topic with %v partitions and replication factor %v",
+			existing.NumPartitions, existing.ReplicationFactor))
+		return notAllowed(
+			fmt.Sprintf("Topic '%s' can only be adopted with its current partition count %d and replication factor %d",
+				topic.Spec.Name, existing.NumPartitions, existing.ReplicationFactor),
+			metav1.StatusReasonInvalid,
+		)
+	}
+	log.Info(fmt.Sprintf("Adopting existing topic %s", topic.Spec.Name))
+	return nil
+}
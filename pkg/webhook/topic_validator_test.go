@@ -129,11 +129,36 @@ func TestValidateTopic(t *testing.T) {
 		t.Error("Expected not allowed for reason already exists, got:", res.Result)
 	}
 
+	// Adoption of the existing topic with different layout
+	topic.SetAnnotations(map[string]string{v1alpha1.AdoptTopicAnnotation: "true"})
+	topic.Spec.Partitions = 3
+	if res = server.validateKafkaTopic(topic); res.Allowed {
+		t.Error("Expected not allowed due to adoption with different partition count, got allowed")
+	} else if res.Result.Reason != metav1.StatusReasonInvalid {
+		t.Error("Expected invalid status reason, got:", res.Result)
+	}
+
+	// Adoption of the existing topic
+	topic.Spec.Partitions = 2
+	if res = server.validateKafkaTopic(topic); !res.Allowed {
+		t.Error("Expected allowed for adoption of existing topic, got:", res.Result)
+	}
+
 	// Add topic and test existing topic reason
 	if err := server.client.Create(context.TODO(), topic); err != nil {
 		t.Error("Expected no error, got:", err)
 	}
 
+	// Adoption of a topic which is already managed by another KafkaTopic
+	other := topic.DeepCopy()
+	other.Name = "other-topic"
+	other.ResourceVersion = ""
+	if res = server.validateKafkaTopic(other); res.Allowed {
+		t.Error("Expected not allowed due to adoption of already managed topic, got allowed")
+	} else if res.Result.Reason != metav1.StatusReasonAlreadyExists {
+		t.Error("Expected not allowed for reason already exists, got:", res.Result)
+	}
+
 	// partition decrease attempt
 	topic.Spec.Partitions = 1
 	if res = server.validateKafkaTopic(topic); res.Allowed {
@@ -142,12 +167,45 @@ func TestValidateTopic(t *testing.T) {
 		t.Error("Expected invalid status reason, got:", res.Result)
 	}
 
-	// replication factor change attempt
+	// replication factor increase beyond the cluster size
 	topic.Spec.Partitions = 2
 	topic.Spec.ReplicationFactor = 2
 	if res = server.validateKafkaTopic(topic); res.Allowed {
-		t.Error("Expected not allowed due to replication factor change, got allowed")
-	} else if res.Result.Reason != metav1.StatusReasonInvalid {
-		t.Error("Expected invalid status reason, got:", res.Result)
+		t.Error("Expected not allowed due to replication factor larger than the cluster size, got allowed")
+	} else if res.Result.Reason != metav1.StatusReasonBadRequest {
+		t.Error("Expected bad request status reason, got:", res.Result)
+	}
+
+	// invalid topic config
+	topic.Spec.ReplicationFactor = 1
+	topic.Spec.Config = map[string]string{"retention.ms": "one week"}
+	if res = server.validateKafkaTopic(topic); res.Allowed {
+		t.Error("Expected not allowed due to invalid topic config, got allowed")
+	} else if res.Result.Reason != metav1.StatusReasonBadRequest {
+		t.Error("Expected bad request status reason, got:", res.Result)
+	}
+	topic.Spec.Config = nil
+
+	// valid partition assignment
+	topic.Spec.ReplicationFactor = 1
+	topic.Spec.PartitionAssignments = []v1alpha1.PartitionAssignment{{Partition: 1, Replicas: []int32{0}}}
+	if res = server.validateKafkaTopic(topic); !res.Allowed {
+		t.Error("Expected allowed for valid partition assignment, got:", res.Result)
+	}
+
+	// partition assignment with unknown broker
+	topic.Spec.PartitionAssignments = []v1alpha1.PartitionAssignment{{Partition: 1, Replicas: []int32{3}}}
+	if res = server.validateKafkaTopic(topic); res.Allowed {
+		t.Error("Expected not allowed due to unknown broker, got allowed")
+	} else if res.Result.Reason != metav1.StatusReasonBadRequest {
+		t.Error("Expected bad request status reason, got:", res.Result)
+	}
+
+	// partition assignment of unknown partition
+	topic.Spec.PartitionAssignments = []v1alpha1.PartitionAssignment{{Partition: 2, Replicas: []int32{0}}}
+	if res = server.validateKafkaTopic(topic); res.Allowed {
+		t.Error("Expected not allowed due to unknown partition, got allowed")
+	} else if res.Result.Reason != metav1.StatusReasonBadRequest {
+		t.Error("Expected bad request status reason, got:", res.Result)
 	}
 }
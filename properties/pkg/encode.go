@@ -97,6 +97,14 @@ func marshal(v interface{}) (*Properties, error) {
 		if vFieldValue.IsZero() && st.OmitEmpty {
 			continue
 		}
+
+		// Nil pointers are never rendered, the others are rendered by the value they point to
+		if vFieldValue.Kind() == reflect.Ptr {
+			if vFieldValue.IsNil() {
+				continue
+			}
+			vFieldValue = vFieldValue.Elem()
+		}
 		err = properties.Set(st.Key, vFieldValue.Interface())
 		if err != nil {
 			return nil, errors.WithDetails(err, "key", st.Key, "value", vFieldValue.Interface())
@@ -40,6 +40,14 @@ type TestStructWithInvalidListField struct {
 	InvalidListField []int `properties:"list.field"`
 }
 
+type TestStructWithPointerFields struct {
+	BoolField     *bool  `properties:"bool.field"`
+	IntField      *int64 `properties:"int.field,omitempty"`
+	NilField      *int64 `properties:"nil.field"`
+	OmitNilField  *bool  `properties:"omitempty.field,omitempty"`
+	StringPointer *string
+}
+
 type TestMarshalerStruct struct {
 	StringField string   `properties:"string.field"`
 	IntField    int64    `properties:"int.field"`
@@ -169,6 +177,23 @@ func TestMarshal(t *testing.T) {
 		g.Expect(err).Should(HaveOccurred())
 	})
 
+	t.Run("Struct with pointer fields", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		disabled, value := false, int64(100)
+		s := TestStructWithPointerFields{BoolField: &disabled, IntField: &value}
+
+		p, err := Marshal(s)
+
+		g.Expect(err).Should(Succeed())
+
+		expected := NewProperties()
+		_ = expected.Set("bool.field", false)
+		_ = expected.Set("int.field", 100)
+
+		g.Expect(p).Should(Equal(expected))
+	})
+
 	t.Run("Struct implementing Marshaler interface", func(t *testing.T) {
 		g := NewGomegaWithT(t)
 